@@ -0,0 +1,19 @@
+package v1
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// openAPISpec is the OpenAPI 3 document describing the loan endpoints. It is maintained by hand
+// alongside the routes and handlers, so changes to either should be reflected in it.
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// serveOpenAPISpec responds with the embedded OpenAPI document
+func serveOpenAPISpec(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", openAPISpec)
+}
@@ -11,37 +11,117 @@ import (
 )
 
 // SetupRoutes configures all API routes
-func SetupRoutes(router *gin.Engine, db *gorm.DB) {
+func SetupRoutes(router *gin.Engine, db *gorm.DB, loanService service.LoanService, autoInvestService service.AutoInvestService, statsService service.StatsService, investorService service.InvestorService, webhookService service.WebhookService) {
 	// Add middleware
+	router.Use(middleware.RequestID())
 	router.Use(middleware.Recovery())
 	router.Use(middleware.Logger())
 	router.Use(middleware.CORS())
 
-	// Health check
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "ok", "service": "loan-service"})
-	})
-
 	// Initialize dependencies
-	loanRepo := repository.NewLoanRepository(db)
-	loanService := service.NewLoanService(loanRepo)
+	healthHandler := handler.NewHealthHandler(db)
+	rateLimit := middleware.WriteRateLimit()
+	idempotency := middleware.Idempotency(repository.NewIdempotencyRepository(db))
 	loanHandler := handler.NewLoanHandler(loanService)
+	autoInvestHandler := handler.NewAutoInvestHandler(autoInvestService)
+	statsHandler := handler.NewStatsHandler(statsService)
+	investorHandler := handler.NewInvestorHandler(investorService)
+	webhookHandler := handler.NewWebhookHandler(webhookService)
+
+	// Health checks: liveness and readiness
+	router.GET("/health", healthHandler.Live)
+	router.GET("/health/ready", healthHandler.Ready)
+
+	// API contract, public so integrators can fetch it without a token
+	router.GET("/api/v1/openapi.json", serveOpenAPISpec)
 
 	// API routes
-	api := router.Group("/api/v1")
+	api := router.Group("/api/v1", middleware.Auth())
 	{
 		// Loan routes
 		loans := api.Group("/loans")
 		{
 			loans.GET("/", loanHandler.GetLoans)
+			loans.GET("/stats", loanHandler.GetLoanStats)
+			loans.GET("/export.csv", loanHandler.ExportLoansCSV)
+			loans.GET("/deleted", loanHandler.GetDeletedLoans)
 			loans.GET("/:id", loanHandler.GetLoan)
-			loans.POST("/", loanHandler.CreateLoan)
-			loans.PUT("/:id", loanHandler.UpdateLoan)
-			loans.DELETE("/:id", loanHandler.DeleteLoan)
-			loans.PUT("/:id/approve", loanHandler.ApproveLoan)
-			loans.PUT("/:id/invest", loanHandler.InvestLoan)
-			loans.PUT("/:id/disburse", loanHandler.DisburseLoan)
+			loans.POST("/", rateLimit, middleware.RequireRole(middleware.RoleBorrower), loanHandler.CreateLoan)
+			loans.POST("/batch", rateLimit, middleware.RequireRole(middleware.RoleBorrower), loanHandler.CreateLoanBatch)
+			loans.POST("/batch/validate", rateLimit, loanHandler.ValidateLoanBatch)
+			loans.PUT("/:id", rateLimit, loanHandler.UpdateLoan)
+			loans.DELETE("/:id", rateLimit, loanHandler.DeleteLoan)
+			loans.POST("/:id/restore", rateLimit, loanHandler.RestoreLoan)
+			loans.PUT("/:id/review", rateLimit, loanHandler.ReviewLoan)
+			loans.PUT("/:id/review/clear", rateLimit, loanHandler.ClearReview)
+			loans.PUT("/:id/approve", rateLimit, middleware.RequireRole(middleware.RoleValidator), loanHandler.ApproveLoan)
+			loans.PUT("/:id/reject", rateLimit, middleware.RequireRole(middleware.RoleValidator), loanHandler.RejectLoan)
+			loans.PUT("/:id/cancel", rateLimit, loanHandler.CancelLoan)
+			loans.PATCH("/:id/approval", rateLimit, loanHandler.CorrectApproval)
+			loans.POST("/:id/revoke-approval", rateLimit, middleware.RequireRole(middleware.RoleAdmin), loanHandler.RevokeApproval)
+			loans.PUT("/:id/covenants/:covenantID/satisfy", rateLimit, loanHandler.SatisfyCovenant)
+			loans.PUT("/:id/invest", rateLimit, middleware.RequireRole(middleware.RoleInvestor), idempotency, loanHandler.InvestLoan)
+			loans.PUT("/:id/disburse", rateLimit, middleware.RequireRole(middleware.RoleOfficer), idempotency, loanHandler.DisburseLoan)
+			loans.POST("/:id/repayments", rateLimit, middleware.RequireRole(middleware.RoleOfficer), idempotency, loanHandler.RecordRepayment)
 			loans.GET("/:id/transitions", loanHandler.GetLoanTransitions)
+			loans.GET("/:id/full", loanHandler.GetLoanDetails)
+			loans.GET("/:id/ledger", loanHandler.GetLedger)
+			loans.GET("/:id/funding-timeline", loanHandler.GetFundingTimeline)
+			loans.POST("/:id/reconcile", rateLimit, middleware.RequireRole(middleware.RoleAdmin), loanHandler.ReconcileLoan)
+			loans.GET("/:id/history", loanHandler.GetLoanHistory)
+			loans.GET("/:id/amortization", loanHandler.GetAmortization)
+			loans.GET("/:id/schedule", loanHandler.GetSchedule)
+			loans.GET("/:id/accrued-interest", loanHandler.GetAccruedInterest)
+			loans.GET("/:id/investments", loanHandler.GetLoanInvestments)
+			loans.DELETE("/:id/investments/:investmentID", rateLimit, middleware.RequireRole(middleware.RoleInvestor, middleware.RoleAdmin), loanHandler.WithdrawInvestment)
+			loans.GET("/:id/diff", loanHandler.GetLoanDiff)
+			loans.POST("/:id/agreement/regenerate", rateLimit, loanHandler.RegenerateAgreement)
+			loans.GET("/:id/agreement/data", loanHandler.GetAgreementData)
+		}
+
+		// Borrower routes
+		borrowers := api.Group("/borrowers/:borrowerID")
+		{
+			borrowers.GET("/history", loanHandler.GetBorrowerHistory)
+			borrowers.GET("/loans", loanHandler.GetBorrowerLoans)
+		}
+
+		// Investor routes
+		investors := api.Group("/investors/:investorID")
+		{
+			investors.GET("", investorHandler.GetInvestor)
+			investors.PUT("/activate", rateLimit, middleware.RequireRole(middleware.RoleAdmin), investorHandler.ActivateInvestor)
+			investors.PUT("/deactivate", rateLimit, middleware.RequireRole(middleware.RoleAdmin), investorHandler.DeactivateInvestor)
+			investors.PUT("/email", rateLimit, investorHandler.UpdateInvestorEmail)
+			investors.GET("/loans", loanHandler.GetInvestorLoans)
+			investors.GET("/investments", investorHandler.GetInvestments)
+			investors.GET("/cashflows", loanHandler.GetInvestorCashflows)
+			investors.GET("/auto-invest/rules", autoInvestHandler.GetRules)
+			investors.POST("/auto-invest/rules", rateLimit, autoInvestHandler.CreateRule)
+			investors.PUT("/auto-invest/rules/:ruleID", rateLimit, autoInvestHandler.UpdateRule)
+			investors.DELETE("/auto-invest/rules/:ruleID", rateLimit, autoInvestHandler.DeleteRule)
+			investors.GET("/auto-invest/actions", autoInvestHandler.GetActions)
+		}
+
+		// Admin routes
+		admin := api.Group("/admin", middleware.RequireRole(middleware.RoleAdmin))
+		{
+			admin.POST("/loans/:id/transition", rateLimit, loanHandler.ForceTransition)
+		}
+
+		// Portfolio statistics routes
+		stats := api.Group("/stats")
+		{
+			stats.GET("", statsHandler.GetStats)
+			stats.GET("/outstanding", statsHandler.GetOutstanding)
+		}
+
+		// Webhook subscription routes, admin only since subscribers receive every investment
+		webhooks := api.Group("/webhooks", middleware.RequireRole(middleware.RoleAdmin))
+		{
+			webhooks.GET("", webhookHandler.GetSubscriptions)
+			webhooks.POST("", rateLimit, webhookHandler.CreateSubscription)
+			webhooks.DELETE("/:id", rateLimit, webhookHandler.DeleteSubscription)
 		}
 	}
 }
@@ -2,8 +2,10 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
-	"log"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -13,35 +15,60 @@ import (
 	v1 "loan-service/api/v1"
 	"loan-service/internal/config"
 	"loan-service/internal/database"
-	"loan-service/internal/domain"
 	"loan-service/internal/dto"
+	"loan-service/internal/handler"
+	"loan-service/internal/logging"
+	"loan-service/internal/middleware"
+	"loan-service/internal/repository"
+	"loan-service/internal/scheduler"
+	"loan-service/internal/service"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 )
 
 func main() {
+	seed := flag.Bool("seed", false, "insert demo loans on startup when the database has none (or set SEED=true)")
+	flag.Parse()
+
 	// Load environment variables
-	if err := godotenv.Load(); err != nil {
-		log.Println("No .env file found, using default configuration")
-	}
+	envErr := godotenv.Load()
 
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
-		log.Fatal("Failed to load configuration:", err)
+		fatal("Failed to load configuration", err)
+	}
+
+	// Set up logging
+	logging.Setup(cfg.Log)
+	if envErr != nil {
+		slog.Info("No .env file found, using default configuration")
 	}
 
 	// Initialize database
 	db, err := database.NewConnection(cfg.Database)
 	if err != nil {
-		log.Fatal("Failed to connect to database:", err)
+		fatal("Failed to connect to database", err)
 	}
 	defer database.CloseConnection(db)
 
 	// Auto migrate the schema
-	if err := db.AutoMigrate(&domain.Loan{}, &domain.Investment{}); err != nil {
-		log.Fatal("Failed to migrate database:", err)
+	if err := database.Migrate(db); err != nil {
+		fatal("Failed to migrate database", err)
+	}
+
+	// Seed demo data
+	if *seed || cfg.Database.Seed {
+		seeded, err := database.Seed(db)
+		if err != nil {
+			fatal("Failed to seed database", err)
+		}
+		if seeded > 0 {
+			slog.Info("Seeded demo loans", "count", seeded)
+		} else {
+			slog.Info("Database already has loans, skipping demo data")
+		}
 	}
 
 	// Set Gin mode
@@ -51,24 +78,81 @@ func main() {
 
 	// Register custom validations
 	dto.RegisterCustomValidations()
-
-	// Create router
+	handler.SetStrictJSON(cfg.Server.StrictJSON)
+	handler.SetMaxEmbeddedInvestments(cfg.Server.MaxEmbeddedInvestments)
+	middleware.SetJWTSecret(cfg.Auth.JWTSecret)
+	middleware.SetIdempotencyKeyTTL(cfg.Loan.IdempotencyKeyTTL)
+	middleware.SetRateLimit(cfg.Server.RateLimitRPS, cfg.Server.RateLimitBurst)
+	middleware.SetCORS(cfg.Server.CORSAllowedOrigins, cfg.Server.CORSAllowedMethods, cfg.Server.CORSAllowedHeaders)
+
+	// Initialize services
+	loanRepo := repository.NewLoanRepository(db).WithRetry(repository.RetryPolicy{
+		MaxRetries: cfg.Database.WriteRetries,
+		BaseDelay:  cfg.Database.WriteRetryBackoff,
+		MaxDelay:   cfg.Database.WriteRetryMaxBackoff,
+	})
+	autoInvestRepo := repository.NewAutoInvestRepository(db)
+	investorRepo := repository.NewInvestorRepository(db)
+	webhookRepo := repository.NewWebhookRepository(db)
+	webhooks := service.NewWebhookDispatcher(webhookRepo, cfg.Webhooks)
+	defer webhooks.Close()
+	loanOptions := []service.Option{
+		service.WithConfig(cfg.Loan),
+		service.WithAutoInvest(autoInvestRepo),
+		service.WithInvestors(investorRepo),
+		service.WithEvents(webhooks),
+	}
+	if cfg.SMTP.Host != "" {
+		loanOptions = append(loanOptions, service.WithNotifier(service.NewSMTPNotifier(cfg.SMTP, investorRepo)))
+	}
+	loanService := service.NewLoanService(loanRepo, loanOptions...)
+	autoInvestService := service.NewAutoInvestService(autoInvestRepo)
+	statsService := service.NewStatsService(repository.NewStatsRepository(db))
+	investorService := service.NewInvestorService(investorRepo, repository.NewInvestmentRepository(db))
+	webhookService := service.NewWebhookService(webhookRepo)
+
+	// Start background jobs
+	jobs := scheduler.New()
+	jobs.Every("agreement-retry", cfg.Jobs.AgreementRetryInterval, func() error {
+		generated, err := loanService.RetryMissingAgreements()
+		if generated > 0 {
+			slog.Info("Generated missing agreements", "count", generated)
+		}
+		return err
+	})
+	jobs.Every("loan-expiry", cfg.Jobs.LoanExpiryInterval, func() error {
+		expired, err := loanService.ExpireOverdueLoans(time.Now())
+		if expired > 0 {
+			slog.Info("Expired loans past their investment deadline", "count", expired)
+		}
+		return err
+	})
+	jobs.Every("stats-refresh", cfg.Jobs.StatsRefreshInterval, func() error {
+		_, err := statsService.RefreshPlatformStats()
+		return err
+	})
+	defer jobs.Stop()
+
+	// Create router. Client IPs only come from X-Forwarded-For when the request passed a trusted proxy.
 	router := gin.New()
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		fatal("Failed to configure trusted proxies", err)
+	}
 
 	// Setup routes
-	v1.SetupRoutes(router, db)
+	v1.SetupRoutes(router, db, loanService, autoInvestService, statsService, investorService, webhookService)
 
-	// Create HTTP server
-	srv := &http.Server{
-		Addr:    fmt.Sprintf(":%s", cfg.Server.Port),
-		Handler: router,
-	}
+	// Create HTTP server. Request contexts derive from baseCtx, which is cancelled when the
+	// shutdown timeout expires so that requests still running abort their database work.
+	baseCtx, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
+	srv := newServer(baseCtx, cfg.Server, router)
 
 	// Start server in a goroutine
 	go func() {
-		log.Printf("Starting loan service on port %s", cfg.Server.Port)
+		slog.Info("Starting loan service", "port", cfg.Server.Port)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatal("Failed to start server:", err)
+			fatal("Failed to start server", err)
 		}
 	}()
 
@@ -76,14 +160,35 @@ func main() {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
-	log.Println("Shutting down server...")
+	slog.Info("Shutting down server")
 
 	// Graceful shutdown
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatal("Server forced to shutdown:", err)
+		cancelRequests()
+		srv.Close()
+		slog.Error("Server forced to shutdown", "error", err)
 	}
 
-	log.Println("Server exited")
+	slog.Info("Server exited")
+}
+
+// fatal logs the error that keeps the service from running and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+// newServer creates the HTTP server listening on the configured port with the configured timeouts.
+// Request contexts derive from baseCtx.
+func newServer(baseCtx context.Context, cfg config.ServerConfig, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:         fmt.Sprintf(":%s", cfg.Port),
+		Handler:      handler,
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+		IdleTimeout:  cfg.IdleTimeout,
+		BaseContext:  func(net.Listener) context.Context { return baseCtx },
+	}
 }
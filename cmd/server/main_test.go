@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"loan-service/internal/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewServer(t *testing.T) {
+	cfg := config.ServerConfig{
+		Port:         "9090",
+		ReadTimeout:  5 * time.Second,
+		WriteTimeout: 15 * time.Second,
+		IdleTimeout:  90 * time.Second,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	srv := newServer(ctx, cfg, http.NotFoundHandler())
+
+	assert.Equal(t, ":9090", srv.Addr)
+	assert.Equal(t, 5*time.Second, srv.ReadTimeout)
+	assert.Equal(t, 15*time.Second, srv.WriteTimeout)
+	assert.Equal(t, 90*time.Second, srv.IdleTimeout)
+	assert.Equal(t, ctx, srv.BaseContext(nil))
+}
@@ -1,35 +1,140 @@
 package config
 
 import (
+	"fmt"
+	"net"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 )
 
 // Config holds all configuration for the application
 type Config struct {
 	Environment string
+	Log         LogConfig
 	Server      ServerConfig
+	Auth        AuthConfig
 	Database    DatabaseConfig
+	Loan        LoanConfig
+	Jobs        JobsConfig
+	Webhooks    WebhooksConfig
+	SMTP        SMTPConfig
+}
+
+// LogConfig holds application logging configuration: the minimum level logged and whether lines are
+// written as text or JSON
+type LogConfig struct {
+	Level  string
+	Format string
 }
 
 // ServerConfig holds server configuration
 type ServerConfig struct {
-	Port         string
-	ReadTimeout  time.Duration
-	WriteTimeout time.Duration
-	IdleTimeout  time.Duration
+	Port                   string
+	ReadTimeout            time.Duration
+	WriteTimeout           time.Duration
+	IdleTimeout            time.Duration
+	StrictJSON             bool
+	MaxEmbeddedInvestments int
+	RateLimitRPS           int
+	RateLimitBurst         int
+	CORSAllowedOrigins     []string
+	CORSAllowedMethods     []string
+	CORSAllowedHeaders     []string
+	// TrustedProxies lists the proxy IPs and CIDR ranges whose X-Forwarded-For header is believed for the
+	// client IP. Without any, the client IP is the address of the connection.
+	TrustedProxies []string
+}
+
+// AuthConfig holds bearer token authentication configuration. Authentication is disabled without a secret.
+type AuthConfig struct {
+	JWTSecret string
 }
 
-// DatabaseConfig holds database configuration
+// DatabaseConfig holds database configuration. Loan writes failing with a transient error are retried
+// up to WriteRetries times, waiting WriteRetryBackoff before the first retry and doubling the wait up to
+// WriteRetryMaxBackoff.
 type DatabaseConfig struct {
-	Driver   string
+	Driver               string
+	Host                 string
+	Port                 string
+	User                 string
+	Password             string
+	Name                 string
+	SSLMode              string
+	LogLevel             string
+	SlowQueryThreshold   time.Duration
+	Seed                 bool
+	WriteRetries         int
+	WriteRetryBackoff    time.Duration
+	WriteRetryMaxBackoff time.Duration
+}
+
+// LoanConfig holds business rule configuration for loans
+type LoanConfig struct {
+	FundingWindow               FundingWindowConfig
+	MaxActiveLoansPerBorrower   int
+	MaxInvestorsPerLoan         int
+	MaxInvestorSharePercent     float64
+	MinInvestorsForDisbursement int
+	WholeUnitsOnly              bool
+	InvestorFeeRate             float64
+	InvestorFeeBasis            string
+	ApprovalCorrectionWindow    time.Duration
+	InvestmentWindow            time.Duration
+	IdempotencyKeyTTL           time.Duration
+	UniqueProofPerLoan          bool
+	RejectDuplicateLoans        bool
+	RequireAgreementMatch       bool
+	OverfundPolicy              string
+	MinROI                      float64
+	MaxROI                      float64
+	ROIRatePolicy               string
+	DisburseMode                string
+	MinFundingPercent           float64
+	MinInvestmentAmount         float64
+	MaxLoanBatchSize            int
+	AgreementLinkTemplate       string
+	MaxAgreementAttempts        int
+	BaseCurrency                string
+	AllowedCurrencies           []string
+}
+
+// FundingWindowConfig holds the hours during which investments are accepted
+type FundingWindowConfig struct {
+	Enabled   bool
+	Days      []time.Weekday
+	OpenHour  int
+	CloseHour int
+	Location  *time.Location
+}
+
+// JobsConfig holds background job configuration
+type JobsConfig struct {
+	AgreementRetryInterval time.Duration
+	StatsRefreshInterval   time.Duration
+	LoanExpiryInterval     time.Duration
+}
+
+// WebhooksConfig holds webhook delivery configuration. URLs receive every event in addition to the
+// subscriptions registered through the API. Bodies are signed when a secret is set.
+type WebhooksConfig struct {
+	Timeout      time.Duration
+	URLs         []string
+	Secret       string
+	MaxRetries   int
+	RetryBackoff time.Duration
+}
+
+// SMTPConfig holds the mail server used to notify investors. Notifications are disabled without a host.
+type SMTPConfig struct {
 	Host     string
-	Port     string
-	User     string
+	Port     int
+	Username string
 	Password string
-	Name     string
-	SSLMode  string
+	From     string
 }
 
 // Load loads configuration from environment variables
@@ -37,27 +142,312 @@ func Load() (*Config, error) {
 	readTimeout, _ := strconv.Atoi(getEnv("SERVER_READ_TIMEOUT", "10"))
 	writeTimeout, _ := strconv.Atoi(getEnv("SERVER_WRITE_TIMEOUT", "10"))
 	idleTimeout, _ := strconv.Atoi(getEnv("SERVER_IDLE_TIMEOUT", "120"))
+	maxEmbeddedInvestments, _ := strconv.Atoi(getEnv("MAX_EMBEDDED_INVESTMENTS", "10"))
+
+	rateLimitRPS, _ := strconv.Atoi(getEnv("RATE_LIMIT_RPS", "0"))
+	rateLimitBurst, _ := strconv.Atoi(getEnv("RATE_LIMIT_BURST", "10"))
+	if rateLimitRPS < 0 || rateLimitBurst < 0 {
+		return nil, fmt.Errorf("invalid rate limit: %d requests per second, burst %d", rateLimitRPS, rateLimitBurst)
+	}
+
+	trustedProxies := getEnvList("TRUSTED_PROXIES")
+	for _, proxy := range trustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			return nil, fmt.Errorf("invalid trusted proxy: %q", proxy)
+		}
+	}
+
+	corsAllowedOrigins := getEnvListOrDefault("CORS_ALLOWED_ORIGINS", "*")
+	if len(corsAllowedOrigins) > 1 && slices.Contains(corsAllowedOrigins, "*") {
+		return nil, fmt.Errorf("CORS allowed origins must be either * or a list of origins: %q", corsAllowedOrigins)
+	}
+
+	environment := getEnv("ENVIRONMENT", "development")
+
+	jwtSecret := getEnv("JWT_SECRET", "")
+	if jwtSecret == "" && environment == "production" {
+		return nil, fmt.Errorf("JWT_SECRET is required in production")
+	}
+
+	logLevel := getEnv("LOG_LEVEL", "info")
+	if logLevel != "debug" && logLevel != "info" && logLevel != "warn" && logLevel != "error" {
+		return nil, fmt.Errorf("invalid log level: %q", logLevel)
+	}
+
+	defaultLogFormat := "text"
+	if environment == "production" {
+		defaultLogFormat = "json"
+	}
+	logFormat := getEnv("LOG_FORMAT", defaultLogFormat)
+	if logFormat != "text" && logFormat != "json" {
+		return nil, fmt.Errorf("invalid log format: %q", logFormat)
+	}
+
+	defaultLogLevel := "info"
+	if environment == "production" {
+		defaultLogLevel = "warn"
+	}
+	dbLogLevel := getEnv("DB_LOG_LEVEL", defaultLogLevel)
+	if dbLogLevel != "silent" && dbLogLevel != "error" && dbLogLevel != "warn" && dbLogLevel != "info" {
+		return nil, fmt.Errorf("invalid database log level: %q", dbLogLevel)
+	}
+	slowQueryThreshold, _ := strconv.Atoi(getEnv("DB_SLOW_QUERY_THRESHOLD", "200"))
+
+	writeRetries, _ := strconv.Atoi(getEnv("DB_WRITE_RETRIES", "3"))
+	writeRetryBackoff, _ := strconv.Atoi(getEnv("DB_WRITE_RETRY_BACKOFF", "50"))
+	writeRetryMaxBackoff, _ := strconv.Atoi(getEnv("DB_WRITE_RETRY_MAX_BACKOFF", "1000"))
+	if writeRetries < 0 || writeRetryBackoff < 0 || writeRetryMaxBackoff < writeRetryBackoff {
+		return nil, fmt.Errorf("invalid database write retries: %d retries, backoff %d-%dms", writeRetries, writeRetryBackoff, writeRetryMaxBackoff)
+	}
+
+	agreementRetryInterval, _ := strconv.Atoi(getEnv("AGREEMENT_RETRY_INTERVAL", "300"))
+	statsRefreshInterval, _ := strconv.Atoi(getEnv("STATS_REFRESH_INTERVAL", "60"))
+	loanExpiryInterval, _ := strconv.Atoi(getEnv("LOAN_EXPIRY_INTERVAL", "300"))
+	webhookTimeout, _ := strconv.Atoi(getEnv("WEBHOOK_TIMEOUT", "5"))
+	webhookMaxRetries, _ := strconv.Atoi(getEnv("WEBHOOK_MAX_RETRIES", "3"))
+	webhookRetryBackoff, _ := strconv.Atoi(getEnv("WEBHOOK_RETRY_BACKOFF", "1"))
+	if webhookMaxRetries < 0 || webhookRetryBackoff < 0 {
+		return nil, fmt.Errorf("invalid webhook retries: %d retries, backoff %d", webhookMaxRetries, webhookRetryBackoff)
+	}
+	smtpPort, _ := strconv.Atoi(getEnv("SMTP_PORT", "587"))
+	maxActiveLoans, _ := strconv.Atoi(getEnv("MAX_ACTIVE_LOANS_PER_BORROWER", "0"))
+	approvalCorrectionWindow, _ := strconv.Atoi(getEnv("APPROVAL_CORRECTION_WINDOW", "900"))
+	idempotencyKeyTTL, _ := strconv.Atoi(getEnv("IDEMPOTENCY_KEY_TTL", "86400"))
+	investmentWindow, _ := strconv.Atoi(getEnv("INVESTMENT_WINDOW", "0"))
+	if investmentWindow < 0 {
+		return nil, fmt.Errorf("invalid investment window: %d", investmentWindow)
+	}
+
+	fundingWindow, err := loadFundingWindow()
+	if err != nil {
+		return nil, err
+	}
+
+	investorFeeRate, _ := strconv.ParseFloat(getEnv("INVESTOR_FEE_RATE", "0"), 64)
+	if investorFeeRate < 0 || investorFeeRate >= 100 {
+		return nil, fmt.Errorf("invalid investor fee rate: %v", investorFeeRate)
+	}
+
+	investorFeeBasis := getEnv("INVESTOR_FEE_BASIS", "gross")
+	if investorFeeBasis != "gross" && investorFeeBasis != "net" {
+		return nil, fmt.Errorf("invalid investor fee basis: %q", investorFeeBasis)
+	}
+
+	minROI, _ := strconv.ParseFloat(getEnv("MIN_ROI", "0"), 64)
+	maxROI, _ := strconv.ParseFloat(getEnv("MAX_ROI", "0"), 64)
+	if minROI < 0 || maxROI < 0 || (maxROI > 0 && minROI > maxROI) {
+		return nil, fmt.Errorf("invalid ROI bounds: %v-%v", minROI, maxROI)
+	}
+
+	roiRatePolicy := getEnv("ROI_RATE_POLICY", "allow_equal")
+	if roiRatePolicy != "off" && roiRatePolicy != "allow_equal" && roiRatePolicy != "strict" {
+		return nil, fmt.Errorf("invalid ROI rate policy: %q", roiRatePolicy)
+	}
+
+	disburseMode := getEnv("DISBURSE_MODE", "full_only")
+	if disburseMode != "full_only" && disburseMode != "partial_allowed" {
+		return nil, fmt.Errorf("invalid disburse mode: %q", disburseMode)
+	}
+
+	minFundingPercent, _ := strconv.ParseFloat(getEnv("MIN_FUNDING_PERCENT", "80"), 64)
+	if minFundingPercent <= 0 || minFundingPercent > 100 {
+		return nil, fmt.Errorf("invalid minimum funding percent: %v", minFundingPercent)
+	}
+
+	maxInvestorsPerLoan, _ := strconv.Atoi(getEnv("MAX_INVESTORS_PER_LOAN", "0"))
+	if maxInvestorsPerLoan < 0 {
+		return nil, fmt.Errorf("invalid maximum investors per loan: %d", maxInvestorsPerLoan)
+	}
+
+	maxInvestorSharePercent, _ := strconv.ParseFloat(getEnv("MAX_INVESTOR_SHARE_PERCENT", "100"), 64)
+	if maxInvestorSharePercent <= 0 || maxInvestorSharePercent > 100 {
+		return nil, fmt.Errorf("invalid maximum investor share percent: %v", maxInvestorSharePercent)
+	}
+
+	minInvestorsForDisbursement, _ := strconv.Atoi(getEnv("MIN_INVESTORS_FOR_DISBURSEMENT", "0"))
+	if minInvestorsForDisbursement < 0 || maxInvestorsPerLoan > 0 && minInvestorsForDisbursement > maxInvestorsPerLoan {
+		return nil, fmt.Errorf("invalid minimum investors for disbursement: %d", minInvestorsForDisbursement)
+	}
+
+	minInvestmentAmount, _ := strconv.ParseFloat(getEnv("MIN_INVESTMENT_AMOUNT", "0"), 64)
+	if minInvestmentAmount < 0 {
+		return nil, fmt.Errorf("invalid minimum investment amount: %v", minInvestmentAmount)
+	}
+
+	maxLoanBatchSize, _ := strconv.Atoi(getEnv("MAX_LOAN_BATCH_SIZE", "100"))
+	if maxLoanBatchSize <= 0 {
+		return nil, fmt.Errorf("invalid maximum loan batch size: %d", maxLoanBatchSize)
+	}
+
+	agreementLinkTemplate := getEnv("AGREEMENT_LINK_TEMPLATE", "https://example.com/agreements/loan_{id}_agreement.pdf")
+	if !strings.Contains(agreementLinkTemplate, "{id}") && !strings.Contains(agreementLinkTemplate, "%s") {
+		return nil, fmt.Errorf("agreement link template must contain an {id} or %%s placeholder: %q", agreementLinkTemplate)
+	}
+
+	maxAgreementAttempts, _ := strconv.Atoi(getEnv("MAX_AGREEMENT_ATTEMPTS", "10"))
+	if maxAgreementAttempts < 0 {
+		return nil, fmt.Errorf("invalid maximum agreement attempts: %d", maxAgreementAttempts)
+	}
+
+	baseCurrency := getEnv("BASE_CURRENCY", "USD")
+	if !isCurrencyCode(baseCurrency) {
+		return nil, fmt.Errorf("invalid base currency: %q", baseCurrency)
+	}
+
+	allowedCurrencies := getEnvListOrDefault("ALLOWED_CURRENCIES", baseCurrency)
+	for _, currency := range allowedCurrencies {
+		if !isCurrencyCode(currency) {
+			return nil, fmt.Errorf("invalid allowed currency: %q", currency)
+		}
+	}
+	if !slices.Contains(allowedCurrencies, baseCurrency) {
+		return nil, fmt.Errorf("base currency %s must be one of the allowed currencies: %q", baseCurrency, allowedCurrencies)
+	}
+
+	overfundPolicy := getEnv("OVERFUND_POLICY", "allow_remainder")
+	if overfundPolicy != "reject" && overfundPolicy != "clamp" && overfundPolicy != "allow_remainder" {
+		return nil, fmt.Errorf("invalid overfund policy: %q", overfundPolicy)
+	}
 
 	return &Config{
-		Environment: getEnv("ENVIRONMENT", "development"),
+		Environment: environment,
+		Log: LogConfig{
+			Level:  logLevel,
+			Format: logFormat,
+		},
 		Server: ServerConfig{
-			Port:         getEnv("PORT", "8080"),
-			ReadTimeout:  time.Duration(readTimeout) * time.Second,
-			WriteTimeout: time.Duration(writeTimeout) * time.Second,
-			IdleTimeout:  time.Duration(idleTimeout) * time.Second,
+			Port:                   getEnv("PORT", "8080"),
+			ReadTimeout:            time.Duration(readTimeout) * time.Second,
+			WriteTimeout:           time.Duration(writeTimeout) * time.Second,
+			IdleTimeout:            time.Duration(idleTimeout) * time.Second,
+			StrictJSON:             getEnvBool("STRICT_JSON", true),
+			MaxEmbeddedInvestments: maxEmbeddedInvestments,
+			RateLimitRPS:           rateLimitRPS,
+			RateLimitBurst:         rateLimitBurst,
+			CORSAllowedOrigins:     corsAllowedOrigins,
+			CORSAllowedMethods:     getEnvListOrDefault("CORS_ALLOWED_METHODS", "GET,POST,PUT,PATCH,DELETE,OPTIONS"),
+			CORSAllowedHeaders:     getEnvListOrDefault("CORS_ALLOWED_HEADERS", "Content-Type,Authorization,Idempotency-Key"),
+			TrustedProxies:         trustedProxies,
+		},
+		Auth: AuthConfig{
+			JWTSecret: jwtSecret,
 		},
 		Database: DatabaseConfig{
-			Driver:   getEnv("DB_DRIVER", "sqlite"),
-			Host:     getEnv("DB_HOST", ""),
-			Port:     getEnv("DB_PORT", ""),
-			User:     getEnv("DB_USER", ""),
-			Password: getEnv("DB_PASSWORD", ""),
-			Name:     getEnv("DB_NAME", "loan_service.db"),
-			SSLMode:  getEnv("DB_SSLMODE", ""),
+			Driver:               getEnv("DB_DRIVER", "sqlite"),
+			Host:                 getEnv("DB_HOST", ""),
+			Port:                 getEnv("DB_PORT", ""),
+			User:                 getEnv("DB_USER", ""),
+			Password:             getEnv("DB_PASSWORD", ""),
+			Name:                 getEnv("DB_NAME", "loan_service.db"),
+			SSLMode:              getEnv("DB_SSLMODE", ""),
+			LogLevel:             dbLogLevel,
+			SlowQueryThreshold:   time.Duration(slowQueryThreshold) * time.Millisecond,
+			Seed:                 getEnvBool("SEED", false),
+			WriteRetries:         writeRetries,
+			WriteRetryBackoff:    time.Duration(writeRetryBackoff) * time.Millisecond,
+			WriteRetryMaxBackoff: time.Duration(writeRetryMaxBackoff) * time.Millisecond,
 		},
+		Loan: LoanConfig{
+			FundingWindow:               fundingWindow,
+			MaxActiveLoansPerBorrower:   maxActiveLoans,
+			MaxInvestorsPerLoan:         maxInvestorsPerLoan,
+			MaxInvestorSharePercent:     maxInvestorSharePercent,
+			MinInvestorsForDisbursement: minInvestorsForDisbursement,
+			WholeUnitsOnly:              getEnvBool("PRINCIPAL_WHOLE_UNITS_ONLY", false),
+			InvestorFeeRate:             investorFeeRate,
+			InvestorFeeBasis:            investorFeeBasis,
+			ApprovalCorrectionWindow:    time.Duration(approvalCorrectionWindow) * time.Second,
+			InvestmentWindow:            time.Duration(investmentWindow) * time.Second,
+			IdempotencyKeyTTL:           time.Duration(idempotencyKeyTTL) * time.Second,
+			UniqueProofPerLoan:          getEnvBool("UNIQUE_PROOF_PER_LOAN", false),
+			RejectDuplicateLoans:        getEnvBool("REJECT_DUPLICATE_LOANS", false),
+			RequireAgreementMatch:       getEnvBool("REQUIRE_AGREEMENT_MATCH", false),
+			OverfundPolicy:              overfundPolicy,
+			MinROI:                      minROI,
+			MaxROI:                      maxROI,
+			ROIRatePolicy:               roiRatePolicy,
+			DisburseMode:                disburseMode,
+			MinFundingPercent:           minFundingPercent,
+			MinInvestmentAmount:         minInvestmentAmount,
+			MaxLoanBatchSize:            maxLoanBatchSize,
+			AgreementLinkTemplate:       agreementLinkTemplate,
+			MaxAgreementAttempts:        maxAgreementAttempts,
+			BaseCurrency:                baseCurrency,
+			AllowedCurrencies:           allowedCurrencies,
+		},
+		Jobs: JobsConfig{
+			AgreementRetryInterval: time.Duration(agreementRetryInterval) * time.Second,
+			StatsRefreshInterval:   time.Duration(statsRefreshInterval) * time.Second,
+			LoanExpiryInterval:     time.Duration(loanExpiryInterval) * time.Second,
+		},
+		Webhooks: WebhooksConfig{
+			Timeout:      time.Duration(webhookTimeout) * time.Second,
+			URLs:         getEnvList("WEBHOOK_URLS"),
+			Secret:       getEnv("WEBHOOK_SECRET", ""),
+			MaxRetries:   webhookMaxRetries,
+			RetryBackoff: time.Duration(webhookRetryBackoff) * time.Second,
+		},
+		SMTP: SMTPConfig{
+			Host:     getEnv("SMTP_HOST", ""),
+			Port:     smtpPort,
+			Username: getEnv("SMTP_USERNAME", ""),
+			Password: getEnv("SMTP_PASSWORD", ""),
+			From:     getEnv("SMTP_FROM", "noreply@example.com"),
+		},
+	}, nil
+}
+
+// loadFundingWindow loads the investment acceptance window configuration
+func loadFundingWindow() (FundingWindowConfig, error) {
+	openHour, _ := strconv.Atoi(getEnv("FUNDING_WINDOW_OPEN_HOUR", "9"))
+	closeHour, _ := strconv.Atoi(getEnv("FUNDING_WINDOW_CLOSE_HOUR", "17"))
+
+	if openHour < 0 || closeHour > 24 || openHour >= closeHour {
+		return FundingWindowConfig{}, fmt.Errorf("invalid funding window hours: %d-%d", openHour, closeHour)
+	}
+
+	days, err := parseWeekdays(getEnv("FUNDING_WINDOW_DAYS", "mon,tue,wed,thu,fri"))
+	if err != nil {
+		return FundingWindowConfig{}, err
+	}
+
+	location, err := time.LoadLocation(getEnv("FUNDING_WINDOW_TIMEZONE", "UTC"))
+	if err != nil {
+		return FundingWindowConfig{}, fmt.Errorf("invalid funding window timezone: %w", err)
+	}
+
+	return FundingWindowConfig{
+		Enabled:   getEnvBool("FUNDING_WINDOW_ENABLED", false),
+		Days:      days,
+		OpenHour:  openHour,
+		CloseHour: closeHour,
+		Location:  location,
 	}, nil
 }
 
+// parseWeekdays parses a comma-separated list of day abbreviations (e.g. "mon,tue")
+func parseWeekdays(value string) ([]time.Weekday, error) {
+	names := map[string]time.Weekday{
+		"sun": time.Sunday,
+		"mon": time.Monday,
+		"tue": time.Tuesday,
+		"wed": time.Wednesday,
+		"thu": time.Thursday,
+		"fri": time.Friday,
+		"sat": time.Saturday,
+	}
+
+	var days []time.Weekday
+	for _, part := range strings.Split(value, ",") {
+		day, ok := names[strings.ToLower(strings.TrimSpace(part))]
+		if !ok {
+			return nil, fmt.Errorf("invalid weekday: %q", part)
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
+
 // getEnv gets an environment variable or returns a default value
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -65,3 +455,45 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvBool gets a boolean environment variable or returns a default value
+func getEnvBool(key string, defaultValue bool) bool {
+	value, err := strconv.ParseBool(getEnv(key, strconv.FormatBool(defaultValue)))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// isCurrencyCode reports whether s has the form of an ISO 4217 currency code, three uppercase letters
+func isCurrencyCode(s string) bool {
+	if len(s) != 3 {
+		return false
+	}
+	for _, r := range s {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// getEnvList gets a comma-separated environment variable as a list, skipping empty entries
+func getEnvList(key string) []string {
+	var values []string
+	for _, part := range strings.Split(os.Getenv(key), ",") {
+		if value := strings.TrimSpace(part); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// getEnvListOrDefault gets a comma-separated environment variable as a list, falling back to the
+// comma-separated default when the variable lists nothing
+func getEnvListOrDefault(key, defaultValue string) []string {
+	if values := getEnvList(key); len(values) > 0 {
+		return values
+	}
+	return strings.Split(defaultValue, ",")
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -17,6 +18,9 @@ func TestLoad(t *testing.T) {
 	assert.Equal(t, "8080", config.Server.Port)
 	assert.Equal(t, "sqlite", config.Database.Driver)
 	assert.Equal(t, "loan_service.db", config.Database.Name)
+	assert.Equal(t, 5*time.Second, config.Webhooks.Timeout)
+	assert.Empty(t, config.SMTP.Host)
+	assert.Equal(t, 587, config.SMTP.Port)
 }
 
 func TestLoadWithEnvironmentVariables(t *testing.T) {
@@ -28,10 +32,12 @@ func TestLoadWithEnvironmentVariables(t *testing.T) {
 	os.Setenv("SERVER_READ_TIMEOUT", "30")
 	os.Setenv("SERVER_WRITE_TIMEOUT", "30")
 	os.Setenv("SERVER_IDLE_TIMEOUT", "300")
+	os.Setenv("JWT_SECRET", "secret")
 
 	// Clean up after test
 	defer func() {
 		os.Unsetenv("ENVIRONMENT")
+		os.Unsetenv("JWT_SECRET")
 		os.Unsetenv("PORT")
 		os.Unsetenv("DB_DRIVER")
 		os.Unsetenv("DB_NAME")
@@ -50,6 +56,8 @@ func TestLoadWithEnvironmentVariables(t *testing.T) {
 	assert.Equal(t, 30, int(config.Server.ReadTimeout.Seconds()))
 	assert.Equal(t, 30, int(config.Server.WriteTimeout.Seconds()))
 	assert.Equal(t, 300, int(config.Server.IdleTimeout.Seconds()))
+	assert.Equal(t, "warn", config.Database.LogLevel)
+	assert.Equal(t, "json", config.Log.Format)
 }
 
 func TestGetEnv(t *testing.T) {
@@ -64,3 +72,535 @@ func TestGetEnv(t *testing.T) {
 	value = getEnv("NON_EXISTENT_VAR", "default_value")
 	assert.Equal(t, "default_value", value)
 }
+
+func TestLoadFundingWindow(t *testing.T) {
+	// Defaults: disabled, weekdays 9-17 UTC
+	config, err := Load()
+	require.NoError(t, err)
+
+	window := config.Loan.FundingWindow
+	assert.False(t, window.Enabled)
+	assert.Equal(t, []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}, window.Days)
+	assert.Equal(t, 9, window.OpenHour)
+	assert.Equal(t, 17, window.CloseHour)
+	assert.Equal(t, "UTC", window.Location.String())
+
+	os.Setenv("FUNDING_WINDOW_ENABLED", "true")
+	os.Setenv("FUNDING_WINDOW_DAYS", "sat,sun")
+	os.Setenv("FUNDING_WINDOW_TIMEZONE", "Asia/Jakarta")
+	defer func() {
+		os.Unsetenv("FUNDING_WINDOW_ENABLED")
+		os.Unsetenv("FUNDING_WINDOW_DAYS")
+		os.Unsetenv("FUNDING_WINDOW_TIMEZONE")
+	}()
+
+	config, err = Load()
+	require.NoError(t, err)
+
+	window = config.Loan.FundingWindow
+	assert.True(t, window.Enabled)
+	assert.Equal(t, []time.Weekday{time.Saturday, time.Sunday}, window.Days)
+	assert.Equal(t, "Asia/Jakarta", window.Location.String())
+}
+
+func TestLoadFundingWindowInvalid(t *testing.T) {
+	os.Setenv("FUNDING_WINDOW_TIMEZONE", "Not/AZone")
+	_, err := Load()
+	os.Unsetenv("FUNDING_WINDOW_TIMEZONE")
+	assert.Error(t, err)
+
+	os.Setenv("FUNDING_WINDOW_DAYS", "mon,funday")
+	_, err = Load()
+	os.Unsetenv("FUNDING_WINDOW_DAYS")
+	assert.Error(t, err)
+}
+
+func TestLoadInvestorFee(t *testing.T) {
+	config, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, config.Loan.InvestorFeeRate)
+	assert.Equal(t, "gross", config.Loan.InvestorFeeBasis)
+
+	os.Setenv("INVESTOR_FEE_RATE", "1.5")
+	os.Setenv("INVESTOR_FEE_BASIS", "net")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, 1.5, config.Loan.InvestorFeeRate)
+	assert.Equal(t, "net", config.Loan.InvestorFeeBasis)
+
+	os.Setenv("INVESTOR_FEE_BASIS", "both")
+	_, err = Load()
+	assert.Error(t, err)
+
+	os.Unsetenv("INVESTOR_FEE_RATE")
+	os.Unsetenv("INVESTOR_FEE_BASIS")
+}
+
+func TestLoadOverfundPolicy(t *testing.T) {
+	config, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, "allow_remainder", config.Loan.OverfundPolicy)
+
+	os.Setenv("OVERFUND_POLICY", "clamp")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, "clamp", config.Loan.OverfundPolicy)
+
+	os.Setenv("OVERFUND_POLICY", "round")
+	_, err = Load()
+	assert.Error(t, err)
+
+	os.Unsetenv("OVERFUND_POLICY")
+}
+
+func TestLoadROIBounds(t *testing.T) {
+	config, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, config.Loan.MinROI)
+	assert.Equal(t, 0.0, config.Loan.MaxROI)
+
+	os.Setenv("MIN_ROI", "2")
+	os.Setenv("MAX_ROI", "12.5")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, 2.0, config.Loan.MinROI)
+	assert.Equal(t, 12.5, config.Loan.MaxROI)
+
+	os.Setenv("MIN_ROI", "15")
+	_, err = Load()
+	assert.Error(t, err)
+
+	os.Unsetenv("MIN_ROI")
+	os.Unsetenv("MAX_ROI")
+}
+
+func TestLoadROIRatePolicy(t *testing.T) {
+	config, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, "allow_equal", config.Loan.ROIRatePolicy)
+
+	os.Setenv("ROI_RATE_POLICY", "strict")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, "strict", config.Loan.ROIRatePolicy)
+
+	os.Setenv("ROI_RATE_POLICY", "lenient")
+	_, err = Load()
+	assert.Error(t, err)
+
+	os.Unsetenv("ROI_RATE_POLICY")
+}
+
+func TestLoadInvestmentWindow(t *testing.T) {
+	config, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(0), config.Loan.InvestmentWindow)
+	assert.Equal(t, 5*time.Minute, config.Jobs.LoanExpiryInterval)
+
+	os.Setenv("INVESTMENT_WINDOW", "1209600")
+	os.Setenv("LOAN_EXPIRY_INTERVAL", "60")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, 14*24*time.Hour, config.Loan.InvestmentWindow)
+	assert.Equal(t, time.Minute, config.Jobs.LoanExpiryInterval)
+
+	os.Setenv("INVESTMENT_WINDOW", "-1")
+	_, err = Load()
+	assert.Error(t, err)
+
+	os.Unsetenv("INVESTMENT_WINDOW")
+	os.Unsetenv("LOAN_EXPIRY_INTERVAL")
+}
+
+func TestLoadDisburseMode(t *testing.T) {
+	config, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, "full_only", config.Loan.DisburseMode)
+	assert.Equal(t, 80.0, config.Loan.MinFundingPercent)
+
+	os.Setenv("DISBURSE_MODE", "partial_allowed")
+	os.Setenv("MIN_FUNDING_PERCENT", "60")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, "partial_allowed", config.Loan.DisburseMode)
+	assert.Equal(t, 60.0, config.Loan.MinFundingPercent)
+
+	os.Setenv("MIN_FUNDING_PERCENT", "120")
+	_, err = Load()
+	assert.Error(t, err)
+
+	os.Setenv("DISBURSE_MODE", "whenever")
+	os.Unsetenv("MIN_FUNDING_PERCENT")
+	_, err = Load()
+	assert.Error(t, err)
+
+	os.Unsetenv("DISBURSE_MODE")
+}
+
+func TestLoadMinInvestmentAmount(t *testing.T) {
+	config, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, config.Loan.MinInvestmentAmount)
+
+	os.Setenv("MIN_INVESTMENT_AMOUNT", "100")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, 100.0, config.Loan.MinInvestmentAmount)
+
+	os.Setenv("MIN_INVESTMENT_AMOUNT", "-5")
+	_, err = Load()
+	assert.Error(t, err)
+
+	os.Unsetenv("MIN_INVESTMENT_AMOUNT")
+}
+
+func TestLoadMaxLoanBatchSize(t *testing.T) {
+	config, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, 100, config.Loan.MaxLoanBatchSize)
+
+	os.Setenv("MAX_LOAN_BATCH_SIZE", "500")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, 500, config.Loan.MaxLoanBatchSize)
+
+	os.Setenv("MAX_LOAN_BATCH_SIZE", "0")
+	_, err = Load()
+	assert.Error(t, err)
+
+	os.Unsetenv("MAX_LOAN_BATCH_SIZE")
+}
+
+func TestLoadAgreementLinkTemplate(t *testing.T) {
+	config, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/agreements/loan_{id}_agreement.pdf", config.Loan.AgreementLinkTemplate)
+
+	os.Setenv("AGREEMENT_LINK_TEMPLATE", "https://docs.example.org/loans/%s.pdf")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, "https://docs.example.org/loans/%s.pdf", config.Loan.AgreementLinkTemplate)
+
+	// Templates without a placeholder would give every loan the same link
+	os.Setenv("AGREEMENT_LINK_TEMPLATE", "https://docs.example.org/loans/agreement.pdf")
+	_, err = Load()
+	assert.Error(t, err)
+
+	os.Unsetenv("AGREEMENT_LINK_TEMPLATE")
+}
+
+func TestLoadMaxAgreementAttempts(t *testing.T) {
+	config, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, 10, config.Loan.MaxAgreementAttempts)
+
+	os.Setenv("MAX_AGREEMENT_ATTEMPTS", "0")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, 0, config.Loan.MaxAgreementAttempts)
+
+	os.Setenv("MAX_AGREEMENT_ATTEMPTS", "-1")
+	_, err = Load()
+	assert.Error(t, err)
+
+	os.Unsetenv("MAX_AGREEMENT_ATTEMPTS")
+}
+
+func TestLoadDatabaseLogging(t *testing.T) {
+	config, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, "info", config.Database.LogLevel)
+	assert.Equal(t, 200*time.Millisecond, config.Database.SlowQueryThreshold)
+
+	os.Setenv("DB_LOG_LEVEL", "silent")
+	os.Setenv("DB_SLOW_QUERY_THRESHOLD", "500")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, "silent", config.Database.LogLevel)
+	assert.Equal(t, 500*time.Millisecond, config.Database.SlowQueryThreshold)
+
+	os.Setenv("DB_LOG_LEVEL", "debug")
+	_, err = Load()
+	assert.Error(t, err)
+
+	os.Unsetenv("DB_LOG_LEVEL")
+	os.Unsetenv("DB_SLOW_QUERY_THRESHOLD")
+}
+
+func TestLoadLogging(t *testing.T) {
+	config, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, "info", config.Log.Level)
+	assert.Equal(t, "text", config.Log.Format)
+
+	os.Setenv("LOG_LEVEL", "debug")
+	os.Setenv("LOG_FORMAT", "json")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, "debug", config.Log.Level)
+	assert.Equal(t, "json", config.Log.Format)
+
+	os.Setenv("LOG_LEVEL", "verbose")
+	_, err = Load()
+	assert.Error(t, err)
+
+	os.Setenv("LOG_LEVEL", "info")
+	os.Setenv("LOG_FORMAT", "xml")
+	_, err = Load()
+	assert.Error(t, err)
+
+	os.Unsetenv("LOG_LEVEL")
+	os.Unsetenv("LOG_FORMAT")
+}
+
+func TestLoadDatabaseWriteRetries(t *testing.T) {
+	config, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, 3, config.Database.WriteRetries)
+	assert.Equal(t, 50*time.Millisecond, config.Database.WriteRetryBackoff)
+	assert.Equal(t, time.Second, config.Database.WriteRetryMaxBackoff)
+
+	os.Setenv("DB_WRITE_RETRIES", "5")
+	os.Setenv("DB_WRITE_RETRY_BACKOFF", "100")
+	os.Setenv("DB_WRITE_RETRY_MAX_BACKOFF", "400")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, 5, config.Database.WriteRetries)
+	assert.Equal(t, 100*time.Millisecond, config.Database.WriteRetryBackoff)
+	assert.Equal(t, 400*time.Millisecond, config.Database.WriteRetryMaxBackoff)
+
+	// The cap must not be below the initial backoff
+	os.Setenv("DB_WRITE_RETRY_MAX_BACKOFF", "50")
+	_, err = Load()
+	assert.Error(t, err)
+
+	os.Setenv("DB_WRITE_RETRY_MAX_BACKOFF", "400")
+	os.Setenv("DB_WRITE_RETRIES", "-1")
+	_, err = Load()
+	assert.Error(t, err)
+
+	os.Unsetenv("DB_WRITE_RETRIES")
+	os.Unsetenv("DB_WRITE_RETRY_BACKOFF")
+	os.Unsetenv("DB_WRITE_RETRY_MAX_BACKOFF")
+}
+
+func TestLoadSeed(t *testing.T) {
+	config, err := Load()
+	require.NoError(t, err)
+	assert.False(t, config.Database.Seed)
+
+	os.Setenv("SEED", "true")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.True(t, config.Database.Seed)
+
+	os.Unsetenv("SEED")
+}
+
+func TestLoadRequireAgreementMatch(t *testing.T) {
+	config, err := Load()
+	require.NoError(t, err)
+	assert.False(t, config.Loan.RequireAgreementMatch)
+
+	os.Setenv("REQUIRE_AGREEMENT_MATCH", "true")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.True(t, config.Loan.RequireAgreementMatch)
+
+	os.Unsetenv("REQUIRE_AGREEMENT_MATCH")
+}
+
+func TestLoadCurrencies(t *testing.T) {
+	config, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, "USD", config.Loan.BaseCurrency)
+	assert.Equal(t, []string{"USD"}, config.Loan.AllowedCurrencies)
+
+	os.Setenv("BASE_CURRENCY", "EUR")
+	os.Setenv("ALLOWED_CURRENCIES", "EUR, USD,IDR")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, "EUR", config.Loan.BaseCurrency)
+	assert.Equal(t, []string{"EUR", "USD", "IDR"}, config.Loan.AllowedCurrencies)
+
+	// The base currency must be allowed
+	os.Setenv("ALLOWED_CURRENCIES", "USD,IDR")
+	_, err = Load()
+	assert.Error(t, err)
+
+	os.Setenv("ALLOWED_CURRENCIES", "EUR,usd")
+	_, err = Load()
+	assert.Error(t, err)
+
+	os.Unsetenv("ALLOWED_CURRENCIES")
+	os.Setenv("BASE_CURRENCY", "EURO")
+	_, err = Load()
+	assert.Error(t, err)
+
+	os.Unsetenv("BASE_CURRENCY")
+}
+
+func TestLoadJWTSecret(t *testing.T) {
+	config, err := Load()
+	require.NoError(t, err)
+	assert.Empty(t, config.Auth.JWTSecret)
+
+	// Production refuses to run unauthenticated
+	os.Setenv("ENVIRONMENT", "production")
+	_, err = Load()
+	assert.Error(t, err)
+
+	os.Setenv("JWT_SECRET", "secret")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, "secret", config.Auth.JWTSecret)
+
+	os.Unsetenv("ENVIRONMENT")
+	os.Unsetenv("JWT_SECRET")
+}
+
+func TestLoadMaxInvestorsPerLoan(t *testing.T) {
+	config, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, 0, config.Loan.MaxInvestorsPerLoan)
+
+	os.Setenv("MAX_INVESTORS_PER_LOAN", "25")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, 25, config.Loan.MaxInvestorsPerLoan)
+
+	os.Setenv("MAX_INVESTORS_PER_LOAN", "-1")
+	_, err = Load()
+	assert.Error(t, err)
+
+	os.Unsetenv("MAX_INVESTORS_PER_LOAN")
+}
+
+func TestLoadMaxInvestorSharePercent(t *testing.T) {
+	config, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, 100.0, config.Loan.MaxInvestorSharePercent)
+
+	os.Setenv("MAX_INVESTOR_SHARE_PERCENT", "25.5")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, 25.5, config.Loan.MaxInvestorSharePercent)
+
+	for _, invalid := range []string{"0", "-5", "101"} {
+		os.Setenv("MAX_INVESTOR_SHARE_PERCENT", invalid)
+		_, err = Load()
+		assert.Error(t, err, invalid)
+	}
+
+	os.Unsetenv("MAX_INVESTOR_SHARE_PERCENT")
+}
+
+func TestLoadMinInvestorsForDisbursement(t *testing.T) {
+	config, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, 0, config.Loan.MinInvestorsForDisbursement)
+
+	os.Setenv("MIN_INVESTORS_FOR_DISBURSEMENT", "3")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, 3, config.Loan.MinInvestorsForDisbursement)
+
+	// The minimum cannot exceed the investor cap
+	os.Setenv("MAX_INVESTORS_PER_LOAN", "2")
+	_, err = Load()
+	assert.Error(t, err)
+	os.Unsetenv("MAX_INVESTORS_PER_LOAN")
+
+	os.Setenv("MIN_INVESTORS_FOR_DISBURSEMENT", "-1")
+	_, err = Load()
+	assert.Error(t, err)
+
+	os.Unsetenv("MIN_INVESTORS_FOR_DISBURSEMENT")
+}
+
+func TestLoadRateLimit(t *testing.T) {
+	config, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, 0, config.Server.RateLimitRPS)
+	assert.Equal(t, 10, config.Server.RateLimitBurst)
+
+	os.Setenv("RATE_LIMIT_RPS", "5")
+	os.Setenv("RATE_LIMIT_BURST", "20")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, 5, config.Server.RateLimitRPS)
+	assert.Equal(t, 20, config.Server.RateLimitBurst)
+
+	os.Setenv("RATE_LIMIT_RPS", "-1")
+	_, err = Load()
+	assert.Error(t, err)
+
+	os.Unsetenv("RATE_LIMIT_RPS")
+	os.Unsetenv("RATE_LIMIT_BURST")
+}
+
+func TestLoadTrustedProxies(t *testing.T) {
+	config, err := Load()
+	require.NoError(t, err)
+	assert.Empty(t, config.Server.TrustedProxies)
+
+	os.Setenv("TRUSTED_PROXIES", "10.0.0.1, 172.16.0.0/12")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.1", "172.16.0.0/12"}, config.Server.TrustedProxies)
+
+	os.Setenv("TRUSTED_PROXIES", "load-balancer")
+	_, err = Load()
+	assert.Error(t, err)
+
+	os.Unsetenv("TRUSTED_PROXIES")
+}
+
+func TestLoadCORS(t *testing.T) {
+	config, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"*"}, config.Server.CORSAllowedOrigins)
+	assert.Equal(t, []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}, config.Server.CORSAllowedMethods)
+	assert.Equal(t, []string{"Content-Type", "Authorization", "Idempotency-Key"}, config.Server.CORSAllowedHeaders)
+
+	os.Setenv("CORS_ALLOWED_ORIGINS", "https://app.example.com, https://admin.example.com")
+	os.Setenv("CORS_ALLOWED_METHODS", "GET,POST")
+	os.Setenv("CORS_ALLOWED_HEADERS", "Content-Type")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://app.example.com", "https://admin.example.com"}, config.Server.CORSAllowedOrigins)
+	assert.Equal(t, []string{"GET", "POST"}, config.Server.CORSAllowedMethods)
+	assert.Equal(t, []string{"Content-Type"}, config.Server.CORSAllowedHeaders)
+
+	os.Setenv("CORS_ALLOWED_ORIGINS", "*,https://app.example.com")
+	_, err = Load()
+	assert.Error(t, err)
+
+	os.Unsetenv("CORS_ALLOWED_ORIGINS")
+	os.Unsetenv("CORS_ALLOWED_METHODS")
+	os.Unsetenv("CORS_ALLOWED_HEADERS")
+}
+
+func TestLoadWebhooks(t *testing.T) {
+	config, err := Load()
+	require.NoError(t, err)
+	assert.Empty(t, config.Webhooks.URLs)
+	assert.Empty(t, config.Webhooks.Secret)
+	assert.Equal(t, 3, config.Webhooks.MaxRetries)
+	assert.Equal(t, time.Second, config.Webhooks.RetryBackoff)
+
+	os.Setenv("WEBHOOK_URLS", "https://accounting.example.com/hook, https://crm.example.com/hook,")
+	os.Setenv("WEBHOOK_SECRET", "secret")
+	config, err = Load()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://accounting.example.com/hook", "https://crm.example.com/hook"}, config.Webhooks.URLs)
+	assert.Equal(t, "secret", config.Webhooks.Secret)
+
+	os.Setenv("WEBHOOK_MAX_RETRIES", "-1")
+	_, err = Load()
+	assert.Error(t, err)
+
+	os.Unsetenv("WEBHOOK_URLS")
+	os.Unsetenv("WEBHOOK_SECRET")
+	os.Unsetenv("WEBHOOK_MAX_RETRIES")
+}
@@ -3,7 +3,13 @@ package database
 import (
 	"fmt"
 	"log"
+	"net"
+	"net/url"
+	"os"
+	"strings"
 
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -19,7 +25,23 @@ func NewConnection(cfg config.DatabaseConfig) (*gorm.DB, error) {
 	switch cfg.Driver {
 	case "sqlite":
 		db, err = gorm.Open(sqlite.Open(cfg.Name), &gorm.Config{
-			Logger: logger.Default.LogMode(logger.Info),
+			Logger: newLogger(cfg),
+		})
+	case "mysql":
+		dsn, dsnErr := MySQLDSN(cfg)
+		if dsnErr != nil {
+			return nil, dsnErr
+		}
+		db, err = gorm.Open(mysql.Open(dsn), &gorm.Config{
+			Logger: newLogger(cfg),
+		})
+	case "postgres":
+		dsn, dsnErr := PostgresDSN(cfg)
+		if dsnErr != nil {
+			return nil, dsnErr
+		}
+		db, err = gorm.Open(postgres.Open(dsn), &gorm.Config{
+			Logger: newLogger(cfg),
 		})
 	default:
 		return nil, fmt.Errorf("unsupported database driver: %s", cfg.Driver)
@@ -33,6 +55,89 @@ func NewConnection(cfg config.DatabaseConfig) (*gorm.DB, error) {
 	return db, nil
 }
 
+// MySQLDSN builds the data source name for a MySQL connection. Host, user and database name are
+// required; the port defaults to 3306. Times are parsed into time.Time in the local time zone.
+func MySQLDSN(cfg config.DatabaseConfig) (string, error) {
+	if err := requireConnectionFields(cfg); err != nil {
+		return "", err
+	}
+
+	port := cfg.Port
+	if port == "" {
+		port = "3306"
+	}
+
+	params := url.Values{}
+	params.Set("charset", "utf8mb4")
+	params.Set("parseTime", "True")
+	params.Set("loc", "Local")
+
+	return fmt.Sprintf("%s:%s@tcp(%s)/%s?%s", cfg.User, cfg.Password, net.JoinHostPort(cfg.Host, port), cfg.Name, params.Encode()), nil
+}
+
+// PostgresDSN builds the connection URL for a PostgreSQL connection. Host, user and database name are
+// required; the port defaults to 5432, and DB_SSLMODE is passed on as the sslmode when set.
+func PostgresDSN(cfg config.DatabaseConfig) (string, error) {
+	if err := requireConnectionFields(cfg); err != nil {
+		return "", err
+	}
+
+	port := cfg.Port
+	if port == "" {
+		port = "5432"
+	}
+
+	dsn := url.URL{
+		Scheme: "postgres",
+		User:   url.UserPassword(cfg.User, cfg.Password),
+		Host:   net.JoinHostPort(cfg.Host, port),
+		Path:   "/" + cfg.Name,
+	}
+	if cfg.SSLMode != "" {
+		dsn.RawQuery = url.Values{"sslmode": {cfg.SSLMode}}.Encode()
+	}
+	return dsn.String(), nil
+}
+
+// requireConnectionFields reports the host, user and database name missing for a network database driver
+func requireConnectionFields(cfg config.DatabaseConfig) error {
+	var missing []string
+	if cfg.Host == "" {
+		missing = append(missing, "DB_HOST")
+	}
+	if cfg.User == "" {
+		missing = append(missing, "DB_USER")
+	}
+	if cfg.Name == "" {
+		missing = append(missing, "DB_NAME")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%s connection requires %s", cfg.Driver, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// newLogger builds the GORM logger from the configured log level and slow query threshold
+func newLogger(cfg config.DatabaseConfig) logger.Interface {
+	levels := map[string]logger.LogLevel{
+		"silent": logger.Silent,
+		"error":  logger.Error,
+		"warn":   logger.Warn,
+		"info":   logger.Info,
+	}
+
+	level, ok := levels[cfg.LogLevel]
+	if !ok {
+		level = logger.Info
+	}
+
+	return logger.New(log.New(os.Stdout, "\r\n", log.LstdFlags), logger.Config{
+		SlowThreshold: cfg.SlowQueryThreshold,
+		LogLevel:      level,
+		Colorful:      true,
+	})
+}
+
 // CloseConnection closes the database connection
 func CloseConnection(db *gorm.DB) {
 	if db != nil {
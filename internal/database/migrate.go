@@ -0,0 +1,29 @@
+package database
+
+import (
+	"loan-service/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// Migrate auto migrates the schema of all entities
+func Migrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&domain.Loan{},
+		&domain.Investment{},
+		&domain.Repayment{},
+		&domain.Refund{},
+		&domain.AutoInvestRule{},
+		&domain.AutoInvestAction{},
+		&domain.ApprovalAmendment{},
+		&domain.Covenant{},
+		&domain.IdempotencyKey{},
+		&domain.IdempotentResponse{},
+		&domain.ApprovalRevocation{},
+		&domain.LoanTag{},
+		&domain.Investor{},
+		&domain.LoanVersion{},
+		&domain.LoanEvent{},
+		&domain.WebhookSubscription{},
+	)
+}
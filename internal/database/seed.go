@@ -0,0 +1,107 @@
+package database
+
+import (
+	"time"
+
+	"loan-service/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// Seed inserts demo loans in the proposed, approved, invested and disbursed states, so the API can be
+// explored without walking each loan through its lifecycle first. It does nothing when the database
+// already holds loans, deleted ones included, so it is safe to run on every startup. It returns the
+// number of loans inserted.
+func Seed(db *gorm.DB) (int, error) {
+	seeded := 0
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		if err := tx.Unscoped().Model(&domain.Loan{}).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return nil
+		}
+
+		loans := demoLoans(time.Now().UTC())
+		if err := tx.Create(&loans).Error; err != nil {
+			return err
+		}
+		seeded = len(loans)
+		return nil
+	})
+	return seeded, err
+}
+
+// demoLoans builds the demo loans, dated relative to now
+func demoLoans(now time.Time) []domain.Loan {
+	approvedAt := now.AddDate(0, 0, -20)
+	disbursedAt := now.AddDate(0, 0, -10)
+	approval := func(validatorID, proof string) *domain.ApprovalDetails {
+		return &domain.ApprovalDetails{
+			FieldValidatorProof: domain.ProofLinks{proof},
+			FieldValidatorID:    validatorID,
+			ApprovalDate:        approvedAt,
+		}
+	}
+
+	return []domain.Loan{
+		{
+			BorrowerID:      "borrower_001",
+			PrincipalAmount: domain.NewMoney(5000),
+			Rate:            10.0,
+			ROI:             8.0,
+			TermMonths:      12,
+			Status:          domain.StatusProposed,
+		},
+		{
+			BorrowerID:      "borrower_002",
+			PrincipalAmount: domain.NewMoney(10000),
+			Rate:            12.0,
+			ROI:             9.5,
+			TermMonths:      12,
+			Status:          domain.StatusApproved,
+			ApprovalDetails: approval("validator_001", "https://example.com/proofs/borrower_002.jpg"),
+			TotalInvested:   domain.NewMoney(4000),
+			Investments: []domain.Investment{
+				{InvestorID: "investor_001", Amount: domain.NewMoney(4000), NetAmount: domain.NewMoney(4000)},
+			},
+		},
+		{
+			BorrowerID:          "borrower_003",
+			PrincipalAmount:     domain.NewMoney(8000),
+			Rate:                11.0,
+			ROI:                 9.0,
+			TermMonths:          6,
+			Status:              domain.StatusInvested,
+			ApprovalDetails:     approval("validator_002", "https://example.com/proofs/borrower_003.jpg"),
+			AgreementLetterLink: "https://example.com/agreements/demo_borrower_003_agreement.pdf",
+			TotalInvested:       domain.NewMoney(8000),
+			Investments: []domain.Investment{
+				{InvestorID: "investor_001", Amount: domain.NewMoney(5000), NetAmount: domain.NewMoney(5000)},
+				{InvestorID: "investor_002", Amount: domain.NewMoney(3000), NetAmount: domain.NewMoney(3000)},
+			},
+		},
+		{
+			BorrowerID:          "borrower_004",
+			PrincipalAmount:     domain.NewMoney(12000),
+			Rate:                13.0,
+			ROI:                 10.0,
+			TermMonths:          12,
+			Status:              domain.StatusDisbursed,
+			ApprovalDetails:     approval("validator_001", "https://example.com/proofs/borrower_004.jpg"),
+			AgreementLetterLink: "https://example.com/agreements/demo_borrower_004_agreement.pdf",
+			TotalInvested:       domain.NewMoney(12000),
+			Investments: []domain.Investment{
+				{InvestorID: "investor_002", Amount: domain.NewMoney(7000), NetAmount: domain.NewMoney(7000)},
+				{InvestorID: "investor_003", Amount: domain.NewMoney(5000), NetAmount: domain.NewMoney(5000)},
+			},
+			DisbursementDetails: &domain.DisbursementDetails{
+				SignedAgreementLink: "https://example.com/signed-agreements/demo_borrower_004_signed.pdf",
+				FieldOfficerID:      "officer_001",
+				DisbursementDate:    disbursedAt,
+				DisbursedAmount:     domain.NewMoney(12000),
+			},
+		},
+	}
+}
@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// AgreementData is the structured content of a loan agreement, independent of how it is rendered
+type AgreementData struct {
+	LoanID              string              `json:"loan_id"`
+	BorrowerID          string              `json:"borrower_id"`
+	PrincipalAmount     Money               `json:"principal_amount"`
+	Rate                float64             `json:"rate"`
+	ROI                 float64             `json:"roi"`
+	TotalInvested       Money               `json:"total_invested"`
+	FieldValidatorID    string              `json:"field_validator_id,omitempty"`
+	ApprovalDate        *time.Time          `json:"approval_date,omitempty"`
+	FundedDate          *time.Time          `json:"funded_date,omitempty"`
+	DisbursementDate    *time.Time          `json:"disbursement_date,omitempty"`
+	AgreementLetterLink string              `json:"agreement_letter_link,omitempty"`
+	Investors           []AgreementInvestor `json:"investors"`
+}
+
+// AgreementInvestor is one investor's share of a loan as stated in the agreement
+type AgreementInvestor struct {
+	InvestorID     string  `json:"investor_id"`
+	Amount         Money   `json:"amount"`
+	Share          float64 `json:"share"`
+	ExpectedReturn float64 `json:"expected_return"`
+}
+
+// AgreementData assembles the agreement content of an invested or disbursed loan.
+// Investments are combined per investor, in the order investors first invested.
+func (l *Loan) AgreementData() (*AgreementData, error) {
+	if !l.CanGenerateAgreement() {
+		return nil, ErrAgreementNotAvailable
+	}
+
+	data := &AgreementData{
+		LoanID:              l.ID,
+		BorrowerID:          l.BorrowerID,
+		PrincipalAmount:     l.PrincipalAmount,
+		Rate:                l.Rate,
+		ROI:                 l.ROI,
+		TotalInvested:       l.TotalInvested,
+		AgreementLetterLink: l.AgreementLetterLink,
+		Investors:           []AgreementInvestor{},
+	}
+
+	if l.ApprovalDetails != nil && !l.ApprovalDetails.ApprovalDate.IsZero() {
+		data.FieldValidatorID = l.ApprovalDetails.FieldValidatorID
+		approvalDate := l.ApprovalDetails.ApprovalDate
+		data.ApprovalDate = &approvalDate
+	}
+	if l.DisbursementDetails != nil && !l.DisbursementDetails.DisbursementDate.IsZero() {
+		disbursementDate := l.DisbursementDetails.DisbursementDate
+		data.DisbursementDate = &disbursementDate
+	}
+
+	investments := make([]Investment, len(l.Investments))
+	copy(investments, l.Investments)
+	sort.SliceStable(investments, func(i, j int) bool {
+		return investments[i].CreatedAt.Before(investments[j].CreatedAt)
+	})
+
+	positions := make(map[string]int)
+	for _, investment := range investments {
+		index, ok := positions[investment.InvestorID]
+		if !ok {
+			index = len(data.Investors)
+			positions[investment.InvestorID] = index
+			data.Investors = append(data.Investors, AgreementInvestor{InvestorID: investment.InvestorID})
+		}
+		data.Investors[index].Amount += investment.Amount
+	}
+
+	for i := range data.Investors {
+		investor := &data.Investors[i]
+		if l.PrincipalAmount > 0 {
+			investor.Share = float64(investor.Amount) / float64(l.PrincipalAmount) * 100
+		}
+		investor.ExpectedReturn = investor.Amount.Float64() * l.ROI / 100
+	}
+
+	if len(investments) > 0 {
+		fundedDate := investments[len(investments)-1].CreatedAt
+		data.FundedDate = &fundedDate
+	}
+
+	return data, nil
+}
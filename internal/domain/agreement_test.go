@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoanAgreementData(t *testing.T) {
+	base := time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC)
+	loan := &Loan{
+		ID:              "loan_001",
+		BorrowerID:      "user123",
+		PrincipalAmount: NewMoney(10000.00),
+		Rate:            4.5,
+		ROI:             6.0,
+		TotalInvested:   NewMoney(10000.00),
+		Status:          StatusInvested,
+		ApprovalDetails: &ApprovalDetails{FieldValidatorID: "validator_001", ApprovalDate: base},
+		Investments: []Investment{
+			{InvestorID: "investor_002", Amount: NewMoney(5000.00), CreatedAt: base.Add(2 * time.Hour)},
+			{InvestorID: "investor_001", Amount: NewMoney(2000.00), CreatedAt: base.Add(time.Hour)},
+			{InvestorID: "investor_001", Amount: NewMoney(3000.00), CreatedAt: base.Add(3 * time.Hour)},
+		},
+	}
+
+	data, err := loan.AgreementData()
+	require.NoError(t, err)
+	assert.Equal(t, "user123", data.BorrowerID)
+	assert.Equal(t, "validator_001", data.FieldValidatorID)
+	assert.Equal(t, base, *data.ApprovalDate)
+	assert.Equal(t, base.Add(3*time.Hour), *data.FundedDate)
+	assert.Nil(t, data.DisbursementDate)
+
+	require.Len(t, data.Investors, 2)
+	assert.Equal(t, "investor_001", data.Investors[0].InvestorID)
+	assert.Equal(t, NewMoney(5000.00), data.Investors[0].Amount)
+	assert.Equal(t, 50.0, data.Investors[0].Share)
+	assert.Equal(t, 300.00, data.Investors[0].ExpectedReturn)
+	assert.Equal(t, "investor_002", data.Investors[1].InvestorID)
+}
+
+func TestLoanAgreementDataNotInvested(t *testing.T) {
+	loan := &Loan{ID: "loan_001", Status: StatusApproved}
+
+	_, err := loan.AgreementData()
+	assert.ErrorIs(t, err, ErrAgreementNotAvailable)
+}
@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ApprovalAmendment records a correction made to the approval details of a loan
+type ApprovalAmendment struct {
+	ID                          string     `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	LoanID                      string     `json:"loan_id" gorm:"not null;index;type:varchar(36)"`
+	PreviousFieldValidatorProof ProofLinks `json:"previous_field_validator_proof"`
+	PreviousFieldValidatorID    string     `json:"previous_field_validator_id"`
+	FieldValidatorProof         ProofLinks `json:"field_validator_proof"`
+	FieldValidatorID            string     `json:"field_validator_id"`
+	AdminOverride               bool       `json:"admin_override"`
+	CreatedAt                   time.Time  `json:"created_at"`
+}
+
+// BeforeCreate is a GORM hook that sets the ID before creating a record
+func (a *ApprovalAmendment) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == "" {
+		a.ID = uuid.New().String()
+	}
+	return nil
+}
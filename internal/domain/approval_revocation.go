@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ApprovalRevocation records an approval that was revoked, returning the loan to proposed
+type ApprovalRevocation struct {
+	ID                  string     `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	LoanID              string     `json:"loan_id" gorm:"not null;index;type:varchar(36)"`
+	FieldValidatorProof ProofLinks `json:"field_validator_proof"`
+	FieldValidatorID    string     `json:"field_validator_id"`
+	ApprovalDate        time.Time  `json:"approval_date"`
+	Reason              string     `json:"reason"`
+	CreatedAt           time.Time  `json:"created_at"`
+}
+
+// BeforeCreate is a GORM hook that sets the ID before creating a record
+func (r *ApprovalRevocation) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == "" {
+		r.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AutoInvestRule describes criteria under which an investor automatically invests in newly approved loans
+type AutoInvestRule struct {
+	ID              string    `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	InvestorID      string    `json:"investor_id" gorm:"not null;index"`
+	MinROI          float64   `json:"min_roi" gorm:"not null;default:0"`
+	MaxPerLoan      float64   `json:"max_per_loan" gorm:"not null"`
+	TotalBudget     float64   `json:"total_budget" gorm:"not null"`
+	RemainingBudget float64   `json:"remaining_budget" gorm:"not null"`
+	Active          bool      `json:"active" gorm:"not null;default:true"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// AutoInvestAction records an investment made on behalf of an investor by an auto-invest rule
+type AutoInvestAction struct {
+	ID         string    `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	RuleID     string    `json:"rule_id" gorm:"not null;index"`
+	InvestorID string    `json:"investor_id" gorm:"not null;index"`
+	LoanID     string    `json:"loan_id" gorm:"not null"`
+	Amount     float64   `json:"amount" gorm:"not null"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// BeforeCreate is a GORM hook that sets the ID before creating a record
+func (r *AutoInvestRule) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == "" {
+		r.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// BeforeCreate is a GORM hook that sets the ID before creating a record
+func (a *AutoInvestAction) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == "" {
+		a.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// Matches checks if the rule applies to the given loan
+func (r *AutoInvestRule) Matches(loan *Loan) bool {
+	return r.Active && r.RemainingBudget > 0 && loan.ROI >= r.MinROI
+}
+
+// AmountFor returns how much the rule would invest in a loan with the given remaining capacity
+func (r *AutoInvestRule) AmountFor(remainingCapacity float64) float64 {
+	amount := r.MaxPerLoan
+	if r.RemainingBudget < amount {
+		amount = r.RemainingBudget
+	}
+	if remainingCapacity < amount {
+		amount = remainingCapacity
+	}
+	return amount
+}
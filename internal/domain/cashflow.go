@@ -0,0 +1,53 @@
+package domain
+
+import "sort"
+
+// CashflowMonth is an investor's projected inflow for one calendar month
+type CashflowMonth struct {
+	Month     string  `json:"month"`
+	Amount    float64 `json:"amount"`
+	Principal float64 `json:"principal"`
+	Interest  float64 `json:"interest"`
+	Loans     int     `json:"loans"`
+}
+
+// ProjectCashflows aggregates the repayment schedules of an investor's loans by month, weighting
+// each installment by the investor's share of the loan's total investments.
+func ProjectCashflows(positions []InvestorPosition) []CashflowMonth {
+	months := make(map[string]*CashflowMonth)
+	for _, position := range positions {
+		var invested Money
+		for _, investment := range position.Loan.Investments {
+			invested += investment.Amount
+		}
+		if invested <= 0 || position.Contribution <= 0 {
+			continue
+		}
+		share := position.Contribution / invested.Float64()
+
+		for _, installment := range position.Loan.RepaymentSchedule() {
+			key := installment.DueDate.Format("2006-01")
+			month, ok := months[key]
+			if !ok {
+				month = &CashflowMonth{Month: key}
+				months[key] = month
+			}
+			month.Principal += installment.Principal * share
+			month.Interest += installment.Interest * share
+			month.Loans++
+		}
+	}
+
+	cashflows := make([]CashflowMonth, 0, len(months))
+	for _, month := range months {
+		month.Principal = roundCents(month.Principal)
+		month.Interest = roundCents(month.Interest)
+		month.Amount = roundCents(month.Principal + month.Interest)
+		cashflows = append(cashflows, *month)
+	}
+
+	sort.Slice(cashflows, func(i, j int) bool {
+		return cashflows[i].Month < cashflows[j].Month
+	})
+	return cashflows
+}
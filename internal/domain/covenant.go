@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Covenant is a condition attached to a loan approval that must be satisfied before disbursement
+type Covenant struct {
+	ID          string     `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	LoanID      string     `json:"loan_id" gorm:"not null;index;type:varchar(36)"`
+	Description string     `json:"description" gorm:"not null"`
+	Satisfied   bool       `json:"satisfied" gorm:"not null;default:false"`
+	SatisfiedAt *time.Time `json:"satisfied_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// BeforeCreate is a GORM hook that sets the ID before creating a record
+func (c *Covenant) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == "" {
+		c.ID = uuid.New().String()
+	}
+	return nil
+}
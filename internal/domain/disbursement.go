@@ -0,0 +1,55 @@
+package domain
+
+// DisburseMode controls whether loans can be disbursed before they are fully funded
+type DisburseMode string
+
+const (
+	DisburseFullOnly       DisburseMode = "full_only"
+	DisbursePartialAllowed DisburseMode = "partial_allowed"
+)
+
+// DisbursementPolicy describes when a loan may be disbursed.
+// The zero value only allows disbursing fully invested loans.
+type DisbursementPolicy struct {
+	Mode              DisburseMode
+	MinFundingPercent float64
+	// MinInvestors is the number of distinct investors a loan needs before disbursement, 0 for no minimum
+	MinInvestors int
+}
+
+// AllowsPartial reports whether approved loans may be disbursed with only part of the principal funded
+func (p DisbursementPolicy) AllowsPartial() bool {
+	return p.Mode == DisbursePartialAllowed
+}
+
+// HasMinimumInvestors reports whether the loan has enough distinct investors to be disbursed under the policy
+func (l *Loan) HasMinimumInvestors(policy DisbursementPolicy) bool {
+	return len(l.InvestorIDs()) >= policy.MinInvestors
+}
+
+// NewFSMForPolicy creates an FSM that additionally allows disbursing approved loans when the
+// policy permits partial funding
+func NewFSMForPolicy(policy DisbursementPolicy) *FSM {
+	fsm := NewFSM()
+	if policy.AllowsPartial() {
+		fsm.Transitions = append(fsm.Transitions, StateTransition{From: StatusApproved, To: StatusDisbursed, Action: "disburse"})
+	}
+	return fsm
+}
+
+// FundedPercent returns the share of the principal that has been invested, in percent
+func (l *Loan) FundedPercent() float64 {
+	if l.PrincipalAmount <= 0 {
+		return 0
+	}
+	return float64(l.TotalInvested) / float64(l.PrincipalAmount) * 100
+}
+
+// FundedPrincipal returns the principal the borrower repays: the amount paid out for disbursed
+// loans, which is less than the requested principal after a partial disbursement
+func (l *Loan) FundedPrincipal() Money {
+	if l.DisbursementDetails != nil && l.DisbursementDetails.DisbursedAmount > 0 {
+		return l.DisbursementDetails.DisbursedAmount
+	}
+	return l.PrincipalAmount
+}
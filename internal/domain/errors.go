@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"errors"
+)
+
+// Errors returned by loan business rules
+var (
+	ErrLoanNotApproved            = errors.New("loan is not in approved status")
+	ErrInvestmentExceedsPrincipal = errors.New("total investment amount would exceed loan principal")
+	ErrAgreementNotAvailable      = errors.New("agreement can only be generated for invested or disbursed loans")
+	ErrAgreementExists            = errors.New("loan already has a valid agreement, use force to regenerate")
+	ErrCannotReview               = errors.New("can only review loans in proposed status")
+	ErrCannotReject               = errors.New("can only reject loans in proposed or under review status")
+	ErrCannotCancel               = errors.New("can only cancel proposed or approved loans that are not fully invested")
+	ErrNotUnderReview             = errors.New("loan is not under review")
+	ErrFractionalAmount           = errors.New("amount must be in whole currency units")
+	ErrNoApproval                 = errors.New("loan has no approval to correct")
+	ErrApprovalNotCorrectable     = errors.New("approval details can only be corrected while the loan is approved")
+	ErrCovenantsNotSatisfied      = errors.New("all approval covenants must be satisfied before disbursement")
+	ErrCannotRevokeApproval       = errors.New("can only revoke the approval of approved loans without investments")
+	ErrInvestorInactive           = errors.New("investor is inactive and cannot make new investments")
+	ErrVersionNotFound            = errors.New("loan version not found")
+	ErrScheduleNotAvailable       = errors.New("repayment schedule is only available for invested or disbursed loans")
+	ErrConcurrentUpdate           = errors.New("loan was modified by another request, retry with the latest version")
+	ErrLoanNotDisbursed           = errors.New("repayments can only be recorded for disbursed loans")
+	ErrRepaymentExceedsBalance    = errors.New("repayment would exceed the remaining balance of principal and interest")
+	ErrCannotRestore              = errors.New("can only restore loans deleted in proposed status")
+	ErrInvestmentNotFound         = errors.New("investment not found in this loan")
+	ErrCannotWithdraw             = errors.New("investments can only be withdrawn while the loan is approved and not fully funded")
+	ErrNotInvestmentOwner         = errors.New("investments can only be withdrawn by the investor who made them")
+	ErrNotAccruing                = errors.New("interest only accrues on disbursed or repaid loans")
+	ErrPrincipalBelowInvested     = errors.New("principal amount must not be below the total invested")
+	ErrInvestmentDeadlinePassed   = errors.New("the investment deadline of the loan has passed")
+	ErrLoanHasHistory             = errors.New("cannot delete a loan with investments or lifecycle history")
+	ErrTransitionNotAllowed       = errors.New("the loan state machine does not allow this transition")
+)
+
+// InvestStatusError explains why a loan in its current status cannot take investments.
+// It matches ErrLoanNotApproved with errors.Is.
+type InvestStatusError struct {
+	Status LoanStatus
+}
+
+// Error describes the status that blocks the investment
+func (e *InvestStatusError) Error() string {
+	switch e.Status {
+	case StatusProposed:
+		return "cannot invest: loan is not approved yet"
+	case StatusUnderReview:
+		return "cannot invest: loan is under review"
+	case StatusInvested:
+		return "cannot invest: loan is already fully invested"
+	case StatusDisbursed:
+		return "cannot invest: loan already disbursed"
+	case StatusRepaid:
+		return "cannot invest: loan already repaid"
+	case StatusRejected:
+		return "cannot invest: loan was rejected"
+	case StatusCancelled:
+		return "cannot invest: loan was cancelled"
+	case StatusExpired:
+		return "cannot invest: loan expired before it was fully funded"
+	default:
+		return "cannot invest: " + ErrLoanNotApproved.Error()
+	}
+}
+
+// Unwrap returns ErrLoanNotApproved
+func (e *InvestStatusError) Unwrap() error {
+	return ErrLoanNotApproved
+}
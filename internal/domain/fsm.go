@@ -23,8 +23,18 @@ func NewFSM() *FSM {
 		CurrentState: StatusProposed,
 		Transitions: []StateTransition{
 			{From: StatusProposed, To: StatusApproved, Action: "approve"},
+			{From: StatusProposed, To: StatusUnderReview, Action: "review"},
+			{From: StatusUnderReview, To: StatusProposed, Action: "clear_review"},
+			{From: StatusUnderReview, To: StatusApproved, Action: "approve"},
+			{From: StatusProposed, To: StatusRejected, Action: "reject"},
+			{From: StatusUnderReview, To: StatusRejected, Action: "reject"},
 			{From: StatusApproved, To: StatusInvested, Action: "invest"},
+			{From: StatusApproved, To: StatusProposed, Action: "revoke_approval"},
+			{From: StatusProposed, To: StatusCancelled, Action: "cancel"},
+			{From: StatusApproved, To: StatusCancelled, Action: "cancel"},
+			{From: StatusApproved, To: StatusExpired, Action: "expire"},
 			{From: StatusInvested, To: StatusDisbursed, Action: "disburse"},
+			{From: StatusDisbursed, To: StatusRepaid, Action: "repay"},
 		},
 	}
 }
@@ -48,6 +58,17 @@ func (fsm *FSM) Transition(to LoanStatus) error {
 	return nil
 }
 
+// ActionFor returns the action of the valid transition from the current state to the given one.
+// It returns false when the state machine does not allow that transition.
+func (fsm *FSM) ActionFor(to LoanStatus) (string, bool) {
+	for _, transition := range fsm.Transitions {
+		if transition.From == fsm.CurrentState && transition.To == to {
+			return transition.Action, true
+		}
+	}
+	return "", false
+}
+
 // GetCurrentState returns the current state
 func (fsm *FSM) GetCurrentState() LoanStatus {
 	return fsm.CurrentState
@@ -68,3 +89,52 @@ func (fsm *FSM) GetValidTransitions() []StateTransition {
 	}
 	return valid
 }
+
+// ActionField describes a request field taken by the endpoint performing a transition action
+type ActionField struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Required bool   `json:"required"`
+}
+
+// actionFields lists the request fields of each transition action, matching the request bodies of their endpoints
+var actionFields = map[string][]ActionField{
+	"approve": {
+		{Name: "field_validator_proof", Type: "image_link[]", Required: true},
+		{Name: "field_validator_id", Type: "string", Required: true},
+		{Name: "covenants", Type: "string[]"},
+	},
+	"review": {
+		{Name: "reviewer_id", Type: "string", Required: true},
+		{Name: "review_notes", Type: "string", Required: true},
+	},
+	"reject": {
+		{Name: "reason", Type: "string", Required: true},
+		{Name: "field_validator_id", Type: "string", Required: true},
+	},
+	"revoke_approval": {
+		{Name: "reason", Type: "string", Required: true},
+	},
+	"cancel": {
+		{Name: "reason", Type: "string"},
+	},
+	"invest": {
+		{Name: "investor_id", Type: "string", Required: true},
+		{Name: "amount", Type: "money", Required: true},
+	},
+	"disburse": {
+		{Name: "signed_agreement_link", Type: "document_link", Required: true},
+		{Name: "field_officer_id", Type: "string", Required: true},
+	},
+	"repay": {
+		{Name: "amount", Type: "money", Required: true},
+		{Name: "repayment_date", Type: "datetime"},
+	},
+}
+
+// ActionFields returns the request fields of a transition action. Actions without a request body have none.
+func ActionFields(action string) []ActionField {
+	fields := make([]ActionField, len(actionFields[action]))
+	copy(fields, actionFields[action])
+	return fields
+}
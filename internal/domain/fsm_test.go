@@ -24,27 +24,58 @@ func TestFSMValidTransitions(t *testing.T) {
 	assert.Equal(t, StatusApproved, fsm.GetCurrentState())
 
 	// Test invalid transition
-	assert.False(t, fsm.CanTransition(StatusProposed))
+	assert.False(t, fsm.CanTransition(StatusDisbursed))
 
-	err = fsm.Transition(StatusProposed)
+	err = fsm.Transition(StatusDisbursed)
 	assert.Error(t, err)
 }
 
+func TestFSMForPolicy(t *testing.T) {
+	fsm := NewFSMForPolicy(DisbursementPolicy{Mode: DisburseFullOnly})
+	fsm.SetCurrentState(StatusApproved)
+	assert.False(t, fsm.CanTransition(StatusDisbursed))
+
+	fsm = NewFSMForPolicy(DisbursementPolicy{Mode: DisbursePartialAllowed})
+	fsm.SetCurrentState(StatusApproved)
+	assert.True(t, fsm.CanTransition(StatusDisbursed))
+	assert.True(t, fsm.CanTransition(StatusInvested))
+}
+
 func TestFSMGetValidTransitions(t *testing.T) {
 	fsm := NewFSM()
 	fsm.SetCurrentState(StatusProposed)
 
 	transitions := fsm.GetValidTransitions()
-	assert.Len(t, transitions, 1)
+	// Proposed state can be approved, put under review, rejected or cancelled
+	assert.Len(t, transitions, 4)
 	assert.Equal(t, StatusApproved, transitions[0].To)
 	assert.Equal(t, "approve", transitions[0].Action)
+	assert.Equal(t, StatusUnderReview, transitions[1].To)
+	assert.Equal(t, "review", transitions[1].Action)
+	assert.Equal(t, StatusRejected, transitions[2].To)
+	assert.Equal(t, "reject", transitions[2].Action)
+
+	fsm.SetCurrentState(StatusUnderReview)
+	transitions = fsm.GetValidTransitions()
+	// Under review state can be cleared back to proposed, approved or rejected
+	assert.Len(t, transitions, 3)
+	assert.Equal(t, StatusProposed, transitions[0].To)
+	assert.Equal(t, "clear_review", transitions[0].Action)
+	assert.Equal(t, StatusApproved, transitions[1].To)
+	assert.Equal(t, "approve", transitions[1].Action)
+	assert.Equal(t, StatusRejected, transitions[2].To)
+	assert.Equal(t, "reject", transitions[2].Action)
 
 	fsm.SetCurrentState(StatusApproved)
 	transitions = fsm.GetValidTransitions()
-	// Approved state has transition to invested
-	assert.Len(t, transitions, 1)
+	// Approved state can be invested, have its approval revoked, be cancelled or expire
+	assert.Len(t, transitions, 4)
 	assert.Equal(t, StatusInvested, transitions[0].To)
 	assert.Equal(t, "invest", transitions[0].Action)
+	assert.Equal(t, StatusProposed, transitions[1].To)
+	assert.Equal(t, "revoke_approval", transitions[1].Action)
+	assert.Equal(t, StatusExpired, transitions[3].To)
+	assert.Equal(t, "expire", transitions[3].Action)
 
 	fsm.SetCurrentState(StatusInvested)
 	transitions = fsm.GetValidTransitions()
@@ -55,7 +86,24 @@ func TestFSMGetValidTransitions(t *testing.T) {
 
 	fsm.SetCurrentState(StatusDisbursed)
 	transitions = fsm.GetValidTransitions()
-	// Disbursed state has no transitions
+	// Disbursed state has transition to repaid
+	assert.Len(t, transitions, 1)
+	assert.Equal(t, StatusRepaid, transitions[0].To)
+	assert.Equal(t, "repay", transitions[0].Action)
+
+	fsm.SetCurrentState(StatusRepaid)
+	transitions = fsm.GetValidTransitions()
+	// Repaid state has no transitions
+	assert.Len(t, transitions, 0)
+
+	fsm.SetCurrentState(StatusRejected)
+	transitions = fsm.GetValidTransitions()
+	// Rejected state has no transitions
+	assert.Len(t, transitions, 0)
+
+	fsm.SetCurrentState(StatusCancelled)
+	transitions = fsm.GetValidTransitions()
+	// Cancelled state has no transitions
 	assert.Len(t, transitions, 0)
 }
 
@@ -80,3 +128,18 @@ func TestFSMCompleteLifecycle(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, StatusDisbursed, fsm.GetCurrentState())
 }
+
+func TestActionFields(t *testing.T) {
+	fields := ActionFields("disburse")
+	assert.Equal(t, []ActionField{
+		{Name: "signed_agreement_link", Type: "document_link", Required: true},
+		{Name: "field_officer_id", Type: "string", Required: true},
+	}, fields)
+
+	// Callers get their own copy
+	fields[0].Required = false
+	assert.True(t, ActionFields("disburse")[0].Required)
+
+	assert.Empty(t, ActionFields("clear_review"))
+	assert.NotNil(t, ActionFields("clear_review"))
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"time"
+)
+
+// FundingWindow defines the days and hours during which investments are accepted
+type FundingWindow struct {
+	Days      []time.Weekday
+	OpenHour  int
+	CloseHour int
+	Location  *time.Location
+}
+
+// IsOpen checks if the window is open at the given time
+func (w FundingWindow) IsOpen(t time.Time) bool {
+	local := t.In(w.location())
+	return w.isFundingDay(local.Weekday()) && local.Hour() >= w.OpenHour && local.Hour() < w.CloseHour
+}
+
+// NextOpen returns the next time the window opens after the given time
+func (w FundingWindow) NextOpen(t time.Time) time.Time {
+	local := t.In(w.location())
+	for i := 0; i <= 7; i++ {
+		day := local.AddDate(0, 0, i)
+		if !w.isFundingDay(day.Weekday()) {
+			continue
+		}
+		open := time.Date(day.Year(), day.Month(), day.Day(), w.OpenHour, 0, 0, 0, day.Location())
+		if open.After(local) {
+			return open
+		}
+	}
+	return time.Time{}
+}
+
+func (w FundingWindow) isFundingDay(day time.Weekday) bool {
+	for _, d := range w.Days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+func (w FundingWindow) location() *time.Location {
+	if w.Location == nil {
+		return time.UTC
+	}
+	return w.Location
+}
@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func weekdayWindow() FundingWindow {
+	return FundingWindow{
+		Days:      []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+		OpenHour:  9,
+		CloseHour: 17,
+		Location:  time.UTC,
+	}
+}
+
+func TestFundingWindowIsOpen(t *testing.T) {
+	window := weekdayWindow()
+
+	// Wednesday 2024-01-10
+	assert.True(t, window.IsOpen(time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC)))
+	assert.True(t, window.IsOpen(time.Date(2024, 1, 10, 16, 59, 0, 0, time.UTC)))
+	assert.False(t, window.IsOpen(time.Date(2024, 1, 10, 17, 0, 0, 0, time.UTC)))
+	assert.False(t, window.IsOpen(time.Date(2024, 1, 10, 8, 59, 0, 0, time.UTC)))
+
+	// Saturday 2024-01-13
+	assert.False(t, window.IsOpen(time.Date(2024, 1, 13, 12, 0, 0, 0, time.UTC)))
+}
+
+func TestFundingWindowNextOpen(t *testing.T) {
+	window := weekdayWindow()
+
+	// Before opening on a weekday opens the same day
+	next := window.NextOpen(time.Date(2024, 1, 10, 7, 0, 0, 0, time.UTC))
+	assert.Equal(t, time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC), next)
+
+	// After closing on a weekday opens the next day
+	next = window.NextOpen(time.Date(2024, 1, 10, 18, 0, 0, 0, time.UTC))
+	assert.Equal(t, time.Date(2024, 1, 11, 9, 0, 0, 0, time.UTC), next)
+
+	// Friday evening opens on Monday
+	next = window.NextOpen(time.Date(2024, 1, 12, 18, 0, 0, 0, time.UTC))
+	assert.Equal(t, time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC), next)
+}
+
+func TestFundingWindowTimezone(t *testing.T) {
+	window := weekdayWindow()
+	window.Location = time.FixedZone("UTC+7", 7*60*60)
+
+	// 03:00 UTC is 10:00 in UTC+7
+	assert.True(t, window.IsOpen(time.Date(2024, 1, 10, 3, 0, 0, 0, time.UTC)))
+	// 12:00 UTC is 19:00 in UTC+7
+	assert.False(t, window.IsOpen(time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)))
+}
@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// IdempotencyKey maps a client-supplied key to the loan created by the request carrying it.
+// Keys are unique per borrower and stop being honoured once expired.
+type IdempotencyKey struct {
+	BorrowerID string    `json:"borrower_id" gorm:"primaryKey;type:varchar(255)"`
+	Key        string    `json:"key" gorm:"primaryKey;type:varchar(255)"`
+	LoanID     string    `json:"loan_id" gorm:"not null"`
+	ExpiresAt  time.Time `json:"expires_at" gorm:"not null"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// IsExpired checks if the key is no longer honoured at the given time
+func (k *IdempotencyKey) IsExpired(now time.Time) bool {
+	return !now.Before(k.ExpiresAt)
+}
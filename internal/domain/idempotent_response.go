@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+// IdempotentResponse is the stored response of a request made with an Idempotency-Key header.
+// Keys are unique per scope (the endpoint and loan) and the response is replayed for repeated
+// requests until the key expires. A zero StatusCode marks a request that is still in progress.
+type IdempotentResponse struct {
+	Scope      string    `json:"scope" gorm:"primaryKey;type:varchar(255)"`
+	Key        string    `json:"key" gorm:"primaryKey;type:varchar(255)"`
+	StatusCode int       `json:"status_code"`
+	Body       []byte    `json:"body"`
+	ExpiresAt  time.Time `json:"expires_at" gorm:"not null"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// IsExpired checks if the key is no longer honoured at the given time
+func (r *IdempotentResponse) IsExpired(now time.Time) bool {
+	return !now.Before(r.ExpiresAt)
+}
+
+// IsComplete reports whether the request finished and its response was stored
+func (r *IdempotentResponse) IsComplete() bool {
+	return r.StatusCode != 0
+}
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"math"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,11 +11,16 @@ import (
 // Investment represents an individual investment in a loan
 type Investment struct {
 	ID         string    `json:"id" gorm:"primaryKey;type:varchar(36)"`
-	LoanID     string    `json:"loan_id" gorm:"not null"`
+	LoanID     string    `json:"loan_id" gorm:"not null;index;type:varchar(36)"`
 	InvestorID string    `json:"investor_id" gorm:"not null"`
-	Amount     float64   `json:"amount" gorm:"not null"`
+	Amount     Money     `json:"amount" gorm:"not null"`
+	FeeAmount  Money     `json:"fee_amount" gorm:"default:0"`
+	NetAmount  Money     `json:"net_amount" gorm:"default:0"`
+	FeeBasis   FeeBasis  `json:"fee_basis" gorm:"type:varchar(5);default:gross"`
 	CreatedAt  time.Time `json:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at"`
+	// DeletedAt is set when the investment is withdrawn; withdrawn investments are kept for the audit trail
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
 // BeforeCreate is a GORM hook that sets the ID before creating a record
@@ -24,3 +30,127 @@ func (i *Investment) BeforeCreate(tx *gorm.DB) error {
 	}
 	return nil
 }
+
+// FundingAmount returns the part of the investment that counted toward the loan principal under the
+// fee basis it was made with
+func (i Investment) FundingAmount() Money {
+	if i.FeeBasis == FeeBasisNet {
+		return i.NetAmount
+	}
+	return i.Amount
+}
+
+// InvestorInvestment is an investment together with the status and terms of the loan it funds
+type InvestorInvestment struct {
+	Investment
+	LoanStatus LoanStatus
+	ROI        float64
+	TermMonths int
+}
+
+// ExpectedReturn returns the return the investment earns over the loan's term
+func (i InvestorInvestment) ExpectedReturn() Money {
+	loan := Loan{ROI: i.ROI, TermMonths: i.TermMonths}
+	return loan.ExpectedReturn(i.Amount)
+}
+
+// ExpectedReturn returns the return an amount earns at an annual ROI percentage over a term in months,
+// rounded to cents
+func ExpectedReturn(amount Money, roi float64, termMonths int) Money {
+	return Money(math.Round(float64(amount) * roi / 100 * float64(termMonths) / 12))
+}
+
+// ExpectedReturn returns the return an amount invested in the loan earns at its ROI over its term
+func (l *Loan) ExpectedReturn(amount Money) Money {
+	return ExpectedReturn(amount, l.ROI, l.Term())
+}
+
+// InvestorPosition is a loan annotated with one investor's total contribution to it
+type InvestorPosition struct {
+	Loan         Loan
+	Contribution float64
+}
+
+// InvestorContribution is the sum of one investor's investments in a loan
+type InvestorContribution struct {
+	InvestorID  string  `json:"investor_id"`
+	Amount      Money   `json:"amount"`
+	Investments int     `json:"investments"`
+	Percentage  float64 `json:"percentage"`
+}
+
+// Contributions sums the loan's investments per investor, in order of each investor's first investment.
+// Percentage is the share of the principal, rounded to two decimals.
+func (l *Loan) Contributions() []InvestorContribution {
+	index := make(map[string]int)
+	var contributions []InvestorContribution
+	for _, investment := range l.Investments {
+		i, ok := index[investment.InvestorID]
+		if !ok {
+			i = len(contributions)
+			index[investment.InvestorID] = i
+			contributions = append(contributions, InvestorContribution{InvestorID: investment.InvestorID})
+		}
+		contributions[i].Amount += investment.Amount
+		contributions[i].Investments++
+	}
+
+	for i := range contributions {
+		contributions[i].Percentage = ContributionPercentage(contributions[i].Amount, l.PrincipalAmount)
+	}
+	return contributions
+}
+
+// ContributionPercentage returns the share of the principal an amount contributes, rounded to two
+// decimals
+func ContributionPercentage(amount, principal Money) float64 {
+	if principal <= 0 {
+		return 0
+	}
+	return math.Round(float64(amount)/float64(principal)*100*100) / 100
+}
+
+// FeeBasis determines which investment amount counts toward funding a loan
+type FeeBasis string
+
+const (
+	// FeeBasisGross counts the full amount paid by the investor toward funding
+	FeeBasisGross FeeBasis = "gross"
+	// FeeBasisNet counts only the amount left after the investor fee toward funding
+	FeeBasisNet FeeBasis = "net"
+)
+
+// InvestorFee describes the fee charged to investors on each investment.
+// Rate is a percentage of the invested amount; the zero value charges no fee.
+type InvestorFee struct {
+	Rate  float64
+	Basis FeeBasis
+}
+
+// Apply splits an invested amount into the fee and the net amount, rounding the fee to cents
+func (f InvestorFee) Apply(amount Money) (fee Money, net Money) {
+	fee = Money(math.Round(float64(amount) * f.Rate / 100))
+	return fee, amount - fee
+}
+
+// FundingAmount returns the part of an invested amount that counts toward the loan principal
+func (f InvestorFee) FundingAmount(amount Money) Money {
+	if f.Basis == FeeBasisNet {
+		_, net := f.Apply(amount)
+		return net
+	}
+	return amount
+}
+
+// OverfundPolicy determines how an investment larger than a loan's remaining capacity is handled
+type OverfundPolicy string
+
+const (
+	// OverfundReject rejects investments exceeding the remaining capacity
+	OverfundReject OverfundPolicy = "reject"
+	// OverfundClamp reduces investments exceeding the remaining capacity to exactly the remainder
+	OverfundClamp OverfundPolicy = "clamp"
+	// OverfundAllowRemainder rejects investments exceeding the remaining capacity but always
+	// accepts an investment of exactly the remainder, however small
+	OverfundAllowRemainder OverfundPolicy = "allow_remainder"
+)
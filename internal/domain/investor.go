@@ -0,0 +1,27 @@
+package domain
+
+import "time"
+
+// InvestorStatus represents whether an investor may make new investments
+type InvestorStatus string
+
+const (
+	InvestorActive   InvestorStatus = "active"
+	InvestorInactive InvestorStatus = "inactive"
+)
+
+// Investor records the compliance status of an investor.
+// Investors are referenced by ID throughout the service, so an investor without a record is active.
+type Investor struct {
+	ID           string         `json:"id" gorm:"primaryKey;type:varchar(255)"`
+	Status       InvestorStatus `json:"status" gorm:"not null;default:'active'"`
+	StatusReason string         `json:"status_reason,omitempty"`
+	Email        string         `json:"email,omitempty"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+}
+
+// IsActive reports whether the investor may make new investments
+func (i *Investor) IsActive() bool {
+	return i.Status != InvestorInactive
+}
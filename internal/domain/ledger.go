@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// LedgerEntryType identifies the kind of funding event recorded in a loan ledger
+type LedgerEntryType string
+
+const (
+	// LedgerInvestment is money committed by an investor
+	LedgerInvestment LedgerEntryType = "investment"
+	// LedgerRefund is an investment returned to its investor
+	LedgerRefund LedgerEntryType = "refund"
+)
+
+// LedgerEntry is a single funding event of a loan with the total invested after it was applied.
+// Amount is signed: positive entries add to the funding, negative entries remove from it.
+type LedgerEntry struct {
+	Type          LedgerEntryType `json:"type"`
+	ReferenceID   string          `json:"reference_id"`
+	InvestorID    string          `json:"investor_id"`
+	Amount        Money           `json:"amount"`
+	FeeAmount     Money           `json:"fee_amount"`
+	TotalInvested Money           `json:"total_invested"`
+	Timestamp     time.Time       `json:"timestamp"`
+}
+
+// Ledger reconstructs the chronological funding events of the loan with running totals.
+// The fee basis decides whether the gross or net investment amount counts toward the total,
+// matching how TotalInvested was accumulated. Every refund takes the amount its investment counted
+// back off, so the investments include those withdrawn for the final total to match TotalInvested.
+func (l *Loan) Ledger(basis FeeBasis) []LedgerEntry {
+	entries := make([]LedgerEntry, 0, len(l.Investments)+len(l.Refunds))
+	counted := make(map[string]Money, len(l.Investments))
+	for _, investment := range l.Investments {
+		amount := investment.Amount
+		if basis == FeeBasisNet {
+			amount -= investment.FeeAmount
+		}
+		counted[investment.ID] = amount
+		entries = append(entries, LedgerEntry{
+			Type:        LedgerInvestment,
+			ReferenceID: investment.ID,
+			InvestorID:  investment.InvestorID,
+			Amount:      amount,
+			FeeAmount:   investment.FeeAmount,
+			Timestamp:   investment.CreatedAt,
+		})
+	}
+	for _, refund := range l.Refunds {
+		amount, ok := counted[refund.InvestmentID]
+		if !ok {
+			amount = refund.Amount
+		}
+		entries = append(entries, LedgerEntry{
+			Type:        LedgerRefund,
+			ReferenceID: refund.ID,
+			InvestorID:  refund.InvestorID,
+			Amount:      -amount,
+			Timestamp:   refund.RefundDate,
+		})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+
+	var total Money
+	for i := range entries {
+		total += entries[i].Amount
+		entries[i].TotalInvested = total
+	}
+	return entries
+}
+
+// InvestedTotal recomputes TotalInvested from the loan's investments that were not refunded, counting
+// the gross or net amount as the fee basis decides
+func (l *Loan) InvestedTotal(basis FeeBasis) Money {
+	refunded := make(map[string]bool, len(l.Refunds))
+	for _, refund := range l.Refunds {
+		refunded[refund.InvestmentID] = true
+	}
+
+	var total Money
+	for _, investment := range l.Investments {
+		if refunded[investment.ID] {
+			continue
+		}
+		amount := investment.Amount
+		if basis == FeeBasisNet {
+			amount -= investment.FeeAmount
+		}
+		total += amount
+	}
+	return total
+}
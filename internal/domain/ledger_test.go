@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoanLedger(t *testing.T) {
+	base := time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC)
+	loan := &Loan{
+		ID:              "loan_001",
+		PrincipalAmount: NewMoney(10000.00),
+		Investments: []Investment{
+			{ID: "inv_002", InvestorID: "investor_002", Amount: NewMoney(6000.00), FeeAmount: NewMoney(60.00), CreatedAt: base.Add(2 * time.Hour)},
+			{ID: "inv_001", InvestorID: "investor_001", Amount: NewMoney(4000.00), FeeAmount: NewMoney(40.00), CreatedAt: base.Add(time.Hour)},
+		},
+	}
+
+	entries := loan.Ledger(FeeBasisGross)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "inv_001", entries[0].ReferenceID)
+	assert.Equal(t, LedgerInvestment, entries[0].Type)
+	assert.Equal(t, NewMoney(4000.00), entries[0].TotalInvested)
+	assert.Equal(t, "inv_002", entries[1].ReferenceID)
+	assert.Equal(t, NewMoney(10000.00), entries[1].TotalInvested)
+
+	entries = loan.Ledger(FeeBasisNet)
+	require.Len(t, entries, 2)
+	assert.Equal(t, NewMoney(3960.00), entries[0].Amount)
+	assert.Equal(t, NewMoney(9900.00), entries[1].TotalInvested)
+}
+
+func TestLoanLedgerRefunds(t *testing.T) {
+	base := time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC)
+	loan := &Loan{
+		ID:              "loan_001",
+		PrincipalAmount: NewMoney(10000.00),
+		TotalInvested:   NewMoney(5940.00),
+		Investments: []Investment{
+			{ID: "inv_001", InvestorID: "investor_001", Amount: NewMoney(4000.00), FeeAmount: NewMoney(40.00), CreatedAt: base.Add(time.Hour)},
+			{ID: "inv_002", InvestorID: "investor_002", Amount: NewMoney(6000.00), FeeAmount: NewMoney(60.00), CreatedAt: base.Add(2 * time.Hour)},
+		},
+		Refunds: []Refund{
+			{ID: "ref_001", InvestmentID: "inv_001", InvestorID: "investor_001", Amount: NewMoney(4000.00), RefundDate: base.Add(3 * time.Hour)},
+		},
+	}
+
+	entries := loan.Ledger(FeeBasisGross)
+	require.Len(t, entries, 3)
+	assert.Equal(t, LedgerRefund, entries[2].Type)
+	assert.Equal(t, "ref_001", entries[2].ReferenceID)
+	assert.Equal(t, "investor_001", entries[2].InvestorID)
+	assert.Equal(t, NewMoney(-4000.00), entries[2].Amount)
+	assert.Equal(t, NewMoney(6000.00), entries[2].TotalInvested)
+	assert.Equal(t, loan.InvestedTotal(FeeBasisGross), entries[2].TotalInvested)
+
+	// The refund takes back the net amount the investment counted
+	entries = loan.Ledger(FeeBasisNet)
+	require.Len(t, entries, 3)
+	assert.Equal(t, NewMoney(-3960.00), entries[2].Amount)
+	assert.Equal(t, loan.TotalInvested, entries[2].TotalInvested)
+}
+
+func TestLoanLedgerEmpty(t *testing.T) {
+	loan := &Loan{ID: "loan_001", Status: StatusProposed}
+	assert.Empty(t, loan.Ledger(FeeBasisGross))
+}
+
+func TestLoanInvestedTotal(t *testing.T) {
+	loan := &Loan{
+		ID:              "loan_001",
+		PrincipalAmount: NewMoney(10000.00),
+		TotalInvested:   NewMoney(12345.00),
+		Investments: []Investment{
+			{ID: "inv_001", InvestorID: "investor_001", Amount: NewMoney(4000.00), FeeAmount: NewMoney(40.00)},
+			{ID: "inv_002", InvestorID: "investor_002", Amount: NewMoney(6000.00), FeeAmount: NewMoney(60.00)},
+		},
+	}
+
+	assert.Equal(t, NewMoney(10000.00), loan.InvestedTotal(FeeBasisGross))
+	assert.Equal(t, NewMoney(9900.00), loan.InvestedTotal(FeeBasisNet))
+
+	// Refunded investments no longer count
+	loan.Refunds = []Refund{{InvestmentID: "inv_002", Amount: NewMoney(6000.00)}}
+	assert.Equal(t, NewMoney(4000.00), loan.InvestedTotal(FeeBasisGross))
+}
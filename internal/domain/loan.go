@@ -1,7 +1,8 @@
 package domain
 
 import (
-	"errors"
+	"math"
+	"net/url"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,35 +13,114 @@ import (
 type LoanStatus string
 
 const (
-	StatusProposed  LoanStatus = "proposed"
-	StatusApproved  LoanStatus = "approved"
-	StatusInvested  LoanStatus = "invested"
-	StatusDisbursed LoanStatus = "disbursed"
+	StatusProposed    LoanStatus = "proposed"
+	StatusUnderReview LoanStatus = "under_review"
+	StatusApproved    LoanStatus = "approved"
+	StatusInvested    LoanStatus = "invested"
+	StatusDisbursed   LoanStatus = "disbursed"
+	StatusRejected    LoanStatus = "rejected"
+	StatusCancelled   LoanStatus = "cancelled"
+	StatusRepaid      LoanStatus = "repaid"
+	StatusExpired     LoanStatus = "expired"
 )
 
+// IsTerminal checks if no further lifecycle transitions are expected from the status
+func (s LoanStatus) IsTerminal() bool {
+	return s == StatusRepaid || s == StatusRejected || s == StatusCancelled || s == StatusExpired
+}
+
+// Statuses returns every loan status in lifecycle order
+func Statuses() []LoanStatus {
+	return []LoanStatus{
+		StatusProposed, StatusUnderReview, StatusApproved, StatusInvested, StatusDisbursed,
+		StatusRepaid, StatusRejected, StatusCancelled, StatusExpired,
+	}
+}
+
+// ActiveStatuses returns the statuses of loans that are still in progress, i.e. not terminal
+func ActiveStatuses() []LoanStatus {
+	var active []LoanStatus
+	for _, status := range Statuses() {
+		if !status.IsTerminal() {
+			active = append(active, status)
+		}
+	}
+	return active
+}
+
+// LoanSortColumns returns the columns a loan list can be sorted by
+func LoanSortColumns() []string {
+	return []string{"created_at", "principal_amount", "status", "total_invested"}
+}
+
+// IsLoanSortColumn checks if a loan list can be sorted by the column
+func IsLoanSortColumn(column string) bool {
+	for _, allowed := range LoanSortColumns() {
+		if column == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultCurrency is the ISO 4217 currency of loans created without one
+const DefaultCurrency = "USD"
+
 // Loan represents a loan entity
 type Loan struct {
 	ID                  string               `json:"id" gorm:"primaryKey;type:varchar(36)"`
 	BorrowerID          string               `json:"borrower_id" gorm:"not null"`
-	PrincipalAmount     float64              `json:"principal_amount" gorm:"not null"`
+	PrincipalAmount     Money                `json:"principal_amount" gorm:"not null"`
+	Currency            string               `json:"currency" gorm:"type:varchar(3);not null;default:'USD'"`
 	Rate                float64              `json:"rate" gorm:"not null"`
 	ROI                 float64              `json:"roi" gorm:"not null"`
+	TermMonths          int                  `json:"term_months" gorm:"not null;default:12"`
 	AgreementLetterLink string               `json:"agreement_letter_link"`
+	AgreementAttempts   int                  `json:"agreement_attempts" gorm:"default:0"`
+	AgreementLastError  string               `json:"agreement_last_error,omitempty"`
 	Status              LoanStatus           `json:"status" gorm:"not null;default:'proposed'"`
+	StatusReason        string               `json:"status_reason,omitempty"`
+	ReviewDetails       *ReviewDetails       `json:"review_details" gorm:"embedded"`
 	ApprovalDetails     *ApprovalDetails     `json:"approval_details" gorm:"embedded"`
+	InvestmentDeadline  *time.Time           `json:"investment_deadline,omitempty" gorm:"index"`
+	RejectionDetails    *RejectionDetails    `json:"rejection_details" gorm:"embedded"`
+	Covenants           []Covenant           `json:"covenants" gorm:"foreignKey:LoanID"`
+	Tags                []LoanTag            `json:"tags" gorm:"foreignKey:LoanID"`
 	Investments         []Investment         `json:"investments" gorm:"foreignKey:LoanID"`
-	TotalInvested       float64              `json:"total_invested" gorm:"default:0"`
+	TotalInvested       Money                `json:"total_invested" gorm:"default:0"`
 	DisbursementDetails *DisbursementDetails `json:"disbursement_details" gorm:"embedded"`
+	Repayments          []Repayment          `json:"repayments" gorm:"foreignKey:LoanID"`
+	TotalRepaid         Money                `json:"total_repaid" gorm:"default:0"`
+	Refunds             []Refund             `json:"refunds" gorm:"foreignKey:LoanID"`
+	Version             uint                 `json:"version" gorm:"not null;default:0"`
 	CreatedAt           time.Time            `json:"created_at"`
 	UpdatedAt           time.Time            `json:"updated_at"`
 	DeletedAt           gorm.DeletedAt       `json:"deleted_at,omitempty" gorm:"index"`
+
+	// pendingEvents holds the audit events of transitions not saved yet
+	pendingEvents []LoanEvent
+}
+
+// ReviewDetails contains information recorded when a loan is put under review
+type ReviewDetails struct {
+	ReviewerID  string    `json:"reviewer_id"`
+	ReviewNotes string    `json:"review_notes"`
+	ReviewDate  time.Time `json:"review_date"`
 }
 
 // ApprovalDetails contains information required for loan approval
 type ApprovalDetails struct {
-	FieldValidatorProof string    `json:"field_validator_proof"`
-	FieldValidatorID    string    `json:"field_validator_id"`
-	ApprovalDate        time.Time `json:"approval_date"`
+	FieldValidatorProof ProofLinks `json:"field_validator_proof"`
+	FieldValidatorID    string     `json:"field_validator_id"`
+	ApprovalDate        time.Time  `json:"approval_date"`
+}
+
+// RejectionDetails contains information recorded when a field validator refuses to approve a loan.
+// Columns are named explicitly so they do not clash with the embedded approval details.
+type RejectionDetails struct {
+	FieldValidatorID string    `json:"field_validator_id" gorm:"column:rejected_by"`
+	Reason           string    `json:"reason" gorm:"column:rejection_reason"`
+	RejectionDate    time.Time `json:"rejection_date"`
 }
 
 // DisbursementDetails contains information required for loan disbursement
@@ -48,6 +128,7 @@ type DisbursementDetails struct {
 	SignedAgreementLink string    `json:"signed_agreement_link"`
 	FieldOfficerID      string    `json:"field_officer_id"`
 	DisbursementDate    time.Time `json:"disbursement_date"`
+	DisbursedAmount     Money     `json:"disbursed_amount"`
 }
 
 // BeforeCreate is a GORM hook that sets the ID before creating a record
@@ -68,9 +149,54 @@ func (l *Loan) CanDelete() bool {
 	return l.Status == StatusProposed
 }
 
+// CanRestore checks if a deleted loan can be restored
+func (l *Loan) CanRestore() bool {
+	return l.DeletedAt.Valid && l.Status == StatusProposed
+}
+
+// CanReview checks if the loan can be put under review
+func (l *Loan) CanReview() bool {
+	return l.Status == StatusProposed
+}
+
+// CanClearReview checks if the loan can be returned from review to proposed
+func (l *Loan) CanClearReview() bool {
+	return l.Status == StatusUnderReview
+}
+
 // CanApprove checks if the loan can be approved
 func (l *Loan) CanApprove() bool {
-	return l.Status == StatusProposed
+	return l.Status == StatusProposed || l.Status == StatusUnderReview
+}
+
+// CanReject checks if the loan can be rejected
+func (l *Loan) CanReject() bool {
+	return l.Status == StatusProposed || l.Status == StatusUnderReview
+}
+
+// CanCancel checks if the loan can be cancelled, which is only possible before it is fully invested.
+// Fully invested loans must be disbursed instead.
+func (l *Loan) CanCancel() bool {
+	return l.Status == StatusProposed || l.Status == StatusApproved && l.TotalInvested < l.PrincipalAmount
+}
+
+// IsOverdue checks if the loan is still approved after its investment deadline
+func (l *Loan) IsOverdue(now time.Time) bool {
+	return l.Status == StatusApproved && l.InvestmentDeadline != nil && now.After(*l.InvestmentDeadline)
+}
+
+// CanCorrectApproval checks if the approval details can be corrected at the given time
+// without an override, i.e. within the correction window following approval
+func (l *Loan) CanCorrectApproval(now time.Time, window time.Duration) bool {
+	if l.ApprovalDetails == nil || l.ApprovalDetails.ApprovalDate.IsZero() {
+		return false
+	}
+	return !now.After(l.ApprovalDetails.ApprovalDate.Add(window))
+}
+
+// CanRevokeApproval checks if the approval can be revoked, which is only possible before any investment
+func (l *Loan) CanRevokeApproval() bool {
+	return l.Status == StatusApproved && l.TotalInvested == 0 && len(l.Investments) == 0
 }
 
 // CanInvest checks if the loan can receive investments
@@ -78,30 +204,149 @@ func (l *Loan) CanInvest() bool {
 	return l.Status == StatusApproved
 }
 
-// CanDisburse checks if the loan can be disbursed
-func (l *Loan) CanDisburse() bool {
-	return l.Status == StatusInvested && l.TotalInvested >= l.PrincipalAmount
+// CanDisburse checks if the loan can be disbursed. Fully invested loans can be disbursed;
+// when the policy allows partial funding, so can approved loans that reached the minimum funding.
+// Either way the loan needs the minimum number of investors of the policy.
+func (l *Loan) CanDisburse(policy DisbursementPolicy) bool {
+	if !l.HasMinimumInvestors(policy) {
+		return false
+	}
+	if l.Status == StatusInvested && l.TotalInvested >= l.PrincipalAmount {
+		return true
+	}
+	return policy.AllowsPartial() && l.Status == StatusApproved &&
+		l.TotalInvested > 0 && l.FundedPercent() >= policy.MinFundingPercent
 }
 
-// AddInvestment adds an investment to the loan
-func (l *Loan) AddInvestment(investorID string, amount float64) error {
+// CovenantsSatisfied checks if every covenant attached to the approval has been satisfied
+func (l *Loan) CovenantsSatisfied() bool {
+	for _, covenant := range l.Covenants {
+		if !covenant.Satisfied {
+			return false
+		}
+	}
+	return true
+}
+
+// CanGenerateAgreement checks if the loan is far enough in its lifecycle to have an agreement
+func (l *Loan) CanGenerateAgreement() bool {
+	return l.Status == StatusInvested || l.Status == StatusDisbursed
+}
+
+// HasValidAgreement checks if the loan has a usable agreement letter link
+func (l *Loan) HasValidAgreement() bool {
+	if l.AgreementLetterLink == "" {
+		return false
+	}
+	parsedURL, err := url.Parse(l.AgreementLetterLink)
+	if err != nil {
+		return false
+	}
+	return (parsedURL.Scheme == "http" || parsedURL.Scheme == "https") && parsedURL.Host != ""
+}
+
+// RemainingCapacity returns the largest amount an investor can still invest without the
+// funding exceeding the principal. Under the net fee basis the amount is grossed up for the fee.
+func (l *Loan) RemainingCapacity(fee InvestorFee) Money {
+	remaining := l.PrincipalAmount - l.TotalInvested
+	if remaining <= 0 {
+		return 0
+	}
+	if fee.Basis != FeeBasisNet || fee.Rate == 0 {
+		return remaining
+	}
+
+	// Step down a cent at a time to absorb the rounding of the fee
+	amount := Money(math.Floor(float64(remaining) * 100 / (100 - fee.Rate)))
+	for amount > 0 && fee.FundingAmount(amount) > remaining {
+		amount--
+	}
+	return amount
+}
+
+// Invest adds an investment under the given overfund policy and returns the recorded investment.
+// With OverfundClamp an amount exceeding the remaining capacity is reduced to the remainder.
+func (l *Loan) Invest(investorID string, amount Money, fee InvestorFee, policy OverfundPolicy) (*Investment, error) {
+	if policy == OverfundClamp && l.CanInvest() {
+		if remaining := l.RemainingCapacity(fee); amount > remaining {
+			amount = remaining
+		}
+	}
+
+	if err := l.AddInvestmentWithFee(investorID, amount, fee); err != nil {
+		return nil, err
+	}
+	return &l.Investments[len(l.Investments)-1], nil
+}
+
+// InvestorIDs returns the IDs of the loan's investors, each listed once in order of their first investment
+func (l *Loan) InvestorIDs() []string {
+	seen := make(map[string]bool)
+	var ids []string
+	for _, investment := range l.Investments {
+		if !seen[investment.InvestorID] {
+			seen[investment.InvestorID] = true
+			ids = append(ids, investment.InvestorID)
+		}
+	}
+	return ids
+}
+
+// HasInvestor reports whether the investor already invested in the loan
+func (l *Loan) HasInvestor(investorID string) bool {
+	for _, investment := range l.Investments {
+		if investment.InvestorID == investorID {
+			return true
+		}
+	}
+	return false
+}
+
+// InvestedBy returns the sum of the investor's investments in the loan
+func (l *Loan) InvestedBy(investorID string) Money {
+	var total Money
+	for _, investment := range l.Investments {
+		if investment.InvestorID == investorID {
+			total += investment.Amount
+		}
+	}
+	return total
+}
+
+// AddInvestment adds an investment to the loan without an investor fee
+func (l *Loan) AddInvestment(investorID string, amount Money) error {
+	return l.AddInvestmentWithFee(investorID, amount, InvestorFee{})
+}
+
+// AddInvestmentWithFee adds an investment to the loan, recording the investor fee.
+// The fee basis decides whether the gross or net amount counts toward TotalInvested.
+func (l *Loan) AddInvestmentWithFee(investorID string, amount Money, fee InvestorFee) error {
 	if !l.CanInvest() {
-		return errors.New("loan is not in approved status")
+		return &InvestStatusError{Status: l.Status}
 	}
 
-	if l.TotalInvested+amount > l.PrincipalAmount {
-		return errors.New("total investment amount would exceed loan principal")
+	funding := fee.FundingAmount(amount)
+	if l.TotalInvested+funding > l.PrincipalAmount {
+		return ErrInvestmentExceedsPrincipal
 	}
 
+	feeAmount, netAmount := fee.Apply(amount)
+	basis := fee.Basis
+	if basis != FeeBasisNet {
+		basis = FeeBasisGross
+	}
 	investment := Investment{
 		ID:         uuid.New().String(),
 		LoanID:     l.ID,
 		InvestorID: investorID,
 		Amount:     amount,
+		FeeAmount:  feeAmount,
+		NetAmount:  netAmount,
+		FeeBasis:   basis,
 	}
 
 	l.Investments = append(l.Investments, investment)
-	l.TotalInvested += amount
+	l.TotalInvested += funding
 
 	// If total invested equals principal amount, automatically transition to invested
 	if l.TotalInvested >= l.PrincipalAmount {
@@ -110,3 +355,42 @@ func (l *Loan) AddInvestment(investorID string, amount float64) error {
 
 	return nil
 }
+
+// WithdrawInvestment removes an investment from a loan that is not fully funded yet, taking its
+// funding off TotalInvested as the fee basis counted it, and records the refund of its amount to the
+// investor. A non-empty investorID only lets that investor withdraw. It returns the refund recorded.
+func (l *Loan) WithdrawInvestment(investmentID, investorID string, basis FeeBasis, withdrawnAt time.Time) (*Refund, error) {
+	index := -1
+	for i := range l.Investments {
+		if l.Investments[i].ID == investmentID {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return nil, ErrInvestmentNotFound
+	}
+	investment := l.Investments[index]
+	if investorID != "" && investment.InvestorID != investorID {
+		return nil, ErrNotInvestmentOwner
+	}
+	if !l.CanInvest() {
+		return nil, ErrCannotWithdraw
+	}
+
+	funding := investment.Amount
+	if basis == FeeBasisNet {
+		funding -= investment.FeeAmount
+	}
+	l.Investments = append(l.Investments[:index], l.Investments[index+1:]...)
+	l.TotalInvested -= funding
+	l.Refunds = append(l.Refunds, Refund{
+		ID:           uuid.New().String(),
+		LoanID:       l.ID,
+		InvestmentID: investment.ID,
+		InvestorID:   investment.InvestorID,
+		Amount:       investment.Amount,
+		RefundDate:   withdrawnAt,
+	})
+	return &l.Refunds[len(l.Refunds)-1], nil
+}
@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// LoanEvent is an entry of a loan's audit trail, recording a status transition, who made it and when.
+// Events are only ever appended. Version is the loan version the transition produced. ManualOverride marks
+// transitions forced by an admin rather than made through the regular action.
+type LoanEvent struct {
+	ID             string            `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	LoanID         string            `json:"loan_id" gorm:"not null;index;type:varchar(36)"`
+	Version        uint              `json:"version"`
+	FromStatus     LoanStatus        `json:"from_status"`
+	ToStatus       LoanStatus        `json:"to_status" gorm:"not null"`
+	Action         string            `json:"action" gorm:"not null"`
+	ActorID        string            `json:"actor_id"`
+	ManualOverride bool              `json:"manual_override,omitempty" gorm:"not null;default:false"`
+	Metadata       map[string]string `json:"metadata,omitempty" gorm:"serializer:json"`
+	Timestamp      time.Time         `json:"timestamp"`
+}
+
+// BeforeCreate is a GORM hook that sets the ID before creating a record
+func (e *LoanEvent) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == "" {
+		e.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// RecordTransition queues an audit event for the loan's change from the given status to its current one.
+// Queued events are persisted together with the loan, so they are only kept if the loan is saved.
+func (l *Loan) RecordTransition(from LoanStatus, action, actorID string, at time.Time, metadata map[string]string) {
+	l.pendingEvents = append(l.pendingEvents, LoanEvent{
+		LoanID:     l.ID,
+		FromStatus: from,
+		ToStatus:   l.Status,
+		Action:     action,
+		ActorID:    actorID,
+		Metadata:   metadata,
+		Timestamp:  at,
+	})
+}
+
+// RecordManualOverride queues an audit event for a transition an admin forced from the given status to the
+// loan's current one, keeping the justification given for it
+func (l *Loan) RecordManualOverride(from LoanStatus, action, actorID string, at time.Time, justification string) {
+	l.RecordTransition(from, action, actorID, at, map[string]string{"justification": justification})
+	l.pendingEvents[len(l.pendingEvents)-1].ManualOverride = true
+}
+
+// TakePendingEvents returns the audit events queued on the loan and clears the queue
+func (l *Loan) TakePendingEvents() []LoanEvent {
+	events := l.pendingEvents
+	l.pendingEvents = nil
+	return events
+}
+
+// TermChanges describes the terms of the loan that differ from a previous copy of it. Each changed field
+// is listed with its new value, and its old value under the field name prefixed with previous_.
+func (l *Loan) TermChanges(previous *Loan) map[string]string {
+	changes := make(map[string]string)
+	record := func(field, before, after string) {
+		if before != after {
+			changes["previous_"+field] = before
+			changes[field] = after
+		}
+	}
+
+	record("principal_amount", previous.PrincipalAmount.String(), l.PrincipalAmount.String())
+	record("rate", formatPercent(previous.Rate), formatPercent(l.Rate))
+	record("roi", formatPercent(previous.ROI), formatPercent(l.ROI))
+	record("agreement_letter_link", previous.AgreementLetterLink, l.AgreementLetterLink)
+	return changes
+}
+
+// formatPercent formats a percentage with as many decimals as it needs
+func formatPercent(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -0,0 +1,42 @@
+package domain
+
+import "time"
+
+// LoanTag is a free-form label attached to a loan for categorization and filtering
+type LoanTag struct {
+	LoanID    string    `json:"loan_id" gorm:"primaryKey;type:varchar(36)"`
+	Tag       string    `json:"tag" gorm:"primaryKey;index"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TagMatch determines how a loan list filtered by several tags is matched
+type TagMatch string
+
+const (
+	// TagMatchAny matches loans carrying at least one of the tags
+	TagMatchAny TagMatch = "any"
+	// TagMatchAll matches loans carrying every one of the tags
+	TagMatchAll TagMatch = "all"
+)
+
+// SetTags replaces the loan's tags, dropping duplicates while keeping the given order
+func (l *Loan) SetTags(tags []string) {
+	seen := make(map[string]bool, len(tags))
+	l.Tags = make([]LoanTag, 0, len(tags))
+	for _, tag := range tags {
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		l.Tags = append(l.Tags, LoanTag{LoanID: l.ID, Tag: tag})
+	}
+}
+
+// TagNames returns the names of the loan's tags
+func (l *Loan) TagNames() []string {
+	names := make([]string, len(l.Tags))
+	for i, tag := range l.Tags {
+		names[i] = tag.Tag
+	}
+	return names
+}
@@ -1,9 +1,12 @@
 package domain
 
 import (
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestLoanCanUpdate(t *testing.T) {
@@ -30,6 +33,142 @@ func TestLoanCanApprove(t *testing.T) {
 	assert.False(t, loan.CanApprove())
 }
 
+func TestLoanCanReject(t *testing.T) {
+	loan := &Loan{Status: StatusProposed}
+	assert.True(t, loan.CanReject())
+
+	loan.Status = StatusUnderReview
+	assert.True(t, loan.CanReject())
+
+	loan.Status = StatusApproved
+	assert.False(t, loan.CanReject())
+
+	// Rejected loans cannot move on
+	loan.Status = StatusRejected
+	assert.False(t, loan.CanReject())
+	assert.False(t, loan.CanApprove())
+	assert.False(t, loan.CanInvest())
+	assert.False(t, loan.CanDisburse(DisbursementPolicy{Mode: DisbursePartialAllowed}))
+	assert.True(t, loan.Status.IsTerminal())
+}
+
+func TestLoanCanCancel(t *testing.T) {
+	loan := &Loan{Status: StatusProposed, PrincipalAmount: NewMoney(10000.00)}
+	assert.True(t, loan.CanCancel())
+
+	loan.Status = StatusApproved
+	assert.True(t, loan.CanCancel())
+
+	// Partially funded loans can still be cancelled, fully invested ones cannot
+	loan.TotalInvested = NewMoney(2500.00)
+	assert.True(t, loan.CanCancel())
+
+	loan.TotalInvested = NewMoney(10000.00)
+	loan.Status = StatusInvested
+	assert.False(t, loan.CanCancel())
+
+	loan.TotalInvested = 0
+	loan.Status = StatusUnderReview
+	assert.False(t, loan.CanCancel())
+
+	loan.Status = StatusCancelled
+	assert.False(t, loan.CanCancel())
+	assert.True(t, loan.Status.IsTerminal())
+}
+
+func TestActiveStatuses(t *testing.T) {
+	assert.Equal(t, []LoanStatus{StatusProposed, StatusUnderReview, StatusApproved, StatusInvested, StatusDisbursed}, ActiveStatuses())
+
+	for _, status := range Statuses() {
+		assert.Equal(t, !status.IsTerminal(), slices.Contains(ActiveStatuses(), status), status)
+	}
+}
+
+func TestLoanIsOverdue(t *testing.T) {
+	deadline := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	loan := &Loan{Status: StatusApproved}
+
+	// Loans without a deadline never become overdue
+	assert.False(t, loan.IsOverdue(deadline.AddDate(1, 0, 0)))
+
+	loan.InvestmentDeadline = &deadline
+	assert.False(t, loan.IsOverdue(deadline))
+	assert.True(t, loan.IsOverdue(deadline.Add(time.Second)))
+
+	loan.Status = StatusInvested
+	assert.False(t, loan.IsOverdue(deadline.Add(time.Second)))
+}
+
+func TestLoanRefundInvestments(t *testing.T) {
+	loan := &Loan{
+		ID:              "loan_001",
+		Status:          StatusApproved,
+		PrincipalAmount: NewMoney(10000.00),
+	}
+	require.NoError(t, loan.AddInvestment("investor_001", NewMoney(2500.00)))
+	require.NoError(t, loan.AddInvestment("investor_002", NewMoney(1500.00)))
+	invested := loan.TotalInvested
+
+	refundedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	refunds := loan.RefundInvestments(refundedAt)
+
+	require.Len(t, refunds, 2)
+	assert.Equal(t, loan.Investments[0].ID, refunds[0].InvestmentID)
+	assert.Equal(t, "investor_002", refunds[1].InvestorID)
+	assert.Equal(t, refundedAt, refunds[1].RefundDate)
+	assert.Equal(t, invested, loan.TotalRefunded())
+	assert.Equal(t, Money(0), loan.TotalInvested)
+}
+
+func TestExpectedReturn(t *testing.T) {
+	// 6% a year on 10,000 over 18 months
+	assert.Equal(t, NewMoney(900.00), ExpectedReturn(NewMoney(10000.00), 6.0, 18))
+	assert.Equal(t, NewMoney(600.00), ExpectedReturn(NewMoney(10000.00), 6.0, 12))
+	// Rounded to cents
+	assert.Equal(t, NewMoney(12.35), ExpectedReturn(NewMoney(333.33), 7.41, 6))
+	assert.Equal(t, Money(0), ExpectedReturn(NewMoney(10000.00), 0, 12))
+
+	// Loans without a term use the default term
+	loan := &Loan{ROI: 6.0}
+	assert.Equal(t, NewMoney(600.00), loan.ExpectedReturn(NewMoney(10000.00)))
+}
+
+func TestLoanInvestorIDs(t *testing.T) {
+	loan := &Loan{Investments: []Investment{
+		{InvestorID: "investor_002"},
+		{InvestorID: "investor_001"},
+		{InvestorID: "investor_002"},
+	}}
+	assert.Equal(t, []string{"investor_002", "investor_001"}, loan.InvestorIDs())
+}
+
+func TestLoanContributions(t *testing.T) {
+	loan := &Loan{PrincipalAmount: NewMoney(30000.00), Investments: []Investment{
+		{InvestorID: "investor_001", Amount: NewMoney(10000.00)},
+		{InvestorID: "investor_002", Amount: NewMoney(5000.00)},
+		{InvestorID: "investor_001", Amount: NewMoney(2500.00)},
+	}}
+
+	contributions := loan.Contributions()
+	require.Len(t, contributions, 2)
+	assert.Equal(t, InvestorContribution{InvestorID: "investor_001", Amount: NewMoney(12500.00), Investments: 2, Percentage: 41.67}, contributions[0])
+	assert.Equal(t, InvestorContribution{InvestorID: "investor_002", Amount: NewMoney(5000.00), Investments: 1, Percentage: 16.67}, contributions[1])
+}
+
+func TestLoanCanReview(t *testing.T) {
+	loan := &Loan{Status: StatusProposed}
+	assert.True(t, loan.CanReview())
+	assert.False(t, loan.CanClearReview())
+
+	loan.Status = StatusUnderReview
+	assert.False(t, loan.CanReview())
+	assert.True(t, loan.CanClearReview())
+	assert.True(t, loan.CanApprove())
+	assert.False(t, loan.CanInvest())
+	assert.False(t, loan.CanDelete())
+	assert.False(t, loan.CanUpdate())
+}
+
 func TestLoanCanInvest(t *testing.T) {
 	loan := &Loan{Status: StatusApproved}
 	assert.True(t, loan.CanInvest())
@@ -41,54 +180,250 @@ func TestLoanCanInvest(t *testing.T) {
 func TestLoanCanDisburse(t *testing.T) {
 	loan := &Loan{
 		Status:          StatusInvested,
-		TotalInvested:   25000.00,
-		PrincipalAmount: 25000.00,
+		TotalInvested:   NewMoney(25000.00),
+		PrincipalAmount: NewMoney(25000.00),
 	}
-	assert.True(t, loan.CanDisburse())
+	assert.True(t, loan.CanDisburse(DisbursementPolicy{}))
 
-	loan.TotalInvested = 20000.00
-	assert.False(t, loan.CanDisburse())
+	loan.TotalInvested = NewMoney(20000.00)
+	assert.False(t, loan.CanDisburse(DisbursementPolicy{}))
+}
+
+func TestLoanCanDisbursePartially(t *testing.T) {
+	loan := &Loan{
+		Status:          StatusApproved,
+		TotalInvested:   NewMoney(20000.00),
+		PrincipalAmount: NewMoney(25000.00),
+	}
+	partial := DisbursementPolicy{Mode: DisbursePartialAllowed, MinFundingPercent: 80}
+
+	assert.False(t, loan.CanDisburse(DisbursementPolicy{Mode: DisburseFullOnly, MinFundingPercent: 80}))
+	assert.True(t, loan.CanDisburse(partial))
+
+	loan.TotalInvested = NewMoney(19999.00)
+	assert.False(t, loan.CanDisburse(partial))
+
+	// Unfunded loans are never disbursed
+	loan.TotalInvested = 0
+	assert.False(t, loan.CanDisburse(DisbursementPolicy{Mode: DisbursePartialAllowed}))
+
+	loan.Status = StatusProposed
+	loan.TotalInvested = NewMoney(25000.00)
+	assert.False(t, loan.CanDisburse(partial))
+}
+
+func TestLoanCanDisburseMinimumInvestors(t *testing.T) {
+	loan := &Loan{
+		Status:          StatusInvested,
+		TotalInvested:   NewMoney(25000.00),
+		PrincipalAmount: NewMoney(25000.00),
+		Investments:     []Investment{{InvestorID: "investor_001", Amount: NewMoney(25000.00)}},
+	}
+	policy := DisbursementPolicy{MinInvestors: 3}
+
+	assert.False(t, loan.CanDisburse(policy))
+
+	loan.Investments = []Investment{
+		{InvestorID: "investor_001", Amount: NewMoney(10000.00)},
+		{InvestorID: "investor_002", Amount: NewMoney(10000.00)},
+		{InvestorID: "investor_001", Amount: NewMoney(5000.00)},
+	}
+	assert.False(t, loan.CanDisburse(policy))
+
+	loan.Investments[2].InvestorID = "investor_003"
+	assert.True(t, loan.CanDisburse(policy))
 }
 
 func TestLoanAddInvestment(t *testing.T) {
 	loan := &Loan{
 		Status:          StatusApproved,
-		PrincipalAmount: 25000.00,
+		PrincipalAmount: NewMoney(25000.00),
 		TotalInvested:   0.0,
 	}
 
-	err := loan.AddInvestment("investor_001", 10000.00)
+	err := loan.AddInvestment("investor_001", NewMoney(10000.00))
 	assert.NoError(t, err)
-	assert.Equal(t, 10000.00, loan.TotalInvested)
+	assert.Equal(t, NewMoney(10000.00), loan.TotalInvested)
 	assert.Len(t, loan.Investments, 1)
 
 	// Add more investment to reach full amount
-	err = loan.AddInvestment("investor_002", 15000.00)
+	err = loan.AddInvestment("investor_002", NewMoney(15000.00))
 	assert.NoError(t, err)
-	assert.Equal(t, 25000.00, loan.TotalInvested)
+	assert.Equal(t, NewMoney(25000.00), loan.TotalInvested)
+	assert.Equal(t, StatusInvested, loan.Status)
+}
+
+func TestLoanAddInvestmentWithFeeGrossBasis(t *testing.T) {
+	loan := &Loan{Status: StatusApproved, PrincipalAmount: NewMoney(10000.00)}
+	fee := InvestorFee{Rate: 2.0, Basis: FeeBasisGross}
+
+	err := loan.AddInvestmentWithFee("investor_001", NewMoney(10000.00), fee)
+	require.NoError(t, err)
+
+	// The full amount counts toward funding
+	assert.Equal(t, NewMoney(10000.00), loan.TotalInvested)
+	assert.Equal(t, StatusInvested, loan.Status)
+	assert.Equal(t, NewMoney(200.00), loan.Investments[0].FeeAmount)
+	assert.Equal(t, NewMoney(9800.00), loan.Investments[0].NetAmount)
+}
+
+func TestLoanAddInvestmentWithFeeNetBasis(t *testing.T) {
+	loan := &Loan{Status: StatusApproved, PrincipalAmount: NewMoney(10000.00)}
+	fee := InvestorFee{Rate: 2.0, Basis: FeeBasisNet}
+
+	err := loan.AddInvestmentWithFee("investor_001", NewMoney(10000.00), fee)
+	require.NoError(t, err)
+
+	// Only the net amount counts toward funding, so the loan is not fully invested yet
+	assert.Equal(t, NewMoney(9800.00), loan.TotalInvested)
+	assert.Equal(t, StatusApproved, loan.Status)
+
+	// A gross investment whose net amount exceeds the remaining capacity is rejected
+	err = loan.AddInvestmentWithFee("investor_002", NewMoney(250.00), fee)
+	assert.ErrorIs(t, err, ErrInvestmentExceedsPrincipal)
+
+	err = loan.AddInvestmentWithFee("investor_002", NewMoney(200.00), fee)
+	require.NoError(t, err)
+	assert.Equal(t, NewMoney(196.00), loan.Investments[1].NetAmount)
+	assert.Equal(t, NewMoney(9996.00), loan.TotalInvested)
+	assert.Equal(t, StatusApproved, loan.Status)
+}
+
+func TestLoanRemainingCapacity(t *testing.T) {
+	loan := &Loan{Status: StatusApproved, PrincipalAmount: NewMoney(10000.00), TotalInvested: NewMoney(9996.00)}
+
+	assert.Equal(t, NewMoney(4.00), loan.RemainingCapacity(InvestorFee{}))
+	assert.Equal(t, NewMoney(4.00), loan.RemainingCapacity(InvestorFee{Rate: 2.0, Basis: FeeBasisGross}))
+
+	// Under the net basis the remainder is grossed up so the net amount fills the loan
+	fee := InvestorFee{Rate: 2.0, Basis: FeeBasisNet}
+	capacity := loan.RemainingCapacity(fee)
+	assert.Equal(t, NewMoney(4.08), capacity)
+	assert.LessOrEqual(t, fee.FundingAmount(capacity), NewMoney(4.00))
+}
+
+func TestLoanInvestClamp(t *testing.T) {
+	loan := &Loan{Status: StatusApproved, PrincipalAmount: NewMoney(10000.00), TotalInvested: NewMoney(9000.00)}
+
+	investment, err := loan.Invest("investor_001", NewMoney(1500.00), InvestorFee{}, OverfundClamp)
+	require.NoError(t, err)
+	assert.Equal(t, NewMoney(1000.00), investment.Amount)
+	assert.Equal(t, NewMoney(10000.00), loan.TotalInvested)
 	assert.Equal(t, StatusInvested, loan.Status)
 }
 
+func TestLoanInvestRejectsOverfund(t *testing.T) {
+	for _, policy := range []OverfundPolicy{OverfundReject, OverfundAllowRemainder} {
+		loan := &Loan{Status: StatusApproved, PrincipalAmount: NewMoney(10000.00), TotalInvested: NewMoney(9000.00)}
+
+		_, err := loan.Invest("investor_001", NewMoney(1500.00), InvestorFee{}, policy)
+		assert.ErrorIs(t, err, ErrInvestmentExceedsPrincipal)
+
+		investment, err := loan.Invest("investor_001", NewMoney(1000.00), InvestorFee{}, policy)
+		require.NoError(t, err)
+		assert.Equal(t, NewMoney(1000.00), investment.Amount)
+	}
+}
+
 func TestLoanAddInvestmentExceedsLimit(t *testing.T) {
 	loan := &Loan{
 		Status:          StatusApproved,
-		PrincipalAmount: 25000.00,
+		PrincipalAmount: NewMoney(25000.00),
 		TotalInvested:   0.0,
 	}
 
-	err := loan.AddInvestment("investor_001", 30000.00)
+	err := loan.AddInvestment("investor_001", NewMoney(30000.00))
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "total investment amount would exceed loan principal")
 }
 
 func TestLoanAddInvestmentInvalidStatus(t *testing.T) {
-	loan := &Loan{
-		Status:          StatusProposed,
-		PrincipalAmount: 25000.00,
-		TotalInvested:   0.0,
+	tests := []struct {
+		status  LoanStatus
+		message string
+	}{
+		{StatusProposed, "cannot invest: loan is not approved yet"},
+		{StatusUnderReview, "cannot invest: loan is under review"},
+		{StatusInvested, "cannot invest: loan is already fully invested"},
+		{StatusDisbursed, "cannot invest: loan already disbursed"},
+		{StatusRepaid, "cannot invest: loan already repaid"},
+		{StatusRejected, "cannot invest: loan was rejected"},
+		{StatusCancelled, "cannot invest: loan was cancelled"},
 	}
 
-	err := loan.AddInvestment("investor_001", 10000.00)
-	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "loan is not in approved status")
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			loan := &Loan{
+				Status:          tt.status,
+				PrincipalAmount: NewMoney(25000.00),
+				TotalInvested:   0.0,
+			}
+
+			err := loan.AddInvestment("investor_001", NewMoney(10000.00))
+			assert.EqualError(t, err, tt.message)
+			assert.ErrorIs(t, err, ErrLoanNotApproved)
+			assert.Empty(t, loan.Investments)
+		})
+	}
+}
+
+func TestLoanWithdrawInvestment(t *testing.T) {
+	loan := &Loan{Status: StatusApproved, PrincipalAmount: NewMoney(10000.00)}
+	fee := InvestorFee{Rate: 1.0, Basis: FeeBasisNet}
+	require.NoError(t, loan.AddInvestmentWithFee("investor_001", NewMoney(3000.00), fee))
+	require.NoError(t, loan.AddInvestmentWithFee("investor_002", NewMoney(2000.00), fee))
+
+	withdrawnAt := time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC)
+	investmentID := loan.Investments[0].ID
+
+	// Other investors cannot withdraw the investment
+	_, err := loan.WithdrawInvestment(investmentID, "investor_002", FeeBasisNet, withdrawnAt)
+	assert.ErrorIs(t, err, ErrNotInvestmentOwner)
+	assert.Len(t, loan.Investments, 2)
+
+	refund, err := loan.WithdrawInvestment(investmentID, "investor_001", FeeBasisNet, withdrawnAt)
+	require.NoError(t, err)
+	assert.Equal(t, "investor_001", refund.InvestorID)
+	assert.Equal(t, investmentID, refund.InvestmentID)
+	assert.Equal(t, NewMoney(3000.00), refund.Amount)
+	assert.Equal(t, withdrawnAt, refund.RefundDate)
+	require.Len(t, loan.Refunds, 1)
+	require.Len(t, loan.Investments, 1)
+	assert.Equal(t, "investor_002", loan.Investments[0].InvestorID)
+	assert.Equal(t, NewMoney(1980.00), loan.TotalInvested)
+
+	_, err = loan.WithdrawInvestment("unknown", "", FeeBasisNet, withdrawnAt)
+	assert.ErrorIs(t, err, ErrInvestmentNotFound)
+
+	// Fully funded loans keep their investments
+	loan.Status = StatusInvested
+	_, err = loan.WithdrawInvestment(loan.Investments[0].ID, "", FeeBasisNet, withdrawnAt)
+	assert.ErrorIs(t, err, ErrCannotWithdraw)
+	assert.Len(t, loan.Investments, 1)
+}
+
+func TestLoanSetTags(t *testing.T) {
+	loan := &Loan{ID: "loan_001"}
+
+	loan.SetTags([]string{"pilot", "agriculture", "pilot"})
+	assert.Equal(t, []string{"pilot", "agriculture"}, loan.TagNames())
+	assert.Equal(t, "loan_001", loan.Tags[0].LoanID)
+
+	loan.SetTags(nil)
+	assert.Empty(t, loan.TagNames())
+}
+
+func TestLoanTermChanges(t *testing.T) {
+	previous := Loan{PrincipalAmount: NewMoney(25000.00), Rate: 4.5, ROI: 6.0}
+	loan := previous
+	assert.Empty(t, loan.TermChanges(&previous))
+
+	loan.PrincipalAmount = NewMoney(30000.00)
+	loan.ROI = 6.75
+	assert.Equal(t, map[string]string{
+		"previous_principal_amount": "25000.00",
+		"principal_amount":          "30000.00",
+		"previous_roi":              "6",
+		"roi":                       "6.75",
+	}, loan.TermChanges(&previous))
 }
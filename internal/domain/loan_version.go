@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"reflect"
+	"time"
+)
+
+// LoanVersion is a snapshot of a loan's fields recorded every time the loan is saved.
+// Versions are numbered from 1 per loan in the order they were recorded.
+type LoanVersion struct {
+	LoanID              string     `json:"loan_id" gorm:"primaryKey;type:varchar(36)"`
+	Version             int        `json:"version" gorm:"primaryKey;autoIncrement:false"`
+	Status              LoanStatus `json:"status"`
+	PrincipalAmount     Money      `json:"principal_amount"`
+	Rate                float64    `json:"rate"`
+	ROI                 float64    `json:"roi"`
+	TermMonths          int        `json:"term_months"`
+	TotalInvested       Money      `json:"total_invested"`
+	TotalRepaid         Money      `json:"total_repaid"`
+	AgreementLetterLink string     `json:"agreement_letter_link"`
+	FieldValidatorProof ProofLinks `json:"field_validator_proof"`
+	FieldValidatorID    string     `json:"field_validator_id"`
+	SignedAgreementLink string     `json:"signed_agreement_link"`
+	FieldOfficerID      string     `json:"field_officer_id"`
+	DisbursedAmount     Money      `json:"disbursed_amount"`
+	CreatedAt           time.Time  `json:"created_at"`
+}
+
+// FieldChange is a single field that differs between two loan versions
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// NewLoanVersion snapshots the current fields of a loan. The version number is assigned when it is recorded.
+func NewLoanVersion(loan *Loan) *LoanVersion {
+	version := &LoanVersion{
+		LoanID:              loan.ID,
+		Status:              loan.Status,
+		PrincipalAmount:     loan.PrincipalAmount,
+		Rate:                loan.Rate,
+		ROI:                 loan.ROI,
+		TermMonths:          loan.TermMonths,
+		TotalInvested:       loan.TotalInvested,
+		TotalRepaid:         loan.TotalRepaid,
+		AgreementLetterLink: loan.AgreementLetterLink,
+	}
+	if loan.ApprovalDetails != nil {
+		version.FieldValidatorProof = loan.ApprovalDetails.FieldValidatorProof
+		version.FieldValidatorID = loan.ApprovalDetails.FieldValidatorID
+	}
+	if loan.DisbursementDetails != nil {
+		version.SignedAgreementLink = loan.DisbursementDetails.SignedAgreementLink
+		version.FieldOfficerID = loan.DisbursementDetails.FieldOfficerID
+		version.DisbursedAmount = loan.DisbursementDetails.DisbursedAmount
+	}
+	return version
+}
+
+// versionFields lists the compared fields of a loan version in the order they are reported
+var versionFields = []struct {
+	name  string
+	value func(v *LoanVersion) interface{}
+}{
+	{"status", func(v *LoanVersion) interface{} { return v.Status }},
+	{"principal_amount", func(v *LoanVersion) interface{} { return v.PrincipalAmount }},
+	{"rate", func(v *LoanVersion) interface{} { return v.Rate }},
+	{"roi", func(v *LoanVersion) interface{} { return v.ROI }},
+	{"term_months", func(v *LoanVersion) interface{} { return v.TermMonths }},
+	{"total_invested", func(v *LoanVersion) interface{} { return v.TotalInvested }},
+	{"total_repaid", func(v *LoanVersion) interface{} { return v.TotalRepaid }},
+	{"agreement_letter_link", func(v *LoanVersion) interface{} { return v.AgreementLetterLink }},
+	{"field_validator_proof", func(v *LoanVersion) interface{} { return v.FieldValidatorProof }},
+	{"field_validator_id", func(v *LoanVersion) interface{} { return v.FieldValidatorID }},
+	{"signed_agreement_link", func(v *LoanVersion) interface{} { return v.SignedAgreementLink }},
+	{"field_officer_id", func(v *LoanVersion) interface{} { return v.FieldOfficerID }},
+	{"disbursed_amount", func(v *LoanVersion) interface{} { return v.DisbursedAmount }},
+}
+
+// DiffVersions returns the fields whose values differ between two versions of a loan
+func DiffVersions(from, to *LoanVersion) []FieldChange {
+	changes := []FieldChange{}
+	for _, field := range versionFields {
+		before, after := field.value(from), field.value(to)
+		if !reflect.DeepEqual(before, after) {
+			changes = append(changes, FieldChange{Field: field.name, From: before, To: after})
+		}
+	}
+	return changes
+}
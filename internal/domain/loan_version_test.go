@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffVersions(t *testing.T) {
+	loan := &Loan{ID: "loan_001", PrincipalAmount: NewMoney(25000.00), Rate: 4.5, ROI: 6.0, Status: StatusProposed}
+	proposed := NewLoanVersion(loan)
+
+	loan.Rate = 5.0
+	loan.Status = StatusApproved
+	loan.ApprovalDetails = &ApprovalDetails{FieldValidatorID: "validator_001", FieldValidatorProof: ProofLinks{"proof"}}
+	approved := NewLoanVersion(loan)
+
+	changes := DiffVersions(proposed, approved)
+	require.Len(t, changes, 4)
+	assert.Equal(t, FieldChange{Field: "status", From: StatusProposed, To: StatusApproved}, changes[0])
+	assert.Equal(t, FieldChange{Field: "rate", From: 4.5, To: 5.0}, changes[1])
+	assert.Equal(t, "field_validator_proof", changes[2].Field)
+	assert.Equal(t, FieldChange{Field: "field_validator_id", From: "", To: "validator_001"}, changes[3])
+
+	assert.Empty(t, DiffVersions(approved, approved))
+}
@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// Money is an amount of currency in whole cents. Sums and comparisons of Money are exact, unlike
+// float64 amounts, while clients and the database still see decimal numbers such as 25000.5.
+type Money int64
+
+// ErrSubCentAmount is returned when a decimal amount has more than two decimal places
+var ErrSubCentAmount = errors.New("amount must not have more than two decimal places")
+
+// NewMoney converts a decimal amount to Money, rounding to the nearest cent
+func NewMoney(amount float64) Money {
+	return Money(math.Round(amount * 100))
+}
+
+// Float64 returns the amount as a decimal number, e.g. for percentages and interest calculations
+func (m Money) Float64() float64 {
+	return float64(m) / 100
+}
+
+// IsWholeUnits reports whether the amount has no cents
+func (m Money) IsWholeUnits() bool {
+	return m%100 == 0
+}
+
+// String formats the amount with two decimal places
+func (m Money) String() string {
+	sign := ""
+	cents := int64(m)
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
+
+// MarshalJSON writes the amount as a decimal number
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.FormatFloat(m.Float64(), 'f', -1, 64)), nil
+}
+
+// UnmarshalJSON reads a decimal number exactly, rejecting fractions of a cent
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	amount, ok := new(big.Rat).SetString(string(data))
+	if !ok {
+		return fmt.Errorf("invalid amount: %s", data)
+	}
+
+	cents := amount.Mul(amount, big.NewRat(100, 1))
+	if !cents.IsInt() {
+		return ErrSubCentAmount
+	}
+	if !cents.Num().IsInt64() {
+		return fmt.Errorf("amount out of range: %s", data)
+	}
+
+	*m = Money(cents.Num().Int64())
+	return nil
+}
+
+// Value stores the amount as a decimal number, keeping the columns readable as currency amounts
+func (m Money) Value() (driver.Value, error) {
+	return m.Float64(), nil
+}
+
+// Scan reads a decimal amount from the database, rounding to the nearest cent
+func (m *Money) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*m = 0
+	case float64:
+		*m = NewMoney(v)
+	case int64:
+		*m = Money(v * 100)
+	case []byte:
+		return m.scanString(string(v))
+	case string:
+		return m.scanString(v)
+	default:
+		return fmt.Errorf("cannot scan %T into Money", value)
+	}
+	return nil
+}
+
+// scanString reads a decimal amount stored as text
+func (m *Money) scanString(value string) error {
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fmt.Errorf("cannot scan %q into Money: %w", value, err)
+	}
+	*m = NewMoney(amount)
+	return nil
+}
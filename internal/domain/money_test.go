@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMoneySumsExactly(t *testing.T) {
+	// float64 amounts drift: 0.1 + 0.2 is not 0.3
+	assert.NotEqual(t, 0.3, 0.1+float64Value(0.2))
+	assert.Equal(t, NewMoney(0.3), NewMoney(0.1)+NewMoney(0.2))
+
+	var total Money
+	for _, amount := range []float64{40000.10, 35000.20, 24999.70} {
+		total += NewMoney(amount)
+	}
+	assert.Equal(t, NewMoney(100000), total)
+}
+
+// float64Value keeps the compiler from folding the drifting sum into an exact constant
+func float64Value(v float64) float64 {
+	return v
+}
+
+func TestLoanAddInvestmentFillsPrincipalExactly(t *testing.T) {
+	loan := &Loan{Status: StatusApproved, PrincipalAmount: NewMoney(0.30)}
+
+	// Three investments of 0.10 sum to 0.30000000000000004 as float64, overshooting the principal
+	for i := 0; i < 3; i++ {
+		require.NoError(t, loan.AddInvestment("investor_001", NewMoney(0.10)))
+	}
+
+	assert.Equal(t, loan.PrincipalAmount, loan.TotalInvested)
+	assert.Equal(t, StatusInvested, loan.Status)
+}
+
+func TestMoneyJSON(t *testing.T) {
+	data, err := json.Marshal(struct {
+		Amount Money `json:"amount"`
+	}{Amount: NewMoney(25000.5)})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"amount": 25000.5}`, string(data))
+
+	tests := []struct {
+		input string
+		want  Money
+	}{
+		{"25000", 2500000},
+		{"25000.75", 2500075},
+		{"0.1", 10},
+		{"1e3", 100000},
+	}
+	for _, tt := range tests {
+		var amount Money
+		require.NoError(t, json.Unmarshal([]byte(tt.input), &amount), tt.input)
+		assert.Equal(t, tt.want, amount, tt.input)
+	}
+
+	var amount Money
+	assert.ErrorIs(t, json.Unmarshal([]byte("10.005"), &amount), ErrSubCentAmount)
+	assert.Error(t, json.Unmarshal([]byte(`"ten"`), &amount))
+}
+
+func TestMoneyScan(t *testing.T) {
+	var amount Money
+
+	require.NoError(t, amount.Scan(25000.75))
+	assert.Equal(t, Money(2500075), amount)
+
+	require.NoError(t, amount.Scan(int64(300)))
+	assert.Equal(t, Money(30000), amount)
+
+	require.NoError(t, amount.Scan([]byte("12.34")))
+	assert.Equal(t, Money(1234), amount)
+
+	value, err := amount.Value()
+	require.NoError(t, err)
+	assert.Equal(t, 12.34, value)
+}
+
+func TestMoneyString(t *testing.T) {
+	assert.Equal(t, "25000.50", NewMoney(25000.5).String())
+	assert.Equal(t, "-0.05", NewMoney(-0.05).String())
+}
@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ProofLinks are the image URLs a field validator captured as proof of a site visit
+type ProofLinks []string
+
+// UnmarshalJSON accepts a list of links or, for clients sending a single proof, one link
+func (p *ProofLinks) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var link string
+	if err := json.Unmarshal(data, &link); err == nil {
+		*p = ProofLinks{link}
+		return nil
+	}
+
+	var links []string
+	if err := json.Unmarshal(data, &links); err != nil {
+		return err
+	}
+	*p = links
+	return nil
+}
+
+// GormDataType stores the links in a text column
+func (ProofLinks) GormDataType() string {
+	return "text"
+}
+
+// Value stores the links as a JSON array
+func (p ProofLinks) Value() (driver.Value, error) {
+	if p == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal([]string(p))
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan reads links stored as a JSON array, or a single link stored before approvals took several
+func (p *ProofLinks) Scan(value interface{}) error {
+	var stored string
+	switch v := value.(type) {
+	case nil:
+		*p = nil
+		return nil
+	case []byte:
+		stored = string(v)
+	case string:
+		stored = v
+	default:
+		return fmt.Errorf("cannot scan %T into ProofLinks", value)
+	}
+
+	if stored == "" {
+		*p = nil
+		return nil
+	}
+	if !strings.HasPrefix(stored, "[") {
+		*p = ProofLinks{stored}
+		return nil
+	}
+	return json.Unmarshal([]byte(stored), (*[]string)(p))
+}
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProofLinksUnmarshalJSON(t *testing.T) {
+	var links ProofLinks
+	require.NoError(t, json.Unmarshal([]byte(`"https://example.com/a.jpg"`), &links))
+	assert.Equal(t, ProofLinks{"https://example.com/a.jpg"}, links)
+
+	require.NoError(t, json.Unmarshal([]byte(`["https://example.com/a.jpg","https://example.com/b.png"]`), &links))
+	assert.Equal(t, ProofLinks{"https://example.com/a.jpg", "https://example.com/b.png"}, links)
+
+	assert.Error(t, json.Unmarshal([]byte(`42`), &links))
+}
+
+func TestProofLinksScan(t *testing.T) {
+	links := ProofLinks{"https://example.com/a.jpg", "https://example.com/b.png?size=large&v=2"}
+	stored, err := links.Value()
+	require.NoError(t, err)
+
+	var scanned ProofLinks
+	require.NoError(t, scanned.Scan(stored))
+	assert.Equal(t, links, scanned)
+
+	// Loans approved before proofs were stored as a list hold the link itself
+	require.NoError(t, scanned.Scan("https://example.com/a.jpg"))
+	assert.Equal(t, ProofLinks{"https://example.com/a.jpg"}, scanned)
+
+	require.NoError(t, scanned.Scan(nil))
+	assert.Nil(t, scanned)
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Refund records an investment returned to its investor when a partially funded loan is cancelled or
+// expires, or when the investor withdraws it
+type Refund struct {
+	ID           string    `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	LoanID       string    `json:"loan_id" gorm:"not null;index;type:varchar(36)"`
+	InvestmentID string    `json:"investment_id" gorm:"not null"`
+	InvestorID   string    `json:"investor_id" gorm:"not null"`
+	Amount       Money     `json:"amount" gorm:"not null"`
+	RefundDate   time.Time `json:"refund_date"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// BeforeCreate is a GORM hook that sets the ID before creating a record
+func (r *Refund) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == "" {
+		r.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// RefundInvestments records a refund of the full amount of every investment in the loan and clears
+// its funding. It returns the refunds recorded.
+func (l *Loan) RefundInvestments(refundedAt time.Time) []Refund {
+	start := len(l.Refunds)
+	for _, investment := range l.Investments {
+		l.Refunds = append(l.Refunds, Refund{
+			ID:           uuid.New().String(),
+			LoanID:       l.ID,
+			InvestmentID: investment.ID,
+			InvestorID:   investment.InvestorID,
+			Amount:       investment.Amount,
+			RefundDate:   refundedAt,
+		})
+	}
+	l.TotalInvested = 0
+	return l.Refunds[start:]
+}
+
+// TotalRefunded returns the sum of the refunds made to the loan's investors
+func (l *Loan) TotalRefunded() Money {
+	var total Money
+	for _, refund := range l.Refunds {
+		total += refund.Amount
+	}
+	return total
+}
@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Repayment is a payment made by the borrower toward a disbursed loan
+type Repayment struct {
+	ID            string    `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	LoanID        string    `json:"loan_id" gorm:"not null;index;type:varchar(36)"`
+	Amount        Money     `json:"amount" gorm:"not null"`
+	RepaymentDate time.Time `json:"repayment_date"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// BeforeCreate is a GORM hook that sets the ID before creating a record
+func (r *Repayment) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == "" {
+		r.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// RepaymentSummary is the state of a loan's repayments: what the borrower owes in total, what has been
+// repaid so far and the repayments made
+type RepaymentSummary struct {
+	AmountDue        Money       `json:"amount_due"`
+	TotalRepaid      Money       `json:"total_repaid"`
+	RemainingBalance Money       `json:"remaining_balance"`
+	Repayments       []Repayment `json:"repayments"`
+}
+
+// RepaymentSummary summarises the loan's repayments
+func (l *Loan) RepaymentSummary() RepaymentSummary {
+	repayments := l.Repayments
+	if repayments == nil {
+		repayments = []Repayment{}
+	}
+	return RepaymentSummary{
+		AmountDue:        l.AmountDue(),
+		TotalRepaid:      l.TotalRepaid,
+		RemainingBalance: l.RemainingBalance(),
+		Repayments:       repayments,
+	}
+}
+
+// CanRepay checks if the loan can receive repayments
+func (l *Loan) CanRepay() bool {
+	return l.Status == StatusDisbursed
+}
+
+// AmountDue returns the principal and interest the borrower repays over the loan's repayment schedule
+func (l *Loan) AmountDue() Money {
+	var due Money
+	for _, installment := range l.RepaymentSchedule() {
+		due += NewMoney(installment.Payment)
+	}
+	return due
+}
+
+// RemainingBalance returns the part of the amount due that has not been repaid yet
+func (l *Loan) RemainingBalance() Money {
+	remaining := l.AmountDue() - l.TotalRepaid
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// Repay records a repayment made at the given time. The loan becomes repaid once the repayments
+// cover the amount due; a repayment exceeding the remaining balance is rejected.
+func (l *Loan) Repay(amount Money, repaidAt time.Time) (*Repayment, error) {
+	if !l.CanRepay() {
+		return nil, ErrLoanNotDisbursed
+	}
+	if amount > l.RemainingBalance() {
+		return nil, ErrRepaymentExceedsBalance
+	}
+
+	l.Repayments = append(l.Repayments, Repayment{
+		ID:            uuid.New().String(),
+		LoanID:        l.ID,
+		Amount:        amount,
+		RepaymentDate: repaidAt,
+	})
+	l.TotalRepaid += amount
+
+	if l.TotalRepaid >= l.AmountDue() {
+		l.Status = StatusRepaid
+	}
+	return &l.Repayments[len(l.Repayments)-1], nil
+}
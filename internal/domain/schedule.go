@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+// DefaultTermMonths is the repayment term of loans created without one
+const DefaultTermMonths = 12
+
+// Installment is one monthly payment of a repayment schedule
+type Installment struct {
+	Period    int       `json:"period"`
+	DueDate   time.Time `json:"due_date"`
+	Payment   float64   `json:"payment"`
+	Principal float64   `json:"principal"`
+	Interest  float64   `json:"interest"`
+	Balance   float64   `json:"balance"`
+}
+
+// AmortizationSchedule computes equal monthly installments repaying principal at the annual
+// percentage rate over the given number of months, with the first payment due a month after start.
+// Amounts are rounded to cents and the final installment absorbs the rounding so the balance ends at zero.
+func AmortizationSchedule(principal, annualRate float64, months int, start time.Time) []Installment {
+	if months <= 0 || principal <= 0 {
+		return []Installment{}
+	}
+
+	monthlyRate := annualRate / 100 / 12
+	payment := principal / float64(months)
+	if monthlyRate > 0 {
+		payment = principal * monthlyRate / (1 - math.Pow(1+monthlyRate, -float64(months)))
+	}
+	payment = roundCents(payment)
+
+	schedule := make([]Installment, 0, months)
+	balance := principal
+	for period := 1; period <= months; period++ {
+		interest := roundCents(balance * monthlyRate)
+		principalPart := roundCents(payment - interest)
+		if period == months || principalPart > balance {
+			principalPart = balance
+		}
+		balance = roundCents(balance - principalPart)
+
+		schedule = append(schedule, Installment{
+			Period:    period,
+			DueDate:   start.AddDate(0, period, 0),
+			Payment:   roundCents(principalPart + interest),
+			Principal: principalPart,
+			Interest:  interest,
+			Balance:   balance,
+		})
+	}
+	return schedule
+}
+
+// Term returns the loan's repayment term in months, falling back to the default for loans without one
+func (l *Loan) Term() int {
+	if l.TermMonths <= 0 {
+		return DefaultTermMonths
+	}
+	return l.TermMonths
+}
+
+// RepaymentSchedule computes the repayment schedule of a disbursed loan's funded principal starting at
+// its disbursement. Loans that have not been disbursed have no schedule yet.
+func (l *Loan) RepaymentSchedule() []Installment {
+	if l.DisbursementDetails == nil || l.DisbursementDetails.DisbursementDate.IsZero() {
+		return []Installment{}
+	}
+	return AmortizationSchedule(l.FundedPrincipal().Float64(), l.Rate, l.Term(), l.DisbursementDetails.DisbursementDate)
+}
+
+// Amortization returns the per-period payment breakdown of the loan. Disbursed loans use their
+// repayment schedule; other loans are projected as if disbursed at the given time.
+func (l *Loan) Amortization(projectFrom time.Time) []Installment {
+	if l.DisbursementDetails != nil && !l.DisbursementDetails.DisbursementDate.IsZero() {
+		return l.RepaymentSchedule()
+	}
+	return AmortizationSchedule(l.PrincipalAmount.Float64(), l.Rate, l.Term(), projectFrom)
+}
+
+// roundCents rounds an amount to whole cents
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
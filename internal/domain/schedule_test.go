@@ -0,0 +1,145 @@
+package domain
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAmortizationSchedule(t *testing.T) {
+	start := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	schedule := AmortizationSchedule(10000.00, 12.0, 12, start)
+	require.Len(t, schedule, 12)
+
+	assert.Equal(t, 888.49, schedule[0].Payment)
+	assert.Equal(t, 100.00, schedule[0].Interest)
+	assert.Equal(t, 788.49, schedule[0].Principal)
+	assert.Equal(t, 9211.51, schedule[0].Balance)
+	assert.Equal(t, start.AddDate(0, 1, 0), schedule[0].DueDate)
+
+	principal, payments := 0.0, 0.0
+	for _, installment := range schedule {
+		principal += installment.Principal
+		payments += installment.Payment
+	}
+	assert.InDelta(t, 10000.00, principal, 0.001)
+	assert.InDelta(t, 10661.85, payments, 0.05)
+	assert.Equal(t, 0.0, schedule[11].Balance)
+}
+
+func TestAmortizationScheduleZeroRate(t *testing.T) {
+	schedule := AmortizationSchedule(1000.00, 0, 3, time.Now())
+	require.Len(t, schedule, 3)
+
+	assert.Equal(t, 333.33, schedule[0].Payment)
+	assert.Equal(t, 0.0, schedule[0].Interest)
+	assert.Equal(t, 333.34, schedule[2].Payment)
+	assert.Equal(t, 0.0, schedule[2].Balance)
+}
+
+func TestAmortizationScheduleInvalid(t *testing.T) {
+	assert.Empty(t, AmortizationSchedule(1000.00, 5.0, 0, time.Now()))
+	assert.Empty(t, AmortizationSchedule(0, 5.0, 12, time.Now()))
+}
+
+func TestAmortizationScheduleFinalBalance(t *testing.T) {
+	tests := []struct {
+		principal float64
+		rate      float64
+		months    int
+	}{
+		{principal: 1000.00, rate: 7.3, months: 7},
+		{principal: 25000.00, rate: 4.5, months: 36},
+		{principal: 999.99, rate: 19.99, months: 11},
+		{principal: 100.00, rate: 0, months: 3},
+	}
+
+	for _, tt := range tests {
+		schedule := AmortizationSchedule(tt.principal, tt.rate, tt.months, time.Now())
+		require.Len(t, schedule, tt.months)
+
+		repaid := 0.0
+		for _, installment := range schedule {
+			assert.Equal(t, installment.Payment, roundCents(installment.Principal+installment.Interest))
+			repaid += installment.Principal
+		}
+		assert.Equal(t, 0.0, schedule[tt.months-1].Balance)
+		assert.Equal(t, tt.principal, roundCents(repaid))
+	}
+}
+
+func TestLoanRepaymentSchedule(t *testing.T) {
+	loan := &Loan{PrincipalAmount: NewMoney(12000.00), Rate: 6.0}
+	assert.Empty(t, loan.RepaymentSchedule())
+
+	disbursed := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	loan.DisbursementDetails = &DisbursementDetails{DisbursementDate: disbursed}
+	schedule := loan.RepaymentSchedule()
+	require.Len(t, schedule, DefaultTermMonths)
+	assert.Equal(t, disbursed.AddDate(0, 1, 0), schedule[0].DueDate)
+
+	loan.TermMonths = 24
+	assert.Len(t, loan.RepaymentSchedule(), 24)
+
+	// Partially disbursed loans repay only the funded principal
+	loan.DisbursementDetails.DisbursedAmount = NewMoney(9000.00)
+	repaid := 0.0
+	for _, installment := range loan.RepaymentSchedule() {
+		repaid += installment.Principal
+	}
+	assert.InDelta(t, 9000.00, repaid, 0.001)
+}
+
+func TestLoanAmortization(t *testing.T) {
+	projectFrom := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+	loan := &Loan{PrincipalAmount: NewMoney(12000.00), Rate: 6.0, TermMonths: 6}
+
+	schedule := loan.Amortization(projectFrom)
+	require.Len(t, schedule, 6)
+	assert.Equal(t, projectFrom.AddDate(0, 1, 0), schedule[0].DueDate)
+
+	disbursed := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	loan.DisbursementDetails = &DisbursementDetails{DisbursementDate: disbursed}
+	assert.Equal(t, loan.RepaymentSchedule(), loan.Amortization(projectFrom))
+}
+
+func TestProjectCashflows(t *testing.T) {
+	disbursed := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	newLoan := func(principal float64, term int, amounts ...float64) Loan {
+		loan := Loan{
+			PrincipalAmount:     NewMoney(principal),
+			Rate:                0,
+			TermMonths:          term,
+			DisbursementDetails: &DisbursementDetails{DisbursementDate: disbursed},
+		}
+		for _, amount := range amounts {
+			loan.Investments = append(loan.Investments, Investment{Amount: NewMoney(amount)})
+		}
+		return loan
+	}
+
+	positions := []InvestorPosition{
+		// A quarter of a 1200 loan repaid over 2 months
+		{Loan: newLoan(1200.00, 2, 300.00, 900.00), Contribution: 300.00},
+		// All of a 600 loan repaid over 3 months
+		{Loan: newLoan(600.00, 3, 600.00), Contribution: 600.00},
+	}
+
+	cashflows := ProjectCashflows(positions)
+	require.Len(t, cashflows, 3)
+	assert.Equal(t, "2024-04", cashflows[0].Month)
+	assert.Equal(t, 350.00, cashflows[0].Amount)
+	assert.Equal(t, 2, cashflows[0].Loans)
+	assert.Equal(t, "2024-06", cashflows[2].Month)
+	assert.Equal(t, 200.00, cashflows[2].Amount)
+	assert.Equal(t, 1, cashflows[2].Loans)
+
+	total := 0.0
+	for _, month := range cashflows {
+		total += month.Amount
+	}
+	assert.Equal(t, 900.00, math.Round(total*100)/100)
+}
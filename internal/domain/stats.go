@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+// OutstandingBalance is the aggregate outstanding balance of a group of disbursed loans
+type OutstandingBalance struct {
+	Group       string  `json:"group,omitempty"`
+	Loans       int64   `json:"loans"`
+	Principal   float64 `json:"principal"`
+	Outstanding float64 `json:"outstanding"`
+}
+
+// PlatformStats is a snapshot of aggregate loan statistics across the platform
+type PlatformStats struct {
+	CountsByStatus   map[LoanStatus]int64 `json:"counts_by_status" gorm:"-"`
+	TotalLoans       int64                `json:"total_loans"`
+	TotalPrincipal   float64              `json:"total_principal"`
+	TotalInvested    float64              `json:"total_invested"`
+	AveragePrincipal float64              `json:"average_principal"`
+	AverageRate      float64              `json:"average_rate"`
+	AverageROI       float64              `json:"average_roi"`
+	ComputedAt       time.Time            `json:"computed_at"`
+}
+
+// LoanAggregate summarises the loan book for the operations dashboard
+type LoanAggregate struct {
+	CountsByStatus            map[LoanStatus]int64 `gorm:"-"`
+	TotalLoans                int64
+	TotalPrincipalOutstanding Money
+	TotalInvested             Money
+	AverageROI                float64
+}
@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimelineEntry is a single dated event in the lifecycle of a loan
+type TimelineEntry struct {
+	LoanID    string     `json:"loan_id"`
+	Event     string     `json:"event"`
+	Status    LoanStatus `json:"status"`
+	ActorID   string     `json:"actor_id,omitempty"`
+	Amount    Money      `json:"amount,omitempty"`
+	Timestamp time.Time  `json:"timestamp"`
+}
+
+// TimelineEntry describes the event as a timeline entry. The amount is taken from the event's metadata,
+// which records it for refunds and withdrawals; an amount that cannot be read is reported as an error.
+func (e *LoanEvent) TimelineEntry() (TimelineEntry, error) {
+	entry := TimelineEntry{
+		LoanID:    e.LoanID,
+		Event:     e.Action,
+		Status:    e.ToStatus,
+		ActorID:   e.ActorID,
+		Timestamp: e.Timestamp,
+	}
+	if amount, ok := e.Metadata["amount"]; ok {
+		if err := entry.Amount.scanString(amount); err != nil {
+			return TimelineEntry{}, fmt.Errorf("event %s has an invalid amount: %w", e.ID, err)
+		}
+	}
+	return entry, nil
+}
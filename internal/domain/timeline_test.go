@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoanEventTimelineEntry(t *testing.T) {
+	at := time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC)
+	event := &LoanEvent{
+		LoanID:     "loan_001",
+		FromStatus: StatusProposed,
+		ToStatus:   StatusApproved,
+		Action:     "approve",
+		ActorID:    "validator_001",
+		Timestamp:  at,
+	}
+
+	entry, err := event.TimelineEntry()
+	require.NoError(t, err)
+	assert.Equal(t, TimelineEntry{LoanID: "loan_001", Event: "approve", Status: StatusApproved, ActorID: "validator_001", Timestamp: at}, entry)
+
+	// Refunds and withdrawals carry their amount in the metadata
+	event = &LoanEvent{
+		LoanID:    "loan_001",
+		ToStatus:  StatusCancelled,
+		Action:    "refund",
+		ActorID:   "investor_001",
+		Metadata:  map[string]string{"investment_id": "investment_001", "amount": "2500.00"},
+		Timestamp: at,
+	}
+	entry, err = event.TimelineEntry()
+	require.NoError(t, err)
+	assert.Equal(t, NewMoney(2500.00), entry.Amount)
+
+	// A malformed amount is reported rather than read as zero
+	event.Metadata["amount"] = "twenty"
+	_, err = event.TimelineEntry()
+	assert.Error(t, err)
+}
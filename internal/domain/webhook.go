@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Event types delivered to webhook subscribers
+const (
+	EventInvestmentCreated = "investment.created"
+	EventLoanInvested      = "loan.invested"
+	EventLoanStatusChanged = "loan.status_changed"
+)
+
+// EventTypes returns every event type subscribers can filter on
+func EventTypes() []string {
+	return []string{EventInvestmentCreated, EventLoanInvested, EventLoanStatusChanged}
+}
+
+// Event is a notification about something that happened to a loan
+type Event struct {
+	ID         string      `json:"id"`
+	Type       string      `json:"type"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+// NewEvent creates an event of the given type with a fresh ID
+func NewEvent(eventType string, occurredAt time.Time, data interface{}) Event {
+	return Event{ID: uuid.New().String(), Type: eventType, OccurredAt: occurredAt, Data: data}
+}
+
+// InvestmentCreatedData is the payload of an investment.created event
+type InvestmentCreatedData struct {
+	LoanID         string  `json:"loan_id"`
+	InvestmentID   string  `json:"investment_id"`
+	InvestorID     string  `json:"investor_id"`
+	Amount         Money   `json:"amount"`
+	TotalInvested  Money   `json:"total_invested"`
+	FundingPercent float64 `json:"funding_percent"`
+}
+
+// LoanInvestedData is the payload of a loan.invested event
+type LoanInvestedData struct {
+	LoanID              string `json:"loan_id"`
+	PrincipalAmount     Money  `json:"principal_amount"`
+	TotalInvested       Money  `json:"total_invested"`
+	Investors           int    `json:"investors"`
+	AgreementLetterLink string `json:"agreement_letter_link"`
+}
+
+// LoanStatusChangedData is the payload of a loan.status_changed event
+type LoanStatusChangedData struct {
+	LoanID     string     `json:"loan_id"`
+	FromStatus LoanStatus `json:"from_status"`
+	ToStatus   LoanStatus `json:"to_status"`
+}
+
+// WebhookSubscription registers a URL to receive events. A subscription without event types receives every event.
+type WebhookSubscription struct {
+	ID         string    `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	URL        string    `json:"url" gorm:"not null"`
+	EventTypes []string  `json:"event_types" gorm:"serializer:json"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// BeforeCreate is a GORM hook that sets the ID before creating a record
+func (s *WebhookSubscription) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == "" {
+		s.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// Subscribes reports whether the subscription receives events of the given type
+func (s *WebhookSubscription) Subscribes(eventType string) bool {
+	if len(s.EventTypes) == 0 {
+		return true
+	}
+	for _, subscribed := range s.EventTypes {
+		if subscribed == eventType {
+			return true
+		}
+	}
+	return false
+}
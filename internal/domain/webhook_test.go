@@ -0,0 +1,17 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookSubscriptionSubscribes(t *testing.T) {
+	all := &WebhookSubscription{}
+	assert.True(t, all.Subscribes(EventInvestmentCreated))
+	assert.True(t, all.Subscribes(EventLoanInvested))
+
+	filtered := &WebhookSubscription{EventTypes: []string{EventLoanInvested}}
+	assert.False(t, filtered.Subscribes(EventInvestmentCreated))
+	assert.True(t, filtered.Subscribes(EventLoanInvested))
+}
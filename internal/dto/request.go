@@ -1,35 +1,133 @@
 package dto
 
+import (
+	"encoding/json"
+	"time"
+
+	"loan-service/internal/domain"
+)
+
 // CreateLoanRequest represents the request body for creating a loan
 type CreateLoanRequest struct {
-	BorrowerID      string  `json:"borrower_id" binding:"required"`
-	PrincipalAmount float64 `json:"principal_amount" binding:"required,gt=0"`
-	Rate            float64 `json:"rate" binding:"required,gt=0"`
-	ROI             float64 `json:"roi" binding:"required,gt=0"`
+	BorrowerID      string       `json:"borrower_id" binding:"required"`
+	PrincipalAmount domain.Money `json:"principal_amount" binding:"required,gt=0"`
+	Currency        string       `json:"currency" binding:"omitempty,iso4217"`
+	Rate            float64      `json:"rate" binding:"required,gt=0"`
+	ROI             float64      `json:"roi" binding:"required,gt=0"`
+	TermMonths      int          `json:"term_months" binding:"omitempty,gt=0,lte=360"`
+	Tags            []string     `json:"tags" binding:"omitempty,max=20,dive,tag"`
+}
+
+// ValidateLoanBatchRequest represents the request body for validating a batch of loan creation requests.
+// Rows are kept raw so each one can be decoded and validated on its own.
+type ValidateLoanBatchRequest struct {
+	Loans []json.RawMessage `json:"loans" binding:"required,min=1,max=100"`
+}
+
+// CreateLoanBatchRequest represents the request body for creating a batch of loans. Rows are kept raw so
+// each one can be decoded and validated on its own; the batch size is capped by configuration.
+type CreateLoanBatchRequest struct {
+	Loans []json.RawMessage `json:"loans" binding:"required,min=1"`
 }
 
 // UpdateLoanRequest represents the request body for updating a loan
 type UpdateLoanRequest struct {
-	PrincipalAmount     *float64 `json:"principal_amount"`
-	Rate                *float64 `json:"rate"`
-	ROI                 *float64 `json:"roi"`
-	AgreementLetterLink *string  `json:"agreement_letter_link"`
+	PrincipalAmount     *domain.Money `json:"principal_amount" binding:"omitempty,gt=0"`
+	Rate                *float64      `json:"rate" binding:"omitempty,gt=0"`
+	ROI                 *float64      `json:"roi" binding:"omitempty,gt=0"`
+	AgreementLetterLink *string       `json:"agreement_letter_link"`
+	Tags                *[]string     `json:"tags" binding:"omitempty,max=20,dive,tag"`
 }
 
 // ApproveLoanRequest represents the request body for approving a loan
 type ApproveLoanRequest struct {
-	FieldValidatorProof string `json:"field_validator_proof" binding:"required,image_link"`
-	FieldValidatorID    string `json:"field_validator_id" binding:"required"`
+	FieldValidatorProof domain.ProofLinks `json:"field_validator_proof" binding:"required,min=1,dive,image_link"`
+	FieldValidatorID    string            `json:"field_validator_id" binding:"required"`
+	Covenants           []string          `json:"covenants" binding:"omitempty,dive,required"`
+}
+
+// CorrectApprovalRequest represents the request body for correcting the approval details of a loan
+type CorrectApprovalRequest struct {
+	FieldValidatorProof domain.ProofLinks `json:"field_validator_proof" binding:"required_without=FieldValidatorID,omitempty,min=1,dive,image_link"`
+	FieldValidatorID    string            `json:"field_validator_id" binding:"required_without=FieldValidatorProof"`
+}
+
+// CancelLoanRequest represents the optional request body for cancelling a loan
+type CancelLoanRequest struct {
+	Reason string `json:"reason"`
+}
+
+// RevokeApprovalRequest represents the request body for revoking a loan approval
+type RevokeApprovalRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// ForceTransitionRequest represents the request body for an admin forcing a loan into another status
+type ForceTransitionRequest struct {
+	Status        domain.LoanStatus `json:"status" binding:"required"`
+	Justification string            `json:"justification" binding:"required"`
+}
+
+// UpdateInvestorEmailRequest represents the request body for setting an investor's notification address
+type UpdateInvestorEmailRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// DeactivateInvestorRequest represents the request body for deactivating an investor
+type DeactivateInvestorRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// CreateWebhookRequest represents the request body for subscribing to events.
+// Leaving event_types empty subscribes to every event type.
+type CreateWebhookRequest struct {
+	URL        string   `json:"url" binding:"required,url"`
+	EventTypes []string `json:"event_types" binding:"omitempty,dive,oneof=investment.created loan.invested loan.status_changed"`
 }
 
 // InvestLoanRequest represents the request body for investing in a loan
 type InvestLoanRequest struct {
-	InvestorID string  `json:"investor_id" binding:"required"`
-	Amount     float64 `json:"amount" binding:"required,gt=0"`
+	InvestorID string       `json:"investor_id" binding:"required"`
+	Amount     domain.Money `json:"amount" binding:"required,gt=0"`
+	Currency   string       `json:"currency" binding:"omitempty,iso4217"`
 }
 
 // DisburseLoanRequest represents the request body for disbursing a loan
 type DisburseLoanRequest struct {
-	SignedAgreementLink string `json:"signed_agreement_link" binding:"required"`
+	SignedAgreementLink string `json:"signed_agreement_link" binding:"required,document_link"`
 	FieldOfficerID      string `json:"field_officer_id" binding:"required"`
 }
+
+// RecordRepaymentRequest represents the request body for recording a repayment. Without a
+// repayment_date the repayment is recorded as made now.
+type RecordRepaymentRequest struct {
+	Amount        domain.Money `json:"amount" binding:"required,gt=0"`
+	RepaymentDate *time.Time   `json:"repayment_date"`
+}
+
+// CreateAutoInvestRuleRequest represents the request body for creating an auto-invest rule
+type CreateAutoInvestRuleRequest struct {
+	MinROI      float64 `json:"min_roi" binding:"gte=0"`
+	MaxPerLoan  float64 `json:"max_per_loan" binding:"required,gt=0"`
+	TotalBudget float64 `json:"total_budget" binding:"required,gt=0"`
+}
+
+// UpdateAutoInvestRuleRequest represents the request body for updating an auto-invest rule
+type UpdateAutoInvestRuleRequest struct {
+	MinROI      *float64 `json:"min_roi" binding:"omitempty,gte=0"`
+	MaxPerLoan  *float64 `json:"max_per_loan" binding:"omitempty,gt=0"`
+	TotalBudget *float64 `json:"total_budget" binding:"omitempty,gt=0"`
+	Active      *bool    `json:"active"`
+}
+
+// RejectLoanRequest represents the request body for rejecting a loan
+type RejectLoanRequest struct {
+	Reason           string `json:"reason" binding:"required"`
+	FieldValidatorID string `json:"field_validator_id" binding:"required"`
+}
+
+// ReviewLoanRequest represents the request body for putting a loan under review
+type ReviewLoanRequest struct {
+	ReviewerID  string `json:"reviewer_id" binding:"required"`
+	ReviewNotes string `json:"review_notes" binding:"required"`
+}
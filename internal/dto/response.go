@@ -1,6 +1,7 @@
 package dto
 
 import (
+	"math"
 	"time"
 
 	"loan-service/internal/domain"
@@ -8,24 +9,106 @@ import (
 
 // LoanResponse represents the response body for loan operations
 type LoanResponse struct {
-	ID                  string                      `json:"id"`
-	BorrowerID          string                      `json:"borrower_id"`
-	PrincipalAmount     float64                     `json:"principal_amount"`
-	Rate                float64                     `json:"rate"`
-	ROI                 float64                     `json:"roi"`
-	AgreementLetterLink string                      `json:"agreement_letter_link"`
-	Status              domain.LoanStatus           `json:"status"`
-	ApprovalDetails     *domain.ApprovalDetails     `json:"approval_details,omitempty"`
-	Investments         []domain.Investment         `json:"investments,omitempty"`
-	TotalInvested       float64                     `json:"total_invested"`
-	DisbursementDetails *domain.DisbursementDetails `json:"disbursement_details,omitempty"`
-	CreatedAt           time.Time                   `json:"created_at"`
-	UpdatedAt           time.Time                   `json:"updated_at"`
-}
-
-// ErrorResponse represents an error response
+	ID                   string                      `json:"id"`
+	BorrowerID           string                      `json:"borrower_id"`
+	PrincipalAmount      domain.Money                `json:"principal_amount"`
+	Currency             string                      `json:"currency"`
+	Rate                 float64                     `json:"rate"`
+	ROI                  float64                     `json:"roi"`
+	TermMonths           int                         `json:"term_months"`
+	AgreementLetterLink  string                      `json:"agreement_letter_link"`
+	AgreementAttempts    int                         `json:"agreement_attempts"`
+	AgreementLastError   string                      `json:"agreement_last_error,omitempty"`
+	Status               domain.LoanStatus           `json:"status"`
+	StatusReason         string                      `json:"status_reason,omitempty"`
+	ReviewDetails        *domain.ReviewDetails       `json:"review_details,omitempty"`
+	ApprovalDetails      *domain.ApprovalDetails     `json:"approval_details,omitempty"`
+	InvestmentDeadline   *time.Time                  `json:"investment_deadline,omitempty"`
+	RejectionDetails     *domain.RejectionDetails    `json:"rejection_details,omitempty"`
+	Covenants            []domain.Covenant           `json:"covenants,omitempty"`
+	CovenantsSatisfied   bool                        `json:"covenants_satisfied"`
+	Tags                 []string                    `json:"tags"`
+	Investments          []InvestmentResponse        `json:"investments,omitempty"`
+	InvestmentCount      int                         `json:"investment_count"`
+	InvestorCount        int                         `json:"distinct_investor_count"`
+	InvestmentsTruncated bool                        `json:"investments_truncated"`
+	TotalInvested        domain.Money                `json:"total_invested"`
+	FundingProgress      float64                     `json:"funding_progress"`
+	FundingStatus        string                      `json:"funding_status"`
+	ExpectedTotalReturn  domain.Money                `json:"expected_total_return"`
+	DisbursementDetails  *domain.DisbursementDetails `json:"disbursement_details,omitempty"`
+	TotalRepaid          domain.Money                `json:"total_repaid"`
+	Refunds              []domain.Refund             `json:"refunds,omitempty"`
+	TotalRefunded        domain.Money                `json:"total_refunded"`
+	CreatedAt            time.Time                   `json:"created_at"`
+	UpdatedAt            time.Time                   `json:"updated_at"`
+	DeletedAt            *time.Time                  `json:"deleted_at,omitempty"`
+}
+
+// Funding statuses reported in LoanResponse.FundingStatus. They are derived from the invested share of
+// the principal and are not part of the loan's persisted status.
+const (
+	FundingOpen         = "open"
+	FundingNearlyFunded = "nearly_funded"
+	FundingFunded       = "funded"
+)
+
+// nearlyFundedPercent is the funding progress from which a loan is reported as nearly funded
+const nearlyFundedPercent = 90
+
+// InvestmentResponse represents an investment with the return it earns over the loan's term
+type InvestmentResponse struct {
+	domain.Investment
+	ExpectedReturn domain.Money `json:"expected_return"`
+}
+
+// Error codes returned in ErrorResponse.Code
+const (
+	CodeInternal            = "INTERNAL"
+	CodeInvalidState        = "INVALID_STATE"
+	CodeCapacityExceeded    = "CAPACITY_EXCEEDED"
+	CodeFundingWindowClosed = "FUNDING_WINDOW_CLOSED"
+	CodeActiveLoanLimit     = "ACTIVE_LOAN_LIMIT"
+	CodeAgreementExists     = "AGREEMENT_EXISTS"
+	CodeAgreementFailed     = "AGREEMENT_GENERATION_FAILED"
+	CodeAgreementMismatch   = "AGREEMENT_MISMATCH"
+	CodeUnsupportedCurrency = "UNSUPPORTED_CURRENCY"
+	CodeCurrencyMismatch    = "CURRENCY_MISMATCH"
+	CodeFractionalAmount    = "FRACTIONAL_AMOUNT"
+	CodeCorrectionClosed    = "CORRECTION_WINDOW_CLOSED"
+	CodeCovenantsPending    = "COVENANTS_NOT_SATISFIED"
+	CodeDuplicateProof      = "DUPLICATE_PROOF"
+	CodeDuplicateLoan       = "DUPLICATE_LOAN"
+	CodeAlreadyApproved     = "ALREADY_APPROVED"
+	CodeROIOutOfRange       = "ROI_OUT_OF_RANGE"
+	CodeROIAboveRate        = "ROI_ABOVE_RATE"
+	CodeInvestorInactive    = "INVESTOR_INACTIVE"
+	CodeMinimumFunding      = "MINIMUM_FUNDING_NOT_MET"
+	CodeMinimumInvestment   = "INVESTMENT_BELOW_MINIMUM"
+	CodeInvestorLimit       = "INVESTOR_LIMIT_REACHED"
+	CodeInvestorShare       = "INVESTOR_SHARE_EXCEEDED"
+	CodeMinimumInvestors    = "MINIMUM_INVESTORS_NOT_MET"
+	CodeConcurrentUpdate    = "CONCURRENT_UPDATE"
+	CodeOverpayment         = "REPAYMENT_EXCEEDS_BALANCE"
+	CodeUnauthorized        = "UNAUTHORIZED"
+	CodeForbidden           = "FORBIDDEN"
+	CodeRateLimited         = "RATE_LIMITED"
+)
+
+// ErrorResponse represents an error response. Errors lists each failed field of an invalid request.
 type ErrorResponse struct {
-	Error   string `json:"error"`
+	Error     string       `json:"error"`
+	Message   string       `json:"message"`
+	Code      string       `json:"code,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+	Details   interface{}  `json:"details,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError describes a request field that failed validation and the rule it broke
+type FieldError struct {
+	Field   string `json:"field"`
+	Tag     string `json:"tag"`
 	Message string `json:"message"`
 }
 
@@ -37,25 +120,320 @@ type SuccessResponse struct {
 
 // TransitionResponse represents a transition response
 type TransitionResponse struct {
-	CurrentState domain.LoanStatus        `json:"current_state"`
-	Transitions  []domain.StateTransition `json:"transitions"`
+	CurrentState domain.LoanStatus  `json:"current_state"`
+	Transitions  []TransitionOption `json:"transitions"`
+}
+
+// TransitionOption represents a valid transition along with the request fields its action takes
+type TransitionOption struct {
+	domain.StateTransition
+	Fields []domain.ActionField `json:"fields"`
+}
+
+// ToTransitionOptions converts transitions into transition options listing the fields of their actions
+func ToTransitionOptions(transitions []domain.StateTransition) []TransitionOption {
+	options := make([]TransitionOption, len(transitions))
+	for i, transition := range transitions {
+		options[i] = TransitionOption{
+			StateTransition: transition,
+			Fields:          domain.ActionFields(transition.Action),
+		}
+	}
+	return options
+}
+
+// LoanDetailResponse represents a loan together with its related data, its history and its repayments
+type LoanDetailResponse struct {
+	Loan        LoanResponse             `json:"loan"`
+	Transitions []domain.StateTransition `json:"transitions"`
+	Events      []domain.LoanEvent       `json:"events"`
+	Repayment   domain.RepaymentSummary  `json:"repayment_summary"`
+}
+
+// LoanValidationResult represents the validation outcome of one row of a loan batch
+type LoanValidationResult struct {
+	Index  int      `json:"index"`
+	Valid  bool     `json:"valid"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+// LoanBatchValidationResponse represents the validation outcome of a loan batch
+type LoanBatchValidationResponse struct {
+	Valid   bool                   `json:"valid"`
+	Results []LoanValidationResult `json:"results"`
+}
+
+// LoanBatchItemResult represents the outcome of one row of a loan batch: the created loan's ID, or the
+// errors that kept the batch from being created
+type LoanBatchItemResult struct {
+	Index  int      `json:"index"`
+	ID     string   `json:"id,omitempty"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+// LoanBatchResponse represents the outcome of creating a loan batch
+type LoanBatchResponse struct {
+	Created bool                  `json:"created"`
+	Results []LoanBatchItemResult `json:"results"`
+}
+
+// BorrowerHistoryResponse represents one page of a borrower's loan timeline
+type BorrowerHistoryResponse struct {
+	BorrowerID string                 `json:"borrower_id"`
+	Entries    []domain.TimelineEntry `json:"entries"`
+	Page       int                    `json:"page"`
+	Limit      int                    `json:"limit"`
+	Total      int                    `json:"total"`
+}
+
+// BorrowerLoansResponse represents a borrower's loans and their combined exposure
+type BorrowerLoansResponse struct {
+	BorrowerID     string         `json:"borrower_id"`
+	Loans          []LoanResponse `json:"loans"`
+	Count          int            `json:"count"`
+	TotalPrincipal domain.Money   `json:"total_principal"`
+	TotalDisbursed domain.Money   `json:"total_disbursed"`
+}
+
+// InvestorInvestmentResponse represents an investment with the status and return of its loan
+type InvestorInvestmentResponse struct {
+	domain.Investment
+	LoanStatus     domain.LoanStatus `json:"loan_status"`
+	ROI            float64           `json:"roi"`
+	ExpectedReturn domain.Money      `json:"expected_return"`
+}
+
+// InvestorInvestmentsResponse represents an investor's investments across loans and their combined totals
+type InvestorInvestmentsResponse struct {
+	InvestorID     string                       `json:"investor_id"`
+	Investments    []InvestorInvestmentResponse `json:"investments"`
+	Count          int                          `json:"count"`
+	TotalCommitted domain.Money                 `json:"total_committed"`
+	ExpectedReturn domain.Money                 `json:"expected_return"`
+}
+
+// LoanHistoryResponse represents the audit trail of a loan's status transitions
+type LoanHistoryResponse struct {
+	LoanID string             `json:"loan_id"`
+	Events []domain.LoanEvent `json:"events"`
+}
+
+// LedgerResponse represents the funding ledger of a loan
+type LedgerResponse struct {
+	LoanID          string               `json:"loan_id"`
+	PrincipalAmount domain.Money         `json:"principal_amount"`
+	TotalInvested   domain.Money         `json:"total_invested"`
+	Entries         []domain.LedgerEntry `json:"entries"`
+}
+
+// FundingTimelineEntry represents an investment with the loan's total invested and funded percentage after it
+type FundingTimelineEntry struct {
+	domain.Investment
+	TotalInvested domain.Money `json:"total_invested"`
+	PercentFunded float64      `json:"percent_funded"`
+}
+
+// FundingTimelineResponse represents the investments of a loan in the order they funded it
+type FundingTimelineResponse struct {
+	LoanID          string                 `json:"loan_id"`
+	PrincipalAmount domain.Money           `json:"principal_amount"`
+	Entries         []FundingTimelineEntry `json:"entries"`
+}
+
+// ReconciliationResponse represents the outcome of recomputing a loan's total invested from its investments
+type ReconciliationResponse struct {
+	LoanID                string       `json:"loan_id"`
+	PreviousTotalInvested domain.Money `json:"previous_total_invested"`
+	TotalInvested         domain.Money `json:"total_invested"`
+	Corrected             bool         `json:"corrected"`
+}
+
+// AmortizationResponse represents the principal and interest breakdown of a loan's repayments.
+// Projected is set for loans that are not disbursed yet, whose due dates assume disbursement today.
+type AmortizationResponse struct {
+	LoanID          string               `json:"loan_id"`
+	PrincipalAmount domain.Money         `json:"principal_amount"`
+	Rate            float64              `json:"rate"`
+	TermMonths      int                  `json:"term_months"`
+	Projected       bool                 `json:"projected"`
+	TotalInterest   float64              `json:"total_interest"`
+	TotalPayment    float64              `json:"total_payment"`
+	Periods         []domain.Installment `json:"periods"`
+}
+
+// AccruedInterestResponse represents the interest a disbursed loan has accrued as of a date. The accrued
+// interest is gross: the repayments made by then, reported as total_repaid, are not deducted.
+type AccruedInterestResponse struct {
+	LoanID          string       `json:"loan_id"`
+	Principal       domain.Money `json:"principal"`
+	Rate            float64      `json:"rate"`
+	Method          string       `json:"method"`
+	From            time.Time    `json:"from"`
+	AsOf            time.Time    `json:"as_of"`
+	Days            int          `json:"days"`
+	AccruedInterest domain.Money `json:"accrued_interest"`
+	TotalRepaid     domain.Money `json:"total_repaid"`
+}
+
+// LoanDiffResponse represents the field-level changes between two versions of a loan
+type LoanDiffResponse struct {
+	LoanID        string               `json:"loan_id"`
+	From          int                  `json:"from"`
+	To            int                  `json:"to"`
+	FromCreatedAt time.Time            `json:"from_created_at"`
+	ToCreatedAt   time.Time            `json:"to_created_at"`
+	Changes       []domain.FieldChange `json:"changes"`
+}
+
+// InvestLoanResponse represents a loan after an investment along with the amount actually recorded,
+// which is lower than requested when the investment was clamped to the remaining capacity
+type InvestLoanResponse struct {
+	LoanResponse
+	RequestedAmount domain.Money `json:"requested_amount"`
+	InvestedAmount  domain.Money `json:"invested_amount"`
+	Clamped         bool         `json:"clamped"`
+	InvestorTotal   domain.Money `json:"investor_total"`
+}
+
+// RepaymentResponse represents a loan after a repayment along with the amount still to be repaid
+type RepaymentResponse struct {
+	LoanResponse
+	Repayment        domain.Repayment `json:"repayment"`
+	AmountDue        domain.Money     `json:"amount_due"`
+	RemainingBalance domain.Money     `json:"remaining_balance"`
+}
+
+// LoanInvestmentsResponse represents one page of the investments in a loan and each investor's total
+// contribution. Total is the number of investments matching across all pages.
+type LoanInvestmentsResponse struct {
+	LoanID          string                        `json:"loan_id"`
+	PrincipalAmount domain.Money                  `json:"principal_amount"`
+	TotalInvested   domain.Money                  `json:"total_invested"`
+	Investments     []domain.Investment           `json:"investments"`
+	Investors       []domain.InvestorContribution `json:"investors"`
+	Offset          int                           `json:"offset"`
+	Limit           int                           `json:"limit"`
+	Total           int64                         `json:"total"`
+}
+
+// InvestorCashflowsResponse represents an investor's projected monthly inflows
+type InvestorCashflowsResponse struct {
+	InvestorID    string                 `json:"investor_id"`
+	TotalExpected float64                `json:"total_expected"`
+	Months        []domain.CashflowMonth `json:"months"`
+}
+
+// InvestorLoanResponse represents a loan annotated with an investor's contribution
+type InvestorLoanResponse struct {
+	LoanResponse
+	InvestorContribution float64 `json:"investor_contribution"`
+}
+
+// InvestorLoansResponse represents one page of the loans an investor has invested in
+type InvestorLoansResponse struct {
+	InvestorID string                 `json:"investor_id"`
+	Loans      []InvestorLoanResponse `json:"loans"`
+	Page       int                    `json:"page"`
+	Limit      int                    `json:"limit"`
+	Total      int64                  `json:"total"`
+}
+
+// OutstandingResponse represents the outstanding balance across disbursed loans
+type OutstandingResponse struct {
+	GroupBy          string                      `json:"group_by,omitempty"`
+	Loans            int64                       `json:"loans"`
+	TotalPrincipal   float64                     `json:"total_principal"`
+	TotalOutstanding float64                     `json:"total_outstanding"`
+	Groups           []domain.OutstandingBalance `json:"groups,omitempty"`
+}
+
+// LoanStatsResponse represents the portfolio summary shown on the operations dashboard
+type LoanStatsResponse struct {
+	CountsByStatus            map[domain.LoanStatus]int64 `json:"counts_by_status"`
+	TotalLoans                int64                       `json:"total_loans"`
+	TotalPrincipalOutstanding domain.Money                `json:"total_principal_outstanding"`
+	TotalInvested             domain.Money                `json:"total_invested"`
+	AverageROI                float64                     `json:"average_roi"`
+}
+
+// ToLoanStatsResponse converts a domain.LoanAggregate to LoanStatsResponse
+func ToLoanStatsResponse(aggregate domain.LoanAggregate) LoanStatsResponse {
+	return LoanStatsResponse{
+		CountsByStatus:            aggregate.CountsByStatus,
+		TotalLoans:                aggregate.TotalLoans,
+		TotalPrincipalOutstanding: aggregate.TotalPrincipalOutstanding,
+		TotalInvested:             aggregate.TotalInvested,
+		AverageROI:                aggregate.AverageROI,
+	}
 }
 
 // ToLoanResponse converts a domain.Loan to LoanResponse
 func ToLoanResponse(loan domain.Loan) LoanResponse {
-	return LoanResponse{
+	response := LoanResponse{
 		ID:                  loan.ID,
 		BorrowerID:          loan.BorrowerID,
 		PrincipalAmount:     loan.PrincipalAmount,
+		Currency:            loan.Currency,
 		Rate:                loan.Rate,
 		ROI:                 loan.ROI,
+		TermMonths:          loan.Term(),
 		AgreementLetterLink: loan.AgreementLetterLink,
+		AgreementAttempts:   loan.AgreementAttempts,
+		AgreementLastError:  loan.AgreementLastError,
 		Status:              loan.Status,
+		StatusReason:        loan.StatusReason,
+		ReviewDetails:       loan.ReviewDetails,
 		ApprovalDetails:     loan.ApprovalDetails,
-		Investments:         loan.Investments,
+		InvestmentDeadline:  loan.InvestmentDeadline,
+		RejectionDetails:    loan.RejectionDetails,
+		Covenants:           loan.Covenants,
+		CovenantsSatisfied:  loan.CovenantsSatisfied(),
+		Tags:                loan.TagNames(),
+		InvestmentCount:     len(loan.Investments),
+		InvestorCount:       len(loan.InvestorIDs()),
 		TotalInvested:       loan.TotalInvested,
+		FundingProgress:     math.Round(loan.FundedPercent()*100) / 100,
 		DisbursementDetails: loan.DisbursementDetails,
+		TotalRepaid:         loan.TotalRepaid,
+		Refunds:             loan.Refunds,
+		TotalRefunded:       loan.TotalRefunded(),
 		CreatedAt:           loan.CreatedAt,
 		UpdatedAt:           loan.UpdatedAt,
 	}
+	if loan.DeletedAt.Valid {
+		response.DeletedAt = &loan.DeletedAt.Time
+	}
+	response.FundingStatus = fundingStatus(loan)
+
+	// The ROI is annual, so each investment's return is prorated over the loan's term
+	for _, investment := range loan.Investments {
+		expected := loan.ExpectedReturn(investment.Amount)
+		response.Investments = append(response.Investments, InvestmentResponse{Investment: investment, ExpectedReturn: expected})
+		response.ExpectedTotalReturn += expected
+	}
+	return response
+}
+
+// LimitInvestments keeps only the latest max embedded investments, flagging the response as
+// truncated when some were dropped. A max of zero or less keeps all investments. The investments are
+// expected oldest first, as the loan repository loads them.
+func (r *LoanResponse) LimitInvestments(max int) {
+	if max <= 0 || len(r.Investments) <= max {
+		return
+	}
+	r.Investments = r.Investments[len(r.Investments)-max:]
+	r.InvestmentsTruncated = true
+}
+
+// fundingStatus describes how far a loan is from being fully invested. It is computed from the exact
+// amounts, as the rounded funding progress can reach 100 while a few cents are still open.
+func fundingStatus(loan domain.Loan) string {
+	switch {
+	case loan.TotalInvested >= loan.PrincipalAmount:
+		return FundingFunded
+	case loan.FundedPercent() >= nearlyFundedPercent:
+		return FundingNearlyFunded
+	default:
+		return FundingOpen
+	}
 }
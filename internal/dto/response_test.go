@@ -1,19 +1,21 @@
 package dto
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
 	"loan-service/internal/domain"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestToLoanResponse(t *testing.T) {
 	loan := domain.Loan{
 		ID:                  "test-id",
 		BorrowerID:          "user123",
-		PrincipalAmount:     25000.00,
+		PrincipalAmount:     domain.NewMoney(25000.00),
 		Rate:                4.5,
 		ROI:                 6.0,
 		Status:              domain.StatusProposed,
@@ -34,3 +36,127 @@ func TestToLoanResponse(t *testing.T) {
 	assert.Equal(t, loan.TotalInvested, response.TotalInvested)
 	assert.Equal(t, loan.AgreementLetterLink, response.AgreementLetterLink)
 }
+
+func TestLoanResponseLimitInvestments(t *testing.T) {
+	loan := domain.Loan{ID: "test-id"}
+	for _, investorID := range []string{"investor_001", "investor_002", "investor_003"} {
+		loan.Investments = append(loan.Investments, domain.Investment{InvestorID: investorID, Amount: domain.NewMoney(1000.00)})
+	}
+
+	response := ToLoanResponse(loan)
+	response.LimitInvestments(2)
+
+	assert.Equal(t, 3, response.InvestmentCount)
+	assert.True(t, response.InvestmentsTruncated)
+	assert.Len(t, response.Investments, 2)
+	assert.Equal(t, "investor_002", response.Investments[0].InvestorID)
+
+	response = ToLoanResponse(loan)
+	response.LimitInvestments(0)
+	assert.False(t, response.InvestmentsTruncated)
+	assert.Len(t, response.Investments, 3)
+}
+
+func TestToLoanResponseExpectedReturn(t *testing.T) {
+	loan := domain.Loan{
+		PrincipalAmount: domain.NewMoney(20000.00),
+		ROI:             8.0,
+		TermMonths:      6,
+		TotalInvested:   domain.NewMoney(15000.00),
+		Investments: []domain.Investment{
+			{InvestorID: "investor_001", Amount: domain.NewMoney(10000.00)},
+			{InvestorID: "investor_002", Amount: domain.NewMoney(5000.00)},
+		},
+	}
+
+	response := ToLoanResponse(loan)
+	require.Len(t, response.Investments, 2)
+	// 8% a year over 6 months
+	assert.Equal(t, domain.NewMoney(400.00), response.Investments[0].ExpectedReturn)
+	assert.Equal(t, domain.NewMoney(200.00), response.Investments[1].ExpectedReturn)
+	assert.Equal(t, domain.NewMoney(600.00), response.ExpectedTotalReturn)
+
+	// Loans without investments or ROI expect no return
+	loan.ROI = 0
+	assert.Equal(t, domain.Money(0), ToLoanResponse(loan).ExpectedTotalReturn)
+	loan.Investments = nil
+	response = ToLoanResponse(loan)
+	assert.Empty(t, response.Investments)
+	assert.Equal(t, domain.Money(0), response.ExpectedTotalReturn)
+}
+
+func TestToLoanResponseStatusReason(t *testing.T) {
+	loan := domain.Loan{Status: domain.StatusRejected, StatusReason: "Collateral could not be verified"}
+
+	data, err := json.Marshal(ToLoanResponse(loan))
+	require.NoError(t, err)
+
+	var response LoanResponse
+	require.NoError(t, json.Unmarshal(data, &response))
+	assert.Equal(t, "Collateral could not be verified", response.StatusReason)
+
+	// Loans without a reason leave the field out
+	data, err = json.Marshal(ToLoanResponse(domain.Loan{Status: domain.StatusApproved}))
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "status_reason")
+}
+
+func TestToLoanResponseFundingProgress(t *testing.T) {
+	tests := []struct {
+		name     string
+		invested float64
+		progress float64
+		status   string
+	}{
+		{"not invested", 0, 0, FundingOpen},
+		{"half invested", 5000.00, 50, FundingOpen},
+		{"nearly invested", 9500.00, 95, FundingNearlyFunded},
+		{"fully invested", 10000.00, 100, FundingFunded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := ToLoanResponse(domain.Loan{
+				Status:          domain.StatusApproved,
+				PrincipalAmount: domain.NewMoney(10000.00),
+				TotalInvested:   domain.NewMoney(tt.invested),
+			})
+			assert.Equal(t, tt.progress, response.FundingProgress)
+			assert.Equal(t, tt.status, response.FundingStatus)
+		})
+	}
+
+	// Progress is rounded to two decimals
+	response := ToLoanResponse(domain.Loan{PrincipalAmount: domain.NewMoney(3000.00), TotalInvested: domain.NewMoney(1000.00)})
+	assert.Equal(t, 33.33, response.FundingProgress)
+
+	// A loan whose progress rounds up to 100 is not funded until the last cent is invested
+	response = ToLoanResponse(domain.Loan{PrincipalAmount: domain.NewMoney(100000.00), TotalInvested: domain.NewMoney(99999.99)})
+	assert.Equal(t, 100.0, response.FundingProgress)
+	assert.Equal(t, FundingNearlyFunded, response.FundingStatus)
+}
+
+func TestToLoanResponseInvestmentCounts(t *testing.T) {
+	loan := domain.Loan{
+		PrincipalAmount: domain.NewMoney(20000.00),
+		Investments: []domain.Investment{
+			{InvestorID: "investor_001", Amount: domain.NewMoney(5000.00)},
+			{InvestorID: "investor_002", Amount: domain.NewMoney(5000.00)},
+			{InvestorID: "investor_001", Amount: domain.NewMoney(2500.00)},
+		},
+	}
+
+	response := ToLoanResponse(loan)
+	assert.Equal(t, 3, response.InvestmentCount)
+	assert.Equal(t, 2, response.InvestorCount)
+
+	// The counts cover every investment even when the embedded list is truncated
+	response.LimitInvestments(1)
+	assert.Len(t, response.Investments, 1)
+	assert.Equal(t, 3, response.InvestmentCount)
+	assert.Equal(t, 2, response.InvestorCount)
+
+	response = ToLoanResponse(domain.Loan{})
+	assert.Equal(t, 0, response.InvestmentCount)
+	assert.Equal(t, 0, response.InvestorCount)
+}
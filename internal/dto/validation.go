@@ -1,7 +1,11 @@
 package dto
 
 import (
+	"errors"
+	"fmt"
 	"net/url"
+	"reflect"
+	"regexp"
 	"strings"
 
 	"github.com/gin-gonic/gin/binding"
@@ -12,6 +16,8 @@ import (
 func RegisterCustomValidations() {
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
 		v.RegisterValidation("image_link", validateImageLink)
+		v.RegisterValidation("document_link", validateDocumentLink)
+		v.RegisterValidation("tag", validateTag)
 	}
 }
 
@@ -53,3 +59,144 @@ func validateImageLink(fl validator.FieldLevel) bool {
 
 	return false
 }
+
+// validateDocumentLink validates that the field is a valid URL of a signed document (PDF or scanned image)
+func validateDocumentLink(fl validator.FieldLevel) bool {
+	link, ok := fl.Field().Interface().(string)
+	if !ok {
+		return false
+	}
+
+	parsedURL, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+
+	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
+		return false
+	}
+
+	// Check the path rather than the whole link so query strings (e.g. signed URLs) are allowed
+	lowerPath := strings.ToLower(parsedURL.Path)
+	documentExtensions := []string{".pdf", ".jpg", ".jpeg", ".png"}
+
+	for _, ext := range documentExtensions {
+		if strings.HasSuffix(lowerPath, ext) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// tagPattern matches lowercase tags made of letters, digits and single hyphens
+var tagPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// maxTagLength is the longest tag accepted on a loan
+const maxTagLength = 32
+
+// validateTag validates that the field is a lowercase tag without spaces
+func validateTag(fl validator.FieldLevel) bool {
+	tag, ok := fl.Field().Interface().(string)
+	if !ok {
+		return false
+	}
+	return len(tag) <= maxTagLength && tagPattern.MatchString(tag)
+}
+
+// ValidationErrors translates a binding error for the request obj into one FieldError per failed field,
+// naming fields by their JSON keys. It returns nil for errors that are not validation failures, such as
+// malformed JSON.
+func ValidationErrors(err error, obj interface{}) []FieldError {
+	var fieldErrors validator.ValidationErrors
+	if !errors.As(err, &fieldErrors) {
+		return nil
+	}
+
+	result := make([]FieldError, len(fieldErrors))
+	for i, fieldError := range fieldErrors {
+		field := jsonFieldPath(reflect.TypeOf(obj), fieldError.StructNamespace())
+		result[i] = FieldError{
+			Field:   field,
+			Tag:     fieldError.Tag(),
+			Message: validationMessage(field, fieldError),
+		}
+	}
+	return result
+}
+
+// jsonFieldPath converts the struct namespace of a failed field, such as CreateLoanRequest.Tags[1],
+// into its path in the JSON request, such as tags[1]
+func jsonFieldPath(t reflect.Type, namespace string) string {
+	// The namespace starts with the request type, which means nothing to clients
+	_, path, _ := strings.Cut(namespace, ".")
+
+	segments := strings.Split(path, ".")
+	for i, segment := range segments {
+		name, index, _ := strings.Cut(segment, "[")
+		for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+			t = t.Elem()
+		}
+		if t == nil || t.Kind() != reflect.Struct {
+			break
+		}
+
+		field, ok := t.FieldByName(name)
+		if !ok {
+			break
+		}
+		if jsonName, _, _ := strings.Cut(field.Tag.Get("json"), ","); jsonName != "" && jsonName != "-" {
+			name = jsonName
+		}
+		if index != "" {
+			name += "[" + index
+		}
+		segments[i] = name
+		t = field.Type
+	}
+	return strings.Join(segments, ".")
+}
+
+// validationMessage describes a failed validation in words
+func validationMessage(field string, fieldError validator.FieldError) string {
+	param := fieldError.Param()
+	switch fieldError.Tag() {
+	case "required", "required_without":
+		return fmt.Sprintf("%s is required", field)
+	case "gt":
+		return fmt.Sprintf("%s must be greater than %s", field, param)
+	case "gte":
+		return fmt.Sprintf("%s must be at least %s", field, param)
+	case "lt":
+		return fmt.Sprintf("%s must be less than %s", field, param)
+	case "lte":
+		return fmt.Sprintf("%s must be at most %s", field, param)
+	case "min", "max":
+		bound := "at least"
+		if fieldError.Tag() == "max" {
+			bound = "at most"
+		}
+		switch fieldError.Kind() {
+		case reflect.String:
+			return fmt.Sprintf("%s must be %s %s characters long", field, bound, param)
+		case reflect.Slice, reflect.Array, reflect.Map:
+			return fmt.Sprintf("%s must contain %s %s items", field, bound, param)
+		}
+		return fmt.Sprintf("%s must be %s %s", field, bound, param)
+	case "oneof":
+		return fmt.Sprintf("%s must be one of: %s", field, strings.ReplaceAll(param, " ", ", "))
+	case "email":
+		return fmt.Sprintf("%s must be a valid email address", field)
+	case "url":
+		return fmt.Sprintf("%s must be a valid URL", field)
+	case "image_link":
+		return fmt.Sprintf("%s must be an http(s) link to an image", field)
+	case "document_link":
+		return fmt.Sprintf("%s must be an http(s) link to a document", field)
+	case "iso4217":
+		return fmt.Sprintf("%s must be an ISO 4217 currency code, such as USD", field)
+	case "tag":
+		return fmt.Sprintf("%s must be lowercase letters, digits and hyphens, at most %d characters long", field, maxTagLength)
+	}
+	return fmt.Sprintf("%s failed the %s validation", field, fieldError.Tag())
+}
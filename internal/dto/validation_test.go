@@ -0,0 +1,62 @@
+package dto
+
+import (
+	"errors"
+	"testing"
+
+	"loan-service/internal/domain"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocumentLinkValidation(t *testing.T) {
+	RegisterCustomValidations()
+
+	tests := []struct {
+		link  string
+		valid bool
+	}{
+		{"https://example.com/signed-agreements/loan_001.pdf", true},
+		{"https://example.com/scans/agreement.JPG", true},
+		{"https://example.com/scans/agreement.jpeg", true},
+		{"http://example.com/scans/agreement.png", true},
+		{"https://storage.example.com/agreement.pdf?signature=abc123", true},
+		{"https://example.com/agreement.docx", false},
+		{"https://example.com/agreement", false},
+		{"https://example.com/", false},
+		{"ftp://example.com/agreement.pdf", false},
+		{"agreement.pdf", false},
+	}
+
+	for _, tt := range tests {
+		err := binding.Validator.ValidateStruct(&DisburseLoanRequest{
+			SignedAgreementLink: tt.link,
+			FieldOfficerID:      "officer_001",
+		})
+		if tt.valid {
+			assert.NoError(t, err, tt.link)
+		} else {
+			assert.ErrorContains(t, err, "SignedAgreementLink", tt.link)
+		}
+	}
+}
+
+func TestValidationErrors(t *testing.T) {
+	RegisterCustomValidations()
+
+	req := &ApproveLoanRequest{
+		FieldValidatorProof: domain.ProofLinks{"https://example.com/front.jpg", "https://example.com/report.pdf"},
+	}
+	err := binding.Validator.ValidateStruct(req)
+	require.Error(t, err)
+
+	assert.Equal(t, []FieldError{
+		{Field: "field_validator_proof[1]", Tag: "image_link", Message: "field_validator_proof[1] must be an http(s) link to an image"},
+		{Field: "field_validator_id", Tag: "required", Message: "field_validator_id is required"},
+	}, ValidationErrors(err, req))
+
+	// Errors other than validation failures have no fields
+	assert.Nil(t, ValidationErrors(errors.New("unexpected EOF"), req))
+}
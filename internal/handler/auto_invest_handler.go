@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"loan-service/internal/domain"
+	"loan-service/internal/dto"
+	"loan-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// AutoInvestHandler handles HTTP requests for investor auto-invest rules
+type AutoInvestHandler struct {
+	autoInvestService service.AutoInvestService
+}
+
+// NewAutoInvestHandler creates a new auto-invest handler
+func NewAutoInvestHandler(autoInvestService service.AutoInvestService) *AutoInvestHandler {
+	return &AutoInvestHandler{
+		autoInvestService: autoInvestService,
+	}
+}
+
+// CreateRule creates an auto-invest rule for an investor
+func (h *AutoInvestHandler) CreateRule(c *gin.Context) {
+	investorID := c.Param("investorID")
+
+	var req dto.CreateAutoInvestRuleRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, validationErrorResponse(err, req))
+		return
+	}
+
+	rule := &domain.AutoInvestRule{
+		InvestorID:  investorID,
+		MinROI:      req.MinROI,
+		MaxPerLoan:  req.MaxPerLoan,
+		TotalBudget: req.TotalBudget,
+	}
+
+	if err := h.autoInvestService.CreateRule(rule); err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse{
+		Message: "Auto-invest rule created successfully",
+		Data:    rule,
+	})
+}
+
+// GetRules retrieves the auto-invest rules of an investor
+func (h *AutoInvestHandler) GetRules(c *gin.Context) {
+	rules, err := h.autoInvestService.GetRules(c.Param("investorID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Auto-invest rules retrieved successfully",
+		Data:    rules,
+	})
+}
+
+// UpdateRule updates an investor's auto-invest rule
+func (h *AutoInvestHandler) UpdateRule(c *gin.Context) {
+	var req dto.UpdateAutoInvestRuleRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, validationErrorResponse(err, req))
+		return
+	}
+
+	updates := make(map[string]interface{})
+	if req.MinROI != nil {
+		updates["min_roi"] = *req.MinROI
+	}
+	if req.MaxPerLoan != nil {
+		updates["max_per_loan"] = *req.MaxPerLoan
+	}
+	if req.TotalBudget != nil {
+		updates["total_budget"] = *req.TotalBudget
+	}
+	if req.Active != nil {
+		updates["active"] = *req.Active
+	}
+
+	rule, err := h.autoInvestService.UpdateRule(c.Param("investorID"), c.Param("ruleID"), updates)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Auto-invest rule updated successfully",
+		Data:    rule,
+	})
+}
+
+// DeleteRule deletes an investor's auto-invest rule
+func (h *AutoInvestHandler) DeleteRule(c *gin.Context) {
+	if err := h.autoInvestService.DeleteRule(c.Param("investorID"), c.Param("ruleID")); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Auto-invest rule deleted successfully",
+	})
+}
+
+// GetActions retrieves the investments made by an investor's auto-invest rules
+func (h *AutoInvestHandler) GetActions(c *gin.Context) {
+	actions, err := h.autoInvestService.GetActions(c.Param("investorID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Auto-invest actions retrieved successfully",
+		Data:    actions,
+	})
+}
+
+// handleError maps auto-invest service errors to HTTP responses
+func (h *AutoInvestHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error:   "Not found",
+			Message: "Auto-invest rule not found",
+		})
+	case errors.Is(err, service.ErrBudgetBelowSpent):
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid operation",
+			Message: err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+	}
+}
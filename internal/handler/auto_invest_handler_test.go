@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"loan-service/internal/dto"
+	"loan-service/internal/repository"
+	"loan-service/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutoInvestRuleCRUD(t *testing.T) {
+	_, router, db := setupTestHandler()
+	handler := NewAutoInvestHandler(service.NewAutoInvestService(repository.NewAutoInvestRepository(db)))
+
+	router.POST("/investors/:investorID/auto-invest/rules", handler.CreateRule)
+	router.GET("/investors/:investorID/auto-invest/rules", handler.GetRules)
+	router.PUT("/investors/:investorID/auto-invest/rules/:ruleID", handler.UpdateRule)
+	router.DELETE("/investors/:investorID/auto-invest/rules/:ruleID", handler.DeleteRule)
+
+	w := performRequest(router, "POST", "/investors/investor_001/auto-invest/rules", dto.CreateAutoInvestRuleRequest{
+		MinROI:      5.0,
+		MaxPerLoan:  5000.00,
+		TotalBudget: 20000.00,
+	})
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var createResponse dto.SuccessResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &createResponse))
+	rule := createResponse.Data.(map[string]interface{})
+	ruleID := rule["id"].(string)
+	assert.Equal(t, 20000.00, rule["remaining_budget"])
+
+	// Invalid rule
+	w = performRequest(router, "POST", "/investors/investor_001/auto-invest/rules", dto.CreateAutoInvestRuleRequest{TotalBudget: 1000})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = performRequest(router, "PUT", "/investors/investor_001/auto-invest/rules/"+ruleID, map[string]interface{}{"active": false})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Another investor cannot modify the rule
+	w = performRequest(router, "PUT", "/investors/investor_002/auto-invest/rules/"+ruleID, map[string]interface{}{"active": true})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = performRequest(router, "GET", "/investors/investor_001/auto-invest/rules", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var listResponse dto.SuccessResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listResponse))
+	rules := listResponse.Data.([]interface{})
+	require.Len(t, rules, 1)
+	assert.Equal(t, false, rules[0].(map[string]interface{})["active"])
+
+	w = performRequest(router, "DELETE", "/investors/investor_001/auto-invest/rules/"+ruleID, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = performRequest(router, "DELETE", "/investors/investor_001/auto-invest/rules/"+ruleID, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
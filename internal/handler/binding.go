@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"loan-service/internal/dto"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// strictJSON controls whether request bodies containing unknown fields are rejected
+var strictJSON = true
+
+// SetStrictJSON enables or disables rejecting unknown fields in JSON request bodies.
+// Relaxing it lets older servers accept requests from newer clients.
+func SetStrictJSON(enabled bool) {
+	strictJSON = enabled
+}
+
+// bindJSON decodes the JSON request body into obj and validates it.
+// In strict mode an unknown field is reported by name instead of being silently ignored.
+func bindJSON(c *gin.Context, obj interface{}) error {
+	if !strictJSON {
+		return c.ShouldBindJSON(obj)
+	}
+
+	if c.Request == nil || c.Request.Body == nil {
+		return errors.New("invalid request")
+	}
+
+	return decodeJSON(c.Request.Body, obj)
+}
+
+// decodeJSON decodes a JSON document into obj and validates it, honouring strict mode
+func decodeJSON(r io.Reader, obj interface{}) error {
+	decoder := json.NewDecoder(r)
+	if strictJSON {
+		decoder.DisallowUnknownFields()
+	}
+	if err := decoder.Decode(obj); err != nil {
+		if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+			return fmt.Errorf("unknown field %s", field)
+		}
+		return err
+	}
+
+	return binding.Validator.ValidateStruct(obj)
+}
+
+// validationErrorResponse describes a request body that failed to bind into req, listing each failed field
+func validationErrorResponse(err error, req interface{}) dto.ErrorResponse {
+	return dto.ErrorResponse{
+		Error:   "Validation error",
+		Message: err.Error(),
+		Errors:  dto.ValidationErrors(err, req),
+	}
+}
+
+// validationMessages splits a binding error into one message per failed field
+func validationMessages(err error) []string {
+	var fieldErrors validator.ValidationErrors
+	if !errors.As(err, &fieldErrors) {
+		return []string{err.Error()}
+	}
+
+	messages := make([]string, len(fieldErrors))
+	for i, fieldError := range fieldErrors {
+		messages[i] = fieldError.Error()
+	}
+	return messages
+}
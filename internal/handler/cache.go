@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"loan-service/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// loanETag computes the ETag of a single loan from its identity and last update
+func loanETag(loan domain.Loan) string {
+	return computeETag(loan.ID, loan.UpdatedAt.UTC().Format(time.RFC3339Nano))
+}
+
+// loansETag computes the ETag of a loan listing from the query, the result count and the latest update.
+// The query is part of the hash so that different filters never share an ETag.
+func loansETag(query string, loans []domain.Loan) string {
+	return computeETag(query, fmt.Sprint(len(loans)), latestUpdate(loans).UTC().Format(time.RFC3339Nano))
+}
+
+// latestUpdate returns the most recent UpdatedAt of the given loans
+func latestUpdate(loans []domain.Loan) time.Time {
+	var latest time.Time
+	for _, loan := range loans {
+		if loan.UpdatedAt.After(latest) {
+			latest = loan.UpdatedAt
+		}
+	}
+	return latest
+}
+
+func computeETag(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "|")))
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// checkNotModified sets the caching headers and responds with 304 when the client's copy is current.
+// It returns true when the response has been written.
+func checkNotModified(c *gin.Context, etag string, lastModified time.Time) bool {
+	c.Header("ETag", etag)
+	if !lastModified.IsZero() {
+		c.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	// If-None-Match takes precedence over If-Modified-Since
+	if ifNoneMatch := c.GetHeader("If-None-Match"); ifNoneMatch != "" {
+		if etagMatches(ifNoneMatch, etag) {
+			c.Status(http.StatusNotModified)
+			return true
+		}
+		return false
+	}
+
+	if ifModifiedSince := c.GetHeader("If-Modified-Since"); ifModifiedSince != "" && !lastModified.IsZero() {
+		since, err := http.ParseTime(ifModifiedSince)
+		if err == nil && !lastModified.Truncate(time.Second).After(since) {
+			c.Status(http.StatusNotModified)
+			return true
+		}
+	}
+
+	return false
+}
+
+// etagMatches checks an If-None-Match header value against an ETag
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// HealthHandler handles the liveness and readiness probes
+type HealthHandler struct {
+	db *gorm.DB
+}
+
+// NewHealthHandler creates a new health handler
+func NewHealthHandler(db *gorm.DB) *HealthHandler {
+	return &HealthHandler{
+		db: db,
+	}
+}
+
+// Live reports that the process is up, without checking its dependencies
+func (h *HealthHandler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "service": "loan-service"})
+}
+
+// Ready reports whether the service can handle requests, returning 503 when the database is unreachable
+func (h *HealthHandler) Ready(c *gin.Context) {
+	if err := h.pingDatabase(c); err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status":  "unavailable",
+			"service": "loan-service",
+			"checks":  gin.H{"database": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "ok",
+		"service": "loan-service",
+		"checks":  gin.H{"database": "ok"},
+	})
+}
+
+// pingDatabase checks that the database connection is alive
+func (h *HealthHandler) pingDatabase(c *gin.Context) error {
+	sqlDB, err := h.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(c.Request.Context())
+}
@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthReady(t *testing.T) {
+	_, router, db := setupTestHandler()
+	handler := NewHealthHandler(db)
+	router.GET("/health", handler.Live)
+	router.GET("/health/ready", handler.Ready)
+
+	w := performRequest(router, "GET", "/health/ready", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	require.NoError(t, sqlDB.Close())
+
+	w = performRequest(router, "GET", "/health/ready", nil)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "unavailable", response["status"])
+	assert.NotEmpty(t, response["checks"].(map[string]interface{})["database"])
+
+	// Liveness does not depend on the database
+	w = performRequest(router, "GET", "/health", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
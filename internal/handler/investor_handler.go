@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"net/http"
+
+	"loan-service/internal/domain"
+	"loan-service/internal/dto"
+	"loan-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// InvestorHandler handles HTTP requests for investor compliance status
+type InvestorHandler struct {
+	investorService service.InvestorService
+}
+
+// NewInvestorHandler creates a new investor handler
+func NewInvestorHandler(investorService service.InvestorService) *InvestorHandler {
+	return &InvestorHandler{
+		investorService: investorService,
+	}
+}
+
+// GetInvestor retrieves the status of an investor
+func (h *InvestorHandler) GetInvestor(c *gin.Context) {
+	investor, err := h.investorService.GetInvestor(c.Param("investorID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Investor retrieved successfully",
+		Data:    investor,
+	})
+}
+
+// ActivateInvestor allows an investor to make new investments again
+func (h *InvestorHandler) ActivateInvestor(c *gin.Context) {
+	investor, err := h.investorService.SetStatus(c.Param("investorID"), domain.InvestorActive, "")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Investor activated successfully",
+		Data:    investor,
+	})
+}
+
+// DeactivateInvestor blocks new investments from an investor, e.g. after a KYC lapse
+func (h *InvestorHandler) DeactivateInvestor(c *gin.Context) {
+	var req dto.DeactivateInvestorRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, validationErrorResponse(err, req))
+		return
+	}
+
+	investor, err := h.investorService.SetStatus(c.Param("investorID"), domain.InvestorInactive, req.Reason)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Investor deactivated successfully",
+		Data:    investor,
+	})
+}
+
+// UpdateInvestorEmail sets the address investor notifications are sent to
+func (h *InvestorHandler) UpdateInvestorEmail(c *gin.Context) {
+	var req dto.UpdateInvestorEmailRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, validationErrorResponse(err, req))
+		return
+	}
+
+	investor, err := h.investorService.SetEmail(c.Param("investorID"), req.Email)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Investor email updated successfully",
+		Data:    investor,
+	})
+}
+
+// GetInvestments returns an investor's investments across all loans with the total committed and expected return
+func (h *InvestorHandler) GetInvestments(c *gin.Context) {
+	investorID := c.Param("investorID")
+
+	portfolio, err := h.investorService.GetInvestments(investorID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	investments := make([]dto.InvestorInvestmentResponse, 0, len(portfolio.Investments))
+	for _, investment := range portfolio.Investments {
+		investments = append(investments, dto.InvestorInvestmentResponse{
+			Investment:     investment.Investment,
+			LoanStatus:     investment.LoanStatus,
+			ROI:            investment.ROI,
+			ExpectedReturn: investment.ExpectedReturn(),
+		})
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Investor investments retrieved successfully",
+		Data: dto.InvestorInvestmentsResponse{
+			InvestorID:     investorID,
+			Investments:    investments,
+			Count:          len(investments),
+			TotalCommitted: portfolio.TotalCommitted,
+			ExpectedReturn: portfolio.ExpectedReturn,
+		},
+	})
+}
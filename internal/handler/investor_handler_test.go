@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"loan-service/internal/domain"
+	"loan-service/internal/dto"
+	"loan-service/internal/repository"
+	"loan-service/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvestorActivation(t *testing.T) {
+	_, router, db := setupTestHandler()
+	investorRepo := repository.NewInvestorRepository(db)
+	handler := NewInvestorHandler(service.NewInvestorService(investorRepo, repository.NewInvestmentRepository(db)))
+	loanHandler := NewLoanHandler(service.NewLoanService(repository.NewLoanRepository(db), service.WithInvestors(investorRepo)))
+
+	router.GET("/investors/:investorID", handler.GetInvestor)
+	router.PUT("/investors/:investorID/activate", handler.ActivateInvestor)
+	router.PUT("/investors/:investorID/deactivate", handler.DeactivateInvestor)
+	router.PUT("/loans/:id/invest", loanHandler.InvestLoan)
+
+	// Investors are active until deactivated
+	w := performRequest(router, "GET", "/investors/investor_001", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Data domain.Investor `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, domain.InvestorActive, response.Data.Status)
+
+	// A reason is required
+	w = performRequest(router, "PUT", "/investors/investor_001/deactivate", map[string]interface{}{})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = performRequest(router, "PUT", "/investors/investor_001/deactivate", dto.DeactivateInvestorRequest{Reason: "KYC expired"})
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, domain.InvestorInactive, response.Data.Status)
+	assert.Equal(t, "KYC expired", response.Data.StatusReason)
+
+	loan := seedLoan(t, db, domain.StatusApproved, 10000.00)
+	w = performRequest(router, "PUT", "/loans/"+loan.ID+"/invest", dto.InvestLoanRequest{InvestorID: "investor_001", Amount: domain.NewMoney(1000.00)})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	var errorResponse dto.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResponse))
+	assert.Equal(t, dto.CodeInvestorInactive, errorResponse.Code)
+
+	w = performRequest(router, "PUT", "/investors/investor_001/activate", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = performRequest(router, "PUT", "/loans/"+loan.ID+"/invest", dto.InvestLoanRequest{InvestorID: "investor_001", Amount: domain.NewMoney(1000.00)})
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestUpdateInvestorEmail(t *testing.T) {
+	_, router, db := setupTestHandler()
+	handler := NewInvestorHandler(service.NewInvestorService(repository.NewInvestorRepository(db), repository.NewInvestmentRepository(db)))
+	router.PUT("/investors/:investorID/email", handler.UpdateInvestorEmail)
+
+	w := performRequest(router, "PUT", "/investors/investor_001/email", dto.UpdateInvestorEmailRequest{Email: "not-an-email"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = performRequest(router, "PUT", "/investors/investor_001/email", dto.UpdateInvestorEmailRequest{Email: "investor@example.com"})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data domain.Investor `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "investor@example.com", response.Data.Email)
+	assert.Equal(t, domain.InvestorActive, response.Data.Status)
+}
+
+func TestGetInvestorInvestments(t *testing.T) {
+	_, router, db := setupTestHandler()
+	handler := NewInvestorHandler(service.NewInvestorService(repository.NewInvestorRepository(db), repository.NewInvestmentRepository(db)))
+	loanHandler := NewLoanHandler(service.NewLoanService(repository.NewLoanRepository(db)))
+	router.GET("/investors/:investorID/investments", handler.GetInvestments)
+	router.PUT("/loans/:id/invest", loanHandler.InvestLoan)
+
+	first := seedLoan(t, db, domain.StatusApproved, 10000.00)
+	second := seedLoan(t, db, domain.StatusApproved, 20000.00)
+	for loanID, amount := range map[string]float64{first.ID: 4000.00, second.ID: 6000.00} {
+		w := performRequest(router, "PUT", "/loans/"+loanID+"/invest", dto.InvestLoanRequest{
+			InvestorID: "investor_001",
+			Amount:     domain.NewMoney(amount),
+		})
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+
+	w := performRequest(router, "GET", "/investors/investor_001/investments", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Data dto.InvestorInvestmentsResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 2, response.Data.Count)
+	assert.Equal(t, domain.NewMoney(10000.00), response.Data.TotalCommitted)
+	// Seeded loans have an ROI of 6%
+	assert.Equal(t, domain.NewMoney(600.00), response.Data.ExpectedReturn)
+	for _, investment := range response.Data.Investments {
+		assert.Equal(t, domain.StatusApproved, investment.LoanStatus)
+		assert.Equal(t, 6.0, investment.ROI)
+		assert.Equal(t, domain.ExpectedReturn(investment.Amount, 6.0, domain.DefaultTermMonths), investment.ExpectedReturn)
+	}
+
+	// Investors without investments get an empty list
+	w = performRequest(router, "GET", "/investors/investor_999/investments", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 0, response.Data.Count)
+	assert.NotNil(t, response.Data.Investments)
+	assert.Contains(t, w.Body.String(), `"investments":[]`)
+}
@@ -1,10 +1,21 @@
 package handler
 
 import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"loan-service/internal/domain"
 	"loan-service/internal/dto"
+	"loan-service/internal/middleware"
 	"loan-service/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -23,8 +34,126 @@ func NewLoanHandler(loanService service.LoanService) *LoanHandler {
 	}
 }
 
+// loans returns the loan service bound to the request context, so its database work stops when the
+// client goes away or the server shuts down
+func (h *LoanHandler) loans(c *gin.Context) service.LoanService {
+	return h.loanService.WithContext(c.Request.Context())
+}
+
 // GetLoans retrieves all loans with optional filtering
 func (h *LoanHandler) GetLoans(c *gin.Context) {
+	investmentsLimit, err := parseInvestmentsLimit(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Validation error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	filters, err := parseLoanFilters(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Validation error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	loans, err := h.loans(c).GetLoans(filters)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if checkNotModified(c, loansETag(c.Request.URL.Query().Encode(), loans), latestUpdate(loans)) {
+		return
+	}
+
+	var responses []dto.LoanResponse
+	for _, loan := range loans {
+		response := dto.ToLoanResponse(loan)
+		response.LimitInvestments(investmentsLimit)
+		responses = append(responses, response)
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Loans retrieved successfully",
+		Data:    responses,
+	})
+}
+
+// exportFlushRows is the number of CSV rows written between flushes to the client
+const exportFlushRows = 100
+
+// loanExportColumns is the header row of the loan CSV export
+var loanExportColumns = []string{"id", "borrower", "principal", "rate", "roi", "status", "total_invested", "created_at"}
+
+// ExportLoansCSV streams the loans matching the GetLoans filters as a CSV file
+func (h *LoanHandler) ExportLoansCSV(c *gin.Context) {
+	filters, err := parseLoanFilters(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Validation error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", `attachment; filename="loans.csv"`)
+
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write(loanExportColumns)
+
+	rows := 0
+	err = h.loans(c).ExportLoans(filters, func(loan *domain.Loan) error {
+		if err := w.Write([]string{
+			loan.ID,
+			loan.BorrowerID,
+			loan.PrincipalAmount.String(),
+			strconv.FormatFloat(loan.Rate, 'f', -1, 64),
+			strconv.FormatFloat(loan.ROI, 'f', -1, 64),
+			string(loan.Status),
+			loan.TotalInvested.String(),
+			loan.CreatedAt.UTC().Format(time.RFC3339),
+		}); err != nil {
+			return err
+		}
+		if rows++; rows%exportFlushRows == 0 {
+			w.Flush()
+			c.Writer.Flush()
+			return w.Error()
+		}
+		return nil
+	})
+	if err != nil && !c.Writer.Written() {
+		// Nothing reached the client yet, so the failure can still be reported as JSON
+		c.Writer.Header().Del("Content-Type")
+		c.Writer.Header().Del("Content-Disposition")
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		// The status line is already sent; the truncated file is all the client gets
+		_ = c.Error(err)
+		return
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		_ = c.Error(err)
+	}
+}
+
+// parseLoanFilters reads the filter and sort query parameters shared by the loan list and export
+func parseLoanFilters(c *gin.Context) (map[string]interface{}, error) {
 	filters := make(map[string]interface{})
 
 	if status := c.Query("status"); status != "" {
@@ -35,7 +164,340 @@ func (h *LoanHandler) GetLoans(c *gin.Context) {
 		filters["borrower_id"] = borrowerID
 	}
 
-	loans, err := h.loanService.GetLoans(filters)
+	if validatorID := c.Query("field_validator_id"); validatorID != "" {
+		filters["field_validator_id"] = validatorID
+	}
+
+	if officerID := c.Query("field_officer_id"); officerID != "" {
+		filters["field_officer_id"] = officerID
+	}
+
+	minPrincipal, maxPrincipal, err := parsePrincipalRange(c)
+	if err != nil {
+		return nil, err
+	}
+	if minPrincipal != nil {
+		filters["min_principal"] = *minPrincipal
+	}
+	if maxPrincipal != nil {
+		filters["max_principal"] = *maxPrincipal
+	}
+
+	createdAfter, createdBefore, err := parseCreatedRange(c)
+	if err != nil {
+		return nil, err
+	}
+	if createdAfter != nil {
+		filters["created_after"] = *createdAfter
+	}
+	if createdBefore != nil {
+		filters["created_before"] = *createdBefore
+	}
+
+	if tags := parseTags(c.QueryArray("tag")); len(tags) > 0 {
+		match := domain.TagMatch(c.DefaultQuery("tag_match", string(domain.TagMatchAny)))
+		if match != domain.TagMatchAny && match != domain.TagMatchAll {
+			return nil, errors.New("tag_match must be one of: any, all")
+		}
+		filters["tags"] = tags
+		filters["tag_match"] = match
+	}
+
+	if sortBy, order := c.Query("sort_by"), c.Query("order"); sortBy != "" || order != "" {
+		if sortBy == "" {
+			sortBy = "created_at"
+		}
+		if order == "" {
+			order = "asc"
+		}
+		if !domain.IsLoanSortColumn(sortBy) {
+			return nil, errors.New("sort_by must be one of: " + strings.Join(domain.LoanSortColumns(), ", "))
+		}
+		if order != "asc" && order != "desc" {
+			return nil, errors.New("order must be one of: asc, desc")
+		}
+		filters["sort_by"] = sortBy
+		filters["order"] = order
+	}
+	return filters, nil
+}
+
+// parseTags collects tag filters given as repeated or comma-separated query values
+func parseTags(values []string) []string {
+	var tags []string
+	for _, value := range values {
+		for _, tag := range strings.Split(value, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags
+}
+
+// parsePrincipalRange reads the min_principal and max_principal query parameters. Absent bounds are nil.
+func parsePrincipalRange(c *gin.Context) (min, max *domain.Money, err error) {
+	parse := func(name string) (*domain.Money, error) {
+		value := c.Query(name)
+		if value == "" {
+			return nil, nil
+		}
+		amount, err := strconv.ParseFloat(value, 64)
+		if err != nil || amount < 0 {
+			return nil, fmt.Errorf("%s must be a non-negative number", name)
+		}
+		money := domain.NewMoney(amount)
+		return &money, nil
+	}
+
+	if min, err = parse("min_principal"); err != nil {
+		return nil, nil, err
+	}
+	if max, err = parse("max_principal"); err != nil {
+		return nil, nil, err
+	}
+	if min != nil && max != nil && *min > *max {
+		return nil, nil, fmt.Errorf("min_principal must not be greater than max_principal")
+	}
+	return min, max, nil
+}
+
+// parseCreatedRange reads the created_after and created_before RFC3339 query parameters. Absent bounds are nil.
+func parseCreatedRange(c *gin.Context) (after, before *time.Time, err error) {
+	parse := func(name string) (*time.Time, error) {
+		value := c.Query(name)
+		if value == "" {
+			return nil, nil
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be an RFC3339 timestamp", name)
+		}
+		return &t, nil
+	}
+
+	if after, err = parse("created_after"); err != nil {
+		return nil, nil, err
+	}
+	if before, err = parse("created_before"); err != nil {
+		return nil, nil, err
+	}
+	if after != nil && before != nil && after.After(*before) {
+		return nil, nil, fmt.Errorf("created_after must not be later than created_before")
+	}
+	return after, before, nil
+}
+
+// GetLoan retrieves a specific loan by ID
+func (h *LoanHandler) GetLoan(c *gin.Context) {
+	id := c.Param("id")
+
+	investmentsLimit, err := parseInvestmentsLimit(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Validation error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	loan, err := h.loans(c).GetLoan(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error:   "Not found",
+				Message: "Loan not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if checkNotModified(c, computeETag(loanETag(*loan), strconv.Itoa(investmentsLimit)), loan.UpdatedAt) {
+		return
+	}
+
+	response := dto.ToLoanResponse(*loan)
+	response.LimitInvestments(investmentsLimit)
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Loan retrieved successfully",
+		Data:    response,
+	})
+}
+
+// CreateLoan creates a new loan
+func (h *LoanHandler) CreateLoan(c *gin.Context) {
+	var req dto.CreateLoanRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, validationErrorResponse(err, req))
+		return
+	}
+
+	loan := &domain.Loan{
+		BorrowerID:      req.BorrowerID,
+		PrincipalAmount: req.PrincipalAmount,
+		Currency:        req.Currency,
+		Rate:            req.Rate,
+		ROI:             req.ROI,
+		TermMonths:      req.TermMonths,
+	}
+	loan.SetTags(req.Tags)
+
+	var err error
+	replayed := false
+	if key := c.GetHeader(middleware.IdempotencyKeyHeader); key != "" {
+		if len(key) > middleware.MaxIdempotencyKeyLength {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Validation error",
+				Message: fmt.Sprintf("%s must be at most %d characters", middleware.IdempotencyKeyHeader, middleware.MaxIdempotencyKeyLength),
+			})
+			return
+		}
+		loan, replayed, err = h.loans(c).CreateLoanWithIdempotencyKey(loan, key)
+	} else {
+		err = h.loans(c).CreateLoan(loan)
+	}
+
+	if err != nil {
+		if errors.Is(err, domain.ErrFractionalAmount) {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Validation error",
+				Message: err.Error(),
+				Code:    dto.CodeFractionalAmount,
+			})
+			return
+		}
+		var currencyErr *service.UnsupportedCurrencyError
+		if errors.As(err, &currencyErr) {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Validation error",
+				Message: err.Error(),
+				Code:    dto.CodeUnsupportedCurrency,
+				Details: gin.H{"allowed_currencies": currencyErr.Allowed},
+			})
+			return
+		}
+		var roiErr *service.ROIOutOfRangeError
+		if errors.As(err, &roiErr) {
+			c.JSON(http.StatusBadRequest, roiOutOfRangeResponse(roiErr))
+			return
+		}
+		var rateErr *service.ROIAboveRateError
+		if errors.As(err, &rateErr) {
+			c.JSON(http.StatusBadRequest, roiAboveRateResponse(rateErr))
+			return
+		}
+		var limitErr *service.ActiveLoanLimitError
+		if errors.As(err, &limitErr) {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Invalid operation",
+				Message: err.Error(),
+				Code:    dto.CodeActiveLoanLimit,
+				Details: gin.H{"active_loans": limitErr.ActiveLoans},
+			})
+			return
+		}
+		var duplicateErr *service.DuplicateLoanError
+		if errors.As(err, &duplicateErr) {
+			c.JSON(http.StatusConflict, dto.ErrorResponse{
+				Error:   "Invalid operation",
+				Message: err.Error(),
+				Code:    dto.CodeDuplicateLoan,
+				Details: gin.H{"loan_id": duplicateErr.LoanID},
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if replayed {
+		c.Header(middleware.IdempotentReplayedHeader, "true")
+		c.JSON(http.StatusOK, dto.SuccessResponse{
+			Message: "Loan already created with this idempotency key",
+			Data:    dto.ToLoanResponse(*loan),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse{
+		Message: "Loan created successfully",
+		Data:    dto.ToLoanResponse(*loan),
+	})
+}
+
+// ValidateLoanBatch runs the creation validations on a batch of loans without creating any of them
+func (h *LoanHandler) ValidateLoanBatch(c *gin.Context) {
+	var req dto.ValidateLoanBatchRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, validationErrorResponse(err, req))
+		return
+	}
+
+	loans, indexes, rowErrors := decodeLoanBatch(req.Loans)
+
+	ruleErrors, err := h.loans(c).ValidateNewLoans(loans)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+		return
+	}
+	mergeRuleErrors(rowErrors, indexes, ruleErrors)
+
+	results := make([]dto.LoanValidationResult, len(req.Loans))
+	valid := true
+	for i, errs := range rowErrors {
+		results[i] = dto.LoanValidationResult{Index: i, Valid: len(errs) == 0, Errors: errs}
+		valid = valid && results[i].Valid
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Loan batch validated",
+		Data: dto.LoanBatchValidationResponse{
+			Valid:   valid,
+			Results: results,
+		},
+	})
+}
+
+// CreateLoanBatch creates a batch of loans in a single transaction. When any row is invalid none of the
+// loans are created and the errors of each row are returned.
+func (h *LoanHandler) CreateLoanBatch(c *gin.Context) {
+	var req dto.CreateLoanBatchRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, validationErrorResponse(err, req))
+		return
+	}
+
+	// Oversized batches are refused before any row is decoded
+	if err := h.loans(c).CheckLoanBatchSize(len(req.Loans)); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Validation error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	loans, indexes, rowErrors := decodeLoanBatch(req.Loans)
+
+	// Rows that failed to decode are reported together with the rule violations of the others
+	var ruleErrors []error
+	var err error
+	if len(loans) < len(req.Loans) {
+		ruleErrors, err = h.loans(c).ValidateNewLoans(loans)
+	} else {
+		ruleErrors, err = h.loans(c).CreateLoans(loans)
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
 			Error:   "Database error",
@@ -43,25 +505,1131 @@ func (h *LoanHandler) GetLoans(c *gin.Context) {
 		})
 		return
 	}
+	mergeRuleErrors(rowErrors, indexes, ruleErrors)
+
+	results := make([]dto.LoanBatchItemResult, len(req.Loans))
+	created := true
+	for i, errs := range rowErrors {
+		results[i] = dto.LoanBatchItemResult{Index: i, Errors: errs}
+		created = created && len(errs) == 0
+	}
+
+	if !created {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Validation error",
+			Message: "loan batch contains invalid loans, none were created",
+			Details: dto.LoanBatchResponse{Results: results},
+		})
+		return
+	}
+
+	for i, loan := range loans {
+		results[indexes[i]].ID = loan.ID
+	}
+	c.JSON(http.StatusCreated, dto.SuccessResponse{
+		Message: "Loan batch created successfully",
+		Data:    dto.LoanBatchResponse{Created: true, Results: results},
+	})
+}
+
+// decodeLoanBatch decodes and validates each row of a loan batch. It returns the loans of the rows that
+// decoded, the row index of each of them, and the validation messages of every row.
+func decodeLoanBatch(rows []json.RawMessage) ([]domain.Loan, []int, [][]string) {
+	rowErrors := make([][]string, len(rows))
+	var loans []domain.Loan
+	var indexes []int
+	for i, raw := range rows {
+		var row dto.CreateLoanRequest
+		if err := decodeJSON(bytes.NewReader(raw), &row); err != nil {
+			rowErrors[i] = validationMessages(err)
+			continue
+		}
+
+		loan := domain.Loan{
+			BorrowerID:      row.BorrowerID,
+			PrincipalAmount: row.PrincipalAmount,
+			Currency:        row.Currency,
+			Rate:            row.Rate,
+			ROI:             row.ROI,
+			TermMonths:      row.TermMonths,
+		}
+		loan.SetTags(row.Tags)
+		loans = append(loans, loan)
+		indexes = append(indexes, i)
+	}
+	return loans, indexes, rowErrors
+}
+
+// mergeRuleErrors adds the rule violations of the decoded loans to the errors of their rows
+func mergeRuleErrors(rowErrors [][]string, indexes []int, ruleErrors []error) {
+	for i, ruleErr := range ruleErrors {
+		if ruleErr != nil {
+			rowErrors[indexes[i]] = []string{ruleErr.Error()}
+		}
+	}
+}
+
+// UpdateLoan updates an existing loan
+func (h *LoanHandler) UpdateLoan(c *gin.Context) {
+	id := c.Param("id")
+
+	var req dto.UpdateLoanRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, validationErrorResponse(err, req))
+		return
+	}
+
+	updates := make(map[string]interface{})
+	if req.PrincipalAmount != nil {
+		updates["principal_amount"] = *req.PrincipalAmount
+	}
+	if req.Rate != nil {
+		updates["rate"] = *req.Rate
+	}
+	if req.ROI != nil {
+		updates["roi"] = *req.ROI
+	}
+	if req.AgreementLetterLink != nil {
+		updates["agreement_letter_link"] = *req.AgreementLetterLink
+	}
+	if req.Tags != nil {
+		updates["tags"] = *req.Tags
+	}
+
+	loan, err := h.loans(c).UpdateLoan(id, updates)
+	if err != nil {
+		if errors.Is(err, domain.ErrConcurrentUpdate) {
+			c.JSON(http.StatusConflict, concurrentUpdateResponse(err))
+			return
+		}
+		if errors.Is(err, domain.ErrFractionalAmount) {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Validation error",
+				Message: err.Error(),
+				Code:    dto.CodeFractionalAmount,
+			})
+			return
+		}
+		if errors.Is(err, domain.ErrPrincipalBelowInvested) {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Validation error",
+				Message: err.Error(),
+			})
+			return
+		}
+		var roiErr *service.ROIOutOfRangeError
+		if errors.As(err, &roiErr) {
+			c.JSON(http.StatusBadRequest, roiOutOfRangeResponse(roiErr))
+			return
+		}
+		var rateErr *service.ROIAboveRateError
+		if errors.As(err, &rateErr) {
+			c.JSON(http.StatusBadRequest, roiAboveRateResponse(rateErr))
+			return
+		}
+		if err.Error() == "can only update loans in proposed status" {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Invalid operation",
+				Message: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Loan updated successfully",
+		Data:    dto.ToLoanResponse(*loan),
+	})
+}
+
+// DeleteLoan deletes a loan
+func (h *LoanHandler) DeleteLoan(c *gin.Context) {
+	id := c.Param("id")
+
+	if err := h.loans(c).DeleteLoan(id); err != nil {
+		if err.Error() == "can only delete loans in proposed status" {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Invalid operation",
+				Message: err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, domain.ErrLoanHasHistory) {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Invalid operation",
+				Message: err.Error(),
+				Code:    dto.CodeInvalidState,
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Loan deleted successfully",
+	})
+}
+
+// GetDeletedLoans retrieves all deleted loans
+func (h *LoanHandler) GetDeletedLoans(c *gin.Context) {
+	loans, err := h.loans(c).GetDeletedLoans()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	responses := []dto.LoanResponse{}
+	for _, loan := range loans {
+		responses = append(responses, dto.ToLoanResponse(loan))
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Deleted loans retrieved successfully",
+		Data:    responses,
+	})
+}
+
+// RestoreLoan restores a loan deleted in proposed status
+func (h *LoanHandler) RestoreLoan(c *gin.Context) {
+	id := c.Param("id")
+
+	loan, err := h.loans(c).RestoreLoan(id)
+	if err != nil {
+		var limitErr *service.ActiveLoanLimitError
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error:   "Not found",
+				Message: "Deleted loan not found",
+			})
+		case errors.Is(err, domain.ErrCannotRestore):
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Invalid operation",
+				Message: err.Error(),
+				Code:    dto.CodeInvalidState,
+			})
+		case errors.As(err, &limitErr):
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Invalid operation",
+				Message: err.Error(),
+				Code:    dto.CodeActiveLoanLimit,
+				Details: gin.H{"active_loans": limitErr.ActiveLoans},
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+				Error:   "Database error",
+				Message: err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Loan restored successfully",
+		Data:    dto.ToLoanResponse(*loan),
+	})
+}
+
+// ApproveLoan approves a loan
+func (h *LoanHandler) ApproveLoan(c *gin.Context) {
+	id := c.Param("id")
+
+	var req dto.ApproveLoanRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, validationErrorResponse(err, req))
+		return
+	}
+
+	approvalDetails := &domain.ApprovalDetails{
+		FieldValidatorProof: req.FieldValidatorProof,
+		FieldValidatorID:    req.FieldValidatorID,
+	}
+
+	loan, err := h.loans(c).ApproveLoan(id, approvalDetails, req.Covenants...)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error:   "Not found",
+				Message: "Loan not found",
+			})
+			return
+		}
+		if err.Error() == "can only approve loans in proposed status" {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Invalid operation",
+				Message: err.Error(),
+			})
+			return
+		}
+		var proofErr *service.DuplicateProofError
+		if errors.As(err, &proofErr) {
+			c.JSON(http.StatusConflict, dto.ErrorResponse{
+				Error:   "Invalid operation",
+				Message: err.Error(),
+				Code:    dto.CodeDuplicateProof,
+				Details: gin.H{"loan_id": proofErr.LoanID},
+			})
+			return
+		}
+		var approvedErr *service.AlreadyApprovedError
+		if errors.As(err, &approvedErr) {
+			c.JSON(http.StatusConflict, dto.ErrorResponse{
+				Error:   "Invalid operation",
+				Message: err.Error(),
+				Code:    dto.CodeAlreadyApproved,
+				Details: gin.H{"field_validator_id": approvedErr.FieldValidatorID},
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Loan approved successfully",
+		Data:    dto.ToLoanResponse(*loan),
+	})
+}
+
+// RejectLoan records that a field validator refused to approve a loan
+func (h *LoanHandler) RejectLoan(c *gin.Context) {
+	id := c.Param("id")
+
+	var req dto.RejectLoanRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, validationErrorResponse(err, req))
+		return
+	}
+
+	rejectionDetails := &domain.RejectionDetails{
+		FieldValidatorID: req.FieldValidatorID,
+		Reason:           req.Reason,
+	}
+
+	loan, err := h.loans(c).RejectLoan(id, rejectionDetails)
+	if err != nil {
+		h.handleTransitionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Loan rejected successfully",
+		Data:    dto.ToLoanResponse(*loan),
+	})
+}
+
+// CorrectApproval corrects the approval details of an approved loan
+func (h *LoanHandler) CorrectApproval(c *gin.Context) {
+	id := c.Param("id")
+
+	var req dto.CorrectApprovalRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, validationErrorResponse(err, req))
+		return
+	}
+
+	correction := &domain.ApprovalDetails{
+		FieldValidatorProof: req.FieldValidatorProof,
+		FieldValidatorID:    req.FieldValidatorID,
+	}
+
+	// Only admins may correct an approval after the correction window
+	adminOverride := c.GetString(middleware.RoleKey) == middleware.RoleAdmin
+
+	loan, err := h.loans(c).CorrectApproval(id, correction, adminOverride)
+	if err != nil {
+		var proofErr *service.DuplicateProofError
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error:   "Not found",
+				Message: "Loan not found",
+			})
+		case errors.Is(err, domain.ErrNoApproval), errors.Is(err, domain.ErrApprovalNotCorrectable):
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Invalid operation",
+				Message: err.Error(),
+				Code:    dto.CodeInvalidState,
+			})
+		case errors.Is(err, service.ErrCorrectionWindowClosed):
+			c.JSON(http.StatusForbidden, dto.ErrorResponse{
+				Error:   "Invalid operation",
+				Message: err.Error(),
+				Code:    dto.CodeCorrectionClosed,
+			})
+		case errors.As(err, &proofErr):
+			c.JSON(http.StatusConflict, dto.ErrorResponse{
+				Error:   "Invalid operation",
+				Message: err.Error(),
+				Code:    dto.CodeDuplicateProof,
+				Details: gin.H{"loan_id": proofErr.LoanID},
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+				Error:   "Database error",
+				Message: err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Approval corrected successfully",
+		Data:    dto.ToLoanResponse(*loan),
+	})
+}
+
+// SatisfyCovenant marks an approval covenant of a loan as satisfied
+func (h *LoanHandler) SatisfyCovenant(c *gin.Context) {
+	id := c.Param("id")
+	covenantID := c.Param("covenantID")
+
+	loan, err := h.loans(c).SatisfyCovenant(id, covenantID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error:   "Not found",
+				Message: "Covenant not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Covenant satisfied successfully",
+		Data:    dto.ToLoanResponse(*loan),
+	})
+}
+
+// RevokeApproval returns an approved loan without investments to proposed
+func (h *LoanHandler) RevokeApproval(c *gin.Context) {
+	id := c.Param("id")
+
+	var req dto.RevokeApprovalRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, validationErrorResponse(err, req))
+		return
+	}
+
+	loan, err := h.loans(c).RevokeApproval(id, req.Reason)
+	if err != nil {
+		h.handleTransitionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Loan approval revoked successfully",
+		Data:    dto.ToLoanResponse(*loan),
+	})
+}
+
+// ForceTransition moves a stuck loan to another status the state machine allows, on behalf of an admin.
+// The authenticated caller is recorded as the actor of the manual override.
+func (h *LoanHandler) ForceTransition(c *gin.Context) {
+	id := c.Param("id")
+
+	var req dto.ForceTransitionRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, validationErrorResponse(err, req))
+		return
+	}
+
+	loan, err := h.loans(c).ForceTransition(id, req.Status, c.GetString(middleware.SubjectKey), req.Justification)
+	if err != nil {
+		if errors.Is(err, service.ErrJustificationRequired) {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Validation error",
+				Message: err.Error(),
+			})
+			return
+		}
+		h.handleTransitionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Loan transition forced successfully",
+		Data:    dto.ToLoanResponse(*loan),
+	})
+}
+
+// ReviewLoan puts a loan under review
+func (h *LoanHandler) ReviewLoan(c *gin.Context) {
+	id := c.Param("id")
+
+	var req dto.ReviewLoanRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, validationErrorResponse(err, req))
+		return
+	}
+
+	reviewDetails := &domain.ReviewDetails{
+		ReviewerID:  req.ReviewerID,
+		ReviewNotes: req.ReviewNotes,
+	}
+
+	loan, err := h.loans(c).ReviewLoan(id, reviewDetails)
+	if err != nil {
+		h.handleTransitionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Loan put under review successfully",
+		Data:    dto.ToLoanResponse(*loan),
+	})
+}
+
+// ClearReview returns a loan under review to proposed
+func (h *LoanHandler) ClearReview(c *gin.Context) {
+	id := c.Param("id")
+
+	loan, err := h.loans(c).ClearReview(id)
+	if err != nil {
+		h.handleTransitionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Loan review cleared successfully",
+		Data:    dto.ToLoanResponse(*loan),
+	})
+}
+
+// CancelLoan withdraws a proposed or approved loan that is not fully invested, refunding its investments.
+// The request body with the cancellation reason is optional.
+func (h *LoanHandler) CancelLoan(c *gin.Context) {
+	id := c.Param("id")
+
+	var req dto.CancelLoanRequest
+	if err := bindJSON(c, &req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, validationErrorResponse(err, req))
+		return
+	}
+
+	loan, err := h.loans(c).CancelLoan(id, req.Reason)
+	if err != nil {
+		h.handleTransitionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Loan cancelled successfully",
+		Data:    dto.ToLoanResponse(*loan),
+	})
+}
+
+// handleTransitionError maps errors of state transition operations to HTTP responses
+func (h *LoanHandler) handleTransitionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Error:   "Not found",
+			Message: "Loan not found",
+		})
+	case errors.Is(err, domain.ErrCannotReview), errors.Is(err, domain.ErrNotUnderReview),
+		errors.Is(err, domain.ErrCannotRevokeApproval), errors.Is(err, domain.ErrCannotReject),
+		errors.Is(err, domain.ErrCannotCancel), errors.Is(err, domain.ErrTransitionNotAllowed),
+		errors.Is(err, service.ErrTransitionNotForceable):
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Invalid operation",
+			Message: err.Error(),
+			Code:    dto.CodeInvalidState,
+		})
+	case errors.Is(err, domain.ErrConcurrentUpdate):
+		c.JSON(http.StatusConflict, concurrentUpdateResponse(err))
+	default:
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+	}
+}
+
+// InvestLoan adds an investment to a loan
+func (h *LoanHandler) InvestLoan(c *gin.Context) {
+	id := c.Param("id")
+
+	var req dto.InvestLoanRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, validationErrorResponse(err, req))
+		return
+	}
+
+	loan, err := h.loans(c).InvestInLoanWithCurrency(id, req.InvestorID, req.Amount, req.Currency)
+	if err != nil {
+		var currencyErr *service.CurrencyMismatchError
+		var windowErr *service.FundingWindowClosedError
+		var minimumErr *service.MinimumInvestmentError
+		var limitErr *service.InvestorLimitError
+		var shareErr *service.InvestorShareError
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error:   "Not found",
+				Message: "Loan not found",
+			})
+		case errors.As(err, &currencyErr):
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Validation error",
+				Message: err.Error(),
+				Code:    dto.CodeCurrencyMismatch,
+				Details: gin.H{"loan_currency": currencyErr.LoanCurrency},
+			})
+		case errors.As(err, &windowErr):
+			c.JSON(http.StatusForbidden, dto.ErrorResponse{
+				Error:   "Investment error",
+				Message: err.Error(),
+				Code:    dto.CodeFundingWindowClosed,
+				Details: gin.H{"next_open_at": windowErr.NextOpen},
+			})
+		case errors.As(err, &minimumErr):
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Investment error",
+				Message: err.Error(),
+				Code:    dto.CodeMinimumInvestment,
+				Details: gin.H{"min_investment_amount": minimumErr.Min},
+			})
+		case errors.As(err, &limitErr):
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Investment error",
+				Message: err.Error(),
+				Code:    dto.CodeInvestorLimit,
+				Details: gin.H{"max_investors": limitErr.Max},
+			})
+		case errors.As(err, &shareErr):
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Investment error",
+				Message: err.Error(),
+				Code:    dto.CodeInvestorShare,
+				Details: gin.H{"max_investor_share_percent": shareErr.MaxPercent, "max_investor_amount": shareErr.Max},
+			})
+		case errors.Is(err, domain.ErrConcurrentUpdate):
+			c.JSON(http.StatusConflict, concurrentUpdateResponse(err))
+		case errors.Is(err, domain.ErrInvestorInactive):
+			c.JSON(http.StatusForbidden, dto.ErrorResponse{
+				Error:   "Investment error",
+				Message: err.Error(),
+				Code:    dto.CodeInvestorInactive,
+			})
+		case errors.Is(err, domain.ErrLoanNotApproved), errors.Is(err, domain.ErrInvestmentDeadlinePassed):
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Investment error",
+				Message: err.Error(),
+				Code:    dto.CodeInvalidState,
+			})
+		case errors.Is(err, domain.ErrFractionalAmount):
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Investment error",
+				Message: err.Error(),
+				Code:    dto.CodeFractionalAmount,
+			})
+		case errors.Is(err, domain.ErrInvestmentExceedsPrincipal):
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Investment error",
+				Message: err.Error(),
+				Code:    dto.CodeCapacityExceeded,
+			})
+		default:
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Investment error",
+				Message: err.Error(),
+			})
+		}
+		return
+	}
+
+	investment := loan.Investments[len(loan.Investments)-1]
+	message := "Investment added successfully"
+	if investment.Amount < req.Amount {
+		message = "Investment reduced to the remaining loan capacity"
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: message,
+		Data: dto.InvestLoanResponse{
+			LoanResponse:    dto.ToLoanResponse(*loan),
+			RequestedAmount: req.Amount,
+			InvestedAmount:  investment.Amount,
+			Clamped:         investment.Amount < req.Amount,
+			InvestorTotal:   investorTotal(loan, req.InvestorID),
+		},
+	})
+}
+
+// DisburseLoan disburses a loan
+func (h *LoanHandler) DisburseLoan(c *gin.Context) {
+	id := c.Param("id")
+
+	var req dto.DisburseLoanRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, validationErrorResponse(err, req))
+		return
+	}
+
+	disbursementDetails := &domain.DisbursementDetails{
+		SignedAgreementLink: req.SignedAgreementLink,
+		FieldOfficerID:      req.FieldOfficerID,
+	}
+
+	loan, err := h.loans(c).DisburseLoan(id, disbursementDetails)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error:   "Not found",
+				Message: "Loan not found",
+			})
+			return
+		}
+		if errors.Is(err, domain.ErrConcurrentUpdate) {
+			c.JSON(http.StatusConflict, concurrentUpdateResponse(err))
+			return
+		}
+		if errors.Is(err, domain.ErrCovenantsNotSatisfied) {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Invalid operation",
+				Message: err.Error(),
+				Code:    dto.CodeCovenantsPending,
+			})
+			return
+		}
+		if errors.Is(err, service.ErrAgreementMismatch) {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Validation error",
+				Message: err.Error(),
+				Code:    dto.CodeAgreementMismatch,
+			})
+			return
+		}
+		var fundingErr *service.MinimumFundingError
+		if errors.As(err, &fundingErr) {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Invalid operation",
+				Message: err.Error(),
+				Code:    dto.CodeMinimumFunding,
+				Details: gin.H{"funded_percent": fundingErr.FundedPercent, "min_funding_percent": fundingErr.MinPercent},
+			})
+			return
+		}
+		var investorsErr *service.MinimumInvestorsError
+		if errors.As(err, &investorsErr) {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Invalid operation",
+				Message: err.Error(),
+				Code:    dto.CodeMinimumInvestors,
+				Details: gin.H{"investors": investorsErr.Investors, "min_investors": investorsErr.Min},
+			})
+			return
+		}
+		if err.Error() == "can only disburse fully invested loans" {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Invalid operation",
+				Message: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Loan disbursed successfully",
+		Data:    dto.ToLoanResponse(*loan),
+	})
+}
+
+// RecordRepayment records a borrower's repayment against a disbursed loan
+func (h *LoanHandler) RecordRepayment(c *gin.Context) {
+	id := c.Param("id")
+
+	var req dto.RecordRepaymentRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, validationErrorResponse(err, req))
+		return
+	}
+
+	var repaidAt time.Time
+	if req.RepaymentDate != nil {
+		repaidAt = *req.RepaymentDate
+	}
+
+	loan, err := h.loans(c).RecordRepayment(id, req.Amount, repaidAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error:   "Not found",
+				Message: "Loan not found",
+			})
+		case errors.Is(err, domain.ErrConcurrentUpdate):
+			c.JSON(http.StatusConflict, concurrentUpdateResponse(err))
+		case errors.Is(err, domain.ErrLoanNotDisbursed):
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Repayment error",
+				Message: err.Error(),
+				Code:    dto.CodeInvalidState,
+			})
+		case errors.Is(err, domain.ErrRepaymentExceedsBalance):
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Repayment error",
+				Message: err.Error(),
+				Code:    dto.CodeOverpayment,
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+				Error:   "Database error",
+				Message: err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse{
+		Message: "Repayment recorded successfully",
+		Data: dto.RepaymentResponse{
+			LoanResponse:     dto.ToLoanResponse(*loan),
+			Repayment:        loan.Repayments[len(loan.Repayments)-1],
+			AmountDue:        loan.AmountDue(),
+			RemainingBalance: loan.RemainingBalance(),
+		},
+	})
+}
+
+// GetLoanTransitions returns valid transitions for a loan
+func (h *LoanHandler) GetLoanTransitions(c *gin.Context) {
+	id := c.Param("id")
+
+	transitions, err := h.loans(c).GetLoanTransitions(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error:   "Not found",
+				Message: "Loan not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	loan, _ := h.loans(c).GetLoan(id)
+	fsm := domain.NewFSM()
+	fsm.SetCurrentState(loan.Status)
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Valid transitions retrieved successfully",
+		Data: dto.TransitionResponse{
+			CurrentState: fsm.GetCurrentState(),
+			Transitions:  dto.ToTransitionOptions(transitions),
+		},
+	})
+}
+
+// GetLoanDetails retrieves a loan with all related data in a single call
+func (h *LoanHandler) GetLoanDetails(c *gin.Context) {
+	id := c.Param("id")
+
+	details, err := h.loans(c).GetLoanDetails(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error:   "Not found",
+				Message: "Loan not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Loan details retrieved successfully",
+		Data: dto.LoanDetailResponse{
+			Loan:        dto.ToLoanResponse(*details.Loan),
+			Transitions: details.Transitions,
+			Events:      details.Events,
+			Repayment:   details.Repayment,
+		},
+	})
+}
+
+// RegenerateAgreement (re)generates the agreement letter for an invested or disbursed loan
+func (h *LoanHandler) RegenerateAgreement(c *gin.Context) {
+	id := c.Param("id")
+	force := c.Query("force") == "true"
+
+	loan, err := h.loans(c).RegenerateAgreement(id, force)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error:   "Not found",
+				Message: "Loan not found",
+			})
+		case errors.Is(err, domain.ErrAgreementNotAvailable):
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Invalid operation",
+				Message: err.Error(),
+				Code:    dto.CodeInvalidState,
+			})
+		case errors.Is(err, domain.ErrAgreementExists):
+			c.JSON(http.StatusConflict, dto.ErrorResponse{
+				Error:   "Invalid operation",
+				Message: err.Error(),
+				Code:    dto.CodeAgreementExists,
+			})
+		case errors.Is(err, service.ErrAgreementGenerationFailed):
+			c.JSON(http.StatusBadGateway, dto.ErrorResponse{
+				Error:   "Agreement error",
+				Message: err.Error(),
+				Code:    dto.CodeAgreementFailed,
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+				Error:   "Database error",
+				Message: err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Agreement generated successfully",
+		Data:    dto.ToLoanResponse(*loan),
+	})
+}
+
+// GetAgreementData returns the structured data populating the agreement of an invested or disbursed loan
+func (h *LoanHandler) GetAgreementData(c *gin.Context) {
+	id := c.Param("id")
+
+	data, err := h.loans(c).GetAgreementData(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error:   "Not found",
+				Message: "Loan not found",
+			})
+		case errors.Is(err, domain.ErrAgreementNotAvailable):
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error:   "Not found",
+				Message: err.Error(),
+				Code:    dto.CodeInvalidState,
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+				Error:   "Database error",
+				Message: err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Agreement data retrieved successfully",
+		Data:    data,
+	})
+}
+
+// GetLedger returns the chronological funding ledger of a loan with running totals
+func (h *LoanHandler) GetLedger(c *gin.Context) {
+	id := c.Param("id")
+
+	loan, entries, err := h.loans(c).GetLedger(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error:   "Not found",
+				Message: "Loan not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Loan ledger retrieved successfully",
+		Data: dto.LedgerResponse{
+			LoanID:          loan.ID,
+			PrincipalAmount: loan.PrincipalAmount,
+			TotalInvested:   loan.TotalInvested,
+			Entries:         entries,
+		},
+	})
+}
+
+// GetFundingTimeline returns a loan's investments, oldest first, with the running total invested and
+// funded percentage after each
+func (h *LoanHandler) GetFundingTimeline(c *gin.Context) {
+	id := c.Param("id")
+
+	timeline, err := h.loans(c).GetFundingTimeline(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error:   "Not found",
+				Message: "Loan not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	entries := make([]dto.FundingTimelineEntry, len(timeline.Steps))
+	for i, step := range timeline.Steps {
+		entries[i] = dto.FundingTimelineEntry{
+			Investment:    step.Investment,
+			TotalInvested: step.TotalInvested,
+			PercentFunded: step.PercentFunded,
+		}
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Funding timeline retrieved successfully",
+		Data: dto.FundingTimelineResponse{
+			LoanID:          timeline.Loan.ID,
+			PrincipalAmount: timeline.Loan.PrincipalAmount,
+			Entries:         entries,
+		},
+	})
+}
+
+// ReconcileLoan recomputes the total invested of a loan from its investments, correcting it when it has drifted
+func (h *LoanHandler) ReconcileLoan(c *gin.Context) {
+	id := c.Param("id")
+
+	result, err := h.loans(c).Reconcile(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error:   "Not found",
+				Message: "Loan not found",
+			})
+		case errors.Is(err, domain.ErrConcurrentUpdate):
+			c.JSON(http.StatusConflict, concurrentUpdateResponse(err))
+		default:
+			c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+				Error:   "Database error",
+				Message: err.Error(),
+			})
+		}
+		return
+	}
+
+	message := "Loan reconciled, no discrepancy found"
+	if result.Corrected {
+		message = "Loan reconciled, total invested corrected"
+	}
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: message,
+		Data: dto.ReconciliationResponse{
+			LoanID:                result.Loan.ID,
+			PreviousTotalInvested: result.PreviousTotalInvested,
+			TotalInvested:         result.Loan.TotalInvested,
+			Corrected:             result.Corrected,
+		},
+	})
+}
+
+// WithdrawInvestment removes an investment from a loan that is not fully funded yet
+func (h *LoanHandler) WithdrawInvestment(c *gin.Context) {
+	id := c.Param("id")
+	investmentID := c.Param("investmentID")
+
+	// Investors can only withdraw their own investments, admins any
+	investorID := ""
+	if c.GetString(middleware.RoleKey) == middleware.RoleInvestor {
+		investorID = c.GetString(middleware.SubjectKey)
+	}
 
-	var responses []dto.LoanResponse
-	for _, loan := range loans {
-		responses = append(responses, dto.ToLoanResponse(loan))
+	loan, err := h.loans(c).WithdrawInvestment(id, investmentID, investorID)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error:   "Not found",
+				Message: "Loan not found",
+			})
+		case errors.Is(err, domain.ErrInvestmentNotFound):
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error:   "Not found",
+				Message: err.Error(),
+			})
+		case errors.Is(err, domain.ErrNotInvestmentOwner):
+			c.JSON(http.StatusForbidden, dto.ErrorResponse{
+				Error:   "Forbidden",
+				Message: err.Error(),
+				Code:    dto.CodeForbidden,
+			})
+		case errors.Is(err, domain.ErrCannotWithdraw):
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Invalid operation",
+				Message: err.Error(),
+				Code:    dto.CodeInvalidState,
+			})
+		case errors.Is(err, domain.ErrConcurrentUpdate):
+			c.JSON(http.StatusConflict, concurrentUpdateResponse(err))
+		default:
+			c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+				Error:   "Database error",
+				Message: err.Error(),
+			})
+		}
+		return
 	}
 
 	c.JSON(http.StatusOK, dto.SuccessResponse{
-		Message: "Loans retrieved successfully",
-		Data:    responses,
+		Message: "Investment withdrawn successfully",
+		Data:    dto.ToLoanResponse(*loan),
 	})
 }
 
-// GetLoan retrieves a specific loan by ID
-func (h *LoanHandler) GetLoan(c *gin.Context) {
+// GetLoanHistory returns the status transitions of a loan with who made them and when
+func (h *LoanHandler) GetLoanHistory(c *gin.Context) {
 	id := c.Param("id")
 
-	loan, err := h.loanService.GetLoan(id)
+	events, err := h.loans(c).GetHistory(id)
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
 			c.JSON(http.StatusNotFound, dto.ErrorResponse{
 				Error:   "Not found",
 				Message: "Loan not found",
@@ -76,30 +1644,41 @@ func (h *LoanHandler) GetLoan(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, dto.SuccessResponse{
-		Message: "Loan retrieved successfully",
-		Data:    dto.ToLoanResponse(*loan),
+		Message: "Loan history retrieved successfully",
+		Data:    dto.LoanHistoryResponse{LoanID: id, Events: events},
 	})
 }
 
-// CreateLoan creates a new loan
-func (h *LoanHandler) CreateLoan(c *gin.Context) {
-	var req dto.CreateLoanRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Validation error",
+// GetLoanStats returns a live summary of the loan book
+func (h *LoanHandler) GetLoanStats(c *gin.Context) {
+	aggregate, err := h.loans(c).GetLoanStats()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Database error",
 			Message: err.Error(),
 		})
 		return
 	}
 
-	loan := &domain.Loan{
-		BorrowerID:      req.BorrowerID,
-		PrincipalAmount: req.PrincipalAmount,
-		Rate:            req.Rate,
-		ROI:             req.ROI,
-	}
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Loan statistics retrieved successfully",
+		Data:    dto.ToLoanStatsResponse(*aggregate),
+	})
+}
+
+// GetAmortization returns the per-period principal, interest and remaining balance of a loan's repayments
+func (h *LoanHandler) GetAmortization(c *gin.Context) {
+	id := c.Param("id")
 
-	if err := h.loanService.CreateLoan(loan); err != nil {
+	loan, periods, err := h.loans(c).GetAmortization(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error:   "Not found",
+				Message: "Loan not found",
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
 			Error:   "Database error",
 			Message: err.Error(),
@@ -107,70 +1686,155 @@ func (h *LoanHandler) CreateLoan(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, dto.SuccessResponse{
-		Message: "Loan created successfully",
-		Data:    dto.ToLoanResponse(*loan),
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Loan amortization retrieved successfully",
+		Data:    amortizationResponse(loan, periods),
 	})
 }
 
-// UpdateLoan updates an existing loan
-func (h *LoanHandler) UpdateLoan(c *gin.Context) {
+// GetSchedule returns the monthly repayment schedule of an invested or disbursed loan
+func (h *LoanHandler) GetSchedule(c *gin.Context) {
 	id := c.Param("id")
 
-	var req dto.UpdateLoanRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Validation error",
-			Message: err.Error(),
-		})
+	loan, periods, err := h.loans(c).GenerateSchedule(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error:   "Not found",
+				Message: "Loan not found",
+			})
+		case errors.Is(err, domain.ErrScheduleNotAvailable):
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Invalid operation",
+				Message: err.Error(),
+				Code:    dto.CodeInvalidState,
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+				Error:   "Database error",
+				Message: err.Error(),
+			})
+		}
 		return
 	}
 
-	updates := make(map[string]interface{})
-	if req.PrincipalAmount != nil {
-		updates["principal_amount"] = *req.PrincipalAmount
-	}
-	if req.Rate != nil {
-		updates["rate"] = *req.Rate
-	}
-	if req.ROI != nil {
-		updates["roi"] = *req.ROI
-	}
-	if req.AgreementLetterLink != nil {
-		updates["agreement_letter_link"] = *req.AgreementLetterLink
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Loan repayment schedule retrieved successfully",
+		Data:    amortizationResponse(loan, periods),
+	})
+}
+
+// GetAccruedInterest returns the interest a disbursed loan has accrued from disbursement until the as_of
+// date (RFC3339 or YYYY-MM-DD, defaulting to now), with simple or daily_compound interest
+func (h *LoanHandler) GetAccruedInterest(c *gin.Context) {
+	id := c.Param("id")
+
+	var asOf time.Time
+	if value := c.Query("as_of"); value != "" {
+		parsed, err := parseDate(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Validation error",
+				Message: "as_of must be an RFC3339 timestamp or a YYYY-MM-DD date",
+			})
+			return
+		}
+		asOf = parsed
 	}
+	method := service.InterestMethod(c.DefaultQuery("method", string(service.InterestSimple)))
 
-	loan, err := h.loanService.UpdateLoan(id, updates)
+	accrued, err := h.loans(c).GetAccruedInterest(id, asOf, method)
 	if err != nil {
-		if err.Error() == "can only update loans in proposed status" {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error:   "Not found",
+				Message: "Loan not found",
+			})
+		case errors.Is(err, service.ErrInvalidInterestMethod), errors.Is(err, service.ErrAccrualBeforeDisbursement):
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Validation error",
+				Message: err.Error(),
+			})
+		case errors.Is(err, domain.ErrNotAccruing):
 			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
 				Error:   "Invalid operation",
 				Message: err.Error(),
+				Code:    dto.CodeInvalidState,
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+				Error:   "Database error",
+				Message: err.Error(),
 			})
-			return
 		}
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error:   "Database error",
-			Message: err.Error(),
-		})
 		return
 	}
 
 	c.JSON(http.StatusOK, dto.SuccessResponse{
-		Message: "Loan updated successfully",
-		Data:    dto.ToLoanResponse(*loan),
+		Message: "Accrued interest calculated successfully",
+		Data: dto.AccruedInterestResponse{
+			LoanID:          accrued.Loan.ID,
+			Principal:       accrued.Principal,
+			Rate:            accrued.Loan.Rate,
+			Method:          string(accrued.Method),
+			From:            accrued.From,
+			AsOf:            accrued.AsOf,
+			Days:            accrued.Days,
+			AccruedInterest: accrued.Interest,
+			TotalRepaid:     accrued.Repaid,
+		},
 	})
 }
 
-// DeleteLoan deletes a loan
-func (h *LoanHandler) DeleteLoan(c *gin.Context) {
+// parseDate reads an RFC3339 timestamp or a YYYY-MM-DD date, taken as midnight UTC
+func parseDate(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, value)
+}
+
+// amortizationResponse summarizes a loan's installments with their total interest and payment
+func amortizationResponse(loan *domain.Loan, periods []domain.Installment) dto.AmortizationResponse {
+	response := dto.AmortizationResponse{
+		LoanID:          loan.ID,
+		PrincipalAmount: loan.PrincipalAmount,
+		Rate:            loan.Rate,
+		TermMonths:      loan.Term(),
+		Projected:       loan.DisbursementDetails == nil || loan.DisbursementDetails.DisbursementDate.IsZero(),
+		Periods:         periods,
+	}
+	for _, period := range periods {
+		response.TotalInterest += period.Interest
+		response.TotalPayment += period.Payment
+	}
+	response.TotalInterest = math.Round(response.TotalInterest*100) / 100
+	response.TotalPayment = math.Round(response.TotalPayment*100) / 100
+	return response
+}
+
+// GetLoanInvestments lists one page of the investments in a loan, optionally filtered by investor,
+// along with each investor's total contribution
+func (h *LoanHandler) GetLoanInvestments(c *gin.Context) {
 	id := c.Param("id")
 
-	if err := h.loanService.DeleteLoan(id); err != nil {
-		if err.Error() == "can only delete loans in proposed status" {
-			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-				Error:   "Invalid operation",
-				Message: err.Error(),
+	offset, limit, err := parseOffsetPagination(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Validation error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	result, err := h.loans(c).GetInvestments(id, c.Query("investor_id"), offset, limit)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error:   "Not found",
+				Message: "Loan not found",
 			})
 			return
 		}
@@ -181,34 +1845,62 @@ func (h *LoanHandler) DeleteLoan(c *gin.Context) {
 		return
 	}
 
+	response := dto.LoanInvestmentsResponse{
+		LoanID:          result.Loan.ID,
+		PrincipalAmount: result.Loan.PrincipalAmount,
+		TotalInvested:   result.Loan.TotalInvested,
+		Investments:     result.Investments,
+		Investors:       result.Contributions,
+		Offset:          offset,
+		Limit:           limit,
+		Total:           result.Total,
+	}
+	if len(response.Investors) == 0 {
+		response.Investors = []domain.InvestorContribution{}
+	}
+
 	c.JSON(http.StatusOK, dto.SuccessResponse{
-		Message: "Loan deleted successfully",
+		Message: "Loan investments retrieved successfully",
+		Data:    response,
 	})
 }
 
-// ApproveLoan approves a loan
-func (h *LoanHandler) ApproveLoan(c *gin.Context) {
+// investorTotal returns the sum of an investor's investments in the loan
+func investorTotal(loan *domain.Loan, investorID string) domain.Money {
+	for _, contribution := range loan.Contributions() {
+		if contribution.InvestorID == investorID {
+			return contribution.Amount
+		}
+	}
+	return 0
+}
+
+// GetLoanDiff returns the field-level differences between two recorded versions of a loan
+func (h *LoanHandler) GetLoanDiff(c *gin.Context) {
 	id := c.Param("id")
 
-	var req dto.ApproveLoanRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	from, fromErr := strconv.Atoi(c.Query("from"))
+	to, toErr := strconv.Atoi(c.Query("to"))
+	if fromErr != nil || toErr != nil || from < 1 || from >= to {
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
 			Error:   "Validation error",
-			Message: err.Error(),
+			Message: "from and to must be positive version numbers with from < to",
 		})
 		return
 	}
 
-	approvalDetails := &domain.ApprovalDetails{
-		FieldValidatorProof: req.FieldValidatorProof,
-		FieldValidatorID:    req.FieldValidatorID,
-	}
-
-	loan, err := h.loanService.ApproveLoan(id, approvalDetails)
+	diff, err := h.loans(c).GetLoanDiff(id, from, to)
 	if err != nil {
-		if err.Error() == "can only approve loans in proposed status" {
-			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-				Error:   "Invalid operation",
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error:   "Not found",
+				Message: "Loan not found",
+			})
+			return
+		}
+		if errors.Is(err, domain.ErrVersionNotFound) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error:   "Not found",
 				Message: err.Error(),
 			})
 			return
@@ -221,17 +1913,24 @@ func (h *LoanHandler) ApproveLoan(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, dto.SuccessResponse{
-		Message: "Loan approved successfully",
-		Data:    dto.ToLoanResponse(*loan),
+		Message: "Loan diff retrieved successfully",
+		Data: dto.LoanDiffResponse{
+			LoanID:        id,
+			From:          diff.From.Version,
+			To:            diff.To.Version,
+			FromCreatedAt: diff.From.CreatedAt,
+			ToCreatedAt:   diff.To.CreatedAt,
+			Changes:       diff.Changes,
+		},
 	})
 }
 
-// InvestLoan adds an investment to a loan
-func (h *LoanHandler) InvestLoan(c *gin.Context) {
-	id := c.Param("id")
+// GetBorrowerHistory returns the combined chronological timeline of a borrower's loans
+func (h *LoanHandler) GetBorrowerHistory(c *gin.Context) {
+	borrowerID := c.Param("borrowerID")
 
-	var req dto.InvestLoanRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	page, limit, err := parsePagination(c)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
 			Error:   "Validation error",
 			Message: err.Error(),
@@ -239,27 +1938,33 @@ func (h *LoanHandler) InvestLoan(c *gin.Context) {
 		return
 	}
 
-	loan, err := h.loanService.InvestInLoan(id, req.InvestorID, req.Amount)
+	entries, total, err := h.loans(c).GetBorrowerHistory(borrowerID, page, limit)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "Investment error",
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Database error",
 			Message: err.Error(),
 		})
 		return
 	}
 
 	c.JSON(http.StatusOK, dto.SuccessResponse{
-		Message: "Investment added successfully",
-		Data:    dto.ToLoanResponse(*loan),
+		Message: "Borrower history retrieved successfully",
+		Data: dto.BorrowerHistoryResponse{
+			BorrowerID: borrowerID,
+			Entries:    entries,
+			Page:       page,
+			Limit:      limit,
+			Total:      total,
+		},
 	})
 }
 
-// DisburseLoan disburses a loan
-func (h *LoanHandler) DisburseLoan(c *gin.Context) {
-	id := c.Param("id")
+// GetBorrowerLoans returns all loans of a borrower with their combined principal and disbursed amounts
+func (h *LoanHandler) GetBorrowerLoans(c *gin.Context) {
+	borrowerID := c.Param("borrowerID")
 
-	var req dto.DisburseLoanRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	investmentsLimit, err := parseInvestmentsLimit(c)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
 			Error:   "Validation error",
 			Message: err.Error(),
@@ -267,20 +1972,40 @@ func (h *LoanHandler) DisburseLoan(c *gin.Context) {
 		return
 	}
 
-	disbursementDetails := &domain.DisbursementDetails{
-		SignedAgreementLink: req.SignedAgreementLink,
-		FieldOfficerID:      req.FieldOfficerID,
+	result, err := h.loans(c).GetBorrowerLoans(borrowerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	responses := []dto.LoanResponse{}
+	for _, loan := range result.Loans {
+		response := dto.ToLoanResponse(loan)
+		response.LimitInvestments(investmentsLimit)
+		responses = append(responses, response)
 	}
 
-	loan, err := h.loanService.DisburseLoan(id, disbursementDetails)
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Borrower loans retrieved successfully",
+		Data: dto.BorrowerLoansResponse{
+			BorrowerID:     borrowerID,
+			Loans:          responses,
+			Count:          len(responses),
+			TotalPrincipal: result.TotalPrincipal,
+			TotalDisbursed: result.TotalDisbursed,
+		},
+	})
+}
+
+// GetInvestorCashflows returns an investor's projected monthly inflows across their disbursed loans
+func (h *LoanHandler) GetInvestorCashflows(c *gin.Context) {
+	investorID := c.Param("investorID")
+
+	months, err := h.loans(c).GetInvestorCashflows(investorID)
 	if err != nil {
-		if err.Error() == "can only disburse fully invested loans" {
-			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-				Error:   "Invalid operation",
-				Message: err.Error(),
-			})
-			return
-		}
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
 			Error:   "Database error",
 			Message: err.Error(),
@@ -288,25 +2013,36 @@ func (h *LoanHandler) DisburseLoan(c *gin.Context) {
 		return
 	}
 
+	total := 0.0
+	for _, month := range months {
+		total += month.Amount
+	}
+
 	c.JSON(http.StatusOK, dto.SuccessResponse{
-		Message: "Loan disbursed successfully",
-		Data:    dto.ToLoanResponse(*loan),
+		Message: "Investor cashflows retrieved successfully",
+		Data: dto.InvestorCashflowsResponse{
+			InvestorID:    investorID,
+			TotalExpected: math.Round(total*100) / 100,
+			Months:        months,
+		},
 	})
 }
 
-// GetLoanTransitions returns valid transitions for a loan
-func (h *LoanHandler) GetLoanTransitions(c *gin.Context) {
-	id := c.Param("id")
+// GetInvestorLoans returns the loans an investor has invested in, optionally filtered by status
+func (h *LoanHandler) GetInvestorLoans(c *gin.Context) {
+	investorID := c.Param("investorID")
 
-	transitions, err := h.loanService.GetLoanTransitions(id)
+	page, limit, err := parsePagination(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "Validation error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	positions, total, err := h.loans(c).GetInvestorLoans(investorID, c.Query("status"), page, limit)
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, dto.ErrorResponse{
-				Error:   "Not found",
-				Message: "Loan not found",
-			})
-			return
-		}
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
 			Error:   "Database error",
 			Message: err.Error(),
@@ -314,15 +2050,51 @@ func (h *LoanHandler) GetLoanTransitions(c *gin.Context) {
 		return
 	}
 
-	loan, _ := h.loanService.GetLoan(id)
-	fsm := domain.NewFSM()
-	fsm.SetCurrentState(loan.Status)
+	loans := make([]dto.InvestorLoanResponse, 0, len(positions))
+	for _, position := range positions {
+		loans = append(loans, dto.InvestorLoanResponse{
+			LoanResponse:         dto.ToLoanResponse(position.Loan),
+			InvestorContribution: position.Contribution,
+		})
+	}
 
 	c.JSON(http.StatusOK, dto.SuccessResponse{
-		Message: "Valid transitions retrieved successfully",
-		Data: dto.TransitionResponse{
-			CurrentState: fsm.GetCurrentState(),
-			Transitions:  transitions,
+		Message: "Investor loans retrieved successfully",
+		Data: dto.InvestorLoansResponse{
+			InvestorID: investorID,
+			Loans:      loans,
+			Page:       page,
+			Limit:      limit,
+			Total:      total,
 		},
 	})
 }
+
+// concurrentUpdateResponse tells the client its write lost a race and can be retried
+func concurrentUpdateResponse(err error) dto.ErrorResponse {
+	return dto.ErrorResponse{
+		Error:   "Conflict",
+		Message: err.Error(),
+		Code:    dto.CodeConcurrentUpdate,
+	}
+}
+
+// roiAboveRateResponse describes an ROI rejected for exceeding the borrower's rate
+func roiAboveRateResponse(err *service.ROIAboveRateError) dto.ErrorResponse {
+	return dto.ErrorResponse{
+		Error:   "Validation error",
+		Message: err.Error(),
+		Code:    dto.CodeROIAboveRate,
+		Details: gin.H{"roi": err.ROI, "rate": err.Rate},
+	}
+}
+
+// roiOutOfRangeResponse describes a rejected ROI together with the configured band
+func roiOutOfRangeResponse(err *service.ROIOutOfRangeError) dto.ErrorResponse {
+	return dto.ErrorResponse{
+		Error:   "Validation error",
+		Message: err.Error(),
+		Code:    dto.CodeROIOutOfRange,
+		Details: gin.H{"min_roi": err.Min, "max_roi": err.Max},
+	}
+}
@@ -2,13 +2,20 @@ package handler
 
 import (
 	"bytes"
+	"encoding/csv"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
+	"time"
 
+	"loan-service/internal/config"
+	"loan-service/internal/database"
 	"loan-service/internal/domain"
 	"loan-service/internal/dto"
+	"loan-service/internal/middleware"
 	"loan-service/internal/repository"
 	"loan-service/internal/service"
 
@@ -24,7 +31,12 @@ func float64Ptr(v float64) *float64 {
 	return &v
 }
 
-func setupTestHandler() (*LoanHandler, *gin.Engine, *gorm.DB) {
+func moneyPtr(v float64) *domain.Money {
+	m := domain.NewMoney(v)
+	return &m
+}
+
+func setupTestHandler(opts ...service.Option) (*LoanHandler, *gin.Engine, *gorm.DB) {
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
 
@@ -32,23 +44,50 @@ func setupTestHandler() (*LoanHandler, *gin.Engine, *gorm.DB) {
 	dto.RegisterCustomValidations()
 
 	// Create test database
-	database, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
 	if err != nil {
 		panic("failed to connect to test database")
 	}
 
 	// Auto migrate the schema
-	err = database.AutoMigrate(&domain.Loan{}, &domain.Investment{})
+	err = database.Migrate(db)
 	if err != nil {
 		panic("failed to migrate test database")
 	}
 
 	// Create dependencies
-	loanRepo := repository.NewLoanRepository(database)
-	loanService := service.NewLoanService(loanRepo)
+	loanRepo := repository.NewLoanRepository(db)
+	loanService := service.NewLoanService(loanRepo, opts...)
 	loanHandler := NewLoanHandler(loanService)
 
-	return loanHandler, router, database
+	return loanHandler, router, db
+}
+
+// performRequest sends a JSON request to the router and returns the recorded response
+func performRequest(router *gin.Engine, method, path string, body interface{}) *httptest.ResponseRecorder {
+	var reqBody []byte
+	if body != nil {
+		reqBody, _ = json.Marshal(body)
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// seedLoan inserts a loan with the given status directly into the database
+func seedLoan(t *testing.T, db *gorm.DB, status domain.LoanStatus, principal float64) *domain.Loan {
+	loan := &domain.Loan{
+		BorrowerID:      "user123",
+		PrincipalAmount: domain.NewMoney(principal),
+		Rate:            4.5,
+		ROI:             6.0,
+		Status:          status,
+	}
+	require.NoError(t, db.Create(loan).Error)
+	return loan
 }
 
 func TestGetLoans(t *testing.T) {
@@ -93,6 +132,221 @@ func TestGetLoansWithFilters(t *testing.T) {
 	assert.Equal(t, http.StatusOK, w2.Code)
 }
 
+func TestGetLoansSorted(t *testing.T) {
+	handler, router, db := setupTestHandler()
+	router.GET("/loans", handler.GetLoans)
+
+	seedLoan(t, db, domain.StatusProposed, 20000.00)
+	seedLoan(t, db, domain.StatusProposed, 5000.00)
+	seedLoan(t, db, domain.StatusProposed, 12000.00)
+
+	principals := func(w *httptest.ResponseRecorder) []domain.Money {
+		var response struct {
+			Data []dto.LoanResponse `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		var amounts []domain.Money
+		for _, loan := range response.Data {
+			amounts = append(amounts, loan.PrincipalAmount)
+		}
+		return amounts
+	}
+
+	w := performRequest(router, "GET", "/loans?sort_by=principal_amount", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []domain.Money{domain.NewMoney(5000.00), domain.NewMoney(12000.00), domain.NewMoney(20000.00)}, principals(w))
+
+	w = performRequest(router, "GET", "/loans?sort_by=principal_amount&order=desc", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []domain.Money{domain.NewMoney(20000.00), domain.NewMoney(12000.00), domain.NewMoney(5000.00)}, principals(w))
+
+	w = performRequest(router, "GET", "/loans?sort_by=borrower_id%3BDROP%20TABLE%20loans", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = performRequest(router, "GET", "/loans?sort_by=created_at&order=sideways", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetLoansByPrincipalRange(t *testing.T) {
+	handler, router, db := setupTestHandler()
+	router.GET("/loans", handler.GetLoans)
+
+	seedLoan(t, db, domain.StatusApproved, 10000.00)
+	seedLoan(t, db, domain.StatusApproved, 50000.00)
+	seedLoan(t, db, domain.StatusApproved, 100000.00)
+
+	w := performRequest(router, "GET", "/loans?min_principal=20000&max_principal=60000", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Data []dto.LoanResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Data, 1)
+	assert.Equal(t, domain.NewMoney(50000.00), response.Data[0].PrincipalAmount)
+
+	w = performRequest(router, "GET", "/loans?min_principal=60000&max_principal=20000", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = performRequest(router, "GET", "/loans?min_principal=abc", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetLoansByCreatedRange(t *testing.T) {
+	handler, router, db := setupTestHandler()
+	router.GET("/loans", handler.GetLoans)
+
+	old := seedLoan(t, db, domain.StatusProposed, 10000.00)
+	require.NoError(t, db.Model(old).UpdateColumn("created_at", time.Now().AddDate(0, -1, 0)).Error)
+	recent := seedLoan(t, db, domain.StatusProposed, 20000.00)
+
+	after := url.QueryEscape(time.Now().AddDate(0, 0, -7).Format(time.RFC3339))
+	w := performRequest(router, "GET", "/loans?created_after="+after, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Data []dto.LoanResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Data, 1)
+	assert.Equal(t, recent.ID, response.Data[0].ID)
+
+	w = performRequest(router, "GET", "/loans?created_before=yesterday", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = performRequest(router, "GET", "/loans?created_after=2024-02-01T00:00:00Z&created_before=2024-01-01T00:00:00Z", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestExportLoansCSV(t *testing.T) {
+	handler, router, db := setupTestHandler()
+	router.GET("/loans/export.csv", handler.ExportLoansCSV)
+
+	approved := seedLoan(t, db, domain.StatusApproved, 5000.00)
+	seedLoan(t, db, domain.StatusProposed, 1000.00)
+
+	w := performRequest(router, "GET", "/loans/export.csv?status=approved", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="loans.csv"`, w.Header().Get("Content-Disposition"))
+
+	records, err := csv.NewReader(w.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, []string{"id", "borrower", "principal", "rate", "roi", "status", "total_invested", "created_at"}, records[0])
+	assert.Equal(t, []string{
+		approved.ID, "user123", "5000.00", "4.5", "6", "approved", "0.00",
+		approved.CreatedAt.UTC().Format(time.RFC3339),
+	}, records[1])
+
+	// Without filters every loan is exported
+	w = performRequest(router, "GET", "/loans/export.csv", nil)
+	records, err = csv.NewReader(w.Body).ReadAll()
+	require.NoError(t, err)
+	assert.Len(t, records, 3)
+
+	w = performRequest(router, "GET", "/loans/export.csv?min_principal=abc", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetLoanInvestments(t *testing.T) {
+	handler, router, db := setupTestHandler()
+	router.PUT("/loans/:id/invest", handler.InvestLoan)
+	router.GET("/loans/:id/investments", handler.GetLoanInvestments)
+
+	loan := seedLoan(t, db, domain.StatusApproved, 10000.00)
+
+	w := performRequest(router, "GET", "/loans/"+loan.ID+"/investments", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"investors":[]`)
+
+	var investResponse struct {
+		Data dto.InvestLoanResponse `json:"data"`
+	}
+	for _, investment := range []dto.InvestLoanRequest{
+		{InvestorID: "investor_001", Amount: domain.NewMoney(4000.00)},
+		{InvestorID: "investor_002", Amount: domain.NewMoney(1000.00)},
+		{InvestorID: "investor_001", Amount: domain.NewMoney(2000.00)},
+	} {
+		w = performRequest(router, "PUT", "/loans/"+loan.ID+"/invest", investment)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &investResponse))
+	}
+	assert.Equal(t, domain.NewMoney(6000.00), investResponse.Data.InvestorTotal)
+
+	w = performRequest(router, "GET", "/loans/"+loan.ID+"/investments", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data dto.LoanInvestmentsResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, domain.NewMoney(7000.00), response.Data.TotalInvested)
+	assert.Len(t, response.Data.Investments, 3)
+	assert.Equal(t, []domain.InvestorContribution{
+		{InvestorID: "investor_001", Amount: domain.NewMoney(6000.00), Investments: 2, Percentage: 60},
+		{InvestorID: "investor_002", Amount: domain.NewMoney(1000.00), Investments: 1, Percentage: 10},
+	}, response.Data.Investors)
+
+	w = performRequest(router, "GET", "/loans/nonexistent-id/investments", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetLoanInvestmentsPagination(t *testing.T) {
+	handler, router, db := setupTestHandler()
+	router.GET("/loans/:id/investments", handler.GetLoanInvestments)
+
+	loan := seedLoan(t, db, domain.StatusApproved, 100000.00)
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	for i := 0; i < 25; i++ {
+		require.NoError(t, db.Create(&domain.Investment{
+			LoanID:     loan.ID,
+			InvestorID: fmt.Sprintf("investor_%03d", i%5),
+			Amount:     domain.NewMoney(float64(100 * (i + 1))),
+			CreatedAt:  start.Add(time.Duration(i) * time.Minute),
+		}).Error)
+	}
+
+	get := func(query string) dto.LoanInvestmentsResponse {
+		w := performRequest(router, "GET", "/loans/"+loan.ID+"/investments"+query, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var response struct {
+			Data dto.LoanInvestmentsResponse `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response.Data
+	}
+
+	// Defaults to the first 20
+	page := get("")
+	assert.Equal(t, int64(25), page.Total)
+	assert.Equal(t, 0, page.Offset)
+	assert.Equal(t, 20, page.Limit)
+	assert.Len(t, page.Investments, 20)
+	assert.Len(t, page.Investors, 5)
+
+	page = get("?offset=10&limit=10")
+	require.Len(t, page.Investments, 10)
+	assert.Equal(t, domain.NewMoney(1100.00), page.Investments[0].Amount)
+	assert.Equal(t, domain.NewMoney(2000.00), page.Investments[9].Amount)
+
+	page = get("?offset=20&limit=10")
+	assert.Len(t, page.Investments, 5)
+	assert.Equal(t, int64(25), page.Total)
+
+	page = get("?investor_id=investor_001&limit=3")
+	assert.Equal(t, int64(5), page.Total)
+	require.Len(t, page.Investments, 3)
+	for _, investment := range page.Investments {
+		assert.Equal(t, "investor_001", investment.InvestorID)
+	}
+	// The per investor summary still covers the whole loan
+	assert.Len(t, page.Investors, 5)
+
+	for _, query := range []string{"?limit=0", "?limit=101", "?offset=-1", "?offset=abc"} {
+		w := performRequest(router, "GET", "/loans/"+loan.ID+"/investments"+query, nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
 func TestCreateLoan(t *testing.T) {
 	handler, router, _ := setupTestHandler()
 
@@ -100,7 +354,7 @@ func TestCreateLoan(t *testing.T) {
 
 	createReq := dto.CreateLoanRequest{
 		BorrowerID:      "user123",
-		PrincipalAmount: 25000.00,
+		PrincipalAmount: domain.NewMoney(25000.00),
 		Rate:            4.5,
 		ROI:             6.0,
 	}
@@ -133,6 +387,33 @@ func TestCreateLoanInvalidRequest(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, w.Code)
 }
 
+func TestCreateLoanValidationErrors(t *testing.T) {
+	handler, router, _ := setupTestHandler()
+	router.POST("/loans", handler.CreateLoan)
+
+	w := performRequest(router, "POST", "/loans", map[string]interface{}{
+		"principal_amount": -100,
+		"rate":             4.5,
+		"roi":              6.0,
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response dto.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "Validation error", response.Error)
+	assert.Equal(t, []dto.FieldError{
+		{Field: "borrower_id", Tag: "required", Message: "borrower_id is required"},
+		{Field: "principal_amount", Tag: "gt", Message: "principal_amount must be greater than 0"},
+	}, response.Errors)
+
+	// Malformed bodies have no failed fields to list
+	w = performRequest(router, "POST", "/loans", "invalid")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var malformed dto.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &malformed))
+	assert.Empty(t, malformed.Errors)
+}
+
 func TestGetLoan(t *testing.T) {
 	handler, router, _ := setupTestHandler()
 
@@ -143,7 +424,7 @@ func TestGetLoan(t *testing.T) {
 	// First create a loan
 	createReq := dto.CreateLoanRequest{
 		BorrowerID:      "user123",
-		PrincipalAmount: 25000.00,
+		PrincipalAmount: domain.NewMoney(25000.00),
 		Rate:            4.5,
 		ROI:             6.0,
 	}
@@ -193,6 +474,38 @@ func TestGetLoanNotFound(t *testing.T) {
 	assert.Equal(t, "Not found", response.Error)
 }
 
+func TestLifecycleOperationsNotFound(t *testing.T) {
+	handler, router, _ := setupTestHandler()
+
+	router.PUT("/loans/:id/approve", handler.ApproveLoan)
+	router.PUT("/loans/:id/invest", handler.InvestLoan)
+	router.PUT("/loans/:id/disburse", handler.DisburseLoan)
+
+	requests := map[string]interface{}{
+		"/loans/nonexistent-id/approve": dto.ApproveLoanRequest{
+			FieldValidatorProof: domain.ProofLinks{"https://example.com/proof.jpg"},
+			FieldValidatorID:    "validator_001",
+		},
+		"/loans/nonexistent-id/invest": dto.InvestLoanRequest{
+			InvestorID: "investor_001",
+			Amount:     domain.NewMoney(1000.00),
+		},
+		"/loans/nonexistent-id/disburse": dto.DisburseLoanRequest{
+			SignedAgreementLink: "https://example.com/agreement.pdf",
+			FieldOfficerID:      "officer_001",
+		},
+	}
+
+	for path, body := range requests {
+		w := performRequest(router, "PUT", path, body)
+		assert.Equal(t, http.StatusNotFound, w.Code, path)
+
+		var response dto.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "Loan not found", response.Message, path)
+	}
+}
+
 func TestUpdateLoan(t *testing.T) {
 	handler, router, _ := setupTestHandler()
 
@@ -203,7 +516,7 @@ func TestUpdateLoan(t *testing.T) {
 	// First create a loan
 	createReq := dto.CreateLoanRequest{
 		BorrowerID:      "user123",
-		PrincipalAmount: 25000.00,
+		PrincipalAmount: domain.NewMoney(25000.00),
 		Rate:            4.5,
 		ROI:             6.0,
 	}
@@ -223,7 +536,7 @@ func TestUpdateLoan(t *testing.T) {
 
 	// Update the loan
 	updateReq := dto.UpdateLoanRequest{
-		PrincipalAmount: float64Ptr(30000.00),
+		PrincipalAmount: moneyPtr(30000.00),
 		Rate:            float64Ptr(5.0),
 	}
 
@@ -254,6 +567,39 @@ func TestUpdateLoanInvalidRequest(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, w.Code)
 }
 
+func TestUpdateLoanNonPositiveTerms(t *testing.T) {
+	handler, router, db := setupTestHandler()
+	router.PUT("/loans/:id", handler.UpdateLoan)
+
+	loan := seedLoan(t, db, domain.StatusProposed, 25000.00)
+
+	for _, body := range []map[string]interface{}{
+		{"principal_amount": 0},
+		{"principal_amount": -1000},
+		{"rate": 0},
+		{"roi": -2.5},
+	} {
+		w := performRequest(router, "PUT", "/loans/"+loan.ID, body)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+
+		var response dto.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Len(t, response.Errors, 1)
+		assert.Equal(t, "gt", response.Errors[0].Tag)
+	}
+
+	var stored domain.Loan
+	require.NoError(t, db.First(&stored, "id = ?", loan.ID).Error)
+	assert.Equal(t, domain.NewMoney(25000.00), stored.PrincipalAmount)
+	assert.Equal(t, 4.5, stored.Rate)
+
+	// A principal below the committed investments is rejected
+	require.NoError(t, db.Model(&stored).Update("total_invested", domain.NewMoney(5000.00)).Error)
+	w := performRequest(router, "PUT", "/loans/"+loan.ID, map[string]interface{}{"principal_amount": 4000})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), domain.ErrPrincipalBelowInvested.Error())
+}
+
 func TestDeleteLoan(t *testing.T) {
 	handler, router, _ := setupTestHandler()
 
@@ -264,7 +610,7 @@ func TestDeleteLoan(t *testing.T) {
 	// First create a loan
 	createReq := dto.CreateLoanRequest{
 		BorrowerID:      "user123",
-		PrincipalAmount: 25000.00,
+		PrincipalAmount: domain.NewMoney(25000.00),
 		Rate:            4.5,
 		ROI:             6.0,
 	}
@@ -295,6 +641,74 @@ func TestDeleteLoan(t *testing.T) {
 	assert.Equal(t, "Loan deleted successfully", response.Message)
 }
 
+func TestDeleteLoanWithHistory(t *testing.T) {
+	handler, router, db := setupTestHandler()
+	router.DELETE("/loans/:id", handler.DeleteLoan)
+
+	loan := seedLoan(t, db, domain.StatusProposed, 25000.00)
+	require.NoError(t, db.Create(&domain.Investment{LoanID: loan.ID, InvestorID: "investor_001", Amount: domain.NewMoney(1000.00)}).Error)
+
+	w := performRequest(router, "DELETE", "/loans/"+loan.ID, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), domain.ErrLoanHasHistory.Error())
+	assert.Contains(t, w.Body.String(), dto.CodeInvalidState)
+
+	var stored domain.Loan
+	assert.NoError(t, db.First(&stored, "id = ?", loan.ID).Error)
+}
+
+func TestRestoreLoan(t *testing.T) {
+	handler, router, db := setupTestHandler()
+	router.GET("/loans/deleted", handler.GetDeletedLoans)
+	router.GET("/loans/:id", handler.GetLoan)
+	router.DELETE("/loans/:id", handler.DeleteLoan)
+	router.POST("/loans/:id/restore", handler.RestoreLoan)
+
+	loan := seedLoan(t, db, domain.StatusProposed, 25000.00)
+
+	w := performRequest(router, "DELETE", "/loans/"+loan.ID, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = performRequest(router, "GET", "/loans/"+loan.ID, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	var listResponse struct {
+		Data []dto.LoanResponse `json:"data"`
+	}
+	w = performRequest(router, "GET", "/loans/deleted", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listResponse))
+	require.Len(t, listResponse.Data, 1)
+	assert.Equal(t, loan.ID, listResponse.Data[0].ID)
+	assert.NotNil(t, listResponse.Data[0].DeletedAt)
+
+	w = performRequest(router, "POST", "/loans/"+loan.ID+"/restore", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = performRequest(router, "GET", "/loans/"+loan.ID, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "deleted_at")
+
+	w = performRequest(router, "GET", "/loans/deleted", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"data":[]`)
+
+	// Restoring a loan that is not deleted
+	w = performRequest(router, "POST", "/loans/"+loan.ID+"/restore", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Loans deleted outside proposed status stay deleted
+	approved := seedLoan(t, db, domain.StatusApproved, 25000.00)
+	require.NoError(t, db.Delete(approved).Error)
+
+	w = performRequest(router, "POST", "/loans/"+approved.ID+"/restore", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var errorResponse dto.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResponse))
+	assert.Equal(t, dto.CodeInvalidState, errorResponse.Code)
+}
+
 func TestApproveLoan(t *testing.T) {
 	handler, router, _ := setupTestHandler()
 
@@ -305,7 +719,7 @@ func TestApproveLoan(t *testing.T) {
 	// First create a loan
 	createReq := dto.CreateLoanRequest{
 		BorrowerID:      "user123",
-		PrincipalAmount: 25000.00,
+		PrincipalAmount: domain.NewMoney(25000.00),
 		Rate:            4.5,
 		ROI:             6.0,
 	}
@@ -325,7 +739,7 @@ func TestApproveLoan(t *testing.T) {
 
 	// Approve the loan
 	approveReq := dto.ApproveLoanRequest{
-		FieldValidatorProof: "https://example.com/proofs/field_visit_123.jpg",
+		FieldValidatorProof: domain.ProofLinks{"https://example.com/proofs/field_visit_123.jpg"},
 		FieldValidatorID:    "validator_001",
 	}
 
@@ -353,7 +767,7 @@ func TestApproveLoanInvalidImageLink(t *testing.T) {
 	// First create a loan
 	createReq := dto.CreateLoanRequest{
 		BorrowerID:      "user123",
-		PrincipalAmount: 25000.00,
+		PrincipalAmount: domain.NewMoney(25000.00),
 		Rate:            4.5,
 		ROI:             6.0,
 	}
@@ -373,7 +787,7 @@ func TestApproveLoanInvalidImageLink(t *testing.T) {
 
 	// Try to approve with invalid image link (not an image URL)
 	approveReq := dto.ApproveLoanRequest{
-		FieldValidatorProof: "https://example.com/document.pdf", // Invalid: not an image
+		FieldValidatorProof: domain.ProofLinks{"https://example.com/document.pdf"}, // Invalid: not an image
 		FieldValidatorID:    "validator_001",
 	}
 
@@ -401,7 +815,7 @@ func TestApproveLoanValidImageLink(t *testing.T) {
 	// First create a loan
 	createReq := dto.CreateLoanRequest{
 		BorrowerID:      "user123",
-		PrincipalAmount: 25000.00,
+		PrincipalAmount: domain.NewMoney(25000.00),
 		Rate:            4.5,
 		ROI:             6.0,
 	}
@@ -421,7 +835,7 @@ func TestApproveLoanValidImageLink(t *testing.T) {
 
 	// Approve with valid image link
 	approveReq := dto.ApproveLoanRequest{
-		FieldValidatorProof: "https://example.com/proofs/field_visit_123.jpg", // Valid: image URL
+		FieldValidatorProof: domain.ProofLinks{"https://example.com/proofs/field_visit_123.jpg"}, // Valid: image URL
 		FieldValidatorID:    "validator_001",
 	}
 
@@ -444,6 +858,43 @@ func TestApproveLoanValidImageLink(t *testing.T) {
 	assert.NotEmpty(t, approvalDetails["approval_date"])
 }
 
+func TestApproveLoanProofs(t *testing.T) {
+	handler, router, db := setupTestHandler()
+	router.PUT("/loans/:id/approve", handler.ApproveLoan)
+
+	approve := func(proof interface{}) *httptest.ResponseRecorder {
+		loan := seedLoan(t, db, domain.StatusProposed, 10000.00)
+		return performRequest(router, "PUT", "/loans/"+loan.ID+"/approve", map[string]interface{}{
+			"field_validator_proof": proof,
+			"field_validator_id":    "validator_001",
+		})
+	}
+	proofOf := func(w *httptest.ResponseRecorder) interface{} {
+		var response dto.SuccessResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response.Data.(map[string]interface{})["approval_details"].(map[string]interface{})["field_validator_proof"]
+	}
+
+	// A single link is still accepted and stored as a list
+	w := approve("https://example.com/proofs/front.jpg")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []interface{}{"https://example.com/proofs/front.jpg"}, proofOf(w))
+
+	w = approve([]string{"https://example.com/proofs/front.jpg", "https://example.com/proofs/back.png"})
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []interface{}{"https://example.com/proofs/front.jpg", "https://example.com/proofs/back.png"}, proofOf(w))
+
+	// Every link must be an image
+	w = approve([]string{"https://example.com/proofs/front.jpg", "https://example.com/report.pdf"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// At least one proof is required
+	w = approve([]string{})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = approve(nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
 func TestInvestLoan(t *testing.T) {
 	handler, router, _ := setupTestHandler()
 
@@ -455,7 +906,7 @@ func TestInvestLoan(t *testing.T) {
 	// First create a loan
 	createReq := dto.CreateLoanRequest{
 		BorrowerID:      "user123",
-		PrincipalAmount: 25000.00,
+		PrincipalAmount: domain.NewMoney(25000.00),
 		Rate:            4.5,
 		ROI:             6.0,
 	}
@@ -475,7 +926,7 @@ func TestInvestLoan(t *testing.T) {
 
 	// Approve the loan first
 	approveReq := dto.ApproveLoanRequest{
-		FieldValidatorProof: "https://example.com/proofs/field_visit_123.jpg",
+		FieldValidatorProof: domain.ProofLinks{"https://example.com/proofs/field_visit_123.jpg"},
 		FieldValidatorID:    "validator_001",
 	}
 
@@ -488,7 +939,7 @@ func TestInvestLoan(t *testing.T) {
 	// Invest in the loan
 	investReq := dto.InvestLoanRequest{
 		InvestorID: "investor_001",
-		Amount:     10000.00,
+		Amount:     domain.NewMoney(10000.00),
 	}
 
 	reqBody3, _ := json.Marshal(investReq)
@@ -517,7 +968,7 @@ func TestDisburseLoan(t *testing.T) {
 	// First create a loan
 	createReq := dto.CreateLoanRequest{
 		BorrowerID:      "user123",
-		PrincipalAmount: 25000.00,
+		PrincipalAmount: domain.NewMoney(25000.00),
 		Rate:            4.5,
 		ROI:             6.0,
 	}
@@ -537,7 +988,7 @@ func TestDisburseLoan(t *testing.T) {
 
 	// Approve the loan
 	approveReq := dto.ApproveLoanRequest{
-		FieldValidatorProof: "https://example.com/proofs/field_visit_123.jpg",
+		FieldValidatorProof: domain.ProofLinks{"https://example.com/proofs/field_visit_123.jpg"},
 		FieldValidatorID:    "validator_001",
 	}
 
@@ -550,7 +1001,7 @@ func TestDisburseLoan(t *testing.T) {
 	// Invest fully in the loan
 	investReq := dto.InvestLoanRequest{
 		InvestorID: "investor_001",
-		Amount:     25000.00,
+		Amount:     domain.NewMoney(25000.00),
 	}
 
 	reqBody3, _ := json.Marshal(investReq)
@@ -579,6 +1030,63 @@ func TestDisburseLoan(t *testing.T) {
 	assert.Equal(t, "Loan disbursed successfully", response.Message)
 }
 
+func TestDisburseLoanInvalidAgreementLink(t *testing.T) {
+	handler, router, db := setupTestHandler()
+	router.PUT("/loans/:id/disburse", handler.DisburseLoan)
+
+	loan := seedLoan(t, db, domain.StatusInvested, 25000)
+
+	for _, link := range []string{"https://example.com/signed-agreement.docx", "https://example.com/signed-agreement"} {
+		w := performRequest(router, "PUT", "/loans/"+loan.ID+"/disburse", dto.DisburseLoanRequest{
+			SignedAgreementLink: link,
+			FieldOfficerID:      "officer_001",
+		})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, link)
+
+		var errorResponse dto.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResponse))
+		assert.Contains(t, errorResponse.Message, "SignedAgreementLink", link)
+		assert.Contains(t, errorResponse.Message, "document_link", link)
+	}
+
+	var stored domain.Loan
+	require.NoError(t, db.First(&stored, "id = ?", loan.ID).Error)
+	assert.Equal(t, domain.StatusInvested, stored.Status)
+}
+
+func TestGetLoanStats(t *testing.T) {
+	handler, router, db := setupTestHandler()
+	router.GET("/loans/stats", handler.GetLoanStats)
+	router.GET("/loans/:id", handler.GetLoan)
+
+	// No loans yet
+	w := performRequest(router, "GET", "/loans/stats", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data dto.LoanStatsResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, int64(0), response.Data.TotalLoans)
+	assert.Empty(t, response.Data.CountsByStatus)
+	assert.Equal(t, domain.Money(0), response.Data.TotalPrincipalOutstanding)
+	assert.Equal(t, 0.0, response.Data.AverageROI)
+
+	seedLoan(t, db, domain.StatusProposed, 10000)
+	seedLoan(t, db, domain.StatusDisbursed, 25000)
+
+	w = performRequest(router, "GET", "/loans/stats", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, int64(2), response.Data.TotalLoans)
+	assert.Equal(t, int64(1), response.Data.CountsByStatus[domain.StatusProposed])
+	assert.Equal(t, int64(1), response.Data.CountsByStatus[domain.StatusDisbursed])
+	assert.Equal(t, domain.NewMoney(25000), response.Data.TotalPrincipalOutstanding)
+	assert.Equal(t, 6.0, response.Data.AverageROI)
+}
+
 func TestGetLoanTransitions(t *testing.T) {
 	handler, router, _ := setupTestHandler()
 
@@ -589,7 +1097,7 @@ func TestGetLoanTransitions(t *testing.T) {
 	// First create a loan
 	createReq := dto.CreateLoanRequest{
 		BorrowerID:      "user123",
-		PrincipalAmount: 25000.00,
+		PrincipalAmount: domain.NewMoney(25000.00),
 		Rate:            4.5,
 		ROI:             6.0,
 	}
@@ -619,3 +1127,1801 @@ func TestGetLoanTransitions(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "Valid transitions retrieved successfully", response.Message)
 }
+
+func TestGetLoanTransitionsFields(t *testing.T) {
+	handler, router, db := setupTestHandler()
+	router.GET("/loans/:id/transitions", handler.GetLoanTransitions)
+
+	loan := seedLoan(t, db, domain.StatusProposed, 10000.00)
+	w := performRequest(router, "GET", "/loans/"+loan.ID+"/transitions", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data dto.TransitionResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+	fields := make(map[string][]domain.ActionField)
+	for _, transition := range response.Data.Transitions {
+		fields[transition.Action] = transition.Fields
+	}
+
+	var required []string
+	for _, field := range fields["approve"] {
+		if field.Required {
+			required = append(required, field.Name)
+		}
+	}
+	assert.Equal(t, []string{"field_validator_proof", "field_validator_id"}, required)
+
+	// The cancellation reason is optional
+	require.Contains(t, fields, "cancel")
+	assert.Equal(t, []domain.ActionField{{Name: "reason", Type: "string"}}, fields["cancel"])
+}
+
+// saturdayClock is a clock fixed on a Saturday, outside the weekday funding window
+type saturdayClock struct{}
+
+func (saturdayClock) Now() time.Time {
+	return time.Date(2024, 1, 13, 10, 0, 0, 0, time.UTC)
+}
+
+func TestInvestLoanOutsideFundingWindow(t *testing.T) {
+	cfg := config.LoanConfig{
+		FundingWindow: config.FundingWindowConfig{
+			Enabled:   true,
+			Days:      []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+			OpenHour:  9,
+			CloseHour: 17,
+			Location:  time.UTC,
+		},
+	}
+	handler, router, db := setupTestHandler(service.WithConfig(cfg), service.WithClock(saturdayClock{}))
+	router.PUT("/loans/:id/invest", handler.InvestLoan)
+
+	loan := seedLoan(t, db, domain.StatusApproved, 25000.00)
+
+	w := performRequest(router, "PUT", "/loans/"+loan.ID+"/invest", dto.InvestLoanRequest{
+		InvestorID: "investor_001",
+		Amount:     domain.NewMoney(10000.00),
+	})
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	var response dto.ErrorResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+	assert.Equal(t, dto.CodeFundingWindowClosed, response.Code)
+	assert.Equal(t, "2024-01-15T09:00:00Z", response.Details.(map[string]interface{})["next_open_at"])
+}
+
+func TestInvestLoanExceedsPrincipalErrorCode(t *testing.T) {
+	handler, router, db := setupTestHandler()
+	router.PUT("/loans/:id/invest", handler.InvestLoan)
+
+	loan := seedLoan(t, db, domain.StatusApproved, 25000.00)
+
+	w := performRequest(router, "PUT", "/loans/"+loan.ID+"/invest", dto.InvestLoanRequest{
+		InvestorID: "investor_001",
+		Amount:     domain.NewMoney(30000.00),
+	})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response dto.ErrorResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+	assert.Equal(t, dto.CodeCapacityExceeded, response.Code)
+}
+
+func TestInvestLoanInvalidStatus(t *testing.T) {
+	tests := []struct {
+		status  domain.LoanStatus
+		message string
+	}{
+		{domain.StatusProposed, "cannot invest: loan is not approved yet"},
+		{domain.StatusDisbursed, "cannot invest: loan already disbursed"},
+		{domain.StatusRejected, "cannot invest: loan was rejected"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			handler, router, db := setupTestHandler()
+			router.PUT("/loans/:id/invest", handler.InvestLoan)
+
+			loan := seedLoan(t, db, tt.status, 25000.00)
+
+			w := performRequest(router, "PUT", "/loans/"+loan.ID+"/invest", dto.InvestLoanRequest{
+				InvestorID: "investor_001",
+				Amount:     domain.NewMoney(10000.00),
+			})
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+
+			var response dto.ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, dto.CodeInvalidState, response.Code)
+			assert.Equal(t, tt.message, response.Message)
+		})
+	}
+}
+
+func TestInvestLoanPastInvestmentDeadline(t *testing.T) {
+	handler, router, db := setupTestHandler()
+	router.GET("/loans/:id", handler.GetLoan)
+	router.PUT("/loans/:id/invest", handler.InvestLoan)
+
+	loan := seedLoan(t, db, domain.StatusApproved, 25000.00)
+	deadline := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	require.NoError(t, db.Model(loan).Update("investment_deadline", deadline).Error)
+
+	w := performRequest(router, "GET", "/loans/"+loan.ID, nil)
+	var success dto.SuccessResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &success))
+	assert.Equal(t, deadline.Format(time.RFC3339), success.Data.(map[string]interface{})["investment_deadline"])
+
+	w = performRequest(router, "PUT", "/loans/"+loan.ID+"/invest", dto.InvestLoanRequest{
+		InvestorID: "investor_001",
+		Amount:     domain.NewMoney(10000.00),
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response dto.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, dto.CodeInvalidState, response.Code)
+	assert.Equal(t, domain.ErrInvestmentDeadlinePassed.Error(), response.Message)
+}
+
+func TestInvestLoanInvestorShareErrorCode(t *testing.T) {
+	handler, router, db := setupTestHandler(service.WithConfig(config.LoanConfig{MaxInvestorSharePercent: 30}))
+	router.PUT("/loans/:id/invest", handler.InvestLoan)
+
+	loan := seedLoan(t, db, domain.StatusApproved, 20000.00)
+
+	w := performRequest(router, "PUT", "/loans/"+loan.ID+"/invest", dto.InvestLoanRequest{
+		InvestorID: "investor_001",
+		Amount:     domain.NewMoney(5000.00),
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = performRequest(router, "PUT", "/loans/"+loan.ID+"/invest", dto.InvestLoanRequest{
+		InvestorID: "investor_001",
+		Amount:     domain.NewMoney(2000.00),
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response dto.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, dto.CodeInvestorShare, response.Code)
+	assert.Equal(t, map[string]interface{}{"max_investor_share_percent": 30.0, "max_investor_amount": 6000.0}, response.Details)
+
+	var stored domain.Loan
+	require.NoError(t, db.First(&stored, "id = ?", loan.ID).Error)
+	assert.Equal(t, domain.NewMoney(5000.00), stored.TotalInvested)
+}
+
+func TestInvestLoanCurrencyMismatch(t *testing.T) {
+	handler, router, db := setupTestHandler()
+	router.PUT("/loans/:id/invest", handler.InvestLoan)
+
+	loan := seedLoan(t, db, domain.StatusApproved, 20000.00)
+
+	w := performRequest(router, "PUT", "/loans/"+loan.ID+"/invest", dto.InvestLoanRequest{
+		InvestorID: "investor_001",
+		Amount:     domain.NewMoney(5000.00),
+		Currency:   "EUR",
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response dto.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "Validation error", response.Error)
+	assert.Equal(t, dto.CodeCurrencyMismatch, response.Code)
+	assert.Equal(t, map[string]interface{}{"loan_currency": "USD"}, response.Details)
+
+	// Codes that are not ISO 4217 fail binding
+	w = performRequest(router, "PUT", "/loans/"+loan.ID+"/invest", dto.InvestLoanRequest{
+		InvestorID: "investor_001",
+		Amount:     domain.NewMoney(5000.00),
+		Currency:   "usd",
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = performRequest(router, "PUT", "/loans/"+loan.ID+"/invest", dto.InvestLoanRequest{
+		InvestorID: "investor_001",
+		Amount:     domain.NewMoney(5000.00),
+		Currency:   "USD",
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var stored domain.Loan
+	require.NoError(t, db.First(&stored, "id = ?", loan.ID).Error)
+	assert.Equal(t, domain.NewMoney(5000.00), stored.TotalInvested)
+}
+
+func TestInvestLoanInvestorLimitErrorCode(t *testing.T) {
+	handler, router, db := setupTestHandler(service.WithConfig(config.LoanConfig{MaxInvestorsPerLoan: 1}))
+	router.PUT("/loans/:id/invest", handler.InvestLoan)
+
+	loan := seedLoan(t, db, domain.StatusApproved, 25000.00)
+
+	w := performRequest(router, "PUT", "/loans/"+loan.ID+"/invest", dto.InvestLoanRequest{
+		InvestorID: "investor_001",
+		Amount:     domain.NewMoney(5000.00),
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = performRequest(router, "PUT", "/loans/"+loan.ID+"/invest", dto.InvestLoanRequest{
+		InvestorID: "investor_002",
+		Amount:     domain.NewMoney(5000.00),
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response dto.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, dto.CodeInvestorLimit, response.Code)
+	assert.Contains(t, response.Message, "maximum allowed is 1")
+
+	w = performRequest(router, "PUT", "/loans/"+loan.ID+"/invest", dto.InvestLoanRequest{
+		InvestorID: "investor_001",
+		Amount:     domain.NewMoney(5000.00),
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestGetLoanDetails(t *testing.T) {
+	handler, router, db := setupTestHandler()
+	router.GET("/loans/:id/full", handler.GetLoanDetails)
+
+	loan := seedLoan(t, db, domain.StatusProposed, 25000.00)
+
+	w := performRequest(router, "GET", "/loans/"+loan.ID+"/full", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response dto.SuccessResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+
+	data := response.Data.(map[string]interface{})
+	assert.Equal(t, loan.ID, data["loan"].(map[string]interface{})["id"])
+	assert.Len(t, data["transitions"], 4)
+	assert.NotNil(t, data["events"])
+	summary := data["repayment_summary"].(map[string]interface{})
+	assert.Equal(t, 0.0, summary["remaining_balance"])
+	assert.Empty(t, summary["repayments"])
+
+	w = performRequest(router, "GET", "/loans/nonexistent-id/full", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestCreateLoanActiveLoanLimit(t *testing.T) {
+	handler, router, db := setupTestHandler(service.WithConfig(config.LoanConfig{MaxActiveLoansPerBorrower: 1}))
+	router.POST("/loans", handler.CreateLoan)
+
+	seedLoan(t, db, domain.StatusApproved, 25000.00)
+
+	w := performRequest(router, "POST", "/loans", dto.CreateLoanRequest{
+		BorrowerID:      "user123",
+		PrincipalAmount: domain.NewMoney(25000.00),
+		Rate:            4.5,
+		ROI:             6.0,
+	})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response dto.ErrorResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+	assert.Equal(t, dto.CodeActiveLoanLimit, response.Code)
+	assert.Equal(t, 1.0, response.Details.(map[string]interface{})["active_loans"])
+}
+
+func TestCreateLoanDuplicate(t *testing.T) {
+	handler, router, db := setupTestHandler(service.WithConfig(config.LoanConfig{RejectDuplicateLoans: true}))
+	router.POST("/loans", handler.CreateLoan)
+
+	existing := seedLoan(t, db, domain.StatusProposed, 25000.00)
+
+	w := performRequest(router, "POST", "/loans", dto.CreateLoanRequest{
+		BorrowerID:      "user123",
+		PrincipalAmount: domain.NewMoney(25000.00),
+		Rate:            4.5,
+		ROI:             6.0,
+	})
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	var response dto.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, dto.CodeDuplicateLoan, response.Code)
+	assert.Equal(t, existing.ID, response.Details.(map[string]interface{})["loan_id"])
+}
+
+func TestDisburseLoanMinimumFunding(t *testing.T) {
+	handler, router, db := setupTestHandler(service.WithConfig(config.LoanConfig{DisburseMode: "partial_allowed", MinFundingPercent: 75}))
+	router.PUT("/loans/:id/disburse", handler.DisburseLoan)
+
+	loan := seedLoan(t, db, domain.StatusApproved, 10000.00)
+	loan.TotalInvested = domain.NewMoney(5000.00)
+	require.NoError(t, db.Save(loan).Error)
+
+	disburseReq := dto.DisburseLoanRequest{
+		SignedAgreementLink: "https://example.com/signed-agreement.pdf",
+		FieldOfficerID:      "officer_001",
+	}
+	w := performRequest(router, "PUT", "/loans/"+loan.ID+"/disburse", disburseReq)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response dto.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, dto.CodeMinimumFunding, response.Code)
+	assert.Equal(t, 50.0, response.Details.(map[string]interface{})["funded_percent"])
+
+	loan.TotalInvested = domain.NewMoney(8000.00)
+	require.NoError(t, db.Save(loan).Error)
+	w = performRequest(router, "PUT", "/loans/"+loan.ID+"/disburse", disburseReq)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestDisburseLoanMinimumInvestors(t *testing.T) {
+	handler, router, db := setupTestHandler(service.WithConfig(config.LoanConfig{MinInvestorsForDisbursement: 2}))
+	router.PUT("/loans/:id/disburse", handler.DisburseLoan)
+
+	loan := seedLoan(t, db, domain.StatusInvested, 10000.00)
+	loan.TotalInvested = domain.NewMoney(10000.00)
+	loan.Investments = []domain.Investment{{InvestorID: "investor_001", Amount: domain.NewMoney(10000.00)}}
+	require.NoError(t, db.Save(loan).Error)
+
+	w := performRequest(router, "PUT", "/loans/"+loan.ID+"/disburse", dto.DisburseLoanRequest{
+		SignedAgreementLink: "https://example.com/signed-agreement.pdf",
+		FieldOfficerID:      "officer_001",
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response dto.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, dto.CodeMinimumInvestors, response.Code)
+	assert.Equal(t, 2.0, response.Details.(map[string]interface{})["min_investors"])
+}
+
+func TestDisburseLoanAgreementMismatch(t *testing.T) {
+	handler, router, db := setupTestHandler(service.WithConfig(config.LoanConfig{RequireAgreementMatch: true}))
+	router.PUT("/loans/:id/disburse", handler.DisburseLoan)
+
+	loan := seedLoan(t, db, domain.StatusInvested, 10000.00)
+	loan.TotalInvested = domain.NewMoney(10000.00)
+	require.NoError(t, db.Save(loan).Error)
+
+	w := performRequest(router, "PUT", "/loans/"+loan.ID+"/disburse", dto.DisburseLoanRequest{
+		SignedAgreementLink: "https://example.com/signed-agreement.pdf",
+		FieldOfficerID:      "officer_001",
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response dto.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, dto.CodeAgreementMismatch, response.Code)
+
+	w = performRequest(router, "PUT", "/loans/"+loan.ID+"/disburse", dto.DisburseLoanRequest{
+		SignedAgreementLink: "https://example.com/agreements/loan_" + loan.ID + "_signed.pdf",
+		FieldOfficerID:      "officer_001",
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestCreateLoanROIOutOfRange(t *testing.T) {
+	handler, router, _ := setupTestHandler(service.WithConfig(config.LoanConfig{MinROI: 5}))
+	router.POST("/loans", handler.CreateLoan)
+
+	w := performRequest(router, "POST", "/loans", dto.CreateLoanRequest{
+		BorrowerID:      "user123",
+		PrincipalAmount: domain.NewMoney(25000.00),
+		Rate:            4.5,
+		ROI:             4.0,
+	})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response dto.ErrorResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+	assert.Equal(t, dto.CodeROIOutOfRange, response.Code)
+	assert.Equal(t, 5.0, response.Details.(map[string]interface{})["min_roi"])
+}
+
+func TestCreateLoanCurrency(t *testing.T) {
+	handler, router, _ := setupTestHandler(service.WithConfig(config.LoanConfig{BaseCurrency: "EUR", AllowedCurrencies: []string{"EUR", "USD"}}))
+	router.POST("/loans", handler.CreateLoan)
+
+	tests := []struct {
+		name     string
+		currency string
+		status   int
+		want     string
+	}{
+		{name: "base currency by default", status: http.StatusCreated, want: "EUR"},
+		{name: "allowed currency", currency: "USD", status: http.StatusCreated, want: "USD"},
+		{name: "currency outside the allowlist", currency: "IDR", status: http.StatusBadRequest},
+		{name: "not an ISO 4217 code", currency: "XYZ", status: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := performRequest(router, "POST", "/loans", dto.CreateLoanRequest{
+				BorrowerID:      "user123",
+				PrincipalAmount: domain.NewMoney(25000.00),
+				Currency:        tt.currency,
+				Rate:            4.5,
+				ROI:             4.0,
+			})
+			require.Equal(t, tt.status, w.Code)
+
+			if tt.status != http.StatusCreated {
+				return
+			}
+			var response struct {
+				Data dto.LoanResponse `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.want, response.Data.Currency)
+		})
+	}
+
+	w := performRequest(router, "POST", "/loans", dto.CreateLoanRequest{
+		BorrowerID:      "user123",
+		PrincipalAmount: domain.NewMoney(25000.00),
+		Currency:        "IDR",
+		Rate:            4.5,
+		ROI:             4.0,
+	})
+	var response dto.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, dto.CodeUnsupportedCurrency, response.Code)
+	assert.Equal(t, map[string]interface{}{"allowed_currencies": []interface{}{"EUR", "USD"}}, response.Details)
+}
+
+func TestCreateLoanROIAboveRate(t *testing.T) {
+	handler, router, db := setupTestHandler(service.WithConfig(config.LoanConfig{ROIRatePolicy: "strict"}))
+	router.POST("/loans", handler.CreateLoan)
+	router.PUT("/loans/:id", handler.UpdateLoan)
+
+	w := performRequest(router, "POST", "/loans", dto.CreateLoanRequest{
+		BorrowerID:      "user123",
+		PrincipalAmount: domain.NewMoney(25000.00),
+		Rate:            6.0,
+		ROI:             6.0,
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response dto.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, dto.CodeROIAboveRate, response.Code)
+	assert.Equal(t, 6.0, response.Details.(map[string]interface{})["rate"])
+
+	loan := seedLoan(t, db, domain.StatusProposed, 25000.00)
+	roi := 5.0
+	w = performRequest(router, "PUT", "/loans/"+loan.ID, dto.UpdateLoanRequest{ROI: &roi})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, dto.CodeROIAboveRate, response.Code)
+}
+
+func TestRegenerateAgreement(t *testing.T) {
+	handler, router, db := setupTestHandler()
+	router.POST("/loans/:id/agreement/regenerate", handler.RegenerateAgreement)
+
+	loan := seedLoan(t, db, domain.StatusInvested, 25000.00)
+
+	w := performRequest(router, "POST", "/loans/"+loan.ID+"/agreement/regenerate", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Already has a valid agreement
+	w = performRequest(router, "POST", "/loans/"+loan.ID+"/agreement/regenerate", nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = performRequest(router, "POST", "/loans/"+loan.ID+"/agreement/regenerate?force=true", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	proposed := seedLoan(t, db, domain.StatusProposed, 25000.00)
+	w = performRequest(router, "POST", "/loans/"+proposed.ID+"/agreement/regenerate", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetAgreementData(t *testing.T) {
+	handler, router, db := setupTestHandler()
+	router.GET("/loans/:id/agreement/data", handler.GetAgreementData)
+
+	loan := seedLoan(t, db, domain.StatusInvested, 25000.00)
+	require.NoError(t, db.Create(&domain.Investment{LoanID: loan.ID, InvestorID: "investor_001", Amount: domain.NewMoney(25000.00)}).Error)
+
+	w := performRequest(router, "GET", "/loans/"+loan.ID+"/agreement/data", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data domain.AgreementData `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "user123", response.Data.BorrowerID)
+	require.Len(t, response.Data.Investors, 1)
+	assert.Equal(t, 100.0, response.Data.Investors[0].Share)
+
+	approved := seedLoan(t, db, domain.StatusApproved, 25000.00)
+	w = performRequest(router, "GET", "/loans/"+approved.ID+"/agreement/data", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetLoanConditionalRequests(t *testing.T) {
+	handler, router, db := setupTestHandler()
+	router.GET("/loans/:id", handler.GetLoan)
+
+	loan := seedLoan(t, db, domain.StatusProposed, 25000.00)
+
+	w := performRequest(router, "GET", "/loans/"+loan.ID, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	lastModified := w.Header().Get("Last-Modified")
+	require.NotEmpty(t, etag)
+	require.NotEmpty(t, lastModified)
+
+	// Matching ETag returns 304 without a body
+	w = httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/loans/"+loan.ID, nil)
+	req.Header.Set("If-None-Match", etag)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+
+	// Unchanged since Last-Modified returns 304
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/loans/"+loan.ID, nil)
+	req.Header.Set("If-Modified-Since", lastModified)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	// A modified loan gets a new ETag
+	require.NoError(t, db.Model(loan).Update("rate", 5.0).Error)
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/loans/"+loan.ID, nil)
+	req.Header.Set("If-None-Match", etag)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEqual(t, etag, w.Header().Get("ETag"))
+}
+
+func TestGetLoanConditionalRequestsAfterCovenantSatisfied(t *testing.T) {
+	handler, router, db := setupTestHandler()
+	router.GET("/loans/:id", handler.GetLoan)
+	router.PUT("/loans/:id/covenants/:covenantID/satisfy", handler.SatisfyCovenant)
+
+	loan := seedLoan(t, db, domain.StatusApproved, 25000.00)
+	covenant := &domain.Covenant{LoanID: loan.ID, Description: "Provide land title"}
+	require.NoError(t, db.Create(covenant).Error)
+
+	w := performRequest(router, "GET", "/loans/"+loan.ID, nil)
+	etag := w.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	w = performRequest(router, "PUT", "/loans/"+loan.ID+"/covenants/"+covenant.ID+"/satisfy", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// The covenant is part of the loan, so the cached copy is stale
+	w = httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/loans/"+loan.ID, nil)
+	req.Header.Set("If-None-Match", etag)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEqual(t, etag, w.Header().Get("ETag"))
+}
+
+func TestGetLoansConditionalRequests(t *testing.T) {
+	handler, router, db := setupTestHandler()
+	router.GET("/loans", handler.GetLoans)
+
+	seedLoan(t, db, domain.StatusProposed, 25000.00)
+	seedLoan(t, db, domain.StatusApproved, 30000.00)
+
+	w := performRequest(router, "GET", "/loans", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	w = httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/loans", nil)
+	req.Header.Set("If-None-Match", etag)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	// Different filters never share an ETag, even with the same result size
+	proposed := performRequest(router, "GET", "/loans?status=proposed", nil)
+	approved := performRequest(router, "GET", "/loans?status=approved", nil)
+	assert.NotEqual(t, proposed.Header().Get("ETag"), approved.Header().Get("ETag"))
+	assert.NotEqual(t, etag, proposed.Header().Get("ETag"))
+
+	// Adding a loan changes the listing ETag
+	seedLoan(t, db, domain.StatusProposed, 10000.00)
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/loans", nil)
+	req.Header.Set("If-None-Match", etag)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestReviewLoan(t *testing.T) {
+	handler, router, db := setupTestHandler()
+	router.PUT("/loans/:id/review", handler.ReviewLoan)
+	router.PUT("/loans/:id/review/clear", handler.ClearReview)
+
+	loan := seedLoan(t, db, domain.StatusProposed, 25000.00)
+
+	// Missing notes
+	w := performRequest(router, "PUT", "/loans/"+loan.ID+"/review", map[string]string{"reviewer_id": "reviewer_001"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = performRequest(router, "PUT", "/loans/"+loan.ID+"/review", dto.ReviewLoanRequest{
+		ReviewerID:  "reviewer_001",
+		ReviewNotes: "Verify collateral",
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response dto.SuccessResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	data := response.Data.(map[string]interface{})
+	assert.Equal(t, "under_review", data["status"])
+	assert.Equal(t, "reviewer_001", data["review_details"].(map[string]interface{})["reviewer_id"])
+
+	w = performRequest(router, "PUT", "/loans/"+loan.ID+"/review/clear", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = performRequest(router, "PUT", "/loans/"+loan.ID+"/review/clear", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = performRequest(router, "PUT", "/loans/nonexistent-id/review/clear", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestRejectLoan(t *testing.T) {
+	handler, router, db := setupTestHandler()
+	router.PUT("/loans/:id/reject", handler.RejectLoan)
+	router.PUT("/loans/:id/approve", handler.ApproveLoan)
+
+	loan := seedLoan(t, db, domain.StatusProposed, 25000.00)
+
+	// Missing reason
+	w := performRequest(router, "PUT", "/loans/"+loan.ID+"/reject", map[string]string{"field_validator_id": "validator_001"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = performRequest(router, "PUT", "/loans/"+loan.ID+"/reject", dto.RejectLoanRequest{
+		FieldValidatorID: "validator_001",
+		Reason:           "Business premises not found",
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response dto.SuccessResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	data := response.Data.(map[string]interface{})
+	assert.Equal(t, "rejected", data["status"])
+	assert.Equal(t, "Business premises not found", data["rejection_details"].(map[string]interface{})["reason"])
+
+	w = performRequest(router, "PUT", "/loans/"+loan.ID+"/approve", dto.ApproveLoanRequest{
+		FieldValidatorProof: domain.ProofLinks{"https://example.com/proofs/field_visit_123.jpg"},
+		FieldValidatorID:    "validator_001",
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = performRequest(router, "PUT", "/loans/"+loan.ID+"/reject", dto.RejectLoanRequest{
+		FieldValidatorID: "validator_001",
+		Reason:           "Again",
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = performRequest(router, "PUT", "/loans/nonexistent-id/reject", dto.RejectLoanRequest{
+		FieldValidatorID: "validator_001",
+		Reason:           "Missing",
+	})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestCancelLoan(t *testing.T) {
+	handler, router, db := setupTestHandler()
+	router.PUT("/loans/:id/cancel", handler.CancelLoan)
+	router.PUT("/loans/:id/invest", handler.InvestLoan)
+
+	loan := seedLoan(t, db, domain.StatusProposed, 25000.00)
+
+	w := performRequest(router, "PUT", "/loans/"+loan.ID+"/cancel", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response dto.SuccessResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "cancelled", response.Data.(map[string]interface{})["status"])
+
+	// Partially invested loans are cancelled with their investments refunded
+	approvedLoan := seedLoan(t, db, domain.StatusApproved, 25000.00)
+	w = performRequest(router, "PUT", "/loans/"+approvedLoan.ID+"/invest", dto.InvestLoanRequest{
+		InvestorID: "investor_001",
+		Amount:     domain.NewMoney(5000.00),
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = performRequest(router, "PUT", "/loans/"+approvedLoan.ID+"/cancel", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var refunded struct {
+		Data dto.LoanResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &refunded))
+	require.Len(t, refunded.Data.Refunds, 1)
+	assert.Equal(t, domain.NewMoney(5000.00), refunded.Data.TotalRefunded)
+	assert.Equal(t, domain.Money(0), refunded.Data.TotalInvested)
+
+	// The cancellation reason is optional and returned with the loan
+	reasonLoan := seedLoan(t, db, domain.StatusProposed, 25000.00)
+	w = performRequest(router, "PUT", "/loans/"+reasonLoan.ID+"/cancel", dto.CancelLoanRequest{Reason: "Borrower withdrew"})
+	require.Equal(t, http.StatusOK, w.Code)
+	var withReason struct {
+		Data dto.LoanResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &withReason))
+	assert.Equal(t, "Borrower withdrew", withReason.Data.StatusReason)
+	assert.Empty(t, refunded.Data.StatusReason)
+
+	// Fully invested loans cannot be cancelled
+	investedLoan := seedLoan(t, db, domain.StatusApproved, 25000.00)
+	w = performRequest(router, "PUT", "/loans/"+investedLoan.ID+"/invest", dto.InvestLoanRequest{
+		InvestorID: "investor_001",
+		Amount:     domain.NewMoney(25000.00),
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = performRequest(router, "PUT", "/loans/"+investedLoan.ID+"/cancel", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var errorResponse dto.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResponse))
+	assert.Equal(t, domain.ErrCannotCancel.Error(), errorResponse.Message)
+
+	w = performRequest(router, "PUT", "/loans/nonexistent-id/cancel", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestWithdrawInvestment(t *testing.T) {
+	handler, router, db := setupTestHandler()
+	router.PUT("/loans/:id/invest", handler.InvestLoan)
+	router.DELETE("/loans/:id/investments/:investmentID", handler.WithdrawInvestment)
+	router.DELETE("/investor/loans/:id/investments/:investmentID", func(c *gin.Context) {
+		c.Set(middleware.RoleKey, middleware.RoleInvestor)
+		c.Set(middleware.SubjectKey, "investor_002")
+	}, handler.WithdrawInvestment)
+
+	loan := seedLoan(t, db, domain.StatusApproved, 25000.00)
+	w := performRequest(router, "PUT", "/loans/"+loan.ID+"/invest", dto.InvestLoanRequest{
+		InvestorID: "investor_001",
+		Amount:     domain.NewMoney(5000.00),
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+	var invested struct {
+		Data dto.LoanResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &invested))
+	investmentID := invested.Data.Investments[0].ID
+
+	// The investment cannot be withdrawn through another loan
+	otherLoan := seedLoan(t, db, domain.StatusApproved, 25000.00)
+	w = performRequest(router, "DELETE", "/loans/"+otherLoan.ID+"/investments/"+investmentID, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Investors cannot withdraw another investor's investment
+	w = performRequest(router, "DELETE", "/investor/loans/"+loan.ID+"/investments/"+investmentID, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	var forbidden dto.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &forbidden))
+	assert.Equal(t, dto.CodeForbidden, forbidden.Code)
+
+	w = performRequest(router, "DELETE", "/loans/"+loan.ID+"/investments/"+investmentID, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var withdrawn struct {
+		Data dto.LoanResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &withdrawn))
+	assert.Equal(t, domain.StatusApproved, withdrawn.Data.Status)
+	assert.Equal(t, domain.Money(0), withdrawn.Data.TotalInvested)
+	assert.Empty(t, withdrawn.Data.Investments)
+	require.Len(t, withdrawn.Data.Refunds, 1)
+	assert.Equal(t, investmentID, withdrawn.Data.Refunds[0].InvestmentID)
+
+	// Withdrawals are blocked once the loan is fully funded
+	w = performRequest(router, "PUT", "/loans/"+loan.ID+"/invest", dto.InvestLoanRequest{
+		InvestorID: "investor_002",
+		Amount:     domain.NewMoney(25000.00),
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &invested))
+
+	w = performRequest(router, "DELETE", "/loans/"+loan.ID+"/investments/"+invested.Data.Investments[0].ID, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var errorResponse dto.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResponse))
+	assert.Equal(t, dto.CodeInvalidState, errorResponse.Code)
+
+	w = performRequest(router, "DELETE", "/loans/nonexistent-id/investments/"+investmentID, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestCreateLoanFractionalPrincipal(t *testing.T) {
+	handler, router, _ := setupTestHandler(service.WithConfig(config.LoanConfig{WholeUnitsOnly: true}))
+	router.POST("/loans", handler.CreateLoan)
+
+	w := performRequest(router, "POST", "/loans", dto.CreateLoanRequest{
+		BorrowerID:      "user123",
+		PrincipalAmount: domain.NewMoney(25000.75),
+		Rate:            4.5,
+		ROI:             6.0,
+	})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response dto.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, dto.CodeFractionalAmount, response.Code)
+}
+
+func TestCreateLoanSubCentPrincipal(t *testing.T) {
+	handler, router, _ := setupTestHandler()
+	router.POST("/loans", handler.CreateLoan)
+
+	w := performRequest(router, "POST", "/loans", map[string]interface{}{
+		"borrower_id":      "user123",
+		"principal_amount": 25000.005,
+		"rate":             4.5,
+		"roi":              6.0,
+	})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response dto.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, domain.ErrSubCentAmount.Error(), response.Message)
+}
+
+func TestValidateLoanBatch(t *testing.T) {
+	handler, router, db := setupTestHandler(service.WithConfig(config.LoanConfig{WholeUnitsOnly: true}))
+	router.POST("/loans/batch/validate", handler.ValidateLoanBatch)
+
+	w := performRequest(router, "POST", "/loans/batch/validate", map[string]interface{}{
+		"loans": []interface{}{
+			dto.CreateLoanRequest{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: 6.0},
+			map[string]interface{}{"borrower_id": "user123", "rate": 4.5},
+			dto.CreateLoanRequest{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.75), Rate: 4.5, ROI: 6.0},
+			map[string]interface{}{"borrower_id": "user123", "principal_amount": 1000, "rate": 4.5, "roi": 6, "term": 12},
+		},
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data dto.LoanBatchValidationResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.False(t, response.Data.Valid)
+	require.Len(t, response.Data.Results, 4)
+	assert.True(t, response.Data.Results[0].Valid)
+	assert.False(t, response.Data.Results[1].Valid)
+	assert.Len(t, response.Data.Results[1].Errors, 2)
+	assert.False(t, response.Data.Results[2].Valid)
+	assert.Equal(t, []string{domain.ErrFractionalAmount.Error()}, response.Data.Results[2].Errors)
+	assert.False(t, response.Data.Results[3].Valid)
+	assert.Contains(t, response.Data.Results[3].Errors[0], `unknown field "term"`)
+
+	var count int64
+	require.NoError(t, db.Model(&domain.Loan{}).Count(&count).Error)
+	assert.Zero(t, count)
+
+	w = performRequest(router, "POST", "/loans/batch/validate", map[string]interface{}{"loans": []interface{}{}})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestCreateLoanBatch(t *testing.T) {
+	handler, router, db := setupTestHandler(service.WithConfig(config.LoanConfig{WholeUnitsOnly: true, MaxLoanBatchSize: 3}))
+	router.POST("/loans/batch", handler.CreateLoanBatch)
+
+	w := performRequest(router, "POST", "/loans/batch", map[string]interface{}{
+		"loans": []interface{}{
+			dto.CreateLoanRequest{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: 6.0},
+			dto.CreateLoanRequest{BorrowerID: "user456", PrincipalAmount: domain.NewMoney(10000.00), Rate: 4.5, ROI: 6.0, Tags: []string{"pilot"}},
+		},
+	})
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var response struct {
+		Data dto.LoanBatchResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, response.Data.Created)
+	require.Len(t, response.Data.Results, 2)
+	for i, result := range response.Data.Results {
+		assert.Equal(t, i, result.Index)
+		assert.NotEmpty(t, result.ID)
+		assert.Empty(t, result.Errors)
+	}
+
+	var tagged domain.Loan
+	require.NoError(t, db.Preload("Tags").First(&tagged, "id = ?", response.Data.Results[1].ID).Error)
+	assert.Equal(t, []string{"pilot"}, tagged.TagNames())
+
+	// One invalid entry rolls back the whole batch
+	for _, invalid := range []interface{}{
+		map[string]interface{}{"borrower_id": "user789", "rate": 4.5},
+		dto.CreateLoanRequest{BorrowerID: "user789", PrincipalAmount: domain.NewMoney(25000.75), Rate: 4.5, ROI: 6.0},
+	} {
+		w = performRequest(router, "POST", "/loans/batch", map[string]interface{}{
+			"loans": []interface{}{
+				dto.CreateLoanRequest{BorrowerID: "user789", PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: 6.0},
+				invalid,
+			},
+		})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var errorResponse struct {
+			Details dto.LoanBatchResponse `json:"details"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResponse))
+		assert.False(t, errorResponse.Details.Created)
+		require.Len(t, errorResponse.Details.Results, 2)
+		assert.Empty(t, errorResponse.Details.Results[0].ID)
+		assert.Empty(t, errorResponse.Details.Results[0].Errors)
+		assert.Empty(t, errorResponse.Details.Results[1].ID)
+		assert.NotEmpty(t, errorResponse.Details.Results[1].Errors)
+	}
+
+	var count int64
+	require.NoError(t, db.Model(&domain.Loan{}).Where("borrower_id = ?", "user789").Count(&count).Error)
+	assert.Zero(t, count)
+
+	// Batches are capped by configuration
+	row := dto.CreateLoanRequest{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(1000.00), Rate: 4.5, ROI: 6.0}
+	w = performRequest(router, "POST", "/loans/batch", map[string]interface{}{
+		"loans": []interface{}{row, row, row, row},
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "maximum allowed is 3")
+
+	// Also when rows fail to decode, since the size is checked first
+	w = performRequest(router, "POST", "/loans/batch", map[string]interface{}{
+		"loans": []interface{}{row, row, row, map[string]interface{}{"borrower_id": "user789"}},
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "maximum allowed is 3")
+	assert.NotContains(t, w.Body.String(), "results")
+}
+
+func TestLoanTags(t *testing.T) {
+	handler, router, _ := setupTestHandler()
+	router.POST("/loans", handler.CreateLoan)
+	router.PUT("/loans/:id", handler.UpdateLoan)
+	router.GET("/loans", handler.GetLoans)
+
+	w := performRequest(router, "POST", "/loans", dto.CreateLoanRequest{
+		BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: 6.0, Tags: []string{"High Risk"},
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = performRequest(router, "POST", "/loans", dto.CreateLoanRequest{
+		BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: 6.0, Tags: []string{"agriculture", "pilot"},
+	})
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var created struct {
+		Data dto.LoanResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, []string{"agriculture", "pilot"}, created.Data.Tags)
+
+	w = performRequest(router, "POST", "/loans", dto.CreateLoanRequest{
+		BorrowerID: "user456", PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: 6.0, Tags: []string{"agriculture"},
+	})
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	invalid := []string{"high_risk"}
+	w = performRequest(router, "PUT", "/loans/"+created.Data.ID, dto.UpdateLoanRequest{Tags: &invalid})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var list struct {
+		Data []dto.LoanResponse `json:"data"`
+	}
+	w = performRequest(router, "GET", "/loans?tag=agriculture", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Len(t, list.Data, 2)
+
+	w = performRequest(router, "GET", "/loans?tag=agriculture,pilot&tag_match=all", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list.Data, 1)
+	assert.Equal(t, created.Data.ID, list.Data[0].ID)
+
+	w = performRequest(router, "GET", "/loans?tag=agriculture&tag_match=some", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetBorrowerHistory(t *testing.T) {
+	handler, router, _ := setupTestHandler()
+	router.POST("/loans", handler.CreateLoan)
+	router.GET("/borrowers/:borrowerID/history", handler.GetBorrowerHistory)
+
+	for _, principal := range []float64{25000.00, 10000.00} {
+		w := performRequest(router, "POST", "/loans", dto.CreateLoanRequest{
+			BorrowerID: "user123", PrincipalAmount: domain.NewMoney(principal), Rate: 4.5, ROI: 6.0,
+		})
+		require.Equal(t, http.StatusCreated, w.Code)
+	}
+
+	w := performRequest(router, "GET", "/borrowers/user123/history?limit=1", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response dto.SuccessResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	data := response.Data.(map[string]interface{})
+	assert.Equal(t, 2.0, data["total"])
+	assert.Equal(t, 1.0, data["limit"])
+	assert.Len(t, data["entries"], 1)
+
+	w = performRequest(router, "GET", "/borrowers/user123/history?page=0", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = performRequest(router, "GET", "/borrowers/user123/history?limit=500", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetBorrowerLoans(t *testing.T) {
+	handler, router, db := setupTestHandler()
+	router.GET("/borrowers/:borrowerID/loans", handler.GetBorrowerLoans)
+
+	seedLoan(t, db, domain.StatusProposed, 10000.00)
+	disbursed := seedLoan(t, db, domain.StatusDisbursed, 25000.00)
+	disbursed.DisbursementDetails = &domain.DisbursementDetails{DisbursedAmount: domain.NewMoney(20000.00)}
+	require.NoError(t, db.Save(disbursed).Error)
+
+	other := &domain.Loan{BorrowerID: "user456", PrincipalAmount: domain.NewMoney(50000.00), Rate: 4.5, ROI: 6.0, Status: domain.StatusDisbursed}
+	require.NoError(t, db.Create(other).Error)
+
+	var response struct {
+		Data dto.BorrowerLoansResponse `json:"data"`
+	}
+
+	w := performRequest(router, "GET", "/borrowers/user123/loans", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "user123", response.Data.BorrowerID)
+	assert.Equal(t, 2, response.Data.Count)
+	assert.Len(t, response.Data.Loans, 2)
+	for _, loan := range response.Data.Loans {
+		assert.Equal(t, "user123", loan.BorrowerID)
+	}
+	assert.Equal(t, domain.NewMoney(35000.00), response.Data.TotalPrincipal)
+	assert.Equal(t, domain.NewMoney(20000.00), response.Data.TotalDisbursed)
+
+	w = performRequest(router, "GET", "/borrowers/user456/loans", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.Data.Count)
+	assert.Equal(t, other.ID, response.Data.Loans[0].ID)
+	assert.Equal(t, domain.NewMoney(50000.00), response.Data.TotalPrincipal)
+	assert.Equal(t, domain.NewMoney(50000.00), response.Data.TotalDisbursed)
+
+	// A borrower without loans gets an empty list
+	w = performRequest(router, "GET", "/borrowers/nobody/loans", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"loans":[]`)
+	assert.Contains(t, w.Body.String(), `"count":0`)
+}
+
+func TestCorrectApproval(t *testing.T) {
+	handler, router, db := setupTestHandler(service.WithConfig(config.LoanConfig{ApprovalCorrectionWindow: 15 * time.Minute}))
+	router.PATCH("/loans/:id/approval", handler.CorrectApproval)
+	router.PATCH("/admin/loans/:id/approval", func(c *gin.Context) {
+		c.Set(middleware.RoleKey, middleware.RoleAdmin)
+	}, handler.CorrectApproval)
+
+	recent := seedLoan(t, db, domain.StatusApproved, 25000.00)
+	recent.ApprovalDetails = &domain.ApprovalDetails{
+		FieldValidatorProof: domain.ProofLinks{"https://example.com/proof.jpg"},
+		FieldValidatorID:    "validator_001",
+		ApprovalDate:        time.Now(),
+	}
+	require.NoError(t, db.Save(recent).Error)
+
+	stale := seedLoan(t, db, domain.StatusApproved, 25000.00)
+	stale.ApprovalDetails = &domain.ApprovalDetails{
+		FieldValidatorProof: domain.ProofLinks{"https://example.com/proof.jpg"},
+		FieldValidatorID:    "validator_001",
+		ApprovalDate:        time.Now().Add(-time.Hour),
+	}
+	require.NoError(t, db.Save(stale).Error)
+
+	// Empty correction
+	w := performRequest(router, "PATCH", "/loans/"+recent.ID+"/approval", map[string]interface{}{})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Proof must still be an image link
+	w = performRequest(router, "PATCH", "/loans/"+recent.ID+"/approval", dto.CorrectApprovalRequest{FieldValidatorProof: domain.ProofLinks{"https://example.com/proof.txt"}})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = performRequest(router, "PATCH", "/loans/"+recent.ID+"/approval", dto.CorrectApprovalRequest{FieldValidatorProof: domain.ProofLinks{"https://example.com/proof-fixed.png"}})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = performRequest(router, "PATCH", "/loans/"+stale.ID+"/approval", dto.CorrectApprovalRequest{FieldValidatorID: "validator_002"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	var response dto.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, dto.CodeCorrectionClosed, response.Code)
+
+	// The override comes from the caller's role, not the request body
+	w = performRequest(router, "PATCH", "/loans/"+stale.ID+"/approval", map[string]interface{}{"field_validator_id": "validator_002", "admin_override": true})
+	assert.NotEqual(t, http.StatusOK, w.Code)
+
+	w = performRequest(router, "PATCH", "/admin/loans/"+stale.ID+"/approval", dto.CorrectApprovalRequest{FieldValidatorID: "validator_002"})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Approvals of loans past approved are final, even for admins
+	disbursed := seedLoan(t, db, domain.StatusDisbursed, 25000.00)
+	disbursed.ApprovalDetails = &domain.ApprovalDetails{
+		FieldValidatorProof: domain.ProofLinks{"https://example.com/proof.jpg"},
+		FieldValidatorID:    "validator_001",
+		ApprovalDate:        time.Now(),
+	}
+	require.NoError(t, db.Save(disbursed).Error)
+
+	w = performRequest(router, "PATCH", "/admin/loans/"+disbursed.ID+"/approval", dto.CorrectApprovalRequest{FieldValidatorID: "validator_002"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, dto.CodeInvalidState, response.Code)
+}
+
+func TestApproveLoanDuplicateProof(t *testing.T) {
+	handler, router, db := setupTestHandler(service.WithConfig(config.LoanConfig{UniqueProofPerLoan: true}))
+	router.PUT("/loans/:id/approve", handler.ApproveLoan)
+
+	approved := seedLoan(t, db, domain.StatusApproved, 25000.00)
+	approved.ApprovalDetails = &domain.ApprovalDetails{
+		FieldValidatorProof: domain.ProofLinks{"https://example.com/proof.jpg"},
+		FieldValidatorID:    "validator_001",
+		ApprovalDate:        time.Now(),
+	}
+	require.NoError(t, db.Save(approved).Error)
+
+	proposed := seedLoan(t, db, domain.StatusProposed, 25000.00)
+
+	w := performRequest(router, "PUT", "/loans/"+proposed.ID+"/approve", dto.ApproveLoanRequest{
+		FieldValidatorProof: domain.ProofLinks{"https://example.com/proof.jpg"},
+		FieldValidatorID:    "validator_002",
+	})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	var response dto.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, dto.CodeDuplicateProof, response.Code)
+	assert.Equal(t, approved.ID, response.Details.(map[string]interface{})["loan_id"])
+}
+
+func TestInvestLoanClamped(t *testing.T) {
+	handler, router, db := setupTestHandler(service.WithConfig(config.LoanConfig{OverfundPolicy: "clamp"}))
+	router.PUT("/loans/:id/invest", handler.InvestLoan)
+
+	loan := seedLoan(t, db, domain.StatusApproved, 10000.00)
+
+	w := performRequest(router, "PUT", "/loans/"+loan.ID+"/invest", dto.InvestLoanRequest{InvestorID: "investor_001", Amount: domain.NewMoney(12000.00)})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data dto.InvestLoanResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, response.Data.Clamped)
+	assert.Equal(t, domain.NewMoney(12000.00), response.Data.RequestedAmount)
+	assert.Equal(t, domain.NewMoney(10000.00), response.Data.InvestedAmount)
+	assert.Equal(t, domain.StatusInvested, response.Data.Status)
+}
+
+func TestGetLedger(t *testing.T) {
+	handler, router, db := setupTestHandler()
+	router.GET("/loans/:id/ledger", handler.GetLedger)
+
+	loan := seedLoan(t, db, domain.StatusApproved, 10000.00)
+	base := time.Now().Add(-time.Hour)
+	require.NoError(t, db.Create(&domain.Investment{LoanID: loan.ID, InvestorID: "investor_002", Amount: domain.NewMoney(2500.00), CreatedAt: base.Add(time.Minute)}).Error)
+	require.NoError(t, db.Create(&domain.Investment{LoanID: loan.ID, InvestorID: "investor_001", Amount: domain.NewMoney(1500.00), CreatedAt: base}).Error)
+
+	w := performRequest(router, "GET", "/loans/"+loan.ID+"/ledger", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data dto.LedgerResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Data.Entries, 2)
+	assert.Equal(t, "investor_001", response.Data.Entries[0].InvestorID)
+	assert.Equal(t, domain.NewMoney(1500.00), response.Data.Entries[0].TotalInvested)
+	assert.Equal(t, domain.NewMoney(4000.00), response.Data.Entries[1].TotalInvested)
+
+	w = performRequest(router, "GET", "/loans/nonexistent-id/ledger", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetFundingTimeline(t *testing.T) {
+	handler, router, db := setupTestHandler()
+	router.GET("/loans/:id/funding-timeline", handler.GetFundingTimeline)
+
+	loan := seedLoan(t, db, domain.StatusInvested, 10000.00)
+	base := time.Now().Add(-time.Hour)
+	require.NoError(t, db.Create(&domain.Investment{LoanID: loan.ID, InvestorID: "investor_003", Amount: domain.NewMoney(5000.00), CreatedAt: base.Add(2 * time.Minute)}).Error)
+	require.NoError(t, db.Create(&domain.Investment{LoanID: loan.ID, InvestorID: "investor_001", Amount: domain.NewMoney(2000.00), CreatedAt: base}).Error)
+	require.NoError(t, db.Create(&domain.Investment{LoanID: loan.ID, InvestorID: "investor_002", Amount: domain.NewMoney(3000.00), CreatedAt: base.Add(time.Minute)}).Error)
+
+	w := performRequest(router, "GET", "/loans/"+loan.ID+"/funding-timeline", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data dto.FundingTimelineResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, loan.ID, response.Data.LoanID)
+	require.Len(t, response.Data.Entries, 3)
+	for i, want := range []struct {
+		investorID string
+		total      float64
+		percent    float64
+	}{{"investor_001", 2000.00, 20.0}, {"investor_002", 5000.00, 50.0}, {"investor_003", 10000.00, 100.0}} {
+		entry := response.Data.Entries[i]
+		assert.Equal(t, want.investorID, entry.InvestorID)
+		assert.Equal(t, domain.NewMoney(want.total), entry.TotalInvested)
+		assert.Equal(t, want.percent, entry.PercentFunded)
+	}
+
+	w = performRequest(router, "GET", "/loans/nonexistent-id/funding-timeline", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestReconcileLoan(t *testing.T) {
+	handler, router, db := setupTestHandler()
+	router.POST("/loans/:id/reconcile", handler.ReconcileLoan)
+
+	loan := seedLoan(t, db, domain.StatusApproved, 10000.00)
+	require.NoError(t, db.Create(&domain.Investment{LoanID: loan.ID, InvestorID: "investor_001", Amount: domain.NewMoney(2500.00)}).Error)
+	require.NoError(t, db.Model(loan).UpdateColumn("total_invested", domain.NewMoney(3000.00)).Error)
+
+	var response struct {
+		Message string                     `json:"message"`
+		Data    dto.ReconciliationResponse `json:"data"`
+	}
+	w := performRequest(router, "POST", "/loans/"+loan.ID+"/reconcile", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, response.Data.Corrected)
+	assert.Equal(t, domain.NewMoney(3000.00), response.Data.PreviousTotalInvested)
+	assert.Equal(t, domain.NewMoney(2500.00), response.Data.TotalInvested)
+
+	w = performRequest(router, "POST", "/loans/"+loan.ID+"/reconcile", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.False(t, response.Data.Corrected)
+	assert.Equal(t, "Loan reconciled, no discrepancy found", response.Message)
+
+	w = performRequest(router, "POST", "/loans/nonexistent-id/reconcile", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetLoanHistory(t *testing.T) {
+	handler, router, _ := setupTestHandler()
+	router.POST("/loans", handler.CreateLoan)
+	router.PUT("/loans/:id/reject", handler.RejectLoan)
+	router.GET("/loans/:id/history", handler.GetLoanHistory)
+
+	w := performRequest(router, "POST", "/loans", dto.CreateLoanRequest{
+		BorrowerID:      "user123",
+		PrincipalAmount: domain.NewMoney(25000.00),
+		Rate:            4.5,
+		ROI:             6.0,
+	})
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var created struct {
+		Data dto.LoanResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	loanID := created.Data.ID
+
+	w = performRequest(router, "PUT", "/loans/"+loanID+"/reject", dto.RejectLoanRequest{
+		FieldValidatorID: "validator_001",
+		Reason:           "Collateral could not be verified",
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = performRequest(router, "GET", "/loans/"+loanID+"/history", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data dto.LoanHistoryResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, loanID, response.Data.LoanID)
+	require.Len(t, response.Data.Events, 2)
+	assert.Equal(t, "create", response.Data.Events[0].Action)
+	assert.Equal(t, "reject", response.Data.Events[1].Action)
+	assert.Equal(t, domain.StatusRejected, response.Data.Events[1].ToStatus)
+	assert.Equal(t, "validator_001", response.Data.Events[1].ActorID)
+	assert.Equal(t, "Collateral could not be verified", response.Data.Events[1].Metadata["reason"])
+
+	w = performRequest(router, "GET", "/loans/nonexistent-id/history", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetAccruedInterest(t *testing.T) {
+	handler, router, db := setupTestHandler()
+	router.GET("/loans/:id/accrued-interest", handler.GetAccruedInterest)
+
+	loan := seedLoan(t, db, domain.StatusDisbursed, 10000.00)
+	loan.Rate = 12.0
+	loan.DisbursementDetails = &domain.DisbursementDetails{
+		FieldOfficerID:   "officer_001",
+		DisbursementDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	require.NoError(t, db.Save(loan).Error)
+
+	w := performRequest(router, "GET", "/loans/"+loan.ID+"/accrued-interest?as_of=2024-01-31", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Data dto.AccruedInterestResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 30, response.Data.Days)
+	assert.Equal(t, "simple", response.Data.Method)
+	assert.Equal(t, domain.NewMoney(98.63), response.Data.AccruedInterest)
+
+	w = performRequest(router, "GET", "/loans/"+loan.ID+"/accrued-interest?as_of=2024-12-31T00:00:00Z&method=daily_compound", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 365, response.Data.Days)
+	assert.Equal(t, domain.NewMoney(1274.75), response.Data.AccruedInterest)
+
+	for _, query := range []string{"?as_of=yesterday", "?method=monthly", "?as_of=2023-12-31"} {
+		w = performRequest(router, "GET", "/loans/"+loan.ID+"/accrued-interest"+query, nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+
+	approved := seedLoan(t, db, domain.StatusApproved, 10000.00)
+	w = performRequest(router, "GET", "/loans/"+approved.ID+"/accrued-interest", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = performRequest(router, "GET", "/loans/nonexistent-id/accrued-interest", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestRecordRepayment(t *testing.T) {
+	handler, router, db := setupTestHandler()
+	router.POST("/loans/:id/repayments", handler.RecordRepayment)
+
+	loan := seedLoan(t, db, domain.StatusDisbursed, 12000.00)
+	loan.TotalInvested = loan.PrincipalAmount
+	loan.DisbursementDetails = &domain.DisbursementDetails{
+		FieldOfficerID:   "officer_001",
+		DisbursementDate: time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC),
+	}
+	require.NoError(t, db.Save(loan).Error)
+
+	w := performRequest(router, "POST", "/loans/"+loan.ID+"/repayments", dto.RecordRepaymentRequest{Amount: domain.NewMoney(1000.00)})
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response struct {
+		Data dto.RepaymentResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, domain.StatusDisbursed, response.Data.Status)
+	assert.Equal(t, domain.NewMoney(1000.00), response.Data.Repayment.Amount)
+	assert.Equal(t, domain.NewMoney(1000.00), response.Data.TotalRepaid)
+	assert.Equal(t, response.Data.AmountDue-domain.NewMoney(1000.00), response.Data.RemainingBalance)
+
+	// Over-payment
+	w = performRequest(router, "POST", "/loans/"+loan.ID+"/repayments", dto.RecordRepaymentRequest{Amount: response.Data.RemainingBalance + 1})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var errorResponse dto.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResponse))
+	assert.Equal(t, dto.CodeOverpayment, errorResponse.Code)
+
+	// Loans that are not disbursed yet
+	approved := seedLoan(t, db, domain.StatusApproved, 12000.00)
+	w = performRequest(router, "POST", "/loans/"+approved.ID+"/repayments", dto.RecordRepaymentRequest{Amount: domain.NewMoney(1000.00)})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResponse))
+	assert.Equal(t, dto.CodeInvalidState, errorResponse.Code)
+
+	w = performRequest(router, "POST", "/loans/nonexistent-id/repayments", dto.RecordRepaymentRequest{Amount: domain.NewMoney(1000.00)})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetAmortization(t *testing.T) {
+	handler, router, db := setupTestHandler()
+	router.GET("/loans/:id/amortization", handler.GetAmortization)
+
+	loan := seedLoan(t, db, domain.StatusDisbursed, 10000.00)
+	loan.Rate = 12.0
+	loan.DisbursementDetails = &domain.DisbursementDetails{
+		FieldOfficerID:   "officer_001",
+		DisbursementDate: time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC),
+	}
+	require.NoError(t, db.Save(loan).Error)
+
+	w := performRequest(router, "GET", "/loans/"+loan.ID+"/amortization", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data dto.AmortizationResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.False(t, response.Data.Projected)
+	assert.Equal(t, domain.DefaultTermMonths, response.Data.TermMonths)
+	require.Len(t, response.Data.Periods, domain.DefaultTermMonths)
+	assert.Equal(t, 100.00, response.Data.Periods[0].Interest)
+	assert.Equal(t, 788.49, response.Data.Periods[0].Principal)
+	assert.Equal(t, 0.0, response.Data.Periods[11].Balance)
+	assert.InDelta(t, response.Data.TotalPayment-10000.00, response.Data.TotalInterest, 0.001)
+
+	// Loans that are not disbursed yet are projected
+	proposed := seedLoan(t, db, domain.StatusProposed, 5000.00)
+	w = performRequest(router, "GET", "/loans/"+proposed.ID+"/amortization", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, response.Data.Projected)
+	assert.NotEmpty(t, response.Data.Periods)
+
+	w = performRequest(router, "GET", "/loans/nonexistent-id/amortization", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetSchedule(t *testing.T) {
+	handler, router, db := setupTestHandler()
+	router.GET("/loans/:id/schedule", handler.GetSchedule)
+
+	loan := seedLoan(t, db, domain.StatusInvested, 10000.00)
+	loan.TermMonths = 6
+	require.NoError(t, db.Save(loan).Error)
+
+	w := performRequest(router, "GET", "/loans/"+loan.ID+"/schedule", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data dto.AmortizationResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, response.Data.Projected)
+	require.Len(t, response.Data.Periods, 6)
+	assert.Equal(t, 0.0, response.Data.Periods[5].Balance)
+
+	approved := seedLoan(t, db, domain.StatusApproved, 5000.00)
+	w = performRequest(router, "GET", "/loans/"+approved.ID+"/schedule", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = performRequest(router, "GET", "/loans/nonexistent-id/schedule", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetLoanDiff(t *testing.T) {
+	handler, router, _ := setupTestHandler()
+	router.POST("/loans", handler.CreateLoan)
+	router.PUT("/loans/:id", handler.UpdateLoan)
+	router.PUT("/loans/:id/approve", handler.ApproveLoan)
+	router.GET("/loans/:id/diff", handler.GetLoanDiff)
+
+	w := performRequest(router, "POST", "/loans", dto.CreateLoanRequest{
+		BorrowerID:      "user123",
+		PrincipalAmount: domain.NewMoney(25000.00),
+		Rate:            4.5,
+		ROI:             6.0,
+	})
+	require.Equal(t, http.StatusCreated, w.Code)
+	var createResponse struct {
+		Data dto.LoanResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &createResponse))
+	loanID := createResponse.Data.ID
+
+	w = performRequest(router, "PUT", "/loans/"+loanID, dto.UpdateLoanRequest{PrincipalAmount: moneyPtr(30000.00)})
+	require.Equal(t, http.StatusOK, w.Code)
+	w = performRequest(router, "PUT", "/loans/"+loanID+"/approve", dto.ApproveLoanRequest{
+		FieldValidatorProof: domain.ProofLinks{"https://example.com/proofs/field_visit_123.jpg"},
+		FieldValidatorID:    "validator_001",
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = performRequest(router, "GET", "/loans/"+loanID+"/diff?from=1&to=3", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Data dto.LoanDiffResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.Data.From)
+	assert.Equal(t, 3, response.Data.To)
+
+	fields := make(map[string]domain.FieldChange)
+	for _, change := range response.Data.Changes {
+		fields[change.Field] = change
+	}
+	assert.Len(t, fields, 4)
+	assert.Equal(t, 25000.00, fields["principal_amount"].From)
+	assert.Equal(t, 30000.00, fields["principal_amount"].To)
+	assert.Equal(t, "approved", fields["status"].To)
+	assert.Contains(t, fields, "field_validator_id")
+	assert.Contains(t, fields, "field_validator_proof")
+
+	// from must be lower than to
+	w = performRequest(router, "GET", "/loans/"+loanID+"/diff?from=3&to=1", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = performRequest(router, "GET", "/loans/"+loanID+"/diff?from=1", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = performRequest(router, "GET", "/loans/"+loanID+"/diff?from=1&to=9", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = performRequest(router, "GET", "/loans/nonexistent-id/diff?from=1&to=2", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetInvestorCashflows(t *testing.T) {
+	handler, router, db := setupTestHandler()
+	router.GET("/investors/:investorID/cashflows", handler.GetInvestorCashflows)
+
+	disbursed := seedLoan(t, db, domain.StatusDisbursed, 1200.00)
+	disbursed.Rate = 0
+	disbursed.TermMonths = 3
+	disbursed.DisbursementDetails = &domain.DisbursementDetails{
+		FieldOfficerID:   "officer_001",
+		DisbursementDate: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+	}
+	require.NoError(t, db.Save(disbursed).Error)
+	require.NoError(t, db.Create(&domain.Investment{LoanID: disbursed.ID, InvestorID: "investor_001", Amount: domain.NewMoney(600.00)}).Error)
+	require.NoError(t, db.Create(&domain.Investment{LoanID: disbursed.ID, InvestorID: "investor_002", Amount: domain.NewMoney(600.00)}).Error)
+
+	// Loans that are not disbursed yet have no cashflows
+	approved := seedLoan(t, db, domain.StatusApproved, 1000.00)
+	require.NoError(t, db.Create(&domain.Investment{LoanID: approved.ID, InvestorID: "investor_001", Amount: domain.NewMoney(500.00)}).Error)
+
+	w := performRequest(router, "GET", "/investors/investor_001/cashflows", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data dto.InvestorCashflowsResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 600.00, response.Data.TotalExpected)
+	require.Len(t, response.Data.Months, 3)
+	assert.Equal(t, "2024-04", response.Data.Months[0].Month)
+	assert.Equal(t, 200.00, response.Data.Months[0].Amount)
+}
+
+func TestGetInvestorLoans(t *testing.T) {
+	handler, router, db := setupTestHandler()
+	router.GET("/investors/:investorID/loans", handler.GetInvestorLoans)
+
+	loan := seedLoan(t, db, domain.StatusApproved, 25000.00)
+	require.NoError(t, db.Create(&domain.Investment{LoanID: loan.ID, InvestorID: "investor_001", Amount: domain.NewMoney(4000.00)}).Error)
+	seedLoan(t, db, domain.StatusApproved, 25000.00)
+
+	w := performRequest(router, "GET", "/investors/investor_001/loans?status=approved", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response dto.SuccessResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	data := response.Data.(map[string]interface{})
+	assert.Equal(t, 1.0, data["total"])
+	loans := data["loans"].([]interface{})
+	require.Len(t, loans, 1)
+	assert.Equal(t, loan.ID, loans[0].(map[string]interface{})["id"])
+	assert.Equal(t, 4000.0, loans[0].(map[string]interface{})["investor_contribution"])
+
+	w = performRequest(router, "GET", "/investors/investor_001/loans?status=disbursed", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Empty(t, response.Data.(map[string]interface{})["loans"])
+}
+
+func TestCreateLoanUnknownField(t *testing.T) {
+	handler, router, _ := setupTestHandler()
+	router.POST("/loans", handler.CreateLoan)
+
+	body := map[string]interface{}{
+		"borrower_id":     "user123",
+		"principalAmount": 25000.00,
+		"rate":            4.5,
+		"roi":             6.0,
+	}
+
+	w := performRequest(router, "POST", "/loans", body)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response dto.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Contains(t, response.Message, `unknown field "principalAmount"`)
+
+	// Relaxed mode ignores unknown fields
+	SetStrictJSON(false)
+	defer SetStrictJSON(true)
+
+	body["principal_amount"] = 25000.00
+	w = performRequest(router, "POST", "/loans", body)
+	assert.Equal(t, http.StatusCreated, w.Code)
+}
+
+func TestApproveLoanWithCovenants(t *testing.T) {
+	handler, router, db := setupTestHandler()
+	router.PUT("/loans/:id/approve", handler.ApproveLoan)
+	router.PUT("/loans/:id/covenants/:covenantID/satisfy", handler.SatisfyCovenant)
+
+	loan := seedLoan(t, db, domain.StatusProposed, 25000.00)
+
+	w := performRequest(router, "PUT", "/loans/"+loan.ID+"/approve", dto.ApproveLoanRequest{
+		FieldValidatorProof: domain.ProofLinks{"https://example.com/proof.jpg"},
+		FieldValidatorID:    "validator_001",
+		Covenants:           []string{"Provide land title"},
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response dto.SuccessResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	data := response.Data.(map[string]interface{})
+	assert.Equal(t, false, data["covenants_satisfied"])
+	covenants := data["covenants"].([]interface{})
+	require.Len(t, covenants, 1)
+	covenantID := covenants[0].(map[string]interface{})["id"].(string)
+
+	w = performRequest(router, "PUT", "/loans/"+loan.ID+"/covenants/"+covenantID+"/satisfy", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, true, response.Data.(map[string]interface{})["covenants_satisfied"])
+
+	w = performRequest(router, "PUT", "/loans/"+loan.ID+"/covenants/nonexistent-id/satisfy", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestCreateLoanIdempotencyKey(t *testing.T) {
+	handler, router, db := setupTestHandler()
+	router.POST("/loans", handler.CreateLoan)
+
+	body := dto.CreateLoanRequest{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: 6.0}
+	send := func() *httptest.ResponseRecorder {
+		jsonBody, _ := json.Marshal(body)
+		req, _ := http.NewRequest("POST", "/loans", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(middleware.IdempotencyKeyHeader, "form-1")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send()
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Empty(t, w.Header().Get(middleware.IdempotentReplayedHeader))
+
+	w = send()
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "true", w.Header().Get(middleware.IdempotentReplayedHeader))
+
+	var count int64
+	require.NoError(t, db.Model(&domain.Loan{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestGetLoanInvestmentsLimit(t *testing.T) {
+	handler, router, db := setupTestHandler()
+	router.GET("/loans/:id", handler.GetLoan)
+	router.GET("/loans", handler.GetLoans)
+
+	loan := seedLoan(t, db, domain.StatusApproved, 25000.00)
+	for i := 0; i < 12; i++ {
+		require.NoError(t, db.Create(&domain.Investment{LoanID: loan.ID, InvestorID: "investor_001", Amount: domain.NewMoney(100.00)}).Error)
+	}
+
+	// Default cap
+	w := performRequest(router, "GET", "/loans/"+loan.ID, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response dto.SuccessResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	data := response.Data.(map[string]interface{})
+	assert.Len(t, data["investments"], 10)
+	assert.Equal(t, 12.0, data["investment_count"])
+	assert.Equal(t, true, data["investments_truncated"])
+
+	// Explicit limit
+	w = performRequest(router, "GET", "/loans?investments_limit=3", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	loans := response.Data.([]interface{})
+	require.Len(t, loans, 1)
+	assert.Len(t, loans[0].(map[string]interface{})["investments"], 3)
+
+	// Zero embeds everything
+	w = performRequest(router, "GET", "/loans/"+loan.ID+"?investments_limit=0", nil)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	data = response.Data.(map[string]interface{})
+	assert.Len(t, data["investments"], 12)
+	assert.Equal(t, false, data["investments_truncated"])
+
+	w = performRequest(router, "GET", "/loans/"+loan.ID+"?investments_limit=-1", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestRevokeApproval(t *testing.T) {
+	handler, router, db := setupTestHandler()
+	router.POST("/loans/:id/revoke-approval", handler.RevokeApproval)
+
+	approved := seedLoan(t, db, domain.StatusApproved, 25000.00)
+	invested := seedLoan(t, db, domain.StatusApproved, 25000.00)
+	require.NoError(t, db.Create(&domain.Investment{LoanID: invested.ID, InvestorID: "investor_001", Amount: domain.NewMoney(1000.00)}).Error)
+
+	w := performRequest(router, "POST", "/loans/"+approved.ID+"/revoke-approval", map[string]string{})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = performRequest(router, "POST", "/loans/"+approved.ID+"/revoke-approval", dto.RevokeApprovalRequest{Reason: "bad proof"})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response dto.SuccessResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "proposed", response.Data.(map[string]interface{})["status"])
+
+	w = performRequest(router, "POST", "/loans/"+invested.ID+"/revoke-approval", dto.RevokeApprovalRequest{Reason: "bad proof"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = performRequest(router, "POST", "/loans/nonexistent-id/revoke-approval", dto.RevokeApprovalRequest{Reason: "bad proof"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestForceTransition(t *testing.T) {
+	handler, router, db := setupTestHandler()
+	router.POST("/admin/loans/:id/transition", func(c *gin.Context) {
+		c.Set(middleware.SubjectKey, "admin_001")
+		handler.ForceTransition(c)
+	})
+
+	approved := seedLoan(t, db, domain.StatusApproved, 25000.00)
+	proposed := seedLoan(t, db, domain.StatusProposed, 25000.00)
+
+	// The justification is mandatory
+	w := performRequest(router, "POST", "/admin/loans/"+approved.ID+"/transition", map[string]string{"status": "invested"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = performRequest(router, "POST", "/admin/loans/"+approved.ID+"/transition", dto.ForceTransitionRequest{
+		Status:        domain.StatusInvested,
+		Justification: "investment confirmed by the bank",
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response dto.SuccessResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "invested", response.Data.(map[string]interface{})["status"])
+
+	var event domain.LoanEvent
+	require.NoError(t, db.Where("loan_id = ?", approved.ID).Last(&event).Error)
+	assert.True(t, event.ManualOverride)
+	assert.Equal(t, "admin_001", event.ActorID)
+	assert.Equal(t, "investment confirmed by the bank", event.Metadata["justification"])
+
+	// No arbitrary jumps
+	w = performRequest(router, "POST", "/admin/loans/"+proposed.ID+"/transition", dto.ForceTransitionRequest{
+		Status:        domain.StatusDisbursed,
+		Justification: "borrower already has the money",
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), dto.CodeInvalidState)
+
+	// Disbursements are never forced
+	invested := seedLoan(t, db, domain.StatusInvested, 25000.00)
+	w = performRequest(router, "POST", "/admin/loans/"+invested.ID+"/transition", dto.ForceTransitionRequest{
+		Status:        domain.StatusDisbursed,
+		Justification: "paid out by bank transfer",
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), dto.CodeInvalidState)
+
+	w = performRequest(router, "POST", "/admin/loans/nonexistent-id/transition", dto.ForceTransitionRequest{
+		Status:        domain.StatusInvested,
+		Justification: "investment confirmed by the bank",
+	})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
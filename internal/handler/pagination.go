@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// maxEmbeddedInvestments is the default number of investments embedded in loan responses
+var maxEmbeddedInvestments = 10
+
+// SetMaxEmbeddedInvestments sets the default number of investments embedded in loan list and
+// detail responses. Zero embeds all investments.
+func SetMaxEmbeddedInvestments(max int) {
+	maxEmbeddedInvestments = max
+}
+
+// parseInvestmentsLimit reads the investments_limit query parameter, falling back to the configured default
+func parseInvestmentsLimit(c *gin.Context) (int, error) {
+	value := c.Query("investments_limit")
+	if value == "" {
+		return maxEmbeddedInvestments, nil
+	}
+
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 0 {
+		return 0, fmt.Errorf("investments_limit must be a non-negative integer")
+	}
+	return limit, nil
+}
+
+// parsePagination reads the page and limit query parameters, applying defaults when absent
+func parsePagination(c *gin.Context) (page int, limit int, err error) {
+	page, limit = 1, defaultPageLimit
+
+	if value := c.Query("page"); value != "" {
+		page, err = strconv.Atoi(value)
+		if err != nil || page < 1 {
+			return 0, 0, fmt.Errorf("page must be a positive integer")
+		}
+	}
+
+	if value := c.Query("limit"); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxPageLimit {
+			return 0, 0, fmt.Errorf("limit must be an integer between 1 and %d", maxPageLimit)
+		}
+	}
+
+	return page, limit, nil
+}
+
+// parseOffsetPagination reads the offset and limit query parameters, applying defaults when absent
+func parseOffsetPagination(c *gin.Context) (offset int, limit int, err error) {
+	offset, limit = 0, defaultPageLimit
+
+	if value := c.Query("offset"); value != "" {
+		offset, err = strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			return 0, 0, fmt.Errorf("offset must be a non-negative integer")
+		}
+	}
+
+	if value := c.Query("limit"); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxPageLimit {
+			return 0, 0, fmt.Errorf("limit must be an integer between 1 and %d", maxPageLimit)
+		}
+	}
+
+	return offset, limit, nil
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"loan-service/internal/dto"
+	"loan-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StatsHandler handles HTTP requests for portfolio statistics
+type StatsHandler struct {
+	statsService service.StatsService
+}
+
+// NewStatsHandler creates a new stats handler
+func NewStatsHandler(statsService service.StatsService) *StatsHandler {
+	return &StatsHandler{
+		statsService: statsService,
+	}
+}
+
+// GetOutstanding returns the outstanding balance across disbursed loans
+func (h *StatsHandler) GetOutstanding(c *gin.Context) {
+	report, err := h.statsService.GetOutstanding(c.Query("group_by"))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidGroupBy) {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Validation error",
+				Message: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Outstanding balance retrieved successfully",
+		Data: dto.OutstandingResponse{
+			GroupBy:          report.GroupBy,
+			Loans:            report.Total.Loans,
+			TotalPrincipal:   report.Total.Principal,
+			TotalOutstanding: report.Total.Outstanding,
+			Groups:           report.Groups,
+		},
+	})
+}
+
+// GetStats returns the platform statistics snapshot, recomputing it when fresh=true
+func (h *StatsHandler) GetStats(c *gin.Context) {
+	stats, err := h.statsService.GetPlatformStats(c.Query("fresh") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Statistics retrieved successfully",
+		Data:    stats,
+	})
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"loan-service/internal/domain"
+	"loan-service/internal/dto"
+	"loan-service/internal/repository"
+	"loan-service/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetOutstanding(t *testing.T) {
+	_, router, db := setupTestHandler()
+	handler := NewStatsHandler(service.NewStatsService(repository.NewStatsRepository(db)))
+	router.GET("/stats/outstanding", handler.GetOutstanding)
+
+	seedLoan(t, db, domain.StatusDisbursed, 25000.00)
+	seedLoan(t, db, domain.StatusDisbursed, 15000.00)
+	seedLoan(t, db, domain.StatusApproved, 10000.00)
+
+	w := performRequest(router, "GET", "/stats/outstanding", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response dto.SuccessResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	data := response.Data.(map[string]interface{})
+	assert.Equal(t, 2.0, data["loans"])
+	assert.Equal(t, 40000.0, data["total_outstanding"])
+	assert.Nil(t, data["groups"])
+
+	w = performRequest(router, "GET", "/stats/outstanding?group_by=borrower", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	data = response.Data.(map[string]interface{})
+	assert.Equal(t, "borrower", data["group_by"])
+	assert.Len(t, data["groups"], 1)
+
+	w = performRequest(router, "GET", "/stats/outstanding?group_by=investor", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetStats(t *testing.T) {
+	_, router, db := setupTestHandler()
+	handler := NewStatsHandler(service.NewStatsService(repository.NewStatsRepository(db)))
+	router.GET("/stats", handler.GetStats)
+
+	seedLoan(t, db, domain.StatusProposed, 25000.00)
+
+	w := performRequest(router, "GET", "/stats", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response dto.SuccessResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	data := response.Data.(map[string]interface{})
+	assert.Equal(t, 1.0, data["total_loans"])
+	assert.NotEmpty(t, data["computed_at"])
+	assert.Equal(t, 1.0, data["counts_by_status"].(map[string]interface{})["proposed"])
+
+	seedLoan(t, db, domain.StatusProposed, 25000.00)
+
+	w = performRequest(router, "GET", "/stats?fresh=true", nil)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 2.0, response.Data.(map[string]interface{})["total_loans"])
+}
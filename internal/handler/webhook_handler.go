@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"loan-service/internal/domain"
+	"loan-service/internal/dto"
+	"loan-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// WebhookHandler handles HTTP requests for webhook subscriptions
+type WebhookHandler struct {
+	webhookService service.WebhookService
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(webhookService service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+// CreateSubscription subscribes a URL to loan events
+func (h *WebhookHandler) CreateSubscription(c *gin.Context) {
+	var req dto.CreateWebhookRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, validationErrorResponse(err, req))
+		return
+	}
+
+	subscription := &domain.WebhookSubscription{
+		URL:        req.URL,
+		EventTypes: req.EventTypes,
+	}
+
+	if err := h.webhookService.CreateSubscription(subscription); err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse{
+		Message: "Webhook subscription created successfully",
+		Data:    subscription,
+	})
+}
+
+// GetSubscriptions retrieves all webhook subscriptions
+func (h *WebhookHandler) GetSubscriptions(c *gin.Context) {
+	subscriptions, err := h.webhookService.GetSubscriptions()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Webhook subscriptions retrieved successfully",
+		Data:    subscriptions,
+	})
+}
+
+// DeleteSubscription removes a webhook subscription
+func (h *WebhookHandler) DeleteSubscription(c *gin.Context) {
+	if err := h.webhookService.DeleteSubscription(c.Param("id")); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse{
+				Error:   "Not found",
+				Message: "Webhook subscription not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "Database error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Message: "Webhook subscription deleted successfully",
+	})
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"loan-service/internal/domain"
+	"loan-service/internal/dto"
+	"loan-service/internal/repository"
+	"loan-service/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookSubscriptionCRUD(t *testing.T) {
+	_, router, db := setupTestHandler()
+	handler := NewWebhookHandler(service.NewWebhookService(repository.NewWebhookRepository(db)))
+
+	router.POST("/webhooks", handler.CreateSubscription)
+	router.GET("/webhooks", handler.GetSubscriptions)
+	router.DELETE("/webhooks/:id", handler.DeleteSubscription)
+
+	w := performRequest(router, "POST", "/webhooks", dto.CreateWebhookRequest{
+		URL:        "https://example.com/hooks/loans",
+		EventTypes: []string{domain.EventInvestmentCreated},
+	})
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var createResponse struct {
+		Data domain.WebhookSubscription `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &createResponse))
+	assert.Equal(t, []string{domain.EventInvestmentCreated}, createResponse.Data.EventTypes)
+
+	// Unknown event types and invalid URLs are rejected
+	w = performRequest(router, "POST", "/webhooks", dto.CreateWebhookRequest{
+		URL:        "https://example.com/hooks/loans",
+		EventTypes: []string{"loan.deleted"},
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = performRequest(router, "POST", "/webhooks", dto.CreateWebhookRequest{URL: "not a url"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = performRequest(router, "GET", "/webhooks", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var listResponse struct {
+		Data []domain.WebhookSubscription `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listResponse))
+	require.Len(t, listResponse.Data, 1)
+	assert.Equal(t, []string{domain.EventInvestmentCreated}, listResponse.Data[0].EventTypes)
+
+	w = performRequest(router, "DELETE", "/webhooks/"+createResponse.Data.ID, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = performRequest(router, "DELETE", "/webhooks/"+createResponse.Data.ID, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,39 @@
+package logging
+
+import (
+	"io"
+	"log/slog"
+	"os"
+
+	"loan-service/internal/config"
+)
+
+// New creates a logger writing to w at the configured level, as JSON lines when the format is
+// json and as key=value text otherwise
+func New(w io.Writer, cfg config.LogConfig) *slog.Logger {
+	levels := map[string]slog.Level{
+		"debug": slog.LevelDebug,
+		"info":  slog.LevelInfo,
+		"warn":  slog.LevelWarn,
+		"error": slog.LevelError,
+	}
+
+	level, ok := levels[cfg.Level]
+	if !ok {
+		level = slog.LevelInfo
+	}
+
+	options := &slog.HandlerOptions{Level: level}
+	if cfg.Format == "json" {
+		return slog.New(slog.NewJSONHandler(w, options))
+	}
+	return slog.New(slog.NewTextHandler(w, options))
+}
+
+// Setup makes a logger writing to stdout the default logger. Lines written through the standard
+// log package are routed through it as well, at info level.
+func Setup(cfg config.LogConfig) *slog.Logger {
+	logger := New(os.Stdout, cfg)
+	slog.SetDefault(logger)
+	return logger
+}
@@ -0,0 +1,53 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"loan-service/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, config.LogConfig{Level: "info", Format: "text"})
+
+	// Debug logs are suppressed at info
+	logger.Debug("loading rules", "count", 3)
+	assert.Empty(t, buf.String())
+
+	logger.Info("loan created", "loan_id", "loan_001")
+	assert.Contains(t, buf.String(), "level=INFO")
+	assert.Contains(t, buf.String(), "loan_id=loan_001")
+
+	buf.Reset()
+	logger = New(&buf, config.LogConfig{Level: "debug", Format: "text"})
+	logger.Debug("loading rules", "count", 3)
+	assert.Contains(t, buf.String(), "level=DEBUG")
+
+	buf.Reset()
+	logger = New(&buf, config.LogConfig{Level: "error", Format: "text"})
+	logger.Warn("slow request")
+	assert.Empty(t, buf.String())
+}
+
+func TestNewFormat(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, config.LogConfig{Level: "info", Format: "json"})
+	logger.Info("loan created", "loan_id", "loan_001")
+
+	var line map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.Equal(t, "INFO", line["level"])
+	assert.Equal(t, "loan created", line["msg"])
+	assert.Equal(t, "loan_001", line["loan_id"])
+
+	buf.Reset()
+	logger = New(&buf, config.LogConfig{Level: "info", Format: "text"})
+	logger.Info("loan created")
+	assert.True(t, strings.HasPrefix(buf.String(), "time="))
+}
@@ -1,18 +1,70 @@
 package middleware
 
 import (
+	"net/http"
+	"strings"
+
 	"github.com/gin-gonic/gin"
 )
 
-// CORS middleware for handling Cross-Origin Resource Sharing
+// Default CORS settings, allowing any origin
+var (
+	corsAllowedOrigins = []string{"*"}
+	corsAllowedMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+	corsAllowedHeaders = []string{"Content-Type", "Authorization", "Idempotency-Key"}
+)
+
+// SetCORS sets the origins, methods and headers allowed by CORS. An origin of "*" allows any origin.
+// Empty lists keep the defaults.
+func SetCORS(origins, methods, headers []string) {
+	if len(origins) > 0 {
+		corsAllowedOrigins = origins
+	}
+	if len(methods) > 0 {
+		corsAllowedMethods = methods
+	}
+	if len(headers) > 0 {
+		corsAllowedHeaders = headers
+	}
+}
+
+// CORS middleware for handling Cross-Origin Resource Sharing with the settings from SetCORS.
+// Unless any origin is allowed, the request origin is echoed back only when it is in the allowed
+// list; other origins get no CORS headers and their preflight requests are rejected with 403.
 func CORS() gin.HandlerFunc {
+	anyOrigin := false
+	allowed := make(map[string]bool, len(corsAllowedOrigins))
+	for _, origin := range corsAllowedOrigins {
+		if origin == "*" {
+			anyOrigin = true
+		}
+		allowed[strings.ToLower(origin)] = true
+	}
+	methods := strings.Join(corsAllowedMethods, ", ")
+	headers := strings.Join(corsAllowedHeaders, ", ")
+
 	return func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		origin := c.GetHeader("Origin")
+		switch {
+		case anyOrigin:
+			c.Header("Access-Control-Allow-Origin", "*")
+		case origin != "" && allowed[strings.ToLower(origin)]:
+			c.Header("Access-Control-Allow-Origin", origin)
+			c.Header("Vary", "Origin")
+		default:
+			c.Header("Vary", "Origin")
+			if c.Request.Method == http.MethodOptions {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+		c.Header("Access-Control-Allow-Methods", methods)
+		c.Header("Access-Control-Allow-Headers", headers)
 
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
+		if c.Request.Method == http.MethodOptions {
+			c.AbortWithStatus(http.StatusNoContent)
 			return
 		}
 
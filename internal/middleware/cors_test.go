@@ -1,12 +1,24 @@
 package middleware
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
+
+	"loan-service/internal/domain"
+	"loan-service/internal/dto"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
 )
 
 func TestCORS(t *testing.T) {
@@ -24,8 +36,8 @@ func TestCORS(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, w.Code)
 	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
-	assert.Equal(t, "GET, POST, PUT, DELETE, OPTIONS", w.Header().Get("Access-Control-Allow-Methods"))
-	assert.Equal(t, "Content-Type, Authorization", w.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "GET, POST, PUT, PATCH, DELETE, OPTIONS", w.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Content-Type, Authorization, Idempotency-Key", w.Header().Get("Access-Control-Allow-Headers"))
 }
 
 func TestCORSWithOptions(t *testing.T) {
@@ -44,29 +56,137 @@ func TestCORSWithOptions(t *testing.T) {
 	// OPTIONS requests typically return 204 No Content
 	assert.Equal(t, http.StatusNoContent, w.Code)
 	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
-	assert.Equal(t, "GET, POST, PUT, DELETE, OPTIONS", w.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "GET, POST, PUT, PATCH, DELETE, OPTIONS", w.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Content-Type, Authorization, Idempotency-Key", w.Header().Get("Access-Control-Allow-Headers"))
+}
+
+func TestCORSAllowedOrigins(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	origins, methods, headers := corsAllowedOrigins, corsAllowedMethods, corsAllowedHeaders
+	defer func() { corsAllowedOrigins, corsAllowedMethods, corsAllowedHeaders = origins, methods, headers }()
+	SetCORS([]string{"https://app.example.com", "https://admin.example.com"}, []string{"GET", "POST"}, []string{"Content-Type", "Authorization"})
+
+	router := gin.New()
+	router.Use(CORS())
+	router.GET("/test", func(c *gin.Context) {
+		c.JSON(200, gin.H{"message": "test"})
+	})
+
+	send := func(method, origin string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, "/test", nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// An allowed origin is echoed back
+	w := send("GET", "https://admin.example.com")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "https://admin.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "Origin", w.Header().Get("Vary"))
+	assert.Equal(t, "GET, POST", w.Header().Get("Access-Control-Allow-Methods"))
 	assert.Equal(t, "Content-Type, Authorization", w.Header().Get("Access-Control-Allow-Headers"))
+
+	// Other origins are served without CORS headers, so browsers block the response
+	w = send("GET", "https://evil.example.com")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Methods"))
+
+	// Requests without an origin, e.g. from other services, are not affected
+	w = send("GET", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+
+	// Preflight requests
+	w = send("OPTIONS", "https://app.example.com")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "GET, POST", w.Header().Get("Access-Control-Allow-Methods"))
+
+	w = send("OPTIONS", "https://evil.example.com")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
 }
 
 func TestLogging(t *testing.T) {
 	gin.SetMode(gin.TestMode)
+
+	var buf bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+	defer slog.SetDefault(defaultLogger)
+
 	router := gin.New()
+	router.Use(RequestID())
 	router.Use(Logger())
 
 	router.GET("/test", func(c *gin.Context) {
 		c.JSON(200, gin.H{"message": "test"})
 	})
+	router.GET("/fail", func(c *gin.Context) {
+		c.JSON(500, gin.H{"message": "fail"})
+	})
 
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest("GET", "/test", nil)
+	req.Header.Set(RequestIDHeader, "req-123")
 	router.ServeHTTP(w, req)
 
 	assert.Equal(t, http.StatusOK, w.Code)
+
+	var line map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.Equal(t, "req-123", line["request_id"])
+	assert.Equal(t, "GET", line["method"])
+	assert.Equal(t, "/test", line["path"])
+	assert.Equal(t, float64(http.StatusOK), line["status"])
+	assert.Contains(t, line, "latency_ms")
+	assert.Equal(t, "INFO", line["level"])
+
+	// Server errors are logged at error level
+	buf.Reset()
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/fail", nil))
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.Equal(t, "ERROR", line["level"])
+	assert.Equal(t, float64(http.StatusInternalServerError), line["status"])
+}
+
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestID())
+
+	router.GET("/test", func(c *gin.Context) {
+		c.JSON(200, gin.H{"request_id": c.GetString(RequestIDKey)})
+	})
+
+	// A client supplied ID is echoed back and available to the handler
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/test", nil)
+	req.Header.Set(RequestIDHeader, "req-123")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, "req-123", w.Header().Get(RequestIDHeader))
+	assert.JSONEq(t, `{"request_id": "req-123"}`, w.Body.String())
+
+	// Without one an ID is generated
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/test", nil)
+	router.ServeHTTP(w, req)
+
+	generated := w.Header().Get(RequestIDHeader)
+	assert.NotEmpty(t, generated)
+	assert.JSONEq(t, `{"request_id": "`+generated+`"}`, w.Body.String())
 }
 
 func TestRecovery(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(RequestID())
 	router.Use(Recovery())
 
 	router.GET("/panic", func(c *gin.Context) {
@@ -79,4 +199,440 @@ func TestRecovery(t *testing.T) {
 
 	// Recovery middleware should catch the panic and return 500
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	var response dto.ErrorResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+	assert.Equal(t, "Internal server error", response.Error)
+	assert.Equal(t, "test panic", response.Message)
+	assert.Equal(t, dto.CodeInternal, response.Code)
+	assert.NotEmpty(t, response.RequestID)
+	assert.Equal(t, w.Header().Get(RequestIDHeader), response.RequestID)
+}
+
+func TestRecoveryNonStringPanic(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Recovery())
+
+	router.GET("/panic", func(c *gin.Context) {
+		panic(errors.New("boom"))
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/panic", nil)
+	req.Header.Set(RequestIDHeader, "req-123")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	var response dto.ErrorResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+	assert.Equal(t, "boom", response.Message)
+	assert.Equal(t, dto.CodeInternal, response.Code)
+	assert.Equal(t, "req-123", response.RequestID)
+}
+
+func TestAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	SetJWTSecret("test-secret")
+	defer SetJWTSecret("")
+
+	router := gin.New()
+	router.Use(Auth())
+	router.GET("/test", func(c *gin.Context) {
+		c.JSON(200, gin.H{"role": c.GetString(RoleKey), "subject": c.GetString(SubjectKey)})
+	})
+
+	send := func(authorization string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/test", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	valid, err := SignToken(Claims{Subject: "investor_001", Role: RoleInvestor}, []byte("test-secret"))
+	require.NoError(t, err)
+	w := send("Bearer " + valid)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"role": "investor", "subject": "investor_001"}`, w.Body.String())
+
+	expired, _ := SignToken(Claims{Subject: "investor_001", Role: RoleInvestor, ExpiresAt: time.Now().Add(-time.Minute).Unix()}, []byte("test-secret"))
+	forged, _ := SignToken(Claims{Subject: "investor_001", Role: RoleOfficer}, []byte("other-secret"))
+	noRole, _ := SignToken(Claims{Subject: "investor_001"}, []byte("test-secret"))
+	parts := strings.Split(valid, ".")
+	unsigned := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." + parts[1] + "."
+
+	for name, authorization := range map[string]string{
+		"missing":    "",
+		"not bearer": "Basic dXNlcjpwYXNz",
+		"malformed":  "Bearer not-a-token",
+		"expired":    "Bearer " + expired,
+		"forged":     "Bearer " + forged,
+		"no role":    "Bearer " + noRole,
+		"alg none":   "Bearer " + unsigned,
+	} {
+		w := send(authorization)
+		assert.Equal(t, http.StatusUnauthorized, w.Code, name)
+
+		var response dto.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response), name)
+		assert.Equal(t, dto.CodeUnauthorized, response.Code, name)
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	SetJWTSecret("test-secret")
+	defer SetJWTSecret("")
+
+	router := gin.New()
+	router.Use(Auth())
+	router.PUT("/approve", RequireRole(RoleValidator), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	for role, want := range map[string]int{
+		RoleValidator: http.StatusOK,
+		RoleBorrower:  http.StatusForbidden,
+		RoleInvestor:  http.StatusForbidden,
+		RoleOfficer:   http.StatusForbidden,
+	} {
+		token, err := SignToken(Claims{Subject: role + "_001", Role: role}, []byte("test-secret"))
+		require.NoError(t, err)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PUT", "/approve", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, want, w.Code, role)
+	}
+}
+
+func TestAuthDisabledWithoutSecret(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Auth())
+	router.PUT("/approve", RequireRole(RoleValidator), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/approve", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+// memoryIdempotencyRepository keeps idempotent responses in memory
+type memoryIdempotencyRepository struct {
+	responses map[string]domain.IdempotentResponse
+}
+
+func (r *memoryIdempotencyRepository) Claim(response *domain.IdempotentResponse) (bool, error) {
+	if _, ok := r.responses[response.Scope+"|"+response.Key]; ok {
+		return false, nil
+	}
+	r.responses[response.Scope+"|"+response.Key] = *response
+	return true, nil
+}
+
+func (r *memoryIdempotencyRepository) Find(scope, key string) (*domain.IdempotentResponse, error) {
+	response, ok := r.responses[scope+"|"+key]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &response, nil
+}
+
+func (r *memoryIdempotencyRepository) Complete(response *domain.IdempotentResponse) error {
+	stored := r.responses[response.Scope+"|"+response.Key]
+	stored.StatusCode = response.StatusCode
+	stored.Body = response.Body
+	r.responses[response.Scope+"|"+response.Key] = stored
+	return nil
+}
+
+func (r *memoryIdempotencyRepository) Release(scope, key string) error {
+	delete(r.responses, scope+"|"+key)
+	return nil
+}
+
+func TestIdempotency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := &memoryIdempotencyRepository{responses: map[string]domain.IdempotentResponse{}}
+
+	calls := 0
+	status := http.StatusCreated
+	router := gin.New()
+	router.Use(Idempotency(repo))
+	router.PUT("/loans/:id/invest", func(c *gin.Context) {
+		calls++
+		c.JSON(status, gin.H{"call": calls})
+	})
+
+	send := func(path, key string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PUT", path, nil)
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send("/loans/1/invest", "key-1")
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, `{"call": 1}`, w.Body.String())
+
+	// The stored response is replayed
+	w = send("/loans/1/invest", "key-1")
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "true", w.Header().Get(IdempotentReplayedHeader))
+	assert.JSONEq(t, `{"call": 1}`, w.Body.String())
+	assert.Equal(t, 1, calls)
+
+	// Requests without a key are not deduplicated
+	send("/loans/1/invest", "")
+	assert.Equal(t, 2, calls)
+
+	// Server errors release the key so the request can be retried
+	status = http.StatusInternalServerError
+	w = send("/loans/2/invest", "key-1")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	status = http.StatusCreated
+	w = send("/loans/2/invest", "key-1")
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Empty(t, w.Header().Get(IdempotentReplayedHeader))
+	assert.Equal(t, 4, calls)
+
+	// A request still holding the key is not run twice
+	repo.responses["PUT /loans/3/invest|key-1"] = domain.IdempotentResponse{Scope: "PUT /loans/3/invest", Key: "key-1", ExpiresAt: time.Now().Add(time.Hour)}
+	w = send("/loans/3/invest", "key-1")
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, 4, calls)
+
+	// Expired keys are claimed again
+	repo.responses["PUT /loans/4/invest|key-1"] = domain.IdempotentResponse{Scope: "PUT /loans/4/invest", Key: "key-1", StatusCode: http.StatusCreated, Body: []byte(`{}`), ExpiresAt: time.Now().Add(-time.Minute)}
+	w = send("/loans/4/invest", "key-1")
+	assert.JSONEq(t, `{"call": 5}`, w.Body.String())
+
+	w = send("/loans/1/invest", strings.Repeat("k", MaxIdempotencyKeyLength+1))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, 5, calls)
+}
+
+func TestIdempotencyReleasesKeyOnRetryableStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := &memoryIdempotencyRepository{responses: map[string]domain.IdempotentResponse{}}
+
+	calls := 0
+	status := http.StatusConflict
+	router := gin.New()
+	router.Use(Idempotency(repo))
+	router.PUT("/loans/:id/invest", func(c *gin.Context) {
+		calls++
+		c.JSON(status, gin.H{"call": calls})
+	})
+
+	send := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PUT", "/loans/1/invest", nil)
+		req.Header.Set(IdempotencyKeyHeader, "key-1")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Conflicts and rate limiting are retried rather than replayed
+	for _, retryable := range []int{http.StatusConflict, http.StatusTooManyRequests} {
+		status = retryable
+		w := send()
+		assert.Equal(t, retryable, w.Code)
+		assert.Empty(t, repo.responses)
+	}
+
+	// Other client errors are deterministic and replayed
+	status = http.StatusBadRequest
+	send()
+	status = http.StatusCreated
+	w := send()
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "true", w.Header().Get(IdempotentReplayedHeader))
+	assert.Equal(t, 3, calls)
+}
+
+func TestIdempotencyScopedToCaller(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := &memoryIdempotencyRepository{responses: map[string]domain.IdempotentResponse{}}
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(SubjectKey, c.GetHeader("X-Subject"))
+	})
+	router.Use(Idempotency(repo))
+	router.PUT("/loans/:id/invest", func(c *gin.Context) {
+		c.JSON(http.StatusCreated, gin.H{"subject": c.GetString(SubjectKey)})
+	})
+
+	send := func(subject string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PUT", "/loans/1/invest", nil)
+		req.Header.Set(IdempotencyKeyHeader, "key-1")
+		req.Header.Set("X-Subject", subject)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	send("investor_001")
+	w := send("investor_002")
+	assert.Empty(t, w.Header().Get(IdempotentReplayedHeader))
+	assert.JSONEq(t, `{"subject": "investor_002"}`, w.Body.String())
+
+	w = send("investor_001")
+	assert.Equal(t, "true", w.Header().Get(IdempotentReplayedHeader))
+	assert.JSONEq(t, `{"subject": "investor_001"}`, w.Body.String())
+	assert.Contains(t, repo.responses, "investor_001 PUT /loans/1/invest|key-1")
+}
+
+func TestIdempotencyReleasesKeyOnPanic(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := &memoryIdempotencyRepository{responses: map[string]domain.IdempotentResponse{}}
+
+	calls := 0
+	router := gin.New()
+	router.Use(Recovery())
+	router.Use(Idempotency(repo))
+	router.PUT("/loans/:id/invest", func(c *gin.Context) {
+		calls++
+		if calls == 1 {
+			panic("investment handler crashed")
+		}
+		c.JSON(http.StatusCreated, gin.H{"call": calls})
+	})
+
+	send := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PUT", "/loans/1/invest", nil)
+		req.Header.Set(IdempotencyKeyHeader, "key-1")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send()
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Empty(t, repo.responses)
+
+	// The retry runs instead of finding the key still in progress
+	w = send()
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, `{"call": 2}`, w.Body.String())
+}
+
+func TestRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RateLimit(1, 3))
+	router.POST("/test", func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+
+	send := func(remoteAddr string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/test", nil)
+		req.RemoteAddr = remoteAddr
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	limited := 0
+	for i := 0; i < 10; i++ {
+		w := send("10.0.0.1:1234")
+		if w.Code == http.StatusTooManyRequests {
+			limited++
+			assert.Equal(t, "1", w.Header().Get("Retry-After"))
+
+			var response dto.ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, dto.CodeRateLimited, response.Code)
+		}
+	}
+	assert.Equal(t, 7, limited)
+
+	// Other clients have their own limit
+	assert.Equal(t, http.StatusCreated, send("10.0.0.2:1234").Code)
+}
+
+func TestRateLimitForwardedFor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	newRouter := func(trustedProxies []string) *gin.Engine {
+		router := gin.New()
+		require.NoError(t, router.SetTrustedProxies(trustedProxies))
+		router.Use(RateLimit(1, 1))
+		router.POST("/test", func(c *gin.Context) {
+			c.Status(http.StatusCreated)
+		})
+		return router
+	}
+	send := func(router *gin.Engine, forwardedFor string) int {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/test", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// Without trusted proxies a client cannot escape its limit by forging the header
+	router := newRouter(nil)
+	assert.Equal(t, http.StatusCreated, send(router, "192.0.2.1"))
+	assert.Equal(t, http.StatusTooManyRequests, send(router, "192.0.2.2"))
+
+	// Behind a trusted proxy every forwarded client has its own limit
+	router = newRouter([]string{"10.0.0.0/8"})
+	assert.Equal(t, http.StatusCreated, send(router, "192.0.2.1"))
+	assert.Equal(t, http.StatusCreated, send(router, "192.0.2.2"))
+	assert.Equal(t, http.StatusTooManyRequests, send(router, "192.0.2.1"))
+}
+
+func TestRateLimitDisabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RateLimit(0, 0))
+	router.POST("/test", func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+
+	for i := 0; i < 10; i++ {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/test", nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusCreated, w.Code)
+	}
+}
+
+func TestIPRateLimiterRefillAndCleanup(t *testing.T) {
+	limiter := newIPRateLimiter(2, 2)
+	start := time.Now()
+
+	allowed, _ := limiter.allow("10.0.0.1", start)
+	assert.True(t, allowed)
+	allowed, _ = limiter.allow("10.0.0.1", start)
+	assert.True(t, allowed)
+	allowed, retryAfter := limiter.allow("10.0.0.1", start)
+	assert.False(t, allowed)
+	assert.Equal(t, 500*time.Millisecond, retryAfter)
+
+	// Tokens are refilled at the configured rate
+	allowed, _ = limiter.allow("10.0.0.1", start.Add(500*time.Millisecond))
+	assert.True(t, allowed)
+
+	// Buckets of idle clients are dropped once they are full again
+	limiter.allow("10.0.0.2", start.Add(2*time.Minute))
+	assert.Len(t, limiter.buckets, 1)
+	assert.Contains(t, limiter.buckets, "10.0.0.2")
 }
@@ -0,0 +1,165 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"loan-service/internal/domain"
+	"loan-service/internal/dto"
+	"loan-service/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Headers used for idempotent requests, and the longest key accepted
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	MaxIdempotencyKeyLength  = 255
+)
+
+// idempotencyKeyTTL is how long stored responses are replayed
+var idempotencyKeyTTL = 24 * time.Hour
+
+// SetIdempotencyKeyTTL sets how long responses of idempotent requests are replayed. Non-positive values keep the default.
+func SetIdempotencyKeyTTL(ttl time.Duration) {
+	if ttl > 0 {
+		idempotencyKeyTTL = ttl
+	}
+}
+
+// Idempotency middleware replays the stored response when a request is repeated with the same
+// Idempotency-Key header. Keys are scoped to the caller's token subject, the method and the path, so
+// the same key can be used on different loans and endpoints and by different callers. Requests
+// without the header are not deduplicated. Responses that may succeed when retried are not stored.
+func Idempotency(repo repository.IdempotencyRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > MaxIdempotencyKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "Validation error",
+				Message: fmt.Sprintf("%s must be at most %d characters", IdempotencyKeyHeader, MaxIdempotencyKeyLength),
+			})
+			return
+		}
+
+		scope := c.Request.Method + " " + c.Request.URL.Path
+		if subject := c.GetString(SubjectKey); subject != "" {
+			scope = subject + " " + scope
+		}
+		stored, err := claimIdempotencyKey(repo, scope, key)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{
+				Error:   "Database error",
+				Message: err.Error(),
+			})
+			return
+		}
+
+		if stored != nil {
+			if !stored.IsComplete() {
+				c.AbortWithStatusJSON(http.StatusConflict, dto.ErrorResponse{
+					Error:   "Conflict",
+					Message: "A request with this idempotency key is still in progress",
+					Code:    dto.CodeConcurrentUpdate,
+				})
+				return
+			}
+			c.Header(IdempotentReplayedHeader, "true")
+			c.Data(stored.StatusCode, "application/json; charset=utf-8", stored.Body)
+			c.Abort()
+			return
+		}
+
+		// Requests that failed for a transient reason release the key so the client can retry them. The
+		// release is deferred so that it also happens when the handler panics.
+		completed := false
+		defer func() {
+			if completed {
+				return
+			}
+			if err := repo.Release(scope, key); err != nil {
+				slog.Error("Failed to release idempotency key", "request_id", requestIDFromContext(c), "error", err)
+			}
+		}()
+
+		writer := &bodyRecorder{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+
+		if isRetryableStatus(writer.Status()) {
+			return
+		}
+
+		completed = true
+		err = repo.Complete(&domain.IdempotentResponse{
+			Scope:      scope,
+			Key:        key,
+			StatusCode: writer.Status(),
+			Body:       writer.body.Bytes(),
+		})
+		if err != nil {
+			slog.Error("Failed to store idempotent response", "request_id", requestIDFromContext(c), "error", err)
+		}
+	}
+}
+
+// isRetryableStatus reports whether a response may turn out differently when the request is retried:
+// server errors, conflicts such as concurrent updates, and rate limiting
+func isRetryableStatus(status int) bool {
+	return status >= http.StatusInternalServerError || status == http.StatusConflict || status == http.StatusTooManyRequests
+}
+
+// claimIdempotencyKey claims a key for the current request. It returns the stored response when
+// another request already holds the key, replacing keys that have expired.
+func claimIdempotencyKey(repo repository.IdempotencyRepository, scope, key string) (*domain.IdempotentResponse, error) {
+	for {
+		now := time.Now()
+		claimed, err := repo.Claim(&domain.IdempotentResponse{Scope: scope, Key: key, ExpiresAt: now.Add(idempotencyKeyTTL)})
+		if err != nil || claimed {
+			return nil, err
+		}
+
+		stored, err := repo.Find(scope, key)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// The holder released the key in the meantime
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !stored.IsExpired(now) {
+			return stored, nil
+		}
+		if err := repo.Release(scope, key); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// bodyRecorder copies the response body while it is written to the client
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+// Write writes the data to the client and records it
+func (w *bodyRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString writes the string to the client and records it
+func (w *bodyRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"loan-service/internal/dto"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/time/rate"
+)
+
+// rateLimitCleanupInterval is how often buckets of idle clients are dropped
+const rateLimitCleanupInterval = time.Minute
+
+// writeRateLimitRPS and writeRateLimitBurst limit the write endpoints per client IP. The client IP is
+// the address of the connection unless it comes from one of the router's trusted proxies.
+var (
+	writeRateLimitRPS   int
+	writeRateLimitBurst int
+)
+
+// SetRateLimit sets the per client IP limit of the write endpoints. Non-positive rps disables the limit.
+func SetRateLimit(rps, burst int) {
+	writeRateLimitRPS = rps
+	writeRateLimitBurst = burst
+}
+
+// WriteRateLimit returns the RateLimit middleware configured with SetRateLimit
+func WriteRateLimit() gin.HandlerFunc {
+	return RateLimit(writeRateLimitRPS, writeRateLimitBurst)
+}
+
+// RateLimit middleware allows each client IP rps requests per second on average, with bursts of up
+// to burst requests. Requests over the limit are rejected with 429 and a Retry-After header.
+// Non-positive rps disables the limit.
+func RateLimit(rps, burst int) gin.HandlerFunc {
+	if rps <= 0 {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	limiter := newIPRateLimiter(rps, burst)
+	return func(c *gin.Context) {
+		allowed, retryAfter := limiter.allow(c.ClientIP(), time.Now())
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, dto.ErrorResponse{
+				Error:   "Too many requests",
+				Message: "Rate limit exceeded, retry later",
+				Code:    dto.CodeRateLimited,
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// ipRateLimiter keeps a token bucket per client IP
+type ipRateLimiter struct {
+	mu          sync.Mutex
+	rate        rate.Limit
+	burst       int
+	buckets     map[string]*rate.Limiter
+	lastCleanup time.Time
+}
+
+func newIPRateLimiter(rps, burst int) *ipRateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &ipRateLimiter{
+		rate:    rate.Limit(rps),
+		burst:   burst,
+		buckets: make(map[string]*rate.Limiter),
+	}
+}
+
+// allow takes a token from the bucket of the given IP. When none is left it returns how long
+// until the next token is available.
+func (l *ipRateLimiter) allow(ip string, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.cleanup(now)
+
+	bucket, ok := l.buckets[ip]
+	if !ok {
+		bucket = rate.NewLimiter(l.rate, l.burst)
+		l.buckets[ip] = bucket
+	}
+
+	if !bucket.AllowN(now, 1) {
+		return false, time.Duration((1 - bucket.TokensAt(now)) / float64(l.rate) * float64(time.Second))
+	}
+	return true, 0
+}
+
+// cleanup periodically drops full buckets, which behave the same as a client that was never seen
+func (l *ipRateLimiter) cleanup(now time.Time) {
+	if now.Sub(l.lastCleanup) < rateLimitCleanupInterval {
+		return
+	}
+	l.lastCleanup = now
+
+	for ip, bucket := range l.buckets {
+		if bucket.TokensAt(now) >= float64(l.burst) {
+			delete(l.buckets, ip)
+		}
+	}
+}
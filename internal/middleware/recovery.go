@@ -1,20 +1,48 @@
 package middleware
 
 import (
+	"fmt"
+	"log/slog"
 	"net/http"
+	"runtime/debug"
+
+	"loan-service/internal/dto"
 
 	"github.com/gin-gonic/gin"
 )
 
 // Recovery middleware for handling panics
 func Recovery() gin.HandlerFunc {
-	return gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
-		if err, ok := recovered.(string); ok {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error":   "Internal server error",
-				"message": err,
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+
+			requestID := requestIDFromContext(c)
+			slog.Error("Panic recovered", "request_id", requestID, "panic", panicMessage(recovered), "stack", string(debug.Stack()))
+
+			c.AbortWithStatusJSON(http.StatusInternalServerError, dto.ErrorResponse{
+				Error:     "Internal server error",
+				Message:   panicMessage(recovered),
+				Code:      dto.CodeInternal,
+				RequestID: requestID,
 			})
-		}
-		c.AbortWithStatus(http.StatusInternalServerError)
-	})
+		}()
+
+		c.Next()
+	}
+}
+
+// panicMessage converts a recovered value into a human readable message
+func panicMessage(recovered interface{}) string {
+	switch v := recovered.(type) {
+	case string:
+		return v
+	case error:
+		return v.Error()
+	default:
+		return fmt.Sprintf("%v", v)
+	}
 }
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader is the header used to correlate a request across logs and responses
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDKey is the gin context key holding the ID of the current request
+const RequestIDKey = "request_id"
+
+// RequestID middleware takes the request ID supplied by the client in X-Request-ID, or generates one,
+// and echoes it in the response. The ID is stored in the gin context so log lines written while serving
+// the request can be correlated.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if requestID == "" {
+			requestID = uuid.New().String()
+		}
+
+		c.Set(RequestIDKey, requestID)
+		c.Header(RequestIDHeader, requestID)
+
+		c.Next()
+	}
+}
+
+// requestIDFromContext returns the ID assigned by RequestID, falling back to the client's header or a
+// new ID when the middleware is not installed
+func requestIDFromContext(c *gin.Context) string {
+	if requestID := c.GetString(RequestIDKey); requestID != "" {
+		return requestID
+	}
+	if requestID := c.GetHeader(RequestIDHeader); requestID != "" {
+		return requestID
+	}
+	return uuid.New().String()
+}
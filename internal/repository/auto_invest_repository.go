@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"loan-service/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// AutoInvestRepository defines the interface for auto-invest rule data operations
+type AutoInvestRepository interface {
+	CreateRule(rule *domain.AutoInvestRule) error
+	FindRuleByID(id string) (*domain.AutoInvestRule, error)
+	FindRulesByInvestor(investorID string) ([]domain.AutoInvestRule, error)
+	FindActiveRules() ([]domain.AutoInvestRule, error)
+	UpdateRule(ruleID string, updates map[string]interface{}) (bool, error)
+	DeleteRule(id string) error
+	ReserveBudget(ruleID string, amount float64) (bool, error)
+	ReleaseBudget(ruleID string, amount float64) error
+	CreateAction(action *domain.AutoInvestAction) error
+	FindActionsByInvestor(investorID string) ([]domain.AutoInvestAction, error)
+}
+
+// autoInvestRepository implements AutoInvestRepository
+type autoInvestRepository struct {
+	db *gorm.DB
+}
+
+// NewAutoInvestRepository creates a new auto-invest repository
+func NewAutoInvestRepository(db *gorm.DB) AutoInvestRepository {
+	return &autoInvestRepository{db: db}
+}
+
+// CreateRule creates a new auto-invest rule
+func (r *autoInvestRepository) CreateRule(rule *domain.AutoInvestRule) error {
+	return r.db.Create(rule).Error
+}
+
+// FindRuleByID finds an auto-invest rule by ID
+func (r *autoInvestRepository) FindRuleByID(id string) (*domain.AutoInvestRule, error) {
+	var rule domain.AutoInvestRule
+	err := r.db.First(&rule, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// FindRulesByInvestor finds all auto-invest rules of an investor
+func (r *autoInvestRepository) FindRulesByInvestor(investorID string) ([]domain.AutoInvestRule, error) {
+	var rules []domain.AutoInvestRule
+	err := r.db.Where("investor_id = ?", investorID).Order("created_at").Find(&rules).Error
+	return rules, err
+}
+
+// FindActiveRules finds all active rules with budget left, oldest first
+func (r *autoInvestRepository) FindActiveRules() ([]domain.AutoInvestRule, error) {
+	var rules []domain.AutoInvestRule
+	err := r.db.Where("active = ? AND remaining_budget > 0", true).Order("created_at").Find(&rules).Error
+	return rules, err
+}
+
+// UpdateRule updates only the given columns of a rule, so it never overwrites a budget reserved concurrently.
+// A new total_budget moves the remaining budget by the same difference in the same statement, and is only
+// applied while it covers what the rule has already spent; otherwise nothing is updated and false is returned.
+func (r *autoInvestRepository) UpdateRule(ruleID string, updates map[string]interface{}) (bool, error) {
+	query := r.db.Model(&domain.AutoInvestRule{}).Where("id = ?", ruleID)
+
+	columns := make(map[string]interface{}, len(updates)+1)
+	for column, value := range updates {
+		columns[column] = value
+	}
+	if totalBudget, ok := updates["total_budget"]; ok {
+		// GORM assigns map columns in name order, so remaining_budget still reads the old total_budget
+		// even on MySQL, which evaluates assignments left to right
+		columns["remaining_budget"] = gorm.Expr("remaining_budget + (? - total_budget)", totalBudget)
+		query = query.Where("total_budget - remaining_budget <= ?", totalBudget)
+	}
+
+	result := query.Updates(columns)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// DeleteRule deletes an auto-invest rule
+func (r *autoInvestRepository) DeleteRule(id string) error {
+	return r.db.Delete(&domain.AutoInvestRule{}, "id = ?", id).Error
+}
+
+// ReserveBudget atomically decrements a rule's remaining budget.
+// It returns false without changing anything when the remaining budget is insufficient.
+func (r *autoInvestRepository) ReserveBudget(ruleID string, amount float64) (bool, error) {
+	result := r.db.Model(&domain.AutoInvestRule{}).
+		Where("id = ? AND remaining_budget >= ?", ruleID, amount).
+		Update("remaining_budget", gorm.Expr("remaining_budget - ?", amount))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// ReleaseBudget returns a previously reserved amount to a rule's remaining budget
+func (r *autoInvestRepository) ReleaseBudget(ruleID string, amount float64) error {
+	return r.db.Model(&domain.AutoInvestRule{}).
+		Where("id = ?", ruleID).
+		Update("remaining_budget", gorm.Expr("remaining_budget + ?", amount)).Error
+}
+
+// CreateAction records an auto-invest action
+func (r *autoInvestRepository) CreateAction(action *domain.AutoInvestAction) error {
+	return r.db.Create(action).Error
+}
+
+// FindActionsByInvestor finds all auto-invest actions of an investor, newest first
+func (r *autoInvestRepository) FindActionsByInvestor(investorID string) ([]domain.AutoInvestAction, error) {
+	var actions []domain.AutoInvestAction
+	err := r.db.Where("investor_id = ?", investorID).Order("created_at DESC").Find(&actions).Error
+	return actions, err
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"testing"
+
+	"loan-service/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutoInvestReserveBudget(t *testing.T) {
+	_, db := setupTestRepository()
+	repo := NewAutoInvestRepository(db)
+
+	rule := &domain.AutoInvestRule{
+		InvestorID:      "investor_001",
+		MaxPerLoan:      5000.00,
+		TotalBudget:     8000.00,
+		RemainingBudget: 8000.00,
+		Active:          true,
+	}
+	require.NoError(t, repo.CreateRule(rule))
+
+	reserved, err := repo.ReserveBudget(rule.ID, 5000.00)
+	require.NoError(t, err)
+	assert.True(t, reserved)
+
+	// Not enough budget left, nothing is reserved
+	reserved, err = repo.ReserveBudget(rule.ID, 5000.00)
+	require.NoError(t, err)
+	assert.False(t, reserved)
+
+	stored, err := repo.FindRuleByID(rule.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 3000.00, stored.RemainingBudget)
+
+	require.NoError(t, repo.ReleaseBudget(rule.ID, 5000.00))
+	stored, err = repo.FindRuleByID(rule.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 8000.00, stored.RemainingBudget)
+}
+
+func TestAutoInvestUpdateRule(t *testing.T) {
+	_, db := setupTestRepository()
+	repo := NewAutoInvestRepository(db)
+
+	rule := &domain.AutoInvestRule{
+		InvestorID:      "investor_001",
+		MaxPerLoan:      5000.00,
+		TotalBudget:     10000.00,
+		RemainingBudget: 10000.00,
+		Active:          true,
+	}
+	require.NoError(t, repo.CreateRule(rule))
+
+	// A reservation landing after the rule was read is kept by updates of other fields
+	reserved, err := repo.ReserveBudget(rule.ID, 3000.00)
+	require.NoError(t, err)
+	require.True(t, reserved)
+
+	updated, err := repo.UpdateRule(rule.ID, map[string]interface{}{"min_roi": 5.0})
+	require.NoError(t, err)
+	assert.True(t, updated)
+
+	stored, err := repo.FindRuleByID(rule.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 5.0, stored.MinROI)
+	assert.Equal(t, 7000.00, stored.RemainingBudget)
+
+	// A new total moves the remaining budget by the difference
+	updated, err = repo.UpdateRule(rule.ID, map[string]interface{}{"total_budget": 20000.00})
+	require.NoError(t, err)
+	assert.True(t, updated)
+
+	stored, err = repo.FindRuleByID(rule.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 20000.00, stored.TotalBudget)
+	assert.Equal(t, 17000.00, stored.RemainingBudget)
+
+	// A total below the 3000 spent is not applied, nor are the other fields
+	updated, err = repo.UpdateRule(rule.ID, map[string]interface{}{"total_budget": 2000.00, "active": false})
+	require.NoError(t, err)
+	assert.False(t, updated)
+
+	stored, err = repo.FindRuleByID(rule.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 20000.00, stored.TotalBudget)
+	assert.Equal(t, 17000.00, stored.RemainingBudget)
+	assert.True(t, stored.Active)
+
+	// Lowering the total to exactly what was spent leaves no budget
+	updated, err = repo.UpdateRule(rule.ID, map[string]interface{}{"total_budget": 3000.00})
+	require.NoError(t, err)
+	assert.True(t, updated)
+
+	stored, err = repo.FindRuleByID(rule.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, stored.RemainingBudget)
+}
+
+func TestAutoInvestFindActiveRules(t *testing.T) {
+	_, db := setupTestRepository()
+	repo := NewAutoInvestRepository(db)
+
+	active := &domain.AutoInvestRule{InvestorID: "investor_001", MaxPerLoan: 1000, TotalBudget: 1000, RemainingBudget: 1000, Active: true}
+	exhausted := &domain.AutoInvestRule{InvestorID: "investor_002", MaxPerLoan: 1000, TotalBudget: 1000, RemainingBudget: 0, Active: true}
+	inactive := &domain.AutoInvestRule{InvestorID: "investor_003", MaxPerLoan: 1000, TotalBudget: 1000, RemainingBudget: 1000, Active: true}
+	require.NoError(t, repo.CreateRule(active))
+	require.NoError(t, repo.CreateRule(exhausted))
+	require.NoError(t, repo.CreateRule(inactive))
+	require.NoError(t, db.Model(inactive).Update("active", false).Error)
+
+	rules, err := repo.FindActiveRules()
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	assert.Equal(t, active.ID, rules[0].ID)
+}
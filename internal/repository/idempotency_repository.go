@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"errors"
+
+	"loan-service/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IdempotencyRepository defines the interface for stored idempotent responses
+type IdempotencyRepository interface {
+	Claim(response *domain.IdempotentResponse) (bool, error)
+	Find(scope, key string) (*domain.IdempotentResponse, error)
+	Complete(response *domain.IdempotentResponse) error
+	Release(scope, key string) error
+}
+
+// idempotencyRepository implements IdempotencyRepository
+type idempotencyRepository struct {
+	db *gorm.DB
+}
+
+// NewIdempotencyRepository creates a new idempotency repository
+func NewIdempotencyRepository(db *gorm.DB) IdempotencyRepository {
+	return &idempotencyRepository{db: db}
+}
+
+// Claim records a key for a request that is about to run. It returns false when the key is already
+// taken in its scope, so only one of several concurrent requests with the same key proceeds.
+func (r *idempotencyRepository) Claim(response *domain.IdempotentResponse) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(response)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Find finds the stored response for a key in the given scope
+func (r *idempotencyRepository) Find(scope, key string) (*domain.IdempotentResponse, error) {
+	var response domain.IdempotentResponse
+	err := r.db.First(&response, keyConditions(scope, key)).Error
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// Complete stores the response of a claimed key
+func (r *idempotencyRepository) Complete(response *domain.IdempotentResponse) error {
+	result := r.db.Model(&domain.IdempotentResponse{}).
+		Where(keyConditions(response.Scope, response.Key)).
+		Updates(map[string]interface{}{"status_code": response.StatusCode, "body": response.Body})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("idempotency key is no longer claimed")
+	}
+	return nil
+}
+
+// Release deletes a key so the request can be made again
+func (r *idempotencyRepository) Release(scope, key string) error {
+	return r.db.Where(keyConditions(scope, key)).Delete(&domain.IdempotentResponse{}).Error
+}
+
+// keyConditions selects a key in its scope. The conditions are given as a map so that GORM quotes the
+// key column, a reserved word in MySQL.
+func keyConditions(scope, key string) map[string]interface{} {
+	return map[string]interface{}{"scope": scope, "key": key}
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"loan-service/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestIdempotencyRepository(t *testing.T) {
+	_, db := setupTestRepository()
+	repo := NewIdempotencyRepository(db)
+
+	claim := &domain.IdempotentResponse{Scope: "PUT /loans/1/invest", Key: "key-1", ExpiresAt: time.Now().Add(time.Hour)}
+	claimed, err := repo.Claim(claim)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	// A second request with the same key does not get it
+	claimed, err = repo.Claim(&domain.IdempotentResponse{Scope: "PUT /loans/1/invest", Key: "key-1", ExpiresAt: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	assert.False(t, claimed)
+
+	// key is a reserved word in MySQL and must be quoted
+	statements := recordSQL(t, db)
+
+	require.NoError(t, repo.Complete(&domain.IdempotentResponse{Scope: "PUT /loans/1/invest", Key: "key-1", StatusCode: http.StatusOK, Body: []byte(`{}`)}))
+	stored, err := repo.Find("PUT /loans/1/invest", "key-1")
+	require.NoError(t, err)
+	assert.True(t, stored.IsComplete())
+	assert.Equal(t, []byte(`{}`), stored.Body)
+
+	require.NoError(t, repo.Release("PUT /loans/1/invest", "key-1"))
+	_, err = repo.Find("PUT /loans/1/invest", "key-1")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	require.Len(t, *statements, 4)
+	for _, statement := range *statements {
+		assert.Contains(t, statement, "`key` = ")
+	}
+
+	// Completing a released key fails
+	err = repo.Complete(&domain.IdempotentResponse{Scope: "PUT /loans/1/invest", Key: "key-1", StatusCode: http.StatusOK})
+	assert.Error(t, err)
+}
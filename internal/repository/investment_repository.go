@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"loan-service/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// InvestmentRepository defines the interface for querying investments across loans
+type InvestmentRepository interface {
+	FindByInvestor(investorID string) ([]domain.InvestorInvestment, error)
+	FindByLoan(loanID, investorID string, offset, limit int) ([]domain.Investment, int64, error)
+	ContributionsByLoan(loanID string) ([]domain.InvestorContribution, error)
+}
+
+// investmentRepository implements InvestmentRepository
+type investmentRepository struct {
+	db *gorm.DB
+}
+
+// NewInvestmentRepository creates a new investment repository
+func NewInvestmentRepository(db *gorm.DB) InvestmentRepository {
+	return &investmentRepository{db: db}
+}
+
+// FindByInvestor finds an investor's investments in loans that are not deleted, oldest first,
+// together with the status, ROI and term of each loan
+func (r *investmentRepository) FindByInvestor(investorID string) ([]domain.InvestorInvestment, error) {
+	investments := []domain.InvestorInvestment{}
+	err := r.db.Model(&domain.Investment{}).
+		Select("investments.*, loans.status AS loan_status, loans.roi AS roi, loans.term_months AS term_months").
+		Joins("JOIN loans ON loans.id = investments.loan_id AND loans.deleted_at IS NULL").
+		Where("investments.investor_id = ?", investorID).
+		Order("investments.created_at ASC").
+		Scan(&investments).Error
+	return investments, err
+}
+
+// FindByLoan finds one page of a loan's investments, oldest first, along with the number of investments
+// matching in total. A non-empty investorID only finds that investor's investments.
+func (r *investmentRepository) FindByLoan(loanID, investorID string, offset, limit int) ([]domain.Investment, int64, error) {
+	query := r.db.Model(&domain.Investment{}).Where("loan_id = ?", loanID)
+	if investorID != "" {
+		query = query.Where("investor_id = ?", investorID)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	investments := []domain.Investment{}
+	err := query.Order("created_at ASC").Order("id ASC").Offset(offset).Limit(limit).Find(&investments).Error
+	return investments, total, err
+}
+
+// ContributionsByLoan sums a loan's investments per investor, in order of each investor's first
+// investment. Percentages are left for the caller, which knows the loan's principal.
+func (r *investmentRepository) ContributionsByLoan(loanID string) ([]domain.InvestorContribution, error) {
+	contributions := []domain.InvestorContribution{}
+	err := r.db.Model(&domain.Investment{}).
+		Select("investor_id, SUM(amount) AS amount, COUNT(*) AS investments").
+		Where("loan_id = ?", loanID).
+		Group("investor_id").
+		Order("MIN(created_at) ASC").Order("investor_id ASC").
+		Scan(&contributions).Error
+	return contributions, err
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"loan-service/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindInvestmentsByInvestor(t *testing.T) {
+	loans, db := setupTestRepository()
+	repo := NewInvestmentRepository(db)
+
+	first := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(10000.00), Rate: 4.5, ROI: 6.0, Status: domain.StatusApproved}
+	second := &domain.Loan{BorrowerID: "user456", PrincipalAmount: domain.NewMoney(20000.00), Rate: 5.0, ROI: 8.0, Status: domain.StatusInvested}
+	require.NoError(t, loans.Create(first))
+	require.NoError(t, loans.Create(second))
+
+	require.NoError(t, db.Create(&domain.Investment{LoanID: first.ID, InvestorID: "investor_001", Amount: domain.NewMoney(4000.00)}).Error)
+	require.NoError(t, db.Create(&domain.Investment{LoanID: second.ID, InvestorID: "investor_001", Amount: domain.NewMoney(5000.00)}).Error)
+	require.NoError(t, db.Create(&domain.Investment{LoanID: second.ID, InvestorID: "investor_002", Amount: domain.NewMoney(15000.00)}).Error)
+
+	investments, err := repo.FindByInvestor("investor_001")
+	require.NoError(t, err)
+	require.Len(t, investments, 2)
+	assert.Equal(t, first.ID, investments[0].LoanID)
+	assert.Equal(t, domain.NewMoney(4000.00), investments[0].Amount)
+	assert.Equal(t, domain.StatusApproved, investments[0].LoanStatus)
+	assert.Equal(t, 6.0, investments[0].ROI)
+	assert.Equal(t, domain.StatusInvested, investments[1].LoanStatus)
+	assert.Equal(t, 8.0, investments[1].ROI)
+
+	// Investments in deleted loans are left out
+	require.NoError(t, loans.Delete(first.ID))
+	investments, err = repo.FindByInvestor("investor_001")
+	require.NoError(t, err)
+	require.Len(t, investments, 1)
+	assert.Equal(t, second.ID, investments[0].LoanID)
+
+	investments, err = repo.FindByInvestor("investor_999")
+	require.NoError(t, err)
+	assert.Empty(t, investments)
+}
+
+func TestFindInvestmentsByLoan(t *testing.T) {
+	loans, db := setupTestRepository()
+	repo := NewInvestmentRepository(db)
+
+	loan := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(100000.00), Rate: 4.5, ROI: 6.0, Status: domain.StatusApproved}
+	other := &domain.Loan{BorrowerID: "user456", PrincipalAmount: domain.NewMoney(10000.00), Rate: 4.5, ROI: 6.0, Status: domain.StatusApproved}
+	require.NoError(t, loans.Create(loan))
+	require.NoError(t, loans.Create(other))
+
+	// 25 investments with increasing amounts, every fifth one by investor_000
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	for i := 0; i < 25; i++ {
+		require.NoError(t, db.Create(&domain.Investment{
+			LoanID:     loan.ID,
+			InvestorID: fmt.Sprintf("investor_%03d", i%5),
+			Amount:     domain.NewMoney(float64(100 * (i + 1))),
+			CreatedAt:  start.Add(time.Duration(i) * time.Minute),
+		}).Error)
+	}
+	require.NoError(t, db.Create(&domain.Investment{LoanID: other.ID, InvestorID: "investor_000", Amount: domain.NewMoney(500.00)}).Error)
+
+	page, total, err := repo.FindByLoan(loan.ID, "", 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(25), total)
+	require.Len(t, page, 10)
+	assert.Equal(t, domain.NewMoney(100.00), page[0].Amount)
+	assert.Equal(t, domain.NewMoney(1000.00), page[9].Amount)
+
+	page, total, err = repo.FindByLoan(loan.ID, "", 20, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(25), total)
+	require.Len(t, page, 5)
+	assert.Equal(t, domain.NewMoney(2100.00), page[0].Amount)
+	assert.Equal(t, domain.NewMoney(2500.00), page[4].Amount)
+
+	page, total, err = repo.FindByLoan(loan.ID, "investor_000", 2, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), total)
+	require.Len(t, page, 2)
+	for _, investment := range page {
+		assert.Equal(t, loan.ID, investment.LoanID)
+		assert.Equal(t, "investor_000", investment.InvestorID)
+	}
+	assert.Equal(t, domain.NewMoney(1100.00), page[0].Amount)
+	assert.Equal(t, domain.NewMoney(1600.00), page[1].Amount)
+
+	page, total, err = repo.FindByLoan(loan.ID, "investor_999", 0, 10)
+	require.NoError(t, err)
+	assert.Zero(t, total)
+	assert.Empty(t, page)
+}
+
+func TestContributionsByLoan(t *testing.T) {
+	loans, db := setupTestRepository()
+	repo := NewInvestmentRepository(db)
+
+	loan := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(10000.00), Rate: 4.5, ROI: 6.0, Status: domain.StatusApproved}
+	other := &domain.Loan{BorrowerID: "user456", PrincipalAmount: domain.NewMoney(10000.00), Rate: 4.5, ROI: 6.0, Status: domain.StatusApproved}
+	require.NoError(t, loans.Create(loan))
+	require.NoError(t, loans.Create(other))
+
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	for i, investment := range []domain.Investment{
+		{LoanID: loan.ID, InvestorID: "investor_002", Amount: domain.NewMoney(1000.00)},
+		{LoanID: loan.ID, InvestorID: "investor_001", Amount: domain.NewMoney(2000.00)},
+		{LoanID: loan.ID, InvestorID: "investor_002", Amount: domain.NewMoney(500.00)},
+		{LoanID: other.ID, InvestorID: "investor_001", Amount: domain.NewMoney(9000.00)},
+	} {
+		investment.CreatedAt = start.Add(time.Duration(i) * time.Minute)
+		require.NoError(t, db.Create(&investment).Error)
+	}
+
+	// Withdrawn investments no longer contribute
+	withdrawn := &domain.Investment{LoanID: loan.ID, InvestorID: "investor_003", Amount: domain.NewMoney(700.00)}
+	require.NoError(t, db.Create(withdrawn).Error)
+	require.NoError(t, db.Delete(withdrawn).Error)
+
+	contributions, err := repo.ContributionsByLoan(loan.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.InvestorContribution{
+		{InvestorID: "investor_002", Amount: domain.NewMoney(1500.00), Investments: 2},
+		{InvestorID: "investor_001", Amount: domain.NewMoney(2000.00), Investments: 1},
+	}, contributions)
+
+	contributions, err = repo.ContributionsByLoan("nonexistent-id")
+	require.NoError(t, err)
+	assert.Empty(t, contributions)
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"loan-service/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// InvestorRepository defines the interface for investor data operations
+type InvestorRepository interface {
+	FindByID(id string) (*domain.Investor, error)
+	Save(investor *domain.Investor) error
+}
+
+// investorRepository implements InvestorRepository
+type investorRepository struct {
+	db *gorm.DB
+}
+
+// NewInvestorRepository creates a new investor repository
+func NewInvestorRepository(db *gorm.DB) InvestorRepository {
+	return &investorRepository{db: db}
+}
+
+// FindByID finds an investor by ID
+func (r *investorRepository) FindByID(id string) (*domain.Investor, error) {
+	var investor domain.Investor
+	err := r.db.First(&investor, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &investor, nil
+}
+
+// Save creates or updates an investor
+func (r *investorRepository) Save(investor *domain.Investor) error {
+	return r.db.Save(investor).Error
+}
@@ -1,23 +1,66 @@
 package repository
 
 import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
 	"loan-service/internal/domain"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // LoanRepository defines the interface for loan data operations
 type LoanRepository interface {
 	Create(loan *domain.Loan) error
+	CreateBatch(loans []domain.Loan) error
 	FindByID(id string) (*domain.Loan, error)
 	FindAll(filters map[string]interface{}) ([]domain.Loan, error)
+	EachLoan(filters map[string]interface{}, fn func(loan *domain.Loan) error) error
 	Update(loan *domain.Loan) error
+	UpdateWithTags(loan *domain.Loan) error
+	Approve(loan *domain.Loan, fromStatus domain.LoanStatus) (bool, error)
+	Reject(loan *domain.Loan, fromStatus domain.LoanStatus) (bool, error)
+	Cancel(loan *domain.Loan, fromStatus domain.LoanStatus, fromInvested domain.Money) (bool, error)
+	Expire(loan *domain.Loan, fromInvested domain.Money) (bool, error)
 	Delete(id string) error
+	HasHistory(loanID string) (bool, error)
+	CountByBorrower(borrowerID string, statuses []domain.LoanStatus) (int64, error)
+	FindMissingAgreements() ([]domain.Loan, error)
+	FindOverdue(now time.Time) ([]domain.Loan, error)
+	UpdateWithApprovalAmendment(loan *domain.Loan, amendment *domain.ApprovalAmendment) error
+	FindApprovalAmendments(loanID string) ([]domain.ApprovalAmendment, error)
+	FindByInvestor(investorID string, status string, offset, limit int) ([]domain.InvestorPosition, int64, error)
+	SatisfyCovenant(loanID, covenantID string, satisfiedAt time.Time) error
+	CreateWithIdempotencyKey(loan *domain.Loan, key *domain.IdempotencyKey) error
+	FindIdempotencyKey(borrowerID, key string) (*domain.IdempotencyKey, error)
+	DeleteIdempotencyKey(borrowerID, key string) error
+	RevokeApproval(loan *domain.Loan, revocation *domain.ApprovalRevocation) error
+	FindByApprovalProof(proofs domain.ProofLinks, excludeID string) (*domain.Loan, error)
+	FindDuplicateProposal(loan *domain.Loan) (*domain.Loan, error)
+	FindVersion(loanID string, version int) (*domain.LoanVersion, error)
+	FindEvents(loanID string) ([]domain.LoanEvent, error)
+	FindEventsByBorrower(borrowerID string, offset, limit int) ([]domain.LoanEvent, int64, error)
+	FindDeleted() ([]domain.Loan, error)
+	FindDeletedByID(id string) (*domain.Loan, error)
+	FindWithWithdrawals(id string) (*domain.Loan, error)
+	FindByIDWithoutAssociations(id string) (*domain.Loan, error)
+	Restore(id string) error
+	Transaction(fn func(repo LoanRepository) error) error
+	WithContext(ctx context.Context) LoanRepository
+	WithRetry(policy RetryPolicy) LoanRepository
+	WithdrawInvestment(loan *domain.Loan, refund *domain.Refund) error
+	Investments() InvestmentRepository
+	Stats() StatsRepository
 }
 
-// loanRepository implements LoanRepository
+// loanRepository implements LoanRepository. Create, Update and Delete are retried under the retry policy
+// unless the repository runs in a transaction, in which case the whole transaction is retried.
 type loanRepository struct {
-	db *gorm.DB
+	db    *gorm.DB
+	retry RetryPolicy
 }
 
 // NewLoanRepository creates a new loan repository
@@ -25,26 +68,95 @@ func NewLoanRepository(db *gorm.DB) LoanRepository {
 	return &loanRepository{db: db}
 }
 
-// Create creates a new loan
+// Create creates a new loan and records it as the loan's first version
 func (r *loanRepository) Create(loan *domain.Loan) error {
-	return r.db.Create(loan).Error
+	return r.retryLoanWrite(loan, func() error {
+		return r.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(loan).Error; err != nil {
+				return err
+			}
+			return recordVersion(tx, loan)
+		})
+	})
+}
+
+// retryLoanWrite runs a write of loan under the retry policy. The failed attempt's transaction was rolled
+// back, so the loan is restored to its state before the write, with its version and queued audit events,
+// before it is written again.
+func (r *loanRepository) retryLoanWrite(loan *domain.Loan, write func() error) error {
+	initial := *loan
+	attempted := false
+	return r.retry.do(r.db.Statement.Context, func() error {
+		if attempted {
+			*loan = initial
+		}
+		attempted = true
+		return write()
+	})
+}
+
+// CreateBatch creates several loans in one transaction, recording each one's first version.
+// When any loan fails to be created none are.
+func (r *loanRepository) CreateBatch(loans []domain.Loan) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for i := range loans {
+			if err := tx.Create(&loans[i]).Error; err != nil {
+				return err
+			}
+			if err := recordVersion(tx, &loans[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
 // FindByID finds a loan by ID
 func (r *loanRepository) FindByID(id string) (*domain.Loan, error) {
 	var loan domain.Loan
-	err := r.db.Preload("Investments").First(&loan, "id = ?", id).Error
+	err := r.db.Preload("Investments", investmentsInOrder).Preload("Covenants").Preload("Tags").Preload("Repayments").Preload("Refunds").First(&loan, "id = ?", id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &loan, nil
 }
 
+// investmentsInOrder orders preloaded investments in the order they were made, which responses
+// embedding only the latest investments rely on
+func investmentsInOrder(db *gorm.DB) *gorm.DB {
+	return db.Order("created_at ASC").Order("id ASC")
+}
+
 // FindAll finds all loans with optional filters
 func (r *loanRepository) FindAll(filters map[string]interface{}) ([]domain.Loan, error) {
 	var loans []domain.Loan
-	query := r.db.Preload("Investments")
+	err := r.filterLoans(r.db.Preload("Investments", investmentsInOrder).Preload("Covenants").Preload("Tags"), filters).Find(&loans).Error
+	return loans, err
+}
+
+// EachLoan calls fn with every loan matching the filters of FindAll, reading them one row at a time
+// without their associations. Iteration stops at the first error fn returns.
+func (r *loanRepository) EachLoan(filters map[string]interface{}, fn func(loan *domain.Loan) error) error {
+	rows, err := r.filterLoans(r.db.Model(&domain.Loan{}), filters).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
 
+	for rows.Next() {
+		var loan domain.Loan
+		if err := r.db.ScanRows(rows, &loan); err != nil {
+			return err
+		}
+		if err := fn(&loan); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// filterLoans narrows a loan query down to the filters and applies the requested order
+func (r *loanRepository) filterLoans(query *gorm.DB, filters map[string]interface{}) *gorm.DB {
 	if status, ok := filters["status"]; ok {
 		query = query.Where("status = ?", status)
 	}
@@ -53,16 +165,609 @@ func (r *loanRepository) FindAll(filters map[string]interface{}) ([]domain.Loan,
 		query = query.Where("borrower_id = ?", borrowerID)
 	}
 
-	err := query.Find(&loans).Error
-	return loans, err
+	if minPrincipal, ok := filters["min_principal"]; ok {
+		query = query.Where("principal_amount >= ?", minPrincipal)
+	}
+
+	if maxPrincipal, ok := filters["max_principal"]; ok {
+		query = query.Where("principal_amount <= ?", maxPrincipal)
+	}
+
+	// Timestamps are stored in local time, so the bounds are converted to compare them as stored
+	if createdAfter, ok := filters["created_after"].(time.Time); ok {
+		query = query.Where("created_at >= ?", createdAfter.Local())
+	}
+
+	if createdBefore, ok := filters["created_before"].(time.Time); ok {
+		query = query.Where("created_at <= ?", createdBefore.Local())
+	}
+
+	// ApprovalDetails and DisbursementDetails are embedded without a prefix, so their fields are plain loan columns
+	if validatorID, ok := filters["field_validator_id"]; ok {
+		query = query.Where("field_validator_id = ?", validatorID)
+	}
+
+	if officerID, ok := filters["field_officer_id"]; ok {
+		query = query.Where("field_officer_id = ?", officerID)
+	}
+
+	if tags, ok := filters["tags"].([]string); ok && len(tags) > 0 {
+		tagged := r.db.Model(&domain.LoanTag{}).Select("loan_id").Where("tag IN ?", tags)
+		if filters["tag_match"] == domain.TagMatchAll {
+			tagged = tagged.Group("loan_id").Having("COUNT(DISTINCT tag) = ?", len(tags))
+		}
+		query = query.Where("id IN (?)", tagged)
+	}
+
+	// The column is checked against the allowlist again since it ends up in the SQL
+	if sortBy, ok := filters["sort_by"].(string); ok && domain.IsLoanSortColumn(sortBy) {
+		desc := filters["order"] == "desc"
+		query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: sortBy}, Desc: desc}).
+			Order(clause.OrderByColumn{Column: clause.Column{Name: "id"}, Desc: desc})
+	}
+	return query
+}
+
+// Transaction runs fn with a repository bound to a single transaction, committing when fn returns nil
+// and rolling back otherwise. Within fn only the given repository may be used, and its writes are not
+// retried on their own; instead a transaction failing with a transient error is retried as a whole under
+// the retry policy, so fn may run more than once and must read what it changes within the transaction.
+func (r *loanRepository) Transaction(fn func(repo LoanRepository) error) error {
+	return r.retry.do(r.db.Statement.Context, func() error {
+		return r.db.Transaction(func(tx *gorm.DB) error {
+			return fn(&loanRepository{db: tx})
+		})
+	})
 }
 
-// Update updates a loan
+// WithContext returns a repository whose queries run with ctx, so they are aborted once ctx is cancelled
+func (r *loanRepository) WithContext(ctx context.Context) LoanRepository {
+	return &loanRepository{db: r.db.WithContext(ctx), retry: r.retry}
+}
+
+// WithRetry returns a repository retrying writes that fail with a transient error under the policy
+func (r *loanRepository) WithRetry(policy RetryPolicy) LoanRepository {
+	return &loanRepository{db: r.db, retry: policy}
+}
+
+// Investments returns an investment repository sharing the repository's connection, so its queries run
+// in the same transaction and with the same context
+func (r *loanRepository) Investments() InvestmentRepository {
+	return NewInvestmentRepository(r.db)
+}
+
+// Stats returns a stats repository sharing the repository's connection and context
+func (r *loanRepository) Stats() StatsRepository {
+	return NewStatsRepository(r.db)
+}
+
+// Update updates a loan and records the result as a new version
 func (r *loanRepository) Update(loan *domain.Loan) error {
-	return r.db.Save(loan).Error
+	return r.retryLoanWrite(loan, func() error {
+		return r.db.Transaction(func(tx *gorm.DB) error {
+			return saveVersioned(tx, loan)
+		})
+	})
+}
+
+// WithdrawInvestment saves a loan an investment was withdrawn from together with the refund of the
+// investment, and soft-deletes the investment, in one transaction. The withdrawn investment stays in the
+// table for the audit trail.
+func (r *loanRepository) WithdrawInvestment(loan *domain.Loan, refund *domain.Refund) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := saveVersioned(tx, loan); err != nil {
+			return err
+		}
+		return tx.Delete(&domain.Investment{}, "id = ? AND loan_id = ?", refund.InvestmentID, loan.ID).Error
+	})
+}
+
+// UpdateWithTags updates a loan and replaces its tags with loan.Tags in one transaction
+func (r *loanRepository) UpdateWithTags(loan *domain.Loan) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := saveVersioned(tx, loan); err != nil {
+			return err
+		}
+
+		stale := tx.Where("loan_id = ?", loan.ID)
+		if names := loan.TagNames(); len(names) > 0 {
+			stale = stale.Where("tag NOT IN ?", names)
+		}
+		return stale.Delete(&domain.LoanTag{}).Error
+	})
+}
+
+// Approve records the approval of a loan and creates its covenants in one transaction, provided the
+// loan is still in fromStatus. It returns false without changing anything when a concurrent request
+// moved the loan out of fromStatus first.
+func (r *loanRepository) Approve(loan *domain.Loan, fromStatus domain.LoanStatus) (bool, error) {
+	approved := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&domain.Loan{}).
+			Where("id = ? AND status = ?", loan.ID, fromStatus).
+			Updates(map[string]interface{}{
+				"status":                loan.Status,
+				"field_validator_proof": loan.ApprovalDetails.FieldValidatorProof,
+				"field_validator_id":    loan.ApprovalDetails.FieldValidatorID,
+				"approval_date":         loan.ApprovalDetails.ApprovalDate,
+				"investment_deadline":   loan.InvestmentDeadline,
+				"version":               gorm.Expr("version + 1"),
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		approved = true
+		loan.Version++
+		if err := recordVersion(tx, loan); err != nil {
+			return err
+		}
+		if len(loan.Covenants) == 0 {
+			return nil
+		}
+		return tx.Create(&loan.Covenants).Error
+	})
+	return approved, err
+}
+
+// Reject records the rejection of a loan, provided the loan is still in fromStatus. It returns false
+// without changing anything when a concurrent request moved the loan out of fromStatus first.
+func (r *loanRepository) Reject(loan *domain.Loan, fromStatus domain.LoanStatus) (bool, error) {
+	rejected := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&domain.Loan{}).
+			Where("id = ? AND status = ?", loan.ID, fromStatus).
+			Updates(map[string]interface{}{
+				"status":           loan.Status,
+				"rejected_by":      loan.RejectionDetails.FieldValidatorID,
+				"rejection_reason": loan.RejectionDetails.Reason,
+				"status_reason":    loan.StatusReason,
+				"rejection_date":   loan.RejectionDetails.RejectionDate,
+				"version":          gorm.Expr("version + 1"),
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		rejected = true
+		loan.Version++
+		return recordVersion(tx, loan)
+	})
+	return rejected, err
+}
+
+// Cancel records the cancellation of a loan along with the refunds of its investments, provided the loan
+// is still in fromStatus with fromInvested invested. It returns false without changing anything when a
+// concurrent request moved the loan on or invested in it first.
+func (r *loanRepository) Cancel(loan *domain.Loan, fromStatus domain.LoanStatus, fromInvested domain.Money) (bool, error) {
+	return r.closeWithRefunds(loan, fromStatus, fromInvested)
+}
+
+// Expire records the expiry of an approved loan along with the refunds of its investments, provided the
+// loan is still approved with fromInvested invested. It returns false without changing anything when a
+// concurrent request moved the loan on or invested in it first.
+func (r *loanRepository) Expire(loan *domain.Loan, fromInvested domain.Money) (bool, error) {
+	return r.closeWithRefunds(loan, domain.StatusApproved, fromInvested)
+}
+
+// closeWithRefunds saves the final status of a loan and the refunds of its investments if the loan is
+// still in fromStatus with fromInvested invested
+func (r *loanRepository) closeWithRefunds(loan *domain.Loan, fromStatus domain.LoanStatus, fromInvested domain.Money) (bool, error) {
+	closed := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&domain.Loan{}).
+			Where("id = ? AND status = ? AND total_invested = ?", loan.ID, fromStatus, fromInvested).
+			Updates(map[string]interface{}{
+				"status":         loan.Status,
+				"status_reason":  loan.StatusReason,
+				"total_invested": loan.TotalInvested,
+				"version":        gorm.Expr("version + 1"),
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		if len(loan.Refunds) > 0 {
+			if err := tx.Create(&loan.Refunds).Error; err != nil {
+				return err
+			}
+		}
+
+		closed = true
+		loan.Version++
+		return recordVersion(tx, loan)
+	})
+	return closed, err
 }
 
 // Delete deletes a loan
 func (r *loanRepository) Delete(id string) error {
-	return r.db.Delete(&domain.Loan{}, "id = ?", id).Error
+	return r.retry.do(r.db.Statement.Context, func() error {
+		return r.db.Delete(&domain.Loan{}, "id = ?", id).Error
+	})
+}
+
+// editActions are the audit event actions a proposed loan records without gaining any lifecycle history
+var editActions = []string{"create", "update"}
+
+// HasHistory reports whether a loan has investments or audit events other than its creation and term updates
+func (r *loanRepository) HasHistory(loanID string) (bool, error) {
+	var investments int64
+	if err := r.db.Model(&domain.Investment{}).Where("loan_id = ?", loanID).Count(&investments).Error; err != nil {
+		return false, err
+	}
+	if investments > 0 {
+		return true, nil
+	}
+
+	var events int64
+	err := r.db.Model(&domain.LoanEvent{}).Where("loan_id = ? AND action NOT IN ?", loanID, editActions).Count(&events).Error
+	return events > 0, err
+}
+
+// FindDeleted finds all soft-deleted loans, most recently deleted first
+func (r *loanRepository) FindDeleted() ([]domain.Loan, error) {
+	var loans []domain.Loan
+	err := r.db.Unscoped().Preload("Investments", investmentsInOrder).Preload("Covenants").Preload("Tags").
+		Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC").
+		Find(&loans).Error
+	return loans, err
+}
+
+// FindByIDWithoutAssociations finds a loan by ID without loading its investments or other associations
+func (r *loanRepository) FindByIDWithoutAssociations(id string) (*domain.Loan, error) {
+	var loan domain.Loan
+	if err := r.db.First(&loan, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &loan, nil
+}
+
+// FindWithWithdrawals finds a loan by ID with its refunds and all its investments, including those
+// withdrawn from it
+func (r *loanRepository) FindWithWithdrawals(id string) (*domain.Loan, error) {
+	var loan domain.Loan
+	err := r.db.Preload("Investments", func(db *gorm.DB) *gorm.DB {
+		return investmentsInOrder(db.Unscoped())
+	}).Preload("Refunds").First(&loan, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &loan, nil
+}
+
+// FindDeletedByID finds a soft-deleted loan by ID
+func (r *loanRepository) FindDeletedByID(id string) (*domain.Loan, error) {
+	var loan domain.Loan
+	err := r.db.Unscoped().First(&loan, "id = ? AND deleted_at IS NOT NULL", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &loan, nil
+}
+
+// Restore clears the deletion of a soft-deleted loan
+func (r *loanRepository) Restore(id string) error {
+	result := r.db.Unscoped().Model(&domain.Loan{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// CountByBorrower counts a borrower's loans in any of the given statuses
+func (r *loanRepository) CountByBorrower(borrowerID string, statuses []domain.LoanStatus) (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.Loan{}).
+		Where("borrower_id = ? AND status IN ?", borrowerID, statuses).
+		Count(&count).Error
+	return count, err
+}
+
+// FindMissingAgreements finds invested or disbursed loans without an agreement letter link
+func (r *loanRepository) FindMissingAgreements() ([]domain.Loan, error) {
+	var loans []domain.Loan
+	err := r.db.Preload("Investments", investmentsInOrder).
+		Where("status IN ?", []domain.LoanStatus{domain.StatusInvested, domain.StatusDisbursed}).
+		Where("agreement_letter_link = '' OR agreement_letter_link IS NULL").
+		Find(&loans).Error
+	return loans, err
+}
+
+// FindOverdue finds approved loans whose investment deadline passed before now, with their investments
+func (r *loanRepository) FindOverdue(now time.Time) ([]domain.Loan, error) {
+	var loans []domain.Loan
+	err := r.db.Preload("Investments", investmentsInOrder).
+		Where("status = ? AND investment_deadline < ?", domain.StatusApproved, now).
+		Order("investment_deadline ASC").
+		Find(&loans).Error
+	return loans, err
+}
+
+// UpdateWithApprovalAmendment updates a loan and records the approval amendment in one transaction
+func (r *loanRepository) UpdateWithApprovalAmendment(loan *domain.Loan, amendment *domain.ApprovalAmendment) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := saveVersioned(tx, loan); err != nil {
+			return err
+		}
+		return tx.Create(amendment).Error
+	})
+}
+
+// FindApprovalAmendments finds the approval amendments of a loan, oldest first
+func (r *loanRepository) FindApprovalAmendments(loanID string) ([]domain.ApprovalAmendment, error) {
+	var amendments []domain.ApprovalAmendment
+	err := r.db.Where("loan_id = ?", loanID).Order("created_at ASC").Find(&amendments).Error
+	return amendments, err
+}
+
+// FindByInvestor finds the loans an investor has invested in, optionally filtered by status,
+// together with the investor's total contribution to each loan. Loans are ordered by the
+// investor's first investment. It also returns the total number of matching loans.
+func (r *loanRepository) FindByInvestor(investorID string, status string, offset, limit int) ([]domain.InvestorPosition, int64, error) {
+	query := r.db.Model(&domain.Investment{}).
+		Joins("JOIN loans ON loans.id = investments.loan_id AND loans.deleted_at IS NULL").
+		Where("investments.investor_id = ?", investorID)
+	if status != "" {
+		query = query.Where("loans.status = ?", status)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Distinct("investments.loan_id").Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []struct {
+		LoanID       string
+		Contribution float64
+	}
+	err := query.Select("investments.loan_id AS loan_id, SUM(investments.amount) AS contribution").
+		Group("investments.loan_id").
+		Order("MIN(investments.created_at) ASC").
+		Offset(offset).
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil || len(rows) == 0 {
+		return []domain.InvestorPosition{}, total, err
+	}
+
+	ids := make([]string, len(rows))
+	for i, row := range rows {
+		ids[i] = row.LoanID
+	}
+
+	var loans []domain.Loan
+	if err := r.db.Preload("Investments", investmentsInOrder).Where("id IN ?", ids).Find(&loans).Error; err != nil {
+		return nil, 0, err
+	}
+
+	loansByID := make(map[string]domain.Loan, len(loans))
+	for _, loan := range loans {
+		loansByID[loan.ID] = loan
+	}
+
+	positions := make([]domain.InvestorPosition, 0, len(rows))
+	for _, row := range rows {
+		positions = append(positions, domain.InvestorPosition{
+			Loan:         loansByID[row.LoanID],
+			Contribution: row.Contribution,
+		})
+	}
+	return positions, total, nil
+}
+
+// SatisfyCovenant marks a covenant of a loan as satisfied and bumps the loan's update time
+func (r *loanRepository) SatisfyCovenant(loanID, covenantID string, satisfiedAt time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&domain.Covenant{}).
+			Where("id = ? AND loan_id = ?", covenantID, loanID).
+			Updates(map[string]interface{}{"satisfied": true, "satisfied_at": satisfiedAt})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		// The loan's covenants_satisfied changes with it, so cached copies of the loan must be refreshed
+		return tx.Model(&domain.Loan{}).Where("id = ?", loanID).UpdateColumn("updated_at", tx.NowFunc()).Error
+	})
+}
+
+// CreateWithIdempotencyKey creates a loan and records the idempotency key pointing to it in one
+// transaction. The key's primary key constraint rejects a second loan for the same borrower and key.
+func (r *loanRepository) CreateWithIdempotencyKey(loan *domain.Loan, key *domain.IdempotencyKey) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(loan).Error; err != nil {
+			return err
+		}
+		if err := recordVersion(tx, loan); err != nil {
+			return err
+		}
+		key.LoanID = loan.ID
+		return tx.Create(key).Error
+	})
+}
+
+// FindIdempotencyKey finds the idempotency key of a borrower. The conditions are given as a map so that
+// GORM quotes the key column, a reserved word in MySQL.
+func (r *loanRepository) FindIdempotencyKey(borrowerID, key string) (*domain.IdempotencyKey, error) {
+	var record domain.IdempotencyKey
+	err := r.db.First(&record, map[string]interface{}{"borrower_id": borrowerID, "key": key}).Error
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// DeleteIdempotencyKey deletes the idempotency key of a borrower
+func (r *loanRepository) DeleteIdempotencyKey(borrowerID, key string) error {
+	return r.db.Where(map[string]interface{}{"borrower_id": borrowerID, "key": key}).Delete(&domain.IdempotencyKey{}).Error
+}
+
+// RevokeApproval saves a loan returned to proposed, removes its approval covenants and records
+// the revocation in one transaction
+func (r *loanRepository) RevokeApproval(loan *domain.Loan, revocation *domain.ApprovalRevocation) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := saveVersioned(tx, loan); err != nil {
+			return err
+		}
+		if err := tx.Where("loan_id = ?", loan.ID).Delete(&domain.Covenant{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(revocation).Error
+	})
+}
+
+// FindByApprovalProof finds a loan other than excludeID whose approval used any of the given field validator
+// proofs. It returns nil when none of the proofs has been used.
+func (r *loanRepository) FindByApprovalProof(proofs domain.ProofLinks, excludeID string) (*domain.Loan, error) {
+	if len(proofs) == 0 {
+		return nil, nil
+	}
+
+	// Proofs are stored as a JSON array, or as the link itself on loans approved with a single proof
+	used := r.db
+	for _, proof := range proofs {
+		encoded, err := json.Marshal(proof)
+		if err != nil {
+			return nil, err
+		}
+		used = used.Or("field_validator_proof = ? OR field_validator_proof LIKE ? ESCAPE '!'", proof, "%"+escapeLike(string(encoded))+"%")
+	}
+
+	var loans []domain.Loan
+	err := r.db.Where(used).Where("id <> ?", excludeID).
+		Order("created_at ASC").
+		Limit(1).
+		Find(&loans).Error
+	if err != nil || len(loans) == 0 {
+		return nil, err
+	}
+	return &loans[0], nil
+}
+
+// FindDuplicateProposal finds the oldest proposed loan of the loan's borrower with the same principal, rate
+// and ROI. It returns nil when there is none.
+func (r *loanRepository) FindDuplicateProposal(loan *domain.Loan) (*domain.Loan, error) {
+	var loans []domain.Loan
+	err := r.db.Where("borrower_id = ? AND status = ? AND principal_amount = ? AND rate = ? AND roi = ?",
+		loan.BorrowerID, domain.StatusProposed, loan.PrincipalAmount, loan.Rate, loan.ROI).
+		Order("created_at ASC").
+		Limit(1).
+		Find(&loans).Error
+	if err != nil || len(loans) == 0 {
+		return nil, err
+	}
+	return &loans[0], nil
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern using ! as the escape character
+func escapeLike(value string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(value)
+}
+
+// FindVersion finds a recorded version of a loan
+func (r *loanRepository) FindVersion(loanID string, version int) (*domain.LoanVersion, error) {
+	var record domain.LoanVersion
+	err := r.db.First(&record, "loan_id = ? AND version = ?", loanID, version).Error
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// FindEvents finds the audit events of a loan in the order they happened
+func (r *loanRepository) FindEvents(loanID string) ([]domain.LoanEvent, error) {
+	var events []domain.LoanEvent
+	err := r.db.Where("loan_id = ?", loanID).Order("version ASC").Order("timestamp ASC").Find(&events).Error
+	return events, err
+}
+
+// FindEventsByBorrower finds one page of the audit events of all of a borrower's loans in the order they
+// happened, together with the total number of events. Events of deleted loans are left out.
+func (r *loanRepository) FindEventsByBorrower(borrowerID string, offset, limit int) ([]domain.LoanEvent, int64, error) {
+	query := r.db.Model(&domain.LoanEvent{}).
+		Joins("JOIN loans ON loans.id = loan_events.loan_id AND loans.deleted_at IS NULL").
+		Where("loans.borrower_id = ?", borrowerID)
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var events []domain.LoanEvent
+	err := query.Select("loan_events.*").
+		Order("loan_events.timestamp ASC").
+		Order("loan_events.version ASC").
+		Offset(offset).
+		Limit(limit).
+		Find(&events).Error
+	return events, total, err
+}
+
+// saveVersioned saves a loan and records the result as its next version
+func saveVersioned(tx *gorm.DB, loan *domain.Loan) error {
+	if err := saveLocked(tx, loan); err != nil {
+		return err
+	}
+	return recordVersion(tx, loan)
+}
+
+// saveLocked saves a loan only if it still has the version it was read with, incrementing the version.
+// It returns domain.ErrConcurrentUpdate when another write got there first; the caller's transaction
+// must then be rolled back, as associations may already have been saved.
+func saveLocked(tx *gorm.DB, loan *domain.Loan) error {
+	expected := loan.Version
+	loan.Version++
+
+	// Selecting all fields keeps Save from falling back to an upsert when no row matches
+	result := tx.Select("*").Where("version = ?", expected).Save(loan)
+	if result.Error == nil && result.RowsAffected == 0 {
+		result.Error = domain.ErrConcurrentUpdate
+	}
+	if result.Error != nil {
+		loan.Version = expected
+	}
+	return result.Error
+}
+
+// recordVersion records a snapshot of the loan numbered after its latest recorded version, along with
+// the audit events queued on the loan. The (loan_id, version) primary key rejects a concurrent write
+// claiming the same number.
+func recordVersion(tx *gorm.DB, loan *domain.Loan) error {
+	var latest int
+	err := tx.Model(&domain.LoanVersion{}).
+		Select("COALESCE(MAX(version), 0)").
+		Where("loan_id = ?", loan.ID).
+		Scan(&latest).Error
+	if err != nil {
+		return err
+	}
+
+	version := domain.NewLoanVersion(loan)
+	version.Version = latest + 1
+	if err := tx.Create(version).Error; err != nil {
+		return err
+	}
+	return recordEvents(tx, loan)
+}
+
+// recordEvents persists the audit events queued on the loan
+func recordEvents(tx *gorm.DB, loan *domain.Loan) error {
+	events := loan.TakePendingEvents()
+	if len(events) == 0 {
+		return nil
+	}
+	for i := range events {
+		events[i].LoanID = loan.ID
+		events[i].Version = loan.Version
+	}
+	return tx.Create(&events).Error
 }
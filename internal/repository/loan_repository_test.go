@@ -1,8 +1,12 @@
 package repository
 
 import (
+	"context"
+	"fmt"
 	"testing"
+	"time"
 
+	"loan-service/internal/database"
 	"loan-service/internal/domain"
 
 	"github.com/stretchr/testify/assert"
@@ -13,19 +17,19 @@ import (
 
 func setupTestRepository() (LoanRepository, *gorm.DB) {
 	// Create test database
-	database, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
 	if err != nil {
 		panic("failed to connect to test database")
 	}
 
 	// Auto migrate the schema
-	err = database.AutoMigrate(&domain.Loan{}, &domain.Investment{})
+	err = database.Migrate(db)
 	if err != nil {
 		panic("failed to migrate test database")
 	}
 
-	repo := NewLoanRepository(database)
-	return repo, database
+	repo := NewLoanRepository(db)
+	return repo, db
 }
 
 func TestCreateLoan(t *testing.T) {
@@ -33,7 +37,7 @@ func TestCreateLoan(t *testing.T) {
 
 	loan := &domain.Loan{
 		BorrowerID:          "user123",
-		PrincipalAmount:     25000.00,
+		PrincipalAmount:     domain.NewMoney(25000.00),
 		Rate:                4.5,
 		ROI:                 6.0,
 		AgreementLetterLink: "https://example.com/agreement/user123.pdf",
@@ -46,13 +50,38 @@ func TestCreateLoan(t *testing.T) {
 	assert.Equal(t, domain.StatusProposed, loan.Status)
 }
 
+func TestCreateBatch(t *testing.T) {
+	repo, db := setupTestRepository()
+
+	loans := []domain.Loan{
+		{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: 6.0},
+		{BorrowerID: "user456", PrincipalAmount: domain.NewMoney(10000.00), Rate: 4.5, ROI: 6.0},
+	}
+	require.NoError(t, repo.CreateBatch(loans))
+	for _, loan := range loans {
+		_, err := repo.FindVersion(loan.ID, 1)
+		assert.NoError(t, err)
+	}
+
+	// A loan failing to insert rolls back the ones before it
+	err := repo.CreateBatch([]domain.Loan{
+		{BorrowerID: "user789", PrincipalAmount: domain.NewMoney(5000.00), Rate: 4.5, ROI: 6.0},
+		{ID: loans[0].ID, BorrowerID: "user789", PrincipalAmount: domain.NewMoney(5000.00), Rate: 4.5, ROI: 6.0},
+	})
+	assert.Error(t, err)
+
+	var count int64
+	require.NoError(t, db.Model(&domain.Loan{}).Where("borrower_id = ?", "user789").Count(&count).Error)
+	assert.Zero(t, count)
+}
+
 func TestFindByID(t *testing.T) {
 	repo, _ := setupTestRepository()
 
 	// Create a loan first
 	loan := &domain.Loan{
 		BorrowerID:          "user123",
-		PrincipalAmount:     25000.00,
+		PrincipalAmount:     domain.NewMoney(25000.00),
 		Rate:                4.5,
 		ROI:                 6.0,
 		AgreementLetterLink: "https://example.com/agreement/user123.pdf",
@@ -70,13 +99,35 @@ func TestFindByID(t *testing.T) {
 	assert.Equal(t, loan.PrincipalAmount, foundLoan.PrincipalAmount)
 }
 
+func TestWithContextCancelled(t *testing.T) {
+	repo, _ := setupTestRepository()
+
+	loan := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Rate: 6.0, ROI: 4.5}
+	require.NoError(t, repo.Create(loan))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	bound := repo.WithContext(ctx)
+
+	_, err := bound.FindByID(loan.ID)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	loan.PrincipalAmount = domain.NewMoney(30000.00)
+	assert.ErrorIs(t, bound.Update(loan), context.Canceled)
+
+	// The repository it was derived from is unaffected
+	found, err := repo.FindByID(loan.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.NewMoney(25000.00), found.PrincipalAmount)
+}
+
 func TestFindAll(t *testing.T) {
 	repo, _ := setupTestRepository()
 
 	// Create multiple loans
 	loan1 := &domain.Loan{
 		BorrowerID:          "user123",
-		PrincipalAmount:     25000.00,
+		PrincipalAmount:     domain.NewMoney(25000.00),
 		Rate:                4.5,
 		ROI:                 6.0,
 		AgreementLetterLink: "https://example.com/agreement/user123.pdf",
@@ -84,7 +135,7 @@ func TestFindAll(t *testing.T) {
 
 	loan2 := &domain.Loan{
 		BorrowerID:          "user456",
-		PrincipalAmount:     30000.00,
+		PrincipalAmount:     domain.NewMoney(30000.00),
 		Rate:                5.0,
 		ROI:                 7.0,
 		AgreementLetterLink: "https://example.com/agreement/user456.pdf",
@@ -103,13 +154,37 @@ func TestFindAll(t *testing.T) {
 	assert.Len(t, loans, 2)
 }
 
+func TestFindAllSorted(t *testing.T) {
+	repo, _ := setupTestRepository()
+
+	for _, principal := range []float64{20000.00, 5000.00, 12000.00} {
+		require.NoError(t, repo.Create(&domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(principal), Rate: 4.5, ROI: 6.0}))
+	}
+
+	loans, err := repo.FindAll(map[string]interface{}{"sort_by": "principal_amount", "order": "asc"})
+	require.NoError(t, err)
+	require.Len(t, loans, 3)
+	assert.Equal(t, domain.NewMoney(5000.00), loans[0].PrincipalAmount)
+	assert.Equal(t, domain.NewMoney(20000.00), loans[2].PrincipalAmount)
+
+	loans, err = repo.FindAll(map[string]interface{}{"sort_by": "principal_amount", "order": "desc"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.NewMoney(20000.00), loans[0].PrincipalAmount)
+	assert.Equal(t, domain.NewMoney(5000.00), loans[2].PrincipalAmount)
+
+	// Columns outside the allowlist are never used
+	loans, err = repo.FindAll(map[string]interface{}{"sort_by": "rate; DROP TABLE loans"})
+	require.NoError(t, err)
+	assert.Len(t, loans, 3)
+}
+
 func TestFindAllWithFilters(t *testing.T) {
 	repo, _ := setupTestRepository()
 
 	// Create loans with different statuses
 	loan1 := &domain.Loan{
 		BorrowerID:          "user123",
-		PrincipalAmount:     25000.00,
+		PrincipalAmount:     domain.NewMoney(25000.00),
 		Rate:                4.5,
 		ROI:                 6.0,
 		Status:              domain.StatusProposed,
@@ -118,7 +193,7 @@ func TestFindAllWithFilters(t *testing.T) {
 
 	loan2 := &domain.Loan{
 		BorrowerID:          "user456",
-		PrincipalAmount:     30000.00,
+		PrincipalAmount:     domain.NewMoney(30000.00),
 		Rate:                5.0,
 		ROI:                 7.0,
 		Status:              domain.StatusApproved,
@@ -143,13 +218,128 @@ func TestFindAllWithFilters(t *testing.T) {
 	assert.Equal(t, domain.StatusProposed, loans[0].Status)
 }
 
+func TestFindAllByPrincipalRange(t *testing.T) {
+	repo, _ := setupTestRepository()
+
+	for _, principal := range []float64{10000.00, 50000.00, 100000.00} {
+		require.NoError(t, repo.Create(&domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(principal), Rate: 4.5, ROI: 6.0}))
+	}
+
+	tests := []struct {
+		name     string
+		filters  map[string]interface{}
+		expected []float64
+	}{
+		{"min only", map[string]interface{}{"min_principal": domain.NewMoney(50000.00)}, []float64{50000.00, 100000.00}},
+		{"max only", map[string]interface{}{"max_principal": domain.NewMoney(50000.00)}, []float64{10000.00, 50000.00}},
+		{"both bounds", map[string]interface{}{"min_principal": domain.NewMoney(20000.00), "max_principal": domain.NewMoney(60000.00)}, []float64{50000.00}},
+		{"exact", map[string]interface{}{"min_principal": domain.NewMoney(100000.00), "max_principal": domain.NewMoney(100000.00)}, []float64{100000.00}},
+		{"empty range", map[string]interface{}{"min_principal": domain.NewMoney(60000.00), "max_principal": domain.NewMoney(90000.00)}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filters := tt.filters
+			filters["sort_by"] = "principal_amount"
+			loans, err := repo.FindAll(filters)
+			require.NoError(t, err)
+
+			var principals []float64
+			for _, loan := range loans {
+				principals = append(principals, loan.PrincipalAmount.Float64())
+			}
+			assert.Equal(t, tt.expected, principals)
+		})
+	}
+}
+
+func TestFindAllByCreatedRange(t *testing.T) {
+	repo, db := setupTestRepository()
+
+	now := time.Now()
+	ages := map[string]time.Duration{"old": 30 * 24 * time.Hour, "recent": 7 * 24 * time.Hour, "new": 0}
+	ids := make(map[string]string)
+	for name, age := range ages {
+		loan := &domain.Loan{BorrowerID: name, PrincipalAmount: domain.NewMoney(10000.00), Rate: 4.5, ROI: 6.0}
+		require.NoError(t, repo.Create(loan))
+		require.NoError(t, db.Model(loan).UpdateColumn("created_at", now.Add(-age)).Error)
+		ids[name] = loan.ID
+	}
+
+	tests := []struct {
+		name     string
+		filters  map[string]interface{}
+		expected []string
+	}{
+		{"after only", map[string]interface{}{"created_after": now.Add(-10 * 24 * time.Hour)}, []string{"recent", "new"}},
+		{"before only", map[string]interface{}{"created_before": now.Add(-10 * 24 * time.Hour)}, []string{"old"}},
+		{"both bounds", map[string]interface{}{"created_after": now.Add(-10 * 24 * time.Hour), "created_before": now.Add(-24 * time.Hour)}, []string{"recent"}},
+		{"other time zone", map[string]interface{}{"created_after": now.Add(-10 * 24 * time.Hour).In(time.FixedZone("UTC+9", 9*60*60))}, []string{"recent", "new"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loans, err := repo.FindAll(tt.filters)
+			require.NoError(t, err)
+
+			var expected []string
+			for _, name := range tt.expected {
+				expected = append(expected, ids[name])
+			}
+			var found []string
+			for _, loan := range loans {
+				found = append(found, loan.ID)
+			}
+			assert.ElementsMatch(t, expected, found)
+		})
+	}
+}
+
+func TestFindAllByEmployee(t *testing.T) {
+	repo, _ := setupTestRepository()
+
+	approved := &domain.Loan{
+		BorrowerID:      "user123",
+		PrincipalAmount: domain.NewMoney(25000.00),
+		Rate:            4.5,
+		ROI:             6.0,
+		Status:          domain.StatusApproved,
+		ApprovalDetails: &domain.ApprovalDetails{FieldValidatorProof: domain.ProofLinks{"proof"}, FieldValidatorID: "validator_001", ApprovalDate: time.Now()},
+	}
+	disbursed := &domain.Loan{
+		BorrowerID:          "user456",
+		PrincipalAmount:     domain.NewMoney(30000.00),
+		Rate:                5.0,
+		ROI:                 7.0,
+		Status:              domain.StatusDisbursed,
+		ApprovalDetails:     &domain.ApprovalDetails{FieldValidatorProof: domain.ProofLinks{"proof"}, FieldValidatorID: "validator_002", ApprovalDate: time.Now()},
+		DisbursementDetails: &domain.DisbursementDetails{FieldOfficerID: "officer_002", DisbursementDate: time.Now()},
+	}
+	require.NoError(t, repo.Create(approved))
+	require.NoError(t, repo.Create(disbursed))
+
+	loans, err := repo.FindAll(map[string]interface{}{"field_validator_id": "validator_001"})
+	require.NoError(t, err)
+	require.Len(t, loans, 1)
+	assert.Equal(t, approved.ID, loans[0].ID)
+
+	loans, err = repo.FindAll(map[string]interface{}{"field_officer_id": "officer_002"})
+	require.NoError(t, err)
+	require.Len(t, loans, 1)
+	assert.Equal(t, disbursed.ID, loans[0].ID)
+
+	loans, err = repo.FindAll(map[string]interface{}{"field_validator_id": "validator_001", "field_officer_id": "officer_002"})
+	require.NoError(t, err)
+	assert.Empty(t, loans)
+}
+
 func TestUpdateLoan(t *testing.T) {
 	repo, _ := setupTestRepository()
 
 	// Create a loan
 	loan := &domain.Loan{
 		BorrowerID:          "user123",
-		PrincipalAmount:     25000.00,
+		PrincipalAmount:     domain.NewMoney(25000.00),
 		Rate:                4.5,
 		ROI:                 6.0,
 		AgreementLetterLink: "https://example.com/agreement/user123.pdf",
@@ -159,7 +349,7 @@ func TestUpdateLoan(t *testing.T) {
 	require.NoError(t, err)
 
 	// Update the loan
-	loan.PrincipalAmount = 30000.00
+	loan.PrincipalAmount = domain.NewMoney(30000.00)
 	loan.Rate = 5.0
 
 	err = repo.Update(loan)
@@ -169,17 +359,57 @@ func TestUpdateLoan(t *testing.T) {
 	updatedLoan, err := repo.FindByID(loan.ID)
 	require.NoError(t, err)
 
-	assert.Equal(t, 30000.00, updatedLoan.PrincipalAmount)
+	assert.Equal(t, domain.NewMoney(30000.00), updatedLoan.PrincipalAmount)
 	assert.Equal(t, 5.0, updatedLoan.Rate)
 }
 
+func TestUpdateLoanStaleVersion(t *testing.T) {
+	repo, _ := setupTestRepository()
+
+	loan := &domain.Loan{
+		BorrowerID:      "user123",
+		PrincipalAmount: domain.NewMoney(10000.00),
+		Rate:            4.5,
+		ROI:             6.0,
+		Status:          domain.StatusApproved,
+	}
+	require.NoError(t, repo.Create(loan))
+
+	// Two requests read the same loan and both invest the remaining capacity
+	first, err := repo.FindByID(loan.ID)
+	require.NoError(t, err)
+	second, err := repo.FindByID(loan.ID)
+	require.NoError(t, err)
+
+	require.NoError(t, first.AddInvestment("investor_001", domain.NewMoney(10000.00)))
+	require.NoError(t, repo.Update(first))
+	assert.Equal(t, uint(1), first.Version)
+
+	require.NoError(t, second.AddInvestment("investor_002", domain.NewMoney(10000.00)))
+	err = repo.Update(second)
+	assert.ErrorIs(t, err, domain.ErrConcurrentUpdate)
+	assert.Equal(t, uint(0), second.Version)
+
+	// The losing write left nothing behind
+	stored, err := repo.FindByID(loan.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.NewMoney(10000.00), stored.TotalInvested)
+	require.Len(t, stored.Investments, 1)
+	assert.Equal(t, "investor_001", stored.Investments[0].InvestorID)
+
+	// Re-reading the loan gives a write that succeeds
+	stored.AgreementLetterLink = "https://example.com/agreement.pdf"
+	require.NoError(t, repo.Update(stored))
+	assert.Equal(t, uint(2), stored.Version)
+}
+
 func TestDeleteLoan(t *testing.T) {
 	repo, _ := setupTestRepository()
 
 	// Create a loan
 	loan := &domain.Loan{
 		BorrowerID:          "user123",
-		PrincipalAmount:     25000.00,
+		PrincipalAmount:     domain.NewMoney(25000.00),
 		Rate:                4.5,
 		ROI:                 6.0,
 		AgreementLetterLink: "https://example.com/agreement/user123.pdf",
@@ -196,3 +426,306 @@ func TestDeleteLoan(t *testing.T) {
 	_, err = repo.FindByID(loan.ID)
 	assert.Error(t, err)
 }
+
+func TestRestoreLoan(t *testing.T) {
+	repo, _ := setupTestRepository()
+
+	loan := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: 6.0}
+	require.NoError(t, repo.Create(loan))
+	kept := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(10000.00), Rate: 4.5, ROI: 6.0}
+	require.NoError(t, repo.Create(kept))
+
+	require.NoError(t, repo.Delete(loan.ID))
+
+	deleted, err := repo.FindDeleted()
+	require.NoError(t, err)
+	require.Len(t, deleted, 1)
+	assert.Equal(t, loan.ID, deleted[0].ID)
+	assert.True(t, deleted[0].DeletedAt.Valid)
+
+	found, err := repo.FindDeletedByID(loan.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusProposed, found.Status)
+
+	_, err = repo.FindDeletedByID(kept.ID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	// Delete, restore, retrieve
+	require.NoError(t, repo.Restore(loan.ID))
+
+	restored, err := repo.FindByID(loan.ID)
+	require.NoError(t, err)
+	assert.False(t, restored.DeletedAt.Valid)
+	assert.Equal(t, domain.NewMoney(25000.00), restored.PrincipalAmount)
+
+	deleted, err = repo.FindDeleted()
+	require.NoError(t, err)
+	assert.Empty(t, deleted)
+
+	// Only deleted loans can be restored
+	assert.ErrorIs(t, repo.Restore(loan.ID), gorm.ErrRecordNotFound)
+	assert.ErrorIs(t, repo.Restore("missing"), gorm.ErrRecordNotFound)
+}
+
+func TestCountByBorrower(t *testing.T) {
+	repo, db := setupTestRepository()
+
+	statuses := []domain.LoanStatus{domain.StatusProposed, domain.StatusApproved, domain.StatusDisbursed, domain.StatusProposed}
+	for _, status := range statuses {
+		loan := &domain.Loan{
+			BorrowerID:      "user123",
+			PrincipalAmount: domain.NewMoney(25000.00),
+			Rate:            4.5,
+			ROI:             6.0,
+			Status:          status,
+		}
+		require.NoError(t, db.Create(loan).Error)
+	}
+
+	other := &domain.Loan{BorrowerID: "user456", PrincipalAmount: domain.NewMoney(10000.00), Rate: 4.5, ROI: 6.0}
+	require.NoError(t, db.Create(other).Error)
+
+	count, err := repo.CountByBorrower("user123", domain.ActiveStatuses())
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), count)
+
+	count, err = repo.CountByBorrower("user123", []domain.LoanStatus{domain.StatusDisbursed})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestFindByInvestor(t *testing.T) {
+	repo, db := setupTestRepository()
+
+	approved := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: 6.0, Status: domain.StatusApproved}
+	invested := &domain.Loan{BorrowerID: "user456", PrincipalAmount: domain.NewMoney(10000.00), Rate: 4.5, ROI: 6.0, Status: domain.StatusInvested}
+	untouched := &domain.Loan{BorrowerID: "user789", PrincipalAmount: domain.NewMoney(10000.00), Rate: 4.5, ROI: 6.0, Status: domain.StatusApproved}
+	for _, loan := range []*domain.Loan{approved, invested, untouched} {
+		require.NoError(t, db.Create(loan).Error)
+	}
+
+	investments := []domain.Investment{
+		{LoanID: approved.ID, InvestorID: "investor_001", Amount: domain.NewMoney(5000.00)},
+		{LoanID: approved.ID, InvestorID: "investor_001", Amount: domain.NewMoney(2500.00)},
+		{LoanID: approved.ID, InvestorID: "investor_002", Amount: domain.NewMoney(1000.00)},
+		{LoanID: invested.ID, InvestorID: "investor_001", Amount: domain.NewMoney(10000.00)},
+		{LoanID: untouched.ID, InvestorID: "investor_002", Amount: domain.NewMoney(1000.00)},
+	}
+	for i := range investments {
+		require.NoError(t, db.Create(&investments[i]).Error)
+	}
+
+	positions, total, err := repo.FindByInvestor("investor_001", "", 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	require.Len(t, positions, 2)
+	assert.Equal(t, approved.ID, positions[0].Loan.ID)
+	assert.Equal(t, 7500.00, positions[0].Contribution)
+	assert.Len(t, positions[0].Loan.Investments, 3)
+
+	positions, total, err = repo.FindByInvestor("investor_001", string(domain.StatusApproved), 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	require.Len(t, positions, 1)
+	assert.Equal(t, approved.ID, positions[0].Loan.ID)
+
+	positions, total, err = repo.FindByInvestor("investor_001", "", 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	require.Len(t, positions, 1)
+	assert.Equal(t, invested.ID, positions[0].Loan.ID)
+
+	positions, total, err = repo.FindByInvestor("investor_999", "", 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), total)
+	assert.Empty(t, positions)
+}
+
+func TestFindEventsByBorrower(t *testing.T) {
+	repo, db := setupTestRepository()
+
+	first := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: 6.0}
+	second := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(10000.00), Rate: 4.5, ROI: 6.0}
+	deleted := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(10000.00), Rate: 4.5, ROI: 6.0}
+	other := &domain.Loan{BorrowerID: "user456", PrincipalAmount: domain.NewMoney(10000.00), Rate: 4.5, ROI: 6.0}
+	for _, loan := range []*domain.Loan{first, second, deleted, other} {
+		require.NoError(t, db.Create(loan).Error)
+	}
+	require.NoError(t, db.Delete(deleted).Error)
+
+	start := time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC)
+	events := []domain.LoanEvent{
+		{LoanID: first.ID, Version: 2, ToStatus: domain.StatusApproved, Action: "approve", Timestamp: start.Add(2 * time.Hour)},
+		{LoanID: first.ID, Version: 1, ToStatus: domain.StatusProposed, Action: "create", Timestamp: start},
+		{LoanID: second.ID, Version: 1, ToStatus: domain.StatusProposed, Action: "create", Timestamp: start.Add(time.Hour)},
+		{LoanID: deleted.ID, Version: 1, ToStatus: domain.StatusProposed, Action: "create", Timestamp: start},
+		{LoanID: other.ID, Version: 1, ToStatus: domain.StatusProposed, Action: "create", Timestamp: start},
+	}
+	for i := range events {
+		require.NoError(t, db.Create(&events[i]).Error)
+	}
+
+	found, total, err := repo.FindEventsByBorrower("user123", 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+	require.Len(t, found, 3)
+	assert.Equal(t, first.ID, found[0].LoanID)
+	assert.Equal(t, "create", found[0].Action)
+	assert.Equal(t, second.ID, found[1].LoanID)
+	assert.Equal(t, "approve", found[2].Action)
+
+	found, total, err = repo.FindEventsByBorrower("user123", 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+	require.Len(t, found, 1)
+	assert.Equal(t, second.ID, found[0].LoanID)
+
+	found, total, err = repo.FindEventsByBorrower("user999", 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), total)
+	assert.Empty(t, found)
+}
+
+func TestLoanVersions(t *testing.T) {
+	repo, _ := setupTestRepository()
+
+	loan := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: 6.0}
+	require.NoError(t, repo.Create(loan))
+
+	loan.Rate = 5.0
+	require.NoError(t, repo.Update(loan))
+
+	loan.Status = domain.StatusApproved
+	loan.ApprovalDetails = &domain.ApprovalDetails{FieldValidatorProof: domain.ProofLinks{"proof"}, FieldValidatorID: "validator_001", ApprovalDate: time.Now()}
+	approved, err := repo.Approve(loan, domain.StatusProposed)
+	require.NoError(t, err)
+	require.True(t, approved)
+
+	first, err := repo.FindVersion(loan.ID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 4.5, first.Rate)
+	assert.Equal(t, domain.StatusProposed, first.Status)
+
+	second, err := repo.FindVersion(loan.ID, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 5.0, second.Rate)
+
+	third, err := repo.FindVersion(loan.ID, 3)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusApproved, third.Status)
+	assert.Equal(t, "validator_001", third.FieldValidatorID)
+
+	// A losing concurrent approval records no version
+	approved, err = repo.Approve(loan, domain.StatusProposed)
+	require.NoError(t, err)
+	assert.False(t, approved)
+
+	_, err = repo.FindVersion(loan.ID, 4)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestFindByApprovalProof(t *testing.T) {
+	repo, db := setupTestRepository()
+
+	approved := &domain.Loan{
+		BorrowerID:      "user123",
+		PrincipalAmount: domain.NewMoney(25000.00),
+		Rate:            4.5,
+		ROI:             6.0,
+		Status:          domain.StatusApproved,
+		ApprovalDetails: &domain.ApprovalDetails{
+			FieldValidatorProof: domain.ProofLinks{"https://example.com/front.jpg", "https://example.com/back_100%.jpg"},
+			FieldValidatorID:    "validator_001",
+			ApprovalDate:        time.Now(),
+		},
+	}
+	require.NoError(t, repo.Create(approved))
+
+	// Approved with a single proof before proofs were stored as a list
+	legacy := &domain.Loan{BorrowerID: "user456", PrincipalAmount: domain.NewMoney(10000.00), Rate: 4.5, ROI: 6.0, Status: domain.StatusApproved}
+	require.NoError(t, repo.Create(legacy))
+	require.NoError(t, db.Model(legacy).UpdateColumn("field_validator_proof", "https://example.com/legacy.jpg").Error)
+
+	found, err := repo.FindByApprovalProof(domain.ProofLinks{"https://example.com/other.jpg", "https://example.com/back_100%.jpg"}, "")
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, approved.ID, found.ID)
+
+	found, err = repo.FindByApprovalProof(domain.ProofLinks{"https://example.com/legacy.jpg"}, "")
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, legacy.ID, found.ID)
+
+	// Wildcards in links match literally, and the loan itself is excluded
+	found, err = repo.FindByApprovalProof(domain.ProofLinks{"https://example.com/back_1%"}, "")
+	require.NoError(t, err)
+	assert.Nil(t, found)
+
+	found, err = repo.FindByApprovalProof(domain.ProofLinks{"https://example.com/front.jpg"}, approved.ID)
+	require.NoError(t, err)
+	assert.Nil(t, found)
+}
+
+func TestInvestmentsPreloadedInOrder(t *testing.T) {
+	repo, db := setupTestRepository()
+
+	loan := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(10000.00), Rate: 4.5, ROI: 6.0, Status: domain.StatusApproved}
+	require.NoError(t, repo.Create(loan))
+
+	// Inserted out of order
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	for _, offset := range []int{2, 0, 1} {
+		require.NoError(t, db.Create(&domain.Investment{
+			LoanID:     loan.ID,
+			InvestorID: fmt.Sprintf("investor_%03d", offset),
+			Amount:     domain.NewMoney(1000.00),
+			CreatedAt:  start.Add(time.Duration(offset) * time.Minute),
+		}).Error)
+	}
+
+	found, err := repo.FindByID(loan.ID)
+	require.NoError(t, err)
+	loans, err := repo.FindAll(map[string]interface{}{})
+	require.NoError(t, err)
+	require.Len(t, loans, 1)
+	for _, investments := range [][]domain.Investment{found.Investments, loans[0].Investments} {
+		require.Len(t, investments, 3)
+		for i, investment := range investments {
+			assert.Equal(t, fmt.Sprintf("investor_%03d", i), investment.InvestorID)
+		}
+	}
+}
+
+// recordSQL records the SQL of the queries, updates and deletes run on db
+func recordSQL(t *testing.T, db *gorm.DB) *[]string {
+	var statements []string
+	record := func(tx *gorm.DB) {
+		statements = append(statements, tx.Statement.SQL.String())
+	}
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:record_sql", record))
+	require.NoError(t, db.Callback().Update().After("gorm:update").Register("test:record_sql", record))
+	require.NoError(t, db.Callback().Delete().After("gorm:delete").Register("test:record_sql", record))
+	return &statements
+}
+
+func TestIdempotencyKeyColumnIsQuoted(t *testing.T) {
+	repo, db := setupTestRepository()
+
+	loan := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: 6.0}
+	key := &domain.IdempotencyKey{BorrowerID: "user123", Key: "key-1", ExpiresAt: time.Now().Add(time.Hour)}
+	require.NoError(t, repo.CreateWithIdempotencyKey(loan, key))
+
+	// key is a reserved word in MySQL and must be quoted
+	statements := recordSQL(t, db)
+	found, err := repo.FindIdempotencyKey("user123", "key-1")
+	require.NoError(t, err)
+	assert.Equal(t, loan.ID, found.LoanID)
+	require.NoError(t, repo.DeleteIdempotencyKey("user123", "key-1"))
+
+	require.Len(t, *statements, 2)
+	for _, statement := range *statements {
+		assert.Contains(t, statement, "`key` = ")
+	}
+	_, err = repo.FindIdempotencyKey("user123", "key-1")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
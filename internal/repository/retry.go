@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"strings"
+	"time"
+)
+
+// RetryPolicy controls how writes failing with a transient database error are retried: up to MaxRetries
+// more times, waiting BaseDelay before the first retry and doubling the wait each time up to MaxDelay.
+// The zero policy does not retry.
+type RetryPolicy struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+}
+
+// retryableMessages are fragments of the errors SQLite, PostgreSQL and MySQL report for failures that
+// can succeed when the write is attempted again
+var retryableMessages = []string{
+	"database is locked",
+	"database table is locked",
+	"could not serialize access",
+	"deadlock detected",
+	"deadlock found",
+	"lock wait timeout exceeded",
+	"connection reset by peer",
+	"connection refused",
+	"broken pipe",
+}
+
+// isRetryable reports whether err is a transient failure, such as a locked SQLite database or a dropped
+// connection. Constraint violations, missing records and conflicting versions are not.
+func isRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	message := strings.ToLower(err.Error())
+	for _, fragment := range retryableMessages {
+		if strings.Contains(message, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// do runs write, retrying it with exponential backoff while it fails with a retryable error. Other
+// errors are returned at once, as is the last error when ctx is done while waiting for a retry.
+func (p RetryPolicy) do(ctx context.Context, write func() error) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	delay := p.BaseDelay
+	err := write()
+	for attempt := 0; attempt < p.MaxRetries && isRetryable(err); attempt++ {
+		if p.MaxDelay > 0 && delay > p.MaxDelay {
+			delay = p.MaxDelay
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+
+		delay *= 2
+		err = write()
+	}
+	return err
+}
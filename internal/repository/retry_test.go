@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"loan-service/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// errLocked is the error SQLite reports when another connection holds the write lock
+var errLocked = errors.New("database is locked (5) (SQLITE_BUSY)")
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		retryable bool
+	}{
+		{"sqlite busy", errLocked, true},
+		{"postgres serialization failure", errors.New("ERROR: could not serialize access due to concurrent update (SQLSTATE 40001)"), true},
+		{"postgres deadlock", errors.New("ERROR: deadlock detected (SQLSTATE 40P01)"), true},
+		{"bad connection", fmt.Errorf("commit: %w", driver.ErrBadConn), true},
+		{"connection reset", errors.New("read tcp 10.0.0.2:5432: connection reset by peer"), true},
+		{"unique constraint", errors.New("UNIQUE constraint failed: loans.id"), false},
+		{"not found", gorm.ErrRecordNotFound, false},
+		{"concurrent update", domain.ErrConcurrentUpdate, false},
+		{"cancelled", context.Canceled, false},
+		{"deadline", context.DeadlineExceeded, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.retryable, isRetryable(tt.err))
+		})
+	}
+}
+
+func TestRetryPolicy(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond}
+
+	// failing returns a write that fails with err the given number of times before succeeding
+	failing := func(failures int, err error) (func() error, *int) {
+		attempts := 0
+		return func() error {
+			attempts++
+			if attempts <= failures {
+				return err
+			}
+			return nil
+		}, &attempts
+	}
+
+	write, attempts := failing(1, errLocked)
+	require.NoError(t, policy.do(context.Background(), write))
+	assert.Equal(t, 2, *attempts)
+
+	// Non-retryable errors pass through immediately
+	write, attempts = failing(1, gorm.ErrRecordNotFound)
+	assert.ErrorIs(t, policy.do(context.Background(), write), gorm.ErrRecordNotFound)
+	assert.Equal(t, 1, *attempts)
+
+	// Retries stop after MaxRetries with the last error
+	write, attempts = failing(10, errLocked)
+	assert.ErrorIs(t, policy.do(context.Background(), write), errLocked)
+	assert.Equal(t, 4, *attempts)
+
+	// The zero policy does not retry
+	write, attempts = failing(1, errLocked)
+	assert.ErrorIs(t, RetryPolicy{}.do(context.Background(), write), errLocked)
+	assert.Equal(t, 1, *attempts)
+
+	// A done context stops the backoff
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	write, attempts = failing(1, errLocked)
+	slow := RetryPolicy{MaxRetries: 3, BaseDelay: time.Hour}
+	assert.ErrorIs(t, slow.do(ctx, write), errLocked)
+	assert.Equal(t, 1, *attempts)
+}
+
+// injectInsertErrors makes the next inserts into each table fail with the errors queued for it, before
+// they reach the database
+func injectInsertErrors(t *testing.T, db *gorm.DB) map[string][]error {
+	queued := map[string][]error{}
+	require.NoError(t, db.Callback().Create().Before("gorm:create").Register("test:inject_errors", func(tx *gorm.DB) {
+		if errs := queued[tx.Statement.Table]; len(errs) > 0 {
+			queued[tx.Statement.Table] = errs[1:]
+			_ = tx.AddError(errs[0])
+		}
+	}))
+	return queued
+}
+
+func TestLoanRepositoryRetriesWrites(t *testing.T) {
+	repo, db := setupTestRepository()
+	repo = repo.WithRetry(RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond})
+	queued := injectInsertErrors(t, db)
+
+	loan := &domain.Loan{
+		BorrowerID:      "user123",
+		PrincipalAmount: domain.NewMoney(10000.00),
+		Rate:            4.5,
+		ROI:             6.0,
+		Status:          domain.StatusProposed,
+	}
+	queued["loans"] = []error{errLocked}
+	require.NoError(t, repo.Create(loan))
+	assert.Empty(t, queued["loans"])
+
+	// The audit event insert fails after the loan row was saved with a new version, so the retry
+	// must start again from the version and queued events the loan had before the write
+	loan.Status = domain.StatusApproved
+	loan.RecordTransition(domain.StatusProposed, "approve", "validator_001", time.Now(), nil)
+	queued["loan_events"] = []error{errLocked}
+	require.NoError(t, repo.Update(loan))
+	assert.Empty(t, queued["loan_events"])
+	assert.Equal(t, uint(1), loan.Version)
+
+	stored, err := repo.FindByID(loan.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusApproved, stored.Status)
+	assert.Equal(t, uint(1), stored.Version)
+
+	events, err := repo.FindEvents(loan.ID)
+	require.NoError(t, err)
+	var approvals int
+	for _, event := range events {
+		if event.Action == "approve" {
+			approvals++
+		}
+	}
+	assert.Equal(t, 1, approvals)
+
+	// Non-retryable errors are returned without retrying
+	constraint := errors.New("UNIQUE constraint failed: loan_events.id")
+	stored.RecordTransition(domain.StatusApproved, "update", "", time.Now(), nil)
+	queued["loan_events"] = []error{constraint, errLocked}
+	assert.ErrorIs(t, repo.Update(stored), constraint)
+	assert.Len(t, queued["loan_events"], 1)
+	queued["loan_events"] = nil
+
+	// A transaction failing with a transient error is run again from the start
+	attempts := 0
+	queued["loans"] = []error{errLocked}
+	require.NoError(t, repo.Transaction(func(tx LoanRepository) error {
+		attempts++
+		return tx.Create(&domain.Loan{BorrowerID: "user456", PrincipalAmount: domain.NewMoney(5000.00), Rate: 4.5, ROI: 6.0, Status: domain.StatusProposed})
+	}))
+	assert.Equal(t, 2, attempts)
+	assert.Empty(t, queued["loans"])
+}
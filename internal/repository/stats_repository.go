@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"loan-service/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// Groupings supported by portfolio aggregations
+const (
+	GroupByNone     = ""
+	GroupByBorrower = "borrower"
+	GroupByMonth    = "month"
+)
+
+// StatsRepository defines the interface for portfolio aggregation queries
+type StatsRepository interface {
+	OutstandingBalances(groupBy string) ([]domain.OutstandingBalance, error)
+	PlatformStats() (*domain.PlatformStats, error)
+	LoanAggregate() (*domain.LoanAggregate, error)
+}
+
+// fundedPrincipal is the SQL expression of the principal a loan was funded with: the amount disbursed,
+// or the full principal for loans disbursed before partial disbursements were recorded
+const fundedPrincipal = "CASE WHEN disbursed_amount > 0 THEN disbursed_amount ELSE principal_amount END"
+
+// statsRepository implements StatsRepository
+type statsRepository struct {
+	db *gorm.DB
+}
+
+// NewStatsRepository creates a new stats repository
+func NewStatsRepository(db *gorm.DB) StatsRepository {
+	return &statsRepository{db: db}
+}
+
+// OutstandingBalances sums the outstanding balance of disbursed loans, optionally grouped by
+// borrower or by month of disbursement. A loan's outstanding balance is its funded principal less what
+// has been repaid so far, and never drops below zero.
+func (r *statsRepository) OutstandingBalances(groupBy string) ([]domain.OutstandingBalance, error) {
+	var group string
+	switch groupBy {
+	case GroupByBorrower:
+		group = "borrower_id"
+	case GroupByMonth:
+		group = monthExpression(r.db, "disbursement_date")
+	default:
+		group = "''"
+	}
+
+	outstanding := "CASE WHEN " + fundedPrincipal + " > total_repaid THEN " + fundedPrincipal + " - total_repaid ELSE 0 END"
+
+	var balances []domain.OutstandingBalance
+	query := r.db.Model(&domain.Loan{}).
+		Select(group+" AS \"group\", COUNT(*) AS loans, COALESCE(SUM(principal_amount), 0) AS principal, COALESCE(SUM("+outstanding+"), 0) AS outstanding").
+		Where("status = ?", domain.StatusDisbursed)
+	if groupBy != GroupByNone {
+		query = query.Group(group).Order(group)
+	}
+
+	err := query.Scan(&balances).Error
+	return balances, err
+}
+
+// monthExpression returns the SQL expression formatting a timestamp column as its year and month
+// (e.g. 2024-01) in the dialect of the database
+func monthExpression(db *gorm.DB, column string) string {
+	switch db.Dialector.Name() {
+	case "mysql":
+		return "DATE_FORMAT(" + column + ", '%Y-%m')"
+	case "postgres":
+		return "TO_CHAR(" + column + ", 'YYYY-MM')"
+	default:
+		return "strftime('%Y-%m', " + column + ")"
+	}
+}
+
+// PlatformStats computes loan counts by status together with platform-wide totals and averages
+func (r *statsRepository) PlatformStats() (*domain.PlatformStats, error) {
+	stats := &domain.PlatformStats{CountsByStatus: make(map[domain.LoanStatus]int64)}
+
+	err := r.db.Model(&domain.Loan{}).
+		Select("COUNT(*) AS total_loans, " +
+			"COALESCE(SUM(principal_amount), 0) AS total_principal, " +
+			"COALESCE(SUM(total_invested), 0) AS total_invested, " +
+			"COALESCE(AVG(principal_amount), 0) AS average_principal, " +
+			"COALESCE(AVG(rate), 0) AS average_rate, " +
+			"COALESCE(AVG(roi), 0) AS average_roi").
+		Scan(stats).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.countByStatus(stats.CountsByStatus); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// LoanAggregate computes loan counts by status, the funded principal of disbursed loans, the total
+// invested and the average ROI
+func (r *statsRepository) LoanAggregate() (*domain.LoanAggregate, error) {
+	aggregate := &domain.LoanAggregate{CountsByStatus: make(map[domain.LoanStatus]int64)}
+
+	err := r.db.Model(&domain.Loan{}).
+		Select("COUNT(*) AS total_loans, "+
+			"COALESCE(SUM(CASE WHEN status = ? THEN "+fundedPrincipal+" ELSE 0 END), 0) AS total_principal_outstanding, "+
+			"COALESCE(SUM(total_invested), 0) AS total_invested, "+
+			"COALESCE(AVG(roi), 0) AS average_roi", domain.StatusDisbursed).
+		Scan(aggregate).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.countByStatus(aggregate.CountsByStatus); err != nil {
+		return nil, err
+	}
+	return aggregate, nil
+}
+
+// countByStatus fills counts with the number of loans in each status
+func (r *statsRepository) countByStatus(counts map[domain.LoanStatus]int64) error {
+	var rows []struct {
+		Status domain.LoanStatus
+		Count  int64
+	}
+	err := r.db.Model(&domain.Loan{}).
+		Select("status, COUNT(*) AS count").
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return nil
+}
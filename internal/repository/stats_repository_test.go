@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"loan-service/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutstandingBalances(t *testing.T) {
+	_, db := setupTestRepository()
+	repo := NewStatsRepository(db)
+
+	disbursed := []struct {
+		borrowerID string
+		principal  float64
+		date       time.Time
+	}{
+		{"user123", 10000.00, time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)},
+		{"user123", 5000.00, time.Date(2024, 2, 3, 0, 0, 0, 0, time.UTC)},
+		{"user456", 20000.00, time.Date(2024, 2, 20, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, d := range disbursed {
+		loan := &domain.Loan{
+			BorrowerID:          d.borrowerID,
+			PrincipalAmount:     domain.NewMoney(d.principal),
+			Rate:                4.5,
+			ROI:                 6.0,
+			Status:              domain.StatusDisbursed,
+			DisbursementDetails: &domain.DisbursementDetails{DisbursementDate: d.date},
+		}
+		require.NoError(t, db.Create(loan).Error)
+	}
+
+	// Loans that are not disbursed are not outstanding
+	approved := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(7000.00), Rate: 4.5, ROI: 6.0, Status: domain.StatusApproved}
+	require.NoError(t, db.Create(approved).Error)
+
+	balances, err := repo.OutstandingBalances(GroupByNone)
+	require.NoError(t, err)
+	require.Len(t, balances, 1)
+	assert.Equal(t, int64(3), balances[0].Loans)
+	assert.Equal(t, 35000.00, balances[0].Outstanding)
+
+	balances, err = repo.OutstandingBalances(GroupByBorrower)
+	require.NoError(t, err)
+	require.Len(t, balances, 2)
+	assert.Equal(t, "user123", balances[0].Group)
+	assert.Equal(t, 15000.00, balances[0].Outstanding)
+	assert.Equal(t, "user456", balances[1].Group)
+
+	balances, err = repo.OutstandingBalances(GroupByMonth)
+	require.NoError(t, err)
+	require.Len(t, balances, 2)
+	assert.Equal(t, "2024-01", balances[0].Group)
+	assert.Equal(t, 10000.00, balances[0].Outstanding)
+	assert.Equal(t, "2024-02", balances[1].Group)
+	assert.Equal(t, int64(2), balances[1].Loans)
+	assert.Equal(t, 25000.00, balances[1].Outstanding)
+}
+
+func TestOutstandingBalancesPartialDisbursement(t *testing.T) {
+	_, db := setupTestRepository()
+	repo := NewStatsRepository(db)
+
+	loan := &domain.Loan{
+		BorrowerID:      "user123",
+		PrincipalAmount: domain.NewMoney(10000.00),
+		Rate:            4.5,
+		ROI:             6.0,
+		Status:          domain.StatusDisbursed,
+		DisbursementDetails: &domain.DisbursementDetails{
+			DisbursementDate: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+			DisbursedAmount:  domain.NewMoney(8000.00),
+		},
+	}
+	require.NoError(t, db.Create(loan).Error)
+
+	// Only the funded principal is outstanding
+	balances, err := repo.OutstandingBalances(GroupByNone)
+	require.NoError(t, err)
+	require.Len(t, balances, 1)
+	assert.Equal(t, 10000.00, balances[0].Principal)
+	assert.Equal(t, 8000.00, balances[0].Outstanding)
+}
+
+func TestOutstandingBalancesPartialRepayment(t *testing.T) {
+	_, db := setupTestRepository()
+	repo := NewStatsRepository(db)
+
+	disbursedAt := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	repaying := &domain.Loan{
+		BorrowerID:          "user123",
+		PrincipalAmount:     domain.NewMoney(10000.00),
+		Rate:                4.5,
+		ROI:                 6.0,
+		Status:              domain.StatusDisbursed,
+		DisbursementDetails: &domain.DisbursementDetails{DisbursementDate: disbursedAt},
+		TotalRepaid:         domain.NewMoney(2500.00),
+	}
+	// Interest can take the repayments past the principal
+	overpaid := &domain.Loan{
+		BorrowerID:          "user456",
+		PrincipalAmount:     domain.NewMoney(5000.00),
+		Rate:                4.5,
+		ROI:                 6.0,
+		Status:              domain.StatusDisbursed,
+		DisbursementDetails: &domain.DisbursementDetails{DisbursementDate: disbursedAt},
+		TotalRepaid:         domain.NewMoney(5100.00),
+	}
+	require.NoError(t, db.Create(repaying).Error)
+	require.NoError(t, db.Create(overpaid).Error)
+
+	balances, err := repo.OutstandingBalances(GroupByBorrower)
+	require.NoError(t, err)
+	require.Len(t, balances, 2)
+	assert.Equal(t, 10000.00, balances[0].Principal)
+	assert.Equal(t, 7500.00, balances[0].Outstanding)
+	assert.Equal(t, 0.0, balances[1].Outstanding)
+}
+
+func TestOutstandingBalancesEmpty(t *testing.T) {
+	_, db := setupTestRepository()
+	repo := NewStatsRepository(db)
+
+	balances, err := repo.OutstandingBalances(GroupByNone)
+	require.NoError(t, err)
+	require.Len(t, balances, 1)
+	assert.Equal(t, int64(0), balances[0].Loans)
+	assert.Equal(t, 0.0, balances[0].Outstanding)
+}
+
+func TestPlatformStats(t *testing.T) {
+	_, db := setupTestRepository()
+	repo := NewStatsRepository(db)
+
+	loans := []*domain.Loan{
+		{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(10000.00), Rate: 4.0, ROI: 6.0, Status: domain.StatusProposed},
+		{BorrowerID: "user456", PrincipalAmount: domain.NewMoney(20000.00), Rate: 5.0, ROI: 7.0, Status: domain.StatusApproved, TotalInvested: domain.NewMoney(5000.00)},
+		{BorrowerID: "user789", PrincipalAmount: domain.NewMoney(30000.00), Rate: 6.0, ROI: 8.0, Status: domain.StatusApproved, TotalInvested: domain.NewMoney(1000.00)},
+	}
+	for _, loan := range loans {
+		require.NoError(t, db.Create(loan).Error)
+	}
+
+	stats, err := repo.PlatformStats()
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(3), stats.TotalLoans)
+	assert.Equal(t, 60000.00, stats.TotalPrincipal)
+	assert.Equal(t, 6000.00, stats.TotalInvested)
+	assert.Equal(t, 20000.00, stats.AveragePrincipal)
+	assert.Equal(t, 5.0, stats.AverageRate)
+	assert.Equal(t, 7.0, stats.AverageROI)
+	assert.Equal(t, int64(1), stats.CountsByStatus[domain.StatusProposed])
+	assert.Equal(t, int64(2), stats.CountsByStatus[domain.StatusApproved])
+}
+
+func TestLoanAggregate(t *testing.T) {
+	repo, db := setupTestRepository()
+
+	loans := []*domain.Loan{
+		{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(10000.00), Rate: 4.0, ROI: 6.0, Status: domain.StatusProposed},
+		{BorrowerID: "user456", PrincipalAmount: domain.NewMoney(20000.00), Rate: 5.0, ROI: 7.0, Status: domain.StatusApproved, TotalInvested: domain.NewMoney(5000.00)},
+		{BorrowerID: "user789", PrincipalAmount: domain.NewMoney(30000.00), Rate: 6.0, ROI: 8.0, Status: domain.StatusDisbursed, TotalInvested: domain.NewMoney(30000.00)},
+		{
+			BorrowerID: "user789", PrincipalAmount: domain.NewMoney(15000.00), Rate: 6.0, ROI: 9.0, Status: domain.StatusDisbursed, TotalInvested: domain.NewMoney(12000.00),
+			DisbursementDetails: &domain.DisbursementDetails{DisbursedAmount: domain.NewMoney(12000.00)},
+		},
+		{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(5000.00), Rate: 4.0, ROI: 6.0, Status: domain.StatusRepaid, TotalInvested: domain.NewMoney(5000.00)},
+	}
+	for _, loan := range loans {
+		require.NoError(t, db.Create(loan).Error)
+	}
+
+	// Soft-deleted loans are excluded
+	deleted := &domain.Loan{BorrowerID: "user999", PrincipalAmount: domain.NewMoney(99000.00), Rate: 9.0, ROI: 20.0, Status: domain.StatusDisbursed, TotalInvested: domain.NewMoney(99000.00)}
+	require.NoError(t, db.Create(deleted).Error)
+	require.NoError(t, repo.Delete(deleted.ID))
+
+	aggregate, err := NewStatsRepository(db).LoanAggregate()
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(5), aggregate.TotalLoans)
+	assert.Equal(t, map[domain.LoanStatus]int64{
+		domain.StatusProposed:  1,
+		domain.StatusApproved:  1,
+		domain.StatusDisbursed: 2,
+		domain.StatusRepaid:    1,
+	}, aggregate.CountsByStatus)
+	// Only the funded principal of disbursed loans is outstanding
+	assert.Equal(t, domain.NewMoney(42000.00), aggregate.TotalPrincipalOutstanding)
+	assert.Equal(t, domain.NewMoney(52000.00), aggregate.TotalInvested)
+	assert.Equal(t, 7.2, aggregate.AverageROI)
+}
+
+func TestLoanAggregateEmpty(t *testing.T) {
+	_, db := setupTestRepository()
+
+	aggregate, err := NewStatsRepository(db).LoanAggregate()
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(0), aggregate.TotalLoans)
+	assert.Empty(t, aggregate.CountsByStatus)
+	assert.NotNil(t, aggregate.CountsByStatus)
+	assert.Equal(t, domain.Money(0), aggregate.TotalPrincipalOutstanding)
+	assert.Equal(t, domain.Money(0), aggregate.TotalInvested)
+	assert.Equal(t, 0.0, aggregate.AverageROI)
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"loan-service/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+// WebhookRepository defines the interface for webhook subscription data operations
+type WebhookRepository interface {
+	CreateSubscription(subscription *domain.WebhookSubscription) error
+	FindSubscriptions() ([]domain.WebhookSubscription, error)
+	DeleteSubscription(id string) (bool, error)
+}
+
+// webhookRepository implements WebhookRepository
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+// NewWebhookRepository creates a new webhook repository
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+// CreateSubscription creates a new webhook subscription
+func (r *webhookRepository) CreateSubscription(subscription *domain.WebhookSubscription) error {
+	return r.db.Create(subscription).Error
+}
+
+// FindSubscriptions finds all webhook subscriptions, oldest first
+func (r *webhookRepository) FindSubscriptions() ([]domain.WebhookSubscription, error) {
+	var subscriptions []domain.WebhookSubscription
+	err := r.db.Order("created_at").Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+// DeleteSubscription deletes a webhook subscription. It returns false when no subscription has the ID.
+func (r *webhookRepository) DeleteSubscription(id string) (bool, error) {
+	result := r.db.Delete(&domain.WebhookSubscription{}, "id = ?", id)
+	return result.RowsAffected > 0, result.Error
+}
@@ -0,0 +1,54 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// Scheduler runs background jobs periodically until it is stopped
+type Scheduler struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// New creates a new scheduler
+func New() *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{ctx: ctx, cancel: cancel}
+}
+
+// Every runs job every interval in the background. A non-positive interval disables the job.
+func (s *Scheduler) Every(name string, interval time.Duration, job func() error) {
+	if interval <= 0 {
+		log.Printf("Background job %s disabled", name)
+		return
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-ticker.C:
+				if err := job(); err != nil {
+					log.Printf("Background job %s failed: %v", name, err)
+				}
+			}
+		}
+	}()
+}
+
+// Stop stops all jobs and waits for any running job to finish
+func (s *Scheduler) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
@@ -0,0 +1,43 @@
+package scheduler
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchedulerRunsJobUntilStopped(t *testing.T) {
+	s := New()
+
+	var runs int32
+	s.Every("test", 5*time.Millisecond, func() error {
+		atomic.AddInt32(&runs, 1)
+		return nil
+	})
+
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&runs) >= 2
+	}, time.Second, 5*time.Millisecond)
+
+	s.Stop()
+	stopped := atomic.LoadInt32(&runs)
+
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, stopped, atomic.LoadInt32(&runs))
+}
+
+func TestSchedulerDisabledJob(t *testing.T) {
+	s := New()
+
+	var runs int32
+	s.Every("disabled", 0, func() error {
+		atomic.AddInt32(&runs, 1)
+		return nil
+	})
+
+	time.Sleep(10 * time.Millisecond)
+	s.Stop()
+	assert.Equal(t, int32(0), atomic.LoadInt32(&runs))
+}
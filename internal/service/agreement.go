@@ -0,0 +1,131 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+
+	"loan-service/internal/domain"
+)
+
+// AgreementGenerator produces the agreement letter for a fully invested loan
+type AgreementGenerator interface {
+	Generate(loan *domain.Loan) (string, error)
+}
+
+// defaultAgreementLinkTemplate is the agreement letter link used when no template is configured
+const defaultAgreementLinkTemplate = "https://example.com/agreements/loan_{id}_agreement.pdf"
+
+// linkAgreementGenerator generates agreement letter links from a template holding an {id} or %s
+// placeholder for the loan ID
+type linkAgreementGenerator struct {
+	template string
+}
+
+// Generate returns the agreement letter link for the loan
+func (g linkAgreementGenerator) Generate(loan *domain.Loan) (string, error) {
+	return generateAgreementLetterLink(g.template, loan.ID), nil
+}
+
+// generateAgreementLetterLink fills the loan ID into the link template, falling back to the default template
+func generateAgreementLetterLink(template, loanID string) string {
+	if template == "" {
+		template = defaultAgreementLinkTemplate
+	}
+	return strings.NewReplacer("{id}", loanID, "%s", loanID).Replace(template)
+}
+
+// signedAgreementMatches reports whether the signed agreement link references the loan, that is whether
+// the loan ID appears in its path as it does in the generated agreement letter link
+func signedAgreementMatches(link, loanID string) bool {
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+	return strings.Contains(parsed.Path, loanID)
+}
+
+// generateAgreement attempts to generate the loan's agreement, recording the attempt and any error.
+// A failed generation leaves the loan without an agreement so it can be retried later.
+func (s *loanService) generateAgreement(loan *domain.Loan) {
+	loan.AgreementAttempts++
+
+	link, err := s.agreements.Generate(loan)
+	if err != nil {
+		loan.AgreementLastError = err.Error()
+		return
+	}
+
+	loan.AgreementLetterLink = link
+	loan.AgreementLastError = ""
+}
+
+// RegenerateAgreement (re)generates the agreement for an invested or disbursed loan.
+// Loans that already have a valid agreement are only regenerated when force is set.
+func (s *loanService) RegenerateAgreement(id string, force bool) (*domain.Loan, error) {
+	loan, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !loan.CanGenerateAgreement() {
+		return nil, domain.ErrAgreementNotAvailable
+	}
+
+	if loan.HasValidAgreement() && !force {
+		return nil, domain.ErrAgreementExists
+	}
+
+	if force {
+		loan.AgreementLetterLink = ""
+	}
+	s.generateAgreement(loan)
+
+	if err := s.repo.Update(loan); err != nil {
+		return nil, err
+	}
+
+	if loan.AgreementLetterLink == "" {
+		return nil, fmt.Errorf("%w: %s", ErrAgreementGenerationFailed, loan.AgreementLastError)
+	}
+
+	return loan, nil
+}
+
+// GetAgreementData returns the structured agreement content of an invested or disbursed loan
+func (s *loanService) GetAgreementData(id string) (*domain.AgreementData, error) {
+	loan, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return loan.AgreementData()
+}
+
+// RetryMissingAgreements retries agreement generation for every loan that is missing one, skipping loans
+// that have used up their configured attempts. A loan that cannot be saved is logged and left for the next
+// sweep. It returns the number of agreements generated successfully.
+func (s *loanService) RetryMissingAgreements() (int, error) {
+	loans, err := s.repo.FindMissingAgreements()
+	if err != nil {
+		return 0, err
+	}
+
+	generated := 0
+	for i := range loans {
+		loan := &loans[i]
+		if s.cfg.MaxAgreementAttempts > 0 && loan.AgreementAttempts >= s.cfg.MaxAgreementAttempts {
+			continue
+		}
+		s.generateAgreement(loan)
+		if err := s.repo.Update(loan); err != nil {
+			log.Printf("Agreement retry failed to save loan %s: %v", loan.ID, err)
+			continue
+		}
+		if loan.AgreementLetterLink != "" {
+			generated++
+		}
+	}
+
+	return generated, nil
+}
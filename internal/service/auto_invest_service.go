@@ -0,0 +1,165 @@
+package service
+
+import (
+	"errors"
+	"log"
+
+	"loan-service/internal/domain"
+	"loan-service/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// ErrBudgetBelowSpent is returned when a rule's budget is lowered below what it already invested
+var ErrBudgetBelowSpent = errors.New("total budget cannot be lower than the amount already invested")
+
+// AutoInvestService defines the interface for managing auto-invest rules
+type AutoInvestService interface {
+	CreateRule(rule *domain.AutoInvestRule) error
+	GetRules(investorID string) ([]domain.AutoInvestRule, error)
+	UpdateRule(investorID, ruleID string, updates map[string]interface{}) (*domain.AutoInvestRule, error)
+	DeleteRule(investorID, ruleID string) error
+	GetActions(investorID string) ([]domain.AutoInvestAction, error)
+}
+
+// autoInvestService implements AutoInvestService
+type autoInvestService struct {
+	repo repository.AutoInvestRepository
+}
+
+// NewAutoInvestService creates a new auto-invest service
+func NewAutoInvestService(repo repository.AutoInvestRepository) AutoInvestService {
+	return &autoInvestService{repo: repo}
+}
+
+// CreateRule creates a new auto-invest rule with its full budget available
+func (s *autoInvestService) CreateRule(rule *domain.AutoInvestRule) error {
+	rule.RemainingBudget = rule.TotalBudget
+	rule.Active = true
+	return s.repo.CreateRule(rule)
+}
+
+// GetRules retrieves the auto-invest rules of an investor
+func (s *autoInvestService) GetRules(investorID string) ([]domain.AutoInvestRule, error) {
+	return s.repo.FindRulesByInvestor(investorID)
+}
+
+// UpdateRule updates an investor's auto-invest rule. Only the given fields are written, and a new total
+// budget is applied relative to the stored one, so budget reserved by a concurrent auto-invest run is kept.
+func (s *autoInvestService) UpdateRule(investorID, ruleID string, updates map[string]interface{}) (*domain.AutoInvestRule, error) {
+	if _, err := s.findInvestorRule(investorID, ruleID); err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]interface{})
+	if minROI, ok := updates["min_roi"].(float64); ok {
+		columns["min_roi"] = minROI
+	}
+	if maxPerLoan, ok := updates["max_per_loan"].(float64); ok {
+		columns["max_per_loan"] = maxPerLoan
+	}
+	if totalBudget, ok := updates["total_budget"].(float64); ok {
+		columns["total_budget"] = totalBudget
+	}
+	if active, ok := updates["active"].(bool); ok {
+		columns["active"] = active
+	}
+
+	if len(columns) > 0 {
+		updated, err := s.repo.UpdateRule(ruleID, columns)
+		if err != nil {
+			return nil, err
+		}
+		if !updated {
+			return nil, ErrBudgetBelowSpent
+		}
+	}
+
+	return s.repo.FindRuleByID(ruleID)
+}
+
+// DeleteRule deletes an investor's auto-invest rule
+func (s *autoInvestService) DeleteRule(investorID, ruleID string) error {
+	if _, err := s.findInvestorRule(investorID, ruleID); err != nil {
+		return err
+	}
+	return s.repo.DeleteRule(ruleID)
+}
+
+// GetActions retrieves the log of investments made by an investor's rules
+func (s *autoInvestService) GetActions(investorID string) ([]domain.AutoInvestAction, error) {
+	return s.repo.FindActionsByInvestor(investorID)
+}
+
+// findInvestorRule finds a rule, treating rules of other investors as not found
+func (s *autoInvestService) findInvestorRule(investorID, ruleID string) (*domain.AutoInvestRule, error) {
+	rule, err := s.repo.FindRuleByID(ruleID)
+	if err != nil {
+		return nil, err
+	}
+	if rule.InvestorID != investorID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return rule, nil
+}
+
+// runAutoInvest executes matching auto-invest rules against a newly approved loan.
+// Budgets are reserved atomically before investing so concurrent approvals cannot over-commit a rule.
+func (s *loanService) runAutoInvest(loan *domain.Loan) (*domain.Loan, error) {
+	if s.autoInvest == nil {
+		return loan, nil
+	}
+
+	rules, err := s.autoInvest.FindActiveRules()
+	if err != nil {
+		return loan, err
+	}
+
+	invested := make(map[string]bool)
+	for i := range rules {
+		rule := &rules[i]
+		if !loan.CanInvest() {
+			break
+		}
+		if invested[rule.InvestorID] || !rule.Matches(loan) {
+			continue
+		}
+
+		amount := rule.AmountFor((loan.PrincipalAmount - loan.TotalInvested).Float64())
+		if amount <= 0 {
+			continue
+		}
+
+		reserved, err := s.autoInvest.ReserveBudget(rule.ID, amount)
+		if err != nil {
+			return loan, err
+		}
+		if !reserved {
+			// The budget was consumed by a concurrent approval
+			continue
+		}
+
+		updated, err := s.InvestInLoan(loan.ID, rule.InvestorID, domain.NewMoney(amount))
+		if err != nil {
+			log.Printf("Auto-invest rule %s skipped loan %s: %v", rule.ID, loan.ID, err)
+			if err := s.autoInvest.ReleaseBudget(rule.ID, amount); err != nil {
+				return loan, err
+			}
+			continue
+		}
+		loan = updated
+		invested[rule.InvestorID] = true
+
+		action := &domain.AutoInvestAction{
+			RuleID:     rule.ID,
+			InvestorID: rule.InvestorID,
+			LoanID:     loan.ID,
+			Amount:     amount,
+		}
+		if err := s.autoInvest.CreateAction(action); err != nil {
+			return loan, err
+		}
+	}
+
+	return loan, nil
+}
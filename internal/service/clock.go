@@ -0,0 +1,18 @@
+package service
+
+import (
+	"time"
+)
+
+// Clock provides the current time so time-dependent rules can be tested
+type Clock interface {
+	Now() time.Time
+}
+
+// systemClock implements Clock using the system time
+type systemClock struct{}
+
+// Now returns the current system time
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
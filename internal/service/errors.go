@@ -0,0 +1,206 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"loan-service/internal/domain"
+)
+
+// ErrAgreementGenerationFailed is returned when the agreement generator fails
+var ErrAgreementGenerationFailed = errors.New("agreement generation failed")
+
+// ErrCorrectionWindowClosed is returned when approval details are corrected after the grace period without an override
+var ErrCorrectionWindowClosed = errors.New("approval correction window has closed, admin override required")
+
+// ErrJustificationRequired is returned when a transition is forced without a justification
+var ErrJustificationRequired = errors.New("a justification is required to force a transition")
+
+// ErrTransitionNotForceable is returned when a transition that needs details only its regular action
+// takes, such as the disbursement of the funds, is forced
+var ErrTransitionNotForceable = errors.New("this transition must go through its regular action and cannot be forced")
+
+// ErrAgreementMismatch is returned when a loan is disbursed with a signed agreement link that does not reference the loan
+var ErrAgreementMismatch = errors.New("signed agreement link must reference the loan's agreement")
+
+// ErrInvalidGroupBy is returned when an aggregation is requested with an unsupported grouping
+var ErrInvalidGroupBy = errors.New("group_by must be one of: borrower, month")
+
+// FundingWindowClosedError is returned when an investment is attempted outside the funding window
+type FundingWindowClosedError struct {
+	NextOpen time.Time
+}
+
+// Error implements the error interface
+func (e *FundingWindowClosedError) Error() string {
+	return fmt.Sprintf("investments are not accepted at this time, next funding window opens at %s", e.NextOpen.Format(time.RFC3339))
+}
+
+// ActiveLoanLimitError is returned when a borrower already has the maximum number of active loans
+type ActiveLoanLimitError struct {
+	ActiveLoans int64
+	Max         int
+}
+
+// Error implements the error interface
+func (e *ActiveLoanLimitError) Error() string {
+	return fmt.Sprintf("borrower already has %d active loans, maximum allowed is %d", e.ActiveLoans, e.Max)
+}
+
+// UnsupportedCurrencyError is returned when a loan is created in a currency outside the configured allowlist
+type UnsupportedCurrencyError struct {
+	Currency string
+	Allowed  []string
+}
+
+// Error implements the error interface
+func (e *UnsupportedCurrencyError) Error() string {
+	return fmt.Sprintf("currency %s is not supported, allowed currencies are %s", e.Currency, strings.Join(e.Allowed, ", "))
+}
+
+// CurrencyMismatchError is returned when an investment is made in a currency other than the loan's
+type CurrencyMismatchError struct {
+	Currency     string
+	LoanCurrency string
+}
+
+// Error implements the error interface
+func (e *CurrencyMismatchError) Error() string {
+	return fmt.Sprintf("investment currency %s does not match the loan currency %s", e.Currency, e.LoanCurrency)
+}
+
+// LoanBatchTooLargeError is returned when a batch holds more loans than are created at once
+type LoanBatchTooLargeError struct {
+	Size int
+	Max  int
+}
+
+// Error implements the error interface
+func (e *LoanBatchTooLargeError) Error() string {
+	return fmt.Sprintf("batch holds %d loans, maximum allowed is %d", e.Size, e.Max)
+}
+
+// DuplicateProofError is returned when an approval proof was already used on another loan
+type DuplicateProofError struct {
+	LoanID string
+}
+
+// Error implements the error interface
+func (e *DuplicateProofError) Error() string {
+	return fmt.Sprintf("field validator proof was already used to approve loan %s", e.LoanID)
+}
+
+// DuplicateLoanError is returned when creating a loan identical to a proposed loan of the same borrower
+type DuplicateLoanError struct {
+	LoanID string
+}
+
+// Error implements the error interface
+func (e *DuplicateLoanError) Error() string {
+	return fmt.Sprintf("an identical proposed loan %s already exists", e.LoanID)
+}
+
+// ErrDuplicateLoanInBatch is returned for a loan of a batch identical to an earlier loan of the batch
+var ErrDuplicateLoanInBatch = errors.New("an identical loan appears earlier in the batch")
+
+// AlreadyApprovedError is returned when approving a loan that another validator already approved
+type AlreadyApprovedError struct {
+	FieldValidatorID string
+}
+
+// Error implements the error interface
+func (e *AlreadyApprovedError) Error() string {
+	if e.FieldValidatorID == "" {
+		return "loan is already approved"
+	}
+	return fmt.Sprintf("loan is already approved by %s", e.FieldValidatorID)
+}
+
+// ROIOutOfRangeError is returned when a loan's ROI falls outside the configured band
+type ROIOutOfRangeError struct {
+	ROI float64
+	Min float64
+	Max float64
+}
+
+// Error implements the error interface
+func (e *ROIOutOfRangeError) Error() string {
+	if e.Max > 0 && e.ROI > e.Max {
+		return fmt.Sprintf("roi %v is above the maximum of %v", e.ROI, e.Max)
+	}
+	return fmt.Sprintf("roi %v is below the minimum of %v", e.ROI, e.Min)
+}
+
+// ROIAboveRateError is returned when a loan's ROI would pay investors more than the borrower is charged
+type ROIAboveRateError struct {
+	ROI        float64
+	Rate       float64
+	AllowEqual bool
+}
+
+// Error implements the error interface
+func (e *ROIAboveRateError) Error() string {
+	if e.AllowEqual {
+		return fmt.Sprintf("roi %v must not exceed the rate of %v", e.ROI, e.Rate)
+	}
+	return fmt.Sprintf("roi %v must be below the rate of %v", e.ROI, e.Rate)
+}
+
+// MinimumInvestmentError is returned when an investment is below the configured minimum and does not complete the loan
+type MinimumInvestmentError struct {
+	Amount domain.Money
+	Min    domain.Money
+}
+
+// Error implements the error interface
+func (e *MinimumInvestmentError) Error() string {
+	return fmt.Sprintf("investment of %v is below the minimum of %v", e.Amount, e.Min)
+}
+
+// InvestorLimitError is returned when a new investor would exceed the maximum number of investors of a loan
+type InvestorLimitError struct {
+	Investors int
+	Max       int
+}
+
+// Error implements the error interface
+func (e *InvestorLimitError) Error() string {
+	return fmt.Sprintf("loan already has %d investors, maximum allowed is %d", e.Investors, e.Max)
+}
+
+// InvestorShareError is returned when an investment would take an investor's share of a loan above the
+// configured maximum percentage of its principal
+type InvestorShareError struct {
+	Amount     domain.Money
+	Max        domain.Money
+	MaxPercent float64
+}
+
+// Error implements the error interface
+func (e *InvestorShareError) Error() string {
+	return fmt.Sprintf("investment would bring the investor's total to %v, above the maximum share of %v%% (%v) of the loan", e.Amount, e.MaxPercent, e.Max)
+}
+
+// MinimumFundingError is returned when partially disbursing a loan that has not reached the minimum funding
+type MinimumFundingError struct {
+	FundedPercent float64
+	MinPercent    float64
+}
+
+// Error implements the error interface
+func (e *MinimumFundingError) Error() string {
+	return fmt.Sprintf("loan is %.2f%% funded, at least %v%% is required for disbursement", e.FundedPercent, e.MinPercent)
+}
+
+// MinimumInvestorsError is returned when disbursing a loan funded by fewer investors than the configured minimum
+type MinimumInvestorsError struct {
+	Investors int
+	Min       int
+}
+
+// Error implements the error interface
+func (e *MinimumInvestorsError) Error() string {
+	return fmt.Sprintf("loan is funded by %d investors, at least %d are required for disbursement", e.Investors, e.Min)
+}
@@ -0,0 +1,239 @@
+package service
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"loan-service/internal/config"
+	"loan-service/internal/domain"
+	"loan-service/internal/repository"
+)
+
+// EventPublisher publishes loan events to interested subscribers
+type EventPublisher interface {
+	Publish(event domain.Event)
+}
+
+// webhookQueueSize is how many events can wait for dispatch, and for delivery to each subscriber, before
+// further events are dropped
+const webhookQueueSize = 100
+
+// SignatureHeader carries the HMAC-SHA256 signature of a webhook body when a signing secret is configured
+const SignatureHeader = "X-Signature"
+
+// WebhookDispatcher delivers events to the configured webhook URLs, which receive every event, and to
+// the subscriptions that receive their type. Every subscriber URL has its own delivery queue, worked
+// through one event at a time in the order the events were published, so subscribers see an
+// investment.created event before the loan.invested event caused by the same investment, and a slow or
+// failing subscriber only delays its own deliveries. Publishing never blocks: events that do not fit in
+// a full queue are dropped and logged.
+type WebhookDispatcher struct {
+	repo   repository.WebhookRepository
+	cfg    config.WebhooksConfig
+	client *http.Client
+	queue  chan domain.Event
+	done   chan struct{}
+
+	// subscribers holds the delivery queue of every subscriber URL, only used by run
+	subscribers map[string]chan webhookDelivery
+	workers     sync.WaitGroup
+}
+
+// webhookDelivery is an encoded event waiting to be posted to a subscriber
+type webhookDelivery struct {
+	event domain.Event
+	body  []byte
+	// name identifies the subscriber in logs
+	name string
+}
+
+// NewWebhookDispatcher creates a dispatcher and starts delivering published events
+func NewWebhookDispatcher(repo repository.WebhookRepository, cfg config.WebhooksConfig) *WebhookDispatcher {
+	d := &WebhookDispatcher{
+		repo:        repo,
+		cfg:         cfg,
+		client:      &http.Client{Timeout: cfg.Timeout},
+		queue:       make(chan domain.Event, webhookQueueSize),
+		done:        make(chan struct{}),
+		subscribers: make(map[string]chan webhookDelivery),
+	}
+	go d.run()
+	return d
+}
+
+// Publish queues an event for delivery without waiting. The event is dropped when the queue is full.
+func (d *WebhookDispatcher) Publish(event domain.Event) {
+	select {
+	case d.queue <- event:
+	default:
+		log.Printf("Webhook queue is full, dropping event %s", event.ID)
+	}
+}
+
+// Close stops accepting events and waits until the queued ones are delivered
+func (d *WebhookDispatcher) Close() {
+	close(d.queue)
+	<-d.done
+}
+
+// run hands queued events to the subscribers' delivery queues until the dispatcher is closed, then
+// waits for the subscribers' queues to drain
+func (d *WebhookDispatcher) run() {
+	defer close(d.done)
+	for event := range d.queue {
+		d.dispatch(event)
+	}
+	for _, deliveries := range d.subscribers {
+		close(deliveries)
+	}
+	d.workers.Wait()
+}
+
+// dispatch queues an event for delivery to every configured URL and subscription receiving its type
+func (d *WebhookDispatcher) dispatch(event domain.Event) {
+	subscriptions, err := d.repo.FindSubscriptions()
+	if err != nil {
+		log.Printf("Webhook delivery of event %s failed: %v", event.ID, err)
+		return
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Webhook delivery of event %s failed: %v", event.ID, err)
+		return
+	}
+
+	for _, url := range d.cfg.URLs {
+		d.enqueue(url, webhookDelivery{event: event, body: body, name: url})
+	}
+	for _, subscription := range subscriptions {
+		if !subscription.Subscribes(event.Type) {
+			continue
+		}
+		d.enqueue(subscription.URL, webhookDelivery{event: event, body: body, name: subscription.ID})
+	}
+}
+
+// enqueue queues a delivery for a subscriber URL, starting the subscriber's worker on its first
+// delivery. The delivery is dropped when the subscriber's queue is full.
+func (d *WebhookDispatcher) enqueue(url string, delivery webhookDelivery) {
+	deliveries, ok := d.subscribers[url]
+	if !ok {
+		deliveries = make(chan webhookDelivery, webhookQueueSize)
+		d.subscribers[url] = deliveries
+		d.workers.Add(1)
+		go d.deliver(url, deliveries)
+	}
+
+	select {
+	case deliveries <- delivery:
+	default:
+		log.Printf("Webhook %s is not keeping up, dropping event %s", delivery.name, delivery.event.ID)
+	}
+}
+
+// deliver posts the deliveries queued for a subscriber URL in order. Deliveries that still fail after
+// the configured retries are logged.
+func (d *WebhookDispatcher) deliver(url string, deliveries <-chan webhookDelivery) {
+	defer d.workers.Done()
+	for delivery := range deliveries {
+		if err := d.postWithRetries(url, delivery.event, delivery.body); err != nil {
+			log.Printf("Webhook %s did not accept event %s: %v", delivery.name, delivery.event.ID, err)
+		}
+	}
+}
+
+// postWithRetries posts an encoded event, retrying failed attempts with a doubling backoff
+func (d *WebhookDispatcher) postWithRetries(url string, event domain.Event, body []byte) error {
+	backoff := d.cfg.RetryBackoff
+	err := d.post(url, event, body)
+	for attempt := 0; err != nil && attempt < d.cfg.MaxRetries; attempt++ {
+		time.Sleep(backoff)
+		backoff *= 2
+		err = d.post(url, event, body)
+	}
+	return err
+}
+
+// post sends an encoded event to a subscriber URL
+func (d *WebhookDispatcher) post(url string, event domain.Event, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Event", event.Type)
+	req.Header.Set("X-Webhook-ID", event.ID)
+	if d.cfg.Secret != "" {
+		req.Header.Set(SignatureHeader, SignWebhookBody(body, d.cfg.Secret))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// SignWebhookBody returns the hex encoded HMAC-SHA256 signature of a webhook body, prefixed with sha256=
+func SignWebhookBody(body []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// publishStatusChange publishes a loan.status_changed event when the loan left the given status
+func (s *loanService) publishStatusChange(loan *domain.Loan, from domain.LoanStatus) {
+	if s.events == nil || loan.Status == from {
+		return
+	}
+
+	s.events.Publish(domain.NewEvent(domain.EventLoanStatusChanged, s.clock.Now(), domain.LoanStatusChangedData{
+		LoanID:     loan.ID,
+		FromStatus: from,
+		ToStatus:   loan.Status,
+	}))
+}
+
+// publishInvestment publishes an investment.created event for the investment and, when it fully
+// funded the loan, a loan.invested event after it
+func (s *loanService) publishInvestment(loan *domain.Loan, investment *domain.Investment) {
+	if s.events == nil {
+		return
+	}
+
+	now := s.clock.Now()
+	s.events.Publish(domain.NewEvent(domain.EventInvestmentCreated, now, domain.InvestmentCreatedData{
+		LoanID:         loan.ID,
+		InvestmentID:   investment.ID,
+		InvestorID:     investment.InvestorID,
+		Amount:         investment.Amount,
+		TotalInvested:  loan.TotalInvested,
+		FundingPercent: loan.FundedPercent(),
+	}))
+
+	if loan.Status != domain.StatusInvested {
+		return
+	}
+
+	s.events.Publish(domain.NewEvent(domain.EventLoanInvested, now, domain.LoanInvestedData{
+		LoanID:              loan.ID,
+		PrincipalAmount:     loan.PrincipalAmount,
+		TotalInvested:       loan.TotalInvested,
+		Investors:           len(loan.InvestorIDs()),
+		AgreementLetterLink: loan.AgreementLetterLink,
+	}))
+}
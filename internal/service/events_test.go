@@ -0,0 +1,244 @@
+package service
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"loan-service/internal/config"
+	"loan-service/internal/domain"
+	"loan-service/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingPublisher collects published events in order
+type recordingPublisher struct {
+	events []domain.Event
+}
+
+func (p *recordingPublisher) Publish(event domain.Event) {
+	p.events = append(p.events, event)
+}
+
+func TestInvestInLoanPublishesEvents(t *testing.T) {
+	publisher := &recordingPublisher{}
+	service := setupTestServiceWithOptions(WithEvents(publisher))
+
+	loan := createApprovedLoan(t, service, 10000.00)
+	publisher.events = nil
+	_, err := service.InvestInLoan(loan.ID, "investor_001", domain.NewMoney(4000.00))
+	require.NoError(t, err)
+
+	require.Len(t, publisher.events, 1)
+	assert.Equal(t, domain.EventInvestmentCreated, publisher.events[0].Type)
+	data := publisher.events[0].Data.(domain.InvestmentCreatedData)
+	assert.Equal(t, "investor_001", data.InvestorID)
+	assert.Equal(t, domain.NewMoney(4000.00), data.Amount)
+	assert.Equal(t, domain.NewMoney(4000.00), data.TotalInvested)
+	assert.Equal(t, 40.0, data.FundingPercent)
+	assert.NotEmpty(t, data.InvestmentID)
+
+	// The investment that fully funds the loan is announced before the loan itself
+	_, err = service.InvestInLoan(loan.ID, "investor_002", domain.NewMoney(6000.00))
+	require.NoError(t, err)
+
+	require.Len(t, publisher.events, 4)
+	assert.Equal(t, domain.EventInvestmentCreated, publisher.events[1].Type)
+	assert.Equal(t, 100.0, publisher.events[1].Data.(domain.InvestmentCreatedData).FundingPercent)
+	assert.Equal(t, domain.EventLoanInvested, publisher.events[2].Type)
+	invested := publisher.events[2].Data.(domain.LoanInvestedData)
+	assert.Equal(t, 2, invested.Investors)
+	assert.NotEmpty(t, invested.AgreementLetterLink)
+	assert.Equal(t, domain.EventLoanStatusChanged, publisher.events[3].Type)
+
+	// Failed investments publish nothing
+	_, err = service.InvestInLoan(loan.ID, "investor_003", domain.NewMoney(1000.00))
+	require.Error(t, err)
+	assert.Len(t, publisher.events, 4)
+}
+
+func TestWebhookDispatcher(t *testing.T) {
+	var mu sync.Mutex
+	received := make(map[string][]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event domain.Event
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+		assert.Equal(t, event.Type, r.Header.Get("X-Webhook-Event"))
+
+		mu.Lock()
+		received[r.URL.Path] = append(received[r.URL.Path], event.Type)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	_, db := setupTestService()
+	repo := repository.NewWebhookRepository(db)
+	require.NoError(t, repo.CreateSubscription(&domain.WebhookSubscription{URL: server.URL + "/all"}))
+	require.NoError(t, repo.CreateSubscription(&domain.WebhookSubscription{
+		URL:        server.URL + "/invested",
+		EventTypes: []string{domain.EventLoanInvested},
+	}))
+
+	dispatcher := NewWebhookDispatcher(repo, config.WebhooksConfig{Timeout: time.Second})
+	dispatcher.Publish(domain.NewEvent(domain.EventInvestmentCreated, time.Now(), domain.InvestmentCreatedData{LoanID: "loan_001"}))
+	dispatcher.Publish(domain.NewEvent(domain.EventLoanInvested, time.Now(), domain.LoanInvestedData{LoanID: "loan_001"}))
+	dispatcher.Close()
+
+	assert.Equal(t, []string{domain.EventInvestmentCreated, domain.EventLoanInvested}, received["/all"])
+	assert.Equal(t, []string{domain.EventLoanInvested}, received["/invested"])
+}
+
+func TestLoanTransitionsPublishStatusChanges(t *testing.T) {
+	publisher := &recordingPublisher{}
+	service := setupTestServiceWithOptions(WithEvents(publisher))
+
+	loan := createApprovedLoan(t, service, 10000.00)
+	_, err := service.CancelLoan(loan.ID, "")
+	require.NoError(t, err)
+
+	require.Len(t, publisher.events, 2)
+	approved := publisher.events[0].Data.(domain.LoanStatusChangedData)
+	assert.Equal(t, domain.LoanStatusChangedData{LoanID: loan.ID, FromStatus: domain.StatusProposed, ToStatus: domain.StatusApproved}, approved)
+	cancelled := publisher.events[1].Data.(domain.LoanStatusChangedData)
+	assert.Equal(t, domain.LoanStatusChangedData{LoanID: loan.ID, FromStatus: domain.StatusApproved, ToStatus: domain.StatusCancelled}, cancelled)
+
+	// Failed transitions publish nothing
+	_, err = service.CancelLoan(loan.ID, "")
+	require.Error(t, err)
+	assert.Len(t, publisher.events, 2)
+}
+
+func TestWebhookDispatcherConfiguredURLs(t *testing.T) {
+	var mu sync.Mutex
+	attempts := 0
+	var delivered []domain.Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, SignWebhookBody(body, "secret"), r.Header.Get(SignatureHeader))
+
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		// The first attempt fails and is retried
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var event domain.Event
+		assert.NoError(t, json.Unmarshal(body, &event))
+		delivered = append(delivered, event)
+	}))
+	defer server.Close()
+
+	_, db := setupTestService()
+	dispatcher := NewWebhookDispatcher(repository.NewWebhookRepository(db), config.WebhooksConfig{
+		Timeout:      time.Second,
+		URLs:         []string{server.URL},
+		Secret:       "secret",
+		MaxRetries:   2,
+		RetryBackoff: time.Millisecond,
+	})
+	dispatcher.Publish(domain.NewEvent(domain.EventLoanStatusChanged, time.Now(), domain.LoanStatusChangedData{
+		LoanID:     "loan_001",
+		FromStatus: domain.StatusProposed,
+		ToStatus:   domain.StatusApproved,
+	}))
+	dispatcher.Close()
+
+	assert.Equal(t, 2, attempts)
+	require.Len(t, delivered, 1)
+	assert.Equal(t, domain.EventLoanStatusChanged, delivered[0].Type)
+	data := delivered[0].Data.(map[string]interface{})
+	assert.Equal(t, "loan_001", data["loan_id"])
+	assert.Equal(t, "proposed", data["from_status"])
+	assert.Equal(t, "approved", data["to_status"])
+}
+
+func TestWebhookDispatcherGivesUpAfterRetries(t *testing.T) {
+	var mu sync.Mutex
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Unsigned without a secret
+		assert.Empty(t, r.Header.Get(SignatureHeader))
+		mu.Lock()
+		attempts++
+		mu.Unlock()
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	_, db := setupTestService()
+	dispatcher := NewWebhookDispatcher(repository.NewWebhookRepository(db), config.WebhooksConfig{
+		Timeout:      time.Second,
+		URLs:         []string{server.URL},
+		MaxRetries:   2,
+		RetryBackoff: time.Millisecond,
+	})
+	dispatcher.Publish(domain.NewEvent(domain.EventLoanStatusChanged, time.Now(), domain.LoanStatusChangedData{LoanID: "loan_001"}))
+	dispatcher.Close()
+
+	assert.Equal(t, 3, attempts)
+}
+
+func TestWebhookDispatcherSlowSubscriber(t *testing.T) {
+	release := make(chan struct{})
+	var mu sync.Mutex
+	received := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			<-release
+		}
+		mu.Lock()
+		received[r.URL.Path]++
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	_, db := setupTestService()
+	dispatcher := NewWebhookDispatcher(repository.NewWebhookRepository(db), config.WebhooksConfig{
+		Timeout: 5 * time.Second,
+		URLs:    []string{server.URL + "/slow", server.URL + "/fast"},
+	})
+	for i := 0; i < 3; i++ {
+		dispatcher.Publish(domain.NewEvent(domain.EventLoanStatusChanged, time.Now(), domain.LoanStatusChangedData{LoanID: "loan_001"}))
+	}
+
+	// The stuck subscriber does not hold up the other one
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return received["/fast"] == 3
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// Publishing does not wait for the stuck subscriber even once its queue is full
+	published := make(chan struct{})
+	go func() {
+		for i := 0; i < 3*webhookQueueSize; i++ {
+			dispatcher.Publish(domain.NewEvent(domain.EventLoanStatusChanged, time.Now(), domain.LoanStatusChangedData{LoanID: "loan_001"}))
+		}
+		close(published)
+	}()
+	select {
+	case <-published:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Publish blocked on a stuck subscriber")
+	}
+
+	close(release)
+	dispatcher.Close()
+	assert.LessOrEqual(t, received["/slow"], webhookQueueSize+3)
+	assert.Greater(t, received["/slow"], 0)
+}
+
+func TestSignWebhookBody(t *testing.T) {
+	// HMAC-SHA256 test vector from RFC 4231, test case 2
+	signature := SignWebhookBody([]byte("what do ya want for nothing?"), "Jefe")
+	assert.Equal(t, "sha256=5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843", signature)
+}
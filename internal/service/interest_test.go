@@ -0,0 +1,95 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"loan-service/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalculateAccruedInterest(t *testing.T) {
+	principal := domain.NewMoney(10000.00)
+
+	tests := []struct {
+		name     string
+		days     int
+		method   InterestMethod
+		expected domain.Money
+	}{
+		{"30 days simple", 30, InterestSimple, domain.NewMoney(98.63)},
+		{"30 days daily compound", 30, InterestDailyCompound, domain.NewMoney(99.10)},
+		{"365 days simple", 365, InterestSimple, domain.NewMoney(1200.00)},
+		{"365 days daily compound", 365, InterestDailyCompound, domain.NewMoney(1274.75)},
+		{"same day", 0, InterestDailyCompound, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, CalculateAccruedInterest(principal, 12.0, tt.days, tt.method))
+		})
+	}
+
+	assert.Equal(t, domain.Money(0), CalculateAccruedInterest(principal, 0, 30, InterestSimple))
+}
+
+func TestGetAccruedInterest(t *testing.T) {
+	disbursedAt := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	service, db := setupTestService()
+	service.clock = fixedClock{now: disbursedAt.AddDate(0, 0, 30)}
+
+	loan := &domain.Loan{
+		BorrowerID:      "user123",
+		PrincipalAmount: domain.NewMoney(10000.00),
+		Rate:            12.0,
+		ROI:             14.0,
+		Status:          domain.StatusDisbursed,
+		DisbursementDetails: &domain.DisbursementDetails{
+			FieldOfficerID:   "officer_001",
+			DisbursementDate: disbursedAt,
+		},
+	}
+	require.NoError(t, db.Create(loan).Error)
+
+	// Without a date interest accrues until now
+	accrued, err := service.GetAccruedInterest(loan.ID, time.Time{}, InterestSimple)
+	require.NoError(t, err)
+	assert.Equal(t, 30, accrued.Days)
+	assert.Equal(t, disbursedAt, accrued.From.UTC())
+	assert.Equal(t, domain.NewMoney(98.63), accrued.Interest)
+
+	accrued, err = service.GetAccruedInterest(loan.ID, disbursedAt.AddDate(0, 0, 365), InterestDailyCompound)
+	require.NoError(t, err)
+	assert.Equal(t, 365, accrued.Days)
+	assert.Equal(t, domain.NewMoney(1274.75), accrued.Interest)
+
+	_, err = service.GetAccruedInterest(loan.ID, disbursedAt.AddDate(0, 0, -1), InterestSimple)
+	assert.ErrorIs(t, err, ErrAccrualBeforeDisbursement)
+
+	_, err = service.GetAccruedInterest(loan.ID, time.Time{}, "monthly")
+	assert.ErrorIs(t, err, ErrInvalidInterestMethod)
+
+	// Repaid loans stop accruing at their final repayment; repayments are reported, not deducted
+	repaidAt := disbursedAt.AddDate(0, 0, 60)
+	require.NoError(t, db.Create(&domain.Repayment{LoanID: loan.ID, Amount: domain.NewMoney(4000.00), RepaymentDate: disbursedAt.AddDate(0, 0, 20)}).Error)
+	require.NoError(t, db.Create(&domain.Repayment{LoanID: loan.ID, Amount: domain.NewMoney(6200.00), RepaymentDate: repaidAt}).Error)
+	accrued, err = service.GetAccruedInterest(loan.ID, time.Time{}, InterestSimple)
+	require.NoError(t, err)
+	assert.Equal(t, domain.NewMoney(98.63), accrued.Interest)
+	assert.Equal(t, domain.NewMoney(4000.00), accrued.Repaid)
+
+	require.NoError(t, db.Model(loan).Update("status", domain.StatusRepaid).Error)
+	accrued, err = service.GetAccruedInterest(loan.ID, disbursedAt.AddDate(0, 0, 365), InterestSimple)
+	require.NoError(t, err)
+	assert.Equal(t, repaidAt, accrued.AsOf.UTC())
+	assert.Equal(t, 60, accrued.Days)
+	assert.Equal(t, domain.NewMoney(197.26), accrued.Interest)
+	assert.Equal(t, domain.NewMoney(10200.00), accrued.Repaid)
+
+	// Loans that were not disbursed accrue nothing
+	approved := createApprovedLoan(t, service, 10000.00)
+	_, err = service.GetAccruedInterest(approved.ID, time.Time{}, InterestSimple)
+	assert.ErrorIs(t, err, domain.ErrNotAccruing)
+}
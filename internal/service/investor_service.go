@@ -0,0 +1,103 @@
+package service
+
+import (
+	"errors"
+
+	"loan-service/internal/domain"
+	"loan-service/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// InvestorService defines the interface for managing investor compliance status and portfolios
+type InvestorService interface {
+	GetInvestor(id string) (*domain.Investor, error)
+	SetStatus(id string, status domain.InvestorStatus, reason string) (*domain.Investor, error)
+	SetEmail(id string, email string) (*domain.Investor, error)
+	GetInvestments(id string) (*InvestorPortfolio, error)
+}
+
+// InvestorPortfolio is an investor's investments across loans together with their combined totals
+type InvestorPortfolio struct {
+	Investments    []domain.InvestorInvestment
+	TotalCommitted domain.Money
+	ExpectedReturn domain.Money
+}
+
+// investorService implements InvestorService
+type investorService struct {
+	repo        repository.InvestorRepository
+	investments repository.InvestmentRepository
+}
+
+// NewInvestorService creates a new investor service
+func NewInvestorService(repo repository.InvestorRepository, investments repository.InvestmentRepository) InvestorService {
+	return &investorService{repo: repo, investments: investments}
+}
+
+// GetInvestor retrieves an investor, treating investors without a record as active
+func (s *investorService) GetInvestor(id string) (*domain.Investor, error) {
+	return findInvestor(s.repo, id)
+}
+
+// SetStatus activates or deactivates an investor. Deactivation blocks new investments
+// but leaves the investor's existing stakes untouched.
+func (s *investorService) SetStatus(id string, status domain.InvestorStatus, reason string) (*domain.Investor, error) {
+	investor, err := findInvestor(s.repo, id)
+	if err != nil {
+		return nil, err
+	}
+
+	investor.Status = status
+	investor.StatusReason = reason
+	if status == domain.InvestorActive {
+		investor.StatusReason = ""
+	}
+
+	if err := s.repo.Save(investor); err != nil {
+		return nil, err
+	}
+	return investor, nil
+}
+
+// SetEmail records the address investor notifications are sent to
+func (s *investorService) SetEmail(id string, email string) (*domain.Investor, error) {
+	investor, err := findInvestor(s.repo, id)
+	if err != nil {
+		return nil, err
+	}
+
+	investor.Email = email
+	if err := s.repo.Save(investor); err != nil {
+		return nil, err
+	}
+	return investor, nil
+}
+
+// GetInvestments returns all investments of an investor with the amount still committed and its
+// expected return. Investments in cancelled or expired loans were refunded, so they do not count toward the totals.
+func (s *investorService) GetInvestments(id string) (*InvestorPortfolio, error) {
+	investments, err := s.investments.FindByInvestor(id)
+	if err != nil {
+		return nil, err
+	}
+
+	portfolio := &InvestorPortfolio{Investments: investments}
+	for _, investment := range investments {
+		if investment.LoanStatus == domain.StatusCancelled || investment.LoanStatus == domain.StatusExpired {
+			continue
+		}
+		portfolio.TotalCommitted += investment.Amount
+		portfolio.ExpectedReturn += investment.ExpectedReturn()
+	}
+	return portfolio, nil
+}
+
+// findInvestor loads an investor, returning an unsaved active investor when none is recorded
+func findInvestor(repo repository.InvestorRepository, id string) (*domain.Investor, error) {
+	investor, err := repo.FindByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &domain.Investor{ID: id, Status: domain.InvestorActive}, nil
+	}
+	return investor, err
+}
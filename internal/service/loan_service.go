@@ -1,47 +1,525 @@
 package service
 
 import (
+	"context"
 	"errors"
-	"fmt"
+	"log"
+	"math"
+	"slices"
+	"strings"
 	"time"
 
+	"loan-service/internal/config"
 	"loan-service/internal/domain"
 	"loan-service/internal/repository"
-)
 
-// generateAgreementLetterLink creates a dummy agreement letter link
-func generateAgreementLetterLink(loanID string) string {
-	return fmt.Sprintf("https://example.com/agreements/loan_%s_agreement.pdf", loanID)
-}
+	"gorm.io/gorm"
+)
 
 // LoanService defines the interface for loan business logic
 type LoanService interface {
 	CreateLoan(loan *domain.Loan) error
+	CreateLoanWithIdempotencyKey(loan *domain.Loan, key string) (*domain.Loan, bool, error)
+	ValidateNewLoans(loans []domain.Loan) ([]error, error)
+	CreateLoans(loans []domain.Loan) ([]error, error)
+	CheckLoanBatchSize(size int) error
 	GetLoan(id string) (*domain.Loan, error)
 	GetLoans(filters map[string]interface{}) ([]domain.Loan, error)
+	ExportLoans(filters map[string]interface{}, fn func(loan *domain.Loan) error) error
 	UpdateLoan(id string, updates map[string]interface{}) (*domain.Loan, error)
 	DeleteLoan(id string) error
-	ApproveLoan(id string, approvalDetails *domain.ApprovalDetails) (*domain.Loan, error)
-	InvestInLoan(id string, investorID string, amount float64) (*domain.Loan, error)
+	ApproveLoan(id string, approvalDetails *domain.ApprovalDetails, covenants ...string) (*domain.Loan, error)
+	InvestInLoan(id string, investorID string, amount domain.Money) (*domain.Loan, error)
+	InvestInLoanWithCurrency(id string, investorID string, amount domain.Money, currency string) (*domain.Loan, error)
 	DisburseLoan(id string, disbursementDetails *domain.DisbursementDetails) (*domain.Loan, error)
+	RecordRepayment(id string, amount domain.Money, repaidAt time.Time) (*domain.Loan, error)
 	GetLoanTransitions(id string) ([]domain.StateTransition, error)
+	GetLoanDetails(id string) (*LoanDetails, error)
+	ReviewLoan(id string, reviewDetails *domain.ReviewDetails) (*domain.Loan, error)
+	RejectLoan(id string, rejectionDetails *domain.RejectionDetails) (*domain.Loan, error)
+	CancelLoan(id, reason string) (*domain.Loan, error)
+	ClearReview(id string) (*domain.Loan, error)
+	RegenerateAgreement(id string, force bool) (*domain.Loan, error)
+	GetAgreementData(id string) (*domain.AgreementData, error)
+	RetryMissingAgreements() (int, error)
+	ExpireOverdueLoans(now time.Time) (int, error)
+	GetBorrowerHistory(borrowerID string, page, limit int) ([]domain.TimelineEntry, int, error)
+	CorrectApproval(id string, correction *domain.ApprovalDetails, adminOverride bool) (*domain.Loan, error)
+	SatisfyCovenant(id string, covenantID string) (*domain.Loan, error)
+	RevokeApproval(id string, reason string) (*domain.Loan, error)
+	ForceTransition(id string, to domain.LoanStatus, actorID, justification string) (*domain.Loan, error)
+	GetInvestorLoans(investorID string, status string, page, limit int) ([]domain.InvestorPosition, int64, error)
+	GetInvestorCashflows(investorID string) ([]domain.CashflowMonth, error)
+	GetLedger(id string) (*domain.Loan, []domain.LedgerEntry, error)
+	GetAmortization(id string) (*domain.Loan, []domain.Installment, error)
+	GenerateSchedule(id string) (*domain.Loan, []domain.Installment, error)
+	GetInvestments(id, investorID string, offset, limit int) (*LoanInvestments, error)
+	GetFundingTimeline(id string) (*FundingTimeline, error)
+	GetLoanDiff(id string, from, to int) (*LoanDiff, error)
+	GetHistory(id string) ([]domain.LoanEvent, error)
+	GetLoanStats() (*domain.LoanAggregate, error)
+	GetBorrowerLoans(borrowerID string) (*BorrowerLoans, error)
+	GetDeletedLoans() ([]domain.Loan, error)
+	RestoreLoan(id string) (*domain.Loan, error)
+	Reconcile(id string) (*Reconciliation, error)
+	WithdrawInvestment(id, investmentID, investorID string) (*domain.Loan, error)
+	GetAccruedInterest(id string, asOf time.Time, method InterestMethod) (*AccruedInterest, error)
+	WithContext(ctx context.Context) LoanService
+}
+
+// defaultIdempotencyKeyTTL is how long idempotency keys are honoured when not configured
+const defaultIdempotencyKeyTTL = 24 * time.Hour
+
+// defaultMaxLoanBatchSize is the largest batch of loans created at once when not configured
+const defaultMaxLoanBatchSize = 100
+
+// LoanDetails is the full object graph of a loan assembled for detail views
+type LoanDetails struct {
+	Loan        *domain.Loan
+	Transitions []domain.StateTransition
+	Events      []domain.LoanEvent
+	Repayment   domain.RepaymentSummary
+}
+
+// BorrowerLoans is a borrower's loans together with their combined exposure
+type BorrowerLoans struct {
+	Loans          []domain.Loan
+	TotalPrincipal domain.Money
+	TotalDisbursed domain.Money
+}
+
+// LoanDiff holds the field-level changes between two recorded versions of a loan
+type LoanDiff struct {
+	From    *domain.LoanVersion
+	To      *domain.LoanVersion
+	Changes []domain.FieldChange
+}
+
+// LoanInvestments is one page of a loan's investments together with each investor's total contribution
+// to the whole loan. Total counts the investments matching the filter across all pages.
+type LoanInvestments struct {
+	Loan          *domain.Loan
+	Investments   []domain.Investment
+	Total         int64
+	Contributions []domain.InvestorContribution
+}
+
+// FundingStep is an investment in a loan together with the loan's funding right after it was made
+type FundingStep struct {
+	Investment    domain.Investment
+	TotalInvested domain.Money
+	PercentFunded float64
+}
+
+// FundingTimeline is a loan's investments in the order they were made, each with the running funding
+type FundingTimeline struct {
+	Loan  *domain.Loan
+	Steps []FundingStep
+}
+
+// Reconciliation is the outcome of recomputing a loan's total invested from its investments.
+// Corrected is set when the stored total had drifted and was replaced.
+type Reconciliation struct {
+	Loan                  *domain.Loan
+	PreviousTotalInvested domain.Money
+	Corrected             bool
 }
 
 // loanService implements LoanService
 type loanService struct {
-	repo repository.LoanRepository
+	repo       repository.LoanRepository
+	cfg        config.LoanConfig
+	clock      Clock
+	agreements AgreementGenerator
+	autoInvest repository.AutoInvestRepository
+	investors  repository.InvestorRepository
+	events     EventPublisher
+	notifier   Notifier
+}
+
+// Option configures optional dependencies of the loan service
+type Option func(*loanService)
+
+// WithConfig sets the business rule configuration
+func WithConfig(cfg config.LoanConfig) Option {
+	return func(s *loanService) {
+		s.cfg = cfg
+	}
+}
+
+// WithClock sets the clock used for time-dependent rules
+func WithClock(clock Clock) Option {
+	return func(s *loanService) {
+		s.clock = clock
+	}
+}
+
+// WithAgreementGenerator sets the generator used to produce agreement letters
+func WithAgreementGenerator(generator AgreementGenerator) Option {
+	return func(s *loanService) {
+		s.agreements = generator
+	}
+}
+
+// WithAutoInvest enables executing auto-invest rules when loans are approved
+func WithAutoInvest(repo repository.AutoInvestRepository) Option {
+	return func(s *loanService) {
+		s.autoInvest = repo
+	}
+}
+
+// WithInvestors enables blocking investments from inactive investors
+func WithInvestors(repo repository.InvestorRepository) Option {
+	return func(s *loanService) {
+		s.investors = repo
+	}
+}
+
+// WithEvents publishes investment events to the given publisher
+func WithEvents(publisher EventPublisher) Option {
+	return func(s *loanService) {
+		s.events = publisher
+	}
+}
+
+// WithNotifier notifies investors when a loan they invested in becomes fully invested
+func WithNotifier(notifier Notifier) Option {
+	return func(s *loanService) {
+		s.notifier = notifier
+	}
 }
 
 // NewLoanService creates a new loan service
-func NewLoanService(repo repository.LoanRepository) LoanService {
-	return &loanService{repo: repo}
+func NewLoanService(repo repository.LoanRepository, opts ...Option) LoanService {
+	s := &loanService{repo: repo, clock: systemClock{}}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.agreements == nil {
+		s.agreements = linkAgreementGenerator{template: s.cfg.AgreementLinkTemplate}
+	}
+	return s
+}
+
+// WithContext returns a copy of the service whose loan queries run with ctx. Handlers bind the request
+// context so that work for cancelled or timed out requests is aborted at the database.
+func (s *loanService) WithContext(ctx context.Context) LoanService {
+	bound := *s
+	bound.repo = s.repo.WithContext(ctx)
+	return &bound
 }
 
 // CreateLoan creates a new loan
 func (s *loanService) CreateLoan(loan *domain.Loan) error {
+	if err := s.prepareNewLoan(loan); err != nil {
+		return err
+	}
+	return s.repo.Create(loan)
+}
+
+// prepareNewLoan checks the creation rules and sets the initial state of a new loan
+func (s *loanService) prepareNewLoan(loan *domain.Loan) error {
+	activeLoans, err := s.countActiveLoans(loan.BorrowerID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.validateNewLoan(loan, activeLoans); err != nil {
+		return err
+	}
+
+	if err := s.checkDuplicateLoan(loan); err != nil {
+		return err
+	}
+
+	s.initNewLoan(loan)
+	return nil
+}
+
+// initNewLoan sets the initial state of a loan that passed the creation rules
+func (s *loanService) initNewLoan(loan *domain.Loan) {
 	loan.Status = domain.StatusProposed
 	loan.TotalInvested = 0
-	return s.repo.Create(loan)
+	if loan.Currency == "" {
+		loan.Currency = s.baseCurrency()
+	}
+	if loan.TermMonths <= 0 {
+		loan.TermMonths = domain.DefaultTermMonths
+	}
+	loan.RecordTransition("", "create", loan.BorrowerID, s.clock.Now(), nil)
+}
+
+// validateNewLoan checks the creation rules of a loan for a borrower with the given number of active loans.
+// It does not touch the database, so every error it returns is a rule violation.
+func (s *loanService) validateNewLoan(loan *domain.Loan, activeLoans int64) error {
+	if err := s.checkWholeUnits(loan.PrincipalAmount); err != nil {
+		return err
+	}
+
+	if err := s.checkCurrency(loan.Currency); err != nil {
+		return err
+	}
+
+	if err := s.checkROI(loan.ROI); err != nil {
+		return err
+	}
+
+	if err := s.checkROIAgainstRate(loan.ROI, loan.Rate); err != nil {
+		return err
+	}
+
+	return s.checkActiveLoanLimit(activeLoans)
+}
+
+// ValidateNewLoans runs the creation rules against a batch of loans without persisting anything.
+// The returned slice holds the rule violation of each loan, or nil when it is valid. Valid loans
+// earlier in the batch count toward their borrower's active loan limit, as if the batch were created.
+// When duplicate loans are rejected, so are loans identical to a proposed loan or to a valid loan
+// earlier in the batch.
+func (s *loanService) ValidateNewLoans(loans []domain.Loan) ([]error, error) {
+	activeLoans := make(map[string]int64)
+	batched := make(map[duplicateLoanKey]bool)
+	results := make([]error, len(loans))
+
+	for i := range loans {
+		borrowerID := loans[i].BorrowerID
+		if _, ok := activeLoans[borrowerID]; !ok {
+			count, err := s.countActiveLoans(borrowerID)
+			if err != nil {
+				return nil, err
+			}
+			activeLoans[borrowerID] = count
+		}
+
+		results[i] = s.validateNewLoan(&loans[i], activeLoans[borrowerID])
+		if results[i] == nil && s.cfg.RejectDuplicateLoans && batched[newDuplicateLoanKey(&loans[i])] {
+			results[i] = ErrDuplicateLoanInBatch
+		}
+		if results[i] == nil {
+			if err := s.checkDuplicateLoan(&loans[i]); err != nil {
+				var duplicateErr *DuplicateLoanError
+				if !errors.As(err, &duplicateErr) {
+					return nil, err
+				}
+				results[i] = err
+			}
+		}
+		if results[i] == nil {
+			activeLoans[borrowerID]++
+			batched[newDuplicateLoanKey(&loans[i])] = true
+		}
+	}
+
+	return results, nil
+}
+
+// CreateLoans creates a batch of loans in a single transaction, so either all of them are created or
+// none are. The returned slice holds the rule violation of each loan as in ValidateNewLoans; when any
+// loan breaks a rule nothing is created.
+func (s *loanService) CreateLoans(loans []domain.Loan) ([]error, error) {
+	if err := s.CheckLoanBatchSize(len(loans)); err != nil {
+		return nil, err
+	}
+
+	results, err := s.ValidateNewLoans(loans)
+	if err != nil {
+		return nil, err
+	}
+	for _, ruleErr := range results {
+		if ruleErr != nil {
+			return results, nil
+		}
+	}
+
+	for i := range loans {
+		s.initNewLoan(&loans[i])
+	}
+	if err := s.repo.CreateBatch(loans); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// CheckLoanBatchSize rejects batches holding more loans than are created at once
+func (s *loanService) CheckLoanBatchSize(size int) error {
+	max := s.cfg.MaxLoanBatchSize
+	if max <= 0 {
+		max = defaultMaxLoanBatchSize
+	}
+	if size > max {
+		return &LoanBatchTooLargeError{Size: size, Max: max}
+	}
+	return nil
+}
+
+// CreateLoanWithIdempotencyKey creates a new loan unless the borrower already created one with the
+// same unexpired key, in which case that loan is returned and the boolean result is true
+func (s *loanService) CreateLoanWithIdempotencyKey(loan *domain.Loan, key string) (*domain.Loan, bool, error) {
+	existing, err := s.findIdempotentLoan(loan.BorrowerID, key)
+	if err != nil {
+		return nil, false, err
+	}
+	if existing != nil {
+		return existing, true, nil
+	}
+
+	if err := s.prepareNewLoan(loan); err != nil {
+		return nil, false, err
+	}
+
+	ttl := s.cfg.IdempotencyKeyTTL
+	if ttl <= 0 {
+		ttl = defaultIdempotencyKeyTTL
+	}
+	record := &domain.IdempotencyKey{
+		BorrowerID: loan.BorrowerID,
+		Key:        key,
+		ExpiresAt:  s.clock.Now().Add(ttl),
+	}
+
+	if err := s.repo.CreateWithIdempotencyKey(loan, record); err != nil {
+		// A concurrent request with the same key may have claimed it first
+		existing, findErr := s.findIdempotentLoan(loan.BorrowerID, key)
+		if findErr == nil && existing != nil {
+			return existing, true, nil
+		}
+		return nil, false, err
+	}
+
+	return loan, false, nil
+}
+
+// findIdempotentLoan returns the loan created with an unexpired idempotency key, or nil if there is none
+func (s *loanService) findIdempotentLoan(borrowerID, key string) (*domain.Loan, error) {
+	record, err := s.repo.FindIdempotencyKey(borrowerID, key)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if record.IsExpired(s.clock.Now()) {
+		return nil, s.repo.DeleteIdempotencyKey(borrowerID, key)
+	}
+
+	return s.repo.FindByID(record.LoanID)
+}
+
+// countActiveLoans counts a borrower's active loans, skipping the query when no limit is configured
+func (s *loanService) countActiveLoans(borrowerID string) (int64, error) {
+	if s.cfg.MaxActiveLoansPerBorrower <= 0 {
+		return 0, nil
+	}
+	return s.repo.CountByBorrower(borrowerID, domain.ActiveStatuses())
+}
+
+// checkDuplicateLoan rejects a new loan identical to a proposed loan of the same borrower when
+// duplicate loans are not allowed
+func (s *loanService) checkDuplicateLoan(loan *domain.Loan) error {
+	if !s.cfg.RejectDuplicateLoans {
+		return nil
+	}
+
+	existing, err := s.repo.FindDuplicateProposal(loan)
+	if err != nil {
+		return err
+	}
+
+	if existing != nil {
+		return &DuplicateLoanError{LoanID: existing.ID}
+	}
+	return nil
+}
+
+// duplicateLoanKey holds the fields that make two proposed loans duplicates of each other
+type duplicateLoanKey struct {
+	borrowerID string
+	principal  domain.Money
+	rate       float64
+	roi        float64
+}
+
+func newDuplicateLoanKey(loan *domain.Loan) duplicateLoanKey {
+	return duplicateLoanKey{borrowerID: loan.BorrowerID, principal: loan.PrincipalAmount, rate: loan.Rate, roi: loan.ROI}
+}
+
+// checkActiveLoanLimit rejects new loans for borrowers at their active loan limit
+func (s *loanService) checkActiveLoanLimit(activeLoans int64) error {
+	if s.cfg.MaxActiveLoansPerBorrower <= 0 {
+		return nil
+	}
+
+	if activeLoans >= int64(s.cfg.MaxActiveLoansPerBorrower) {
+		return &ActiveLoanLimitError{ActiveLoans: activeLoans, Max: s.cfg.MaxActiveLoansPerBorrower}
+	}
+	return nil
+}
+
+// baseCurrency returns the currency of loans created without one
+func (s *loanService) baseCurrency() string {
+	if s.cfg.BaseCurrency == "" {
+		return domain.DefaultCurrency
+	}
+	return s.cfg.BaseCurrency
+}
+
+// checkCurrency rejects currencies outside the configured allowlist. Loans without a currency are
+// created in the base currency, and any currency is accepted when no allowlist is configured.
+func (s *loanService) checkCurrency(currency string) error {
+	if currency == "" || len(s.cfg.AllowedCurrencies) == 0 || slices.Contains(s.cfg.AllowedCurrencies, currency) {
+		return nil
+	}
+	return &UnsupportedCurrencyError{Currency: currency, Allowed: s.cfg.AllowedCurrencies}
+}
+
+// checkWholeUnits rejects amounts with a fractional part when whole units are required
+func (s *loanService) checkWholeUnits(amount domain.Money) error {
+	if s.cfg.WholeUnitsOnly && !amount.IsWholeUnits() {
+		return domain.ErrFractionalAmount
+	}
+	return nil
+}
+
+// checkROI rejects ROIs outside the configured band; a zero bound is not enforced
+func (s *loanService) checkROI(roi float64) error {
+	if (s.cfg.MinROI > 0 && roi < s.cfg.MinROI) || (s.cfg.MaxROI > 0 && roi > s.cfg.MaxROI) {
+		return &ROIOutOfRangeError{ROI: roi, Min: s.cfg.MinROI, Max: s.cfg.MaxROI}
+	}
+	return nil
+}
+
+// checkROIAgainstRate rejects ROIs above the borrower's rate, and under the strict policy ROIs equal to it.
+// The rule is not enforced without a policy or with the "off" policy.
+func (s *loanService) checkROIAgainstRate(roi, rate float64) error {
+	switch s.cfg.ROIRatePolicy {
+	case "allow_equal":
+		if roi > rate {
+			return &ROIAboveRateError{ROI: roi, Rate: rate, AllowEqual: true}
+		}
+	case "strict":
+		if roi >= rate {
+			return &ROIAboveRateError{ROI: roi, Rate: rate}
+		}
+	}
+	return nil
+}
+
+// checkUniqueProof rejects approval proofs already used on another loan when unique proofs are required
+func (s *loanService) checkUniqueProof(proofs domain.ProofLinks, loanID string) error {
+	if !s.cfg.UniqueProofPerLoan {
+		return nil
+	}
+
+	existing, err := s.repo.FindByApprovalProof(proofs, loanID)
+	if err != nil {
+		return err
+	}
+
+	if existing != nil {
+		return &DuplicateProofError{LoanID: existing.ID}
+	}
+	return nil
 }
 
 // GetLoan retrieves a loan by ID
@@ -54,6 +532,12 @@ func (s *loanService) GetLoans(filters map[string]interface{}) ([]domain.Loan, e
 	return s.repo.FindAll(filters)
 }
 
+// ExportLoans calls fn with each loan matching the filters of GetLoans without loading them all at once.
+// The loans are passed without their investments, covenants and tags.
+func (s *loanService) ExportLoans(filters map[string]interface{}, fn func(loan *domain.Loan) error) error {
+	return s.repo.EachLoan(filters, fn)
+}
+
 // UpdateLoan updates a loan
 func (s *loanService) UpdateLoan(id string, updates map[string]interface{}) (*domain.Loan, error) {
 	loan, err := s.repo.FindByID(id)
@@ -64,22 +548,46 @@ func (s *loanService) UpdateLoan(id string, updates map[string]interface{}) (*do
 	if !loan.CanUpdate() {
 		return nil, errors.New("can only update loans in proposed status")
 	}
+	previous := *loan
 
 	// Apply updates
-	if principalAmount, ok := updates["principal_amount"].(float64); ok {
+	if principalAmount, ok := updates["principal_amount"].(domain.Money); ok {
+		if err := s.checkWholeUnits(principalAmount); err != nil {
+			return nil, err
+		}
+		// Committed investments must still fit in the loan
+		if principalAmount < loan.TotalInvested {
+			return nil, domain.ErrPrincipalBelowInvested
+		}
 		loan.PrincipalAmount = principalAmount
 	}
 	if rate, ok := updates["rate"].(float64); ok {
 		loan.Rate = rate
 	}
 	if roi, ok := updates["roi"].(float64); ok {
+		if err := s.checkROI(roi); err != nil {
+			return nil, err
+		}
 		loan.ROI = roi
 	}
+	if err := s.checkROIAgainstRate(loan.ROI, loan.Rate); err != nil {
+		return nil, err
+	}
 	if agreementLetterLink, ok := updates["agreement_letter_link"].(string); ok {
 		loan.AgreementLetterLink = agreementLetterLink
 	}
 
-	err = s.repo.Update(loan)
+	// Renegotiated terms are recorded in the loan's history; updates that change nothing are not
+	if changes := loan.TermChanges(&previous); len(changes) > 0 {
+		loan.RecordTransition(loan.Status, "update", "", s.clock.Now(), changes)
+	}
+
+	if tags, ok := updates["tags"].([]string); ok {
+		loan.SetTags(tags)
+		err = s.repo.UpdateWithTags(loan)
+	} else {
+		err = s.repo.Update(loan)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -87,7 +595,7 @@ func (s *loanService) UpdateLoan(id string, updates map[string]interface{}) (*do
 	return loan, nil
 }
 
-// DeleteLoan deletes a loan
+// DeleteLoan deletes a proposed loan that has no investments or lifecycle history
 func (s *loanService) DeleteLoan(id string) error {
 	loan, err := s.repo.FindByID(id)
 	if err != nil {
@@ -98,18 +606,63 @@ func (s *loanService) DeleteLoan(id string) error {
 		return errors.New("can only delete loans in proposed status")
 	}
 
+	// A proposed loan may still have been approved and invested in before, and deleting it would orphan
+	// that history
+	hasHistory, err := s.repo.HasHistory(id)
+	if err != nil {
+		return err
+	}
+	if hasHistory {
+		return domain.ErrLoanHasHistory
+	}
+
 	return s.repo.Delete(id)
 }
 
-// ApproveLoan approves a loan
-func (s *loanService) ApproveLoan(id string, approvalDetails *domain.ApprovalDetails) (*domain.Loan, error) {
+// GetDeletedLoans retrieves all deleted loans
+func (s *loanService) GetDeletedLoans() ([]domain.Loan, error) {
+	return s.repo.FindDeleted()
+}
+
+// RestoreLoan restores a loan that was deleted in proposed status. The restored loan is active
+// again, so it counts against the borrower's active loan limit.
+func (s *loanService) RestoreLoan(id string) (*domain.Loan, error) {
+	loan, err := s.repo.FindDeletedByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !loan.CanRestore() {
+		return nil, domain.ErrCannotRestore
+	}
+
+	activeLoans, err := s.countActiveLoans(loan.BorrowerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkActiveLoanLimit(activeLoans); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Restore(id); err != nil {
+		return nil, err
+	}
+	return s.repo.FindByID(id)
+}
+
+// ApproveLoan approves a loan, attaching any covenants that must be satisfied before disbursement
+func (s *loanService) ApproveLoan(id string, approvalDetails *domain.ApprovalDetails, covenants ...string) (*domain.Loan, error) {
 	loan, err := s.repo.FindByID(id)
 	if err != nil {
 		return nil, err
 	}
 
-	if !loan.CanApprove() {
-		return nil, errors.New("can only approve loans in proposed status")
+	if err := approvalConflict(loan); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkUniqueProof(approvalDetails.FieldValidatorProof, loan.ID); err != nil {
+		return nil, err
 	}
 
 	fsm := domain.NewFSM()
@@ -118,79 +671,940 @@ func (s *loanService) ApproveLoan(id string, approvalDetails *domain.ApprovalDet
 		return nil, err
 	}
 
+	fromStatus := loan.Status
 	loan.Status = fsm.GetCurrentState()
 	loan.ApprovalDetails = approvalDetails
-	loan.ApprovalDetails.ApprovalDate = time.Now()
+	loan.ApprovalDetails.ApprovalDate = s.clock.Now()
+	if s.cfg.InvestmentWindow > 0 {
+		deadline := loan.ApprovalDetails.ApprovalDate.Add(s.cfg.InvestmentWindow)
+		loan.InvestmentDeadline = &deadline
+	}
+	for _, description := range covenants {
+		loan.Covenants = append(loan.Covenants, domain.Covenant{LoanID: loan.ID, Description: description})
+	}
+	loan.RecordTransition(fromStatus, "approve", approvalDetails.FieldValidatorID, loan.ApprovalDetails.ApprovalDate, nil)
 
-	err = s.repo.Update(loan)
+	// The approval only applies if no concurrent request changed the status since the loan was read
+	approved, err := s.repo.Approve(loan, fromStatus)
 	if err != nil {
 		return nil, err
 	}
+	if !approved {
+		current, err := s.repo.FindByID(id)
+		if err != nil {
+			return nil, err
+		}
+		if err := approvalConflict(current); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("can only approve loans in proposed status")
+	}
 
-	return loan, nil
+	s.publishStatusChange(loan, fromStatus)
+
+	// Auto-invest failures must not undo the approval
+	autoInvested, err := s.runAutoInvest(loan)
+	if err != nil {
+		log.Printf("Auto-invest failed for loan %s: %v", loan.ID, err)
+	}
+
+	return autoInvested, nil
+}
+
+// approvalConflict explains why a loan cannot be approved, naming the validator when it already is
+func approvalConflict(loan *domain.Loan) error {
+	if loan.Status == domain.StatusApproved {
+		conflict := &AlreadyApprovedError{}
+		if loan.ApprovalDetails != nil {
+			conflict.FieldValidatorID = loan.ApprovalDetails.FieldValidatorID
+		}
+		return conflict
+	}
+
+	if !loan.CanApprove() {
+		return errors.New("can only approve loans in proposed status")
+	}
+	return nil
 }
 
-// InvestInLoan adds an investment to a loan
-func (s *loanService) InvestInLoan(id string, investorID string, amount float64) (*domain.Loan, error) {
+// CorrectApproval corrects the proof or validator of an approved loan without re-running the transition.
+// Outside the configured correction window the correction requires an admin override, which the caller
+// grants for admins. Every correction is recorded as an approval amendment, noting whether it needed the
+// override.
+func (s *loanService) CorrectApproval(id string, correction *domain.ApprovalDetails, adminOverride bool) (*domain.Loan, error) {
 	loan, err := s.repo.FindByID(id)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := loan.AddInvestment(investorID, amount); err != nil {
-		return nil, err
+	if loan.ApprovalDetails == nil {
+		return nil, domain.ErrNoApproval
 	}
 
-	// Auto-generate the agreement letter link when the loan becomes invested
-	if loan.Status == domain.StatusInvested {
-		loan.AgreementLetterLink = generateAgreementLetterLink(loan.ID)
+	// Once invested or disbursed, the approval is part of what investors and the agreement relied on
+	if loan.Status != domain.StatusApproved {
+		return nil, domain.ErrApprovalNotCorrectable
 	}
 
-	err = s.repo.Update(loan)
-	if err != nil {
+	overridden := !loan.CanCorrectApproval(s.clock.Now(), s.cfg.ApprovalCorrectionWindow)
+	if overridden && !adminOverride {
+		return nil, ErrCorrectionWindowClosed
+	}
+
+	amendment := &domain.ApprovalAmendment{
+		LoanID:                      loan.ID,
+		PreviousFieldValidatorProof: loan.ApprovalDetails.FieldValidatorProof,
+		PreviousFieldValidatorID:    loan.ApprovalDetails.FieldValidatorID,
+		AdminOverride:               overridden,
+	}
+
+	if len(correction.FieldValidatorProof) > 0 && !slices.Equal(correction.FieldValidatorProof, loan.ApprovalDetails.FieldValidatorProof) {
+		if err := s.checkUniqueProof(correction.FieldValidatorProof, loan.ID); err != nil {
+			return nil, err
+		}
+		loan.ApprovalDetails.FieldValidatorProof = correction.FieldValidatorProof
+	}
+	if correction.FieldValidatorID != "" {
+		loan.ApprovalDetails.FieldValidatorID = correction.FieldValidatorID
+	}
+
+	amendment.FieldValidatorProof = loan.ApprovalDetails.FieldValidatorProof
+	amendment.FieldValidatorID = loan.ApprovalDetails.FieldValidatorID
+
+	if err := s.repo.UpdateWithApprovalAmendment(loan, amendment); err != nil {
 		return nil, err
 	}
 
 	return loan, nil
 }
 
-// DisburseLoan disburses a loan
-func (s *loanService) DisburseLoan(id string, disbursementDetails *domain.DisbursementDetails) (*domain.Loan, error) {
+// SatisfyCovenant marks an approval covenant of a loan as satisfied
+func (s *loanService) SatisfyCovenant(id string, covenantID string) (*domain.Loan, error) {
+	if err := s.repo.SatisfyCovenant(id, covenantID, s.clock.Now()); err != nil {
+		return nil, err
+	}
+	return s.repo.FindByID(id)
+}
+
+// RevokeApproval returns an approved loan without investments to proposed, clearing its approval
+// details and covenants and recording the revocation
+func (s *loanService) RevokeApproval(id string, reason string) (*domain.Loan, error) {
 	loan, err := s.repo.FindByID(id)
 	if err != nil {
 		return nil, err
 	}
 
-	if !loan.CanDisburse() {
-		return nil, errors.New("can only disburse fully invested loans")
+	if !loan.CanRevokeApproval() {
+		return nil, domain.ErrCannotRevokeApproval
 	}
 
 	fsm := domain.NewFSM()
 	fsm.SetCurrentState(loan.Status)
-	if err := fsm.Transition(domain.StatusDisbursed); err != nil {
+	if err := fsm.Transition(domain.StatusProposed); err != nil {
 		return nil, err
 	}
 
+	revocation := &domain.ApprovalRevocation{LoanID: loan.ID, Reason: reason}
+	if loan.ApprovalDetails != nil {
+		revocation.FieldValidatorProof = loan.ApprovalDetails.FieldValidatorProof
+		revocation.FieldValidatorID = loan.ApprovalDetails.FieldValidatorID
+		revocation.ApprovalDate = loan.ApprovalDetails.ApprovalDate
+	}
+
+	fromStatus := loan.Status
 	loan.Status = fsm.GetCurrentState()
-	loan.DisbursementDetails = disbursementDetails
-	loan.DisbursementDetails.DisbursementDate = time.Now()
+	loan.ApprovalDetails = nil
+	loan.InvestmentDeadline = nil
+	loan.Covenants = nil
+	loan.RecordTransition(fromStatus, "revoke_approval", "", s.clock.Now(), map[string]string{"reason": reason})
 
-	err = s.repo.Update(loan)
-	if err != nil {
+	if err := s.repo.RevokeApproval(loan, revocation); err != nil {
 		return nil, err
 	}
+	s.publishStatusChange(loan, fromStatus)
 
 	return loan, nil
 }
 
-// GetLoanTransitions returns valid transitions for a loan
-func (s *loanService) GetLoanTransitions(id string) ([]domain.StateTransition, error) {
+// ForceTransition moves a stuck loan to the given status on behalf of an admin, recording the transition
+// as a manual override with its justification. The transition must still be one the state machine
+// allows. Cancelling or expiring the loan refunds its investments as the regular actions do, and
+// disbursing it cannot be forced. Otherwise only the status changes: other side effects of the regular
+// action, such as agreement generation, are not performed.
+func (s *loanService) ForceTransition(id string, to domain.LoanStatus, actorID, justification string) (*domain.Loan, error) {
+	justification = strings.TrimSpace(justification)
+	if justification == "" {
+		return nil, ErrJustificationRequired
+	}
+
 	loan, err := s.repo.FindByID(id)
 	if err != nil {
 		return nil, err
 	}
 
-	fsm := domain.NewFSM()
+	fsm := domain.NewFSMForPolicy(s.disbursementPolicy())
 	fsm.SetCurrentState(loan.Status)
-	return fsm.GetValidTransitions(), nil
+	action, ok := fsm.ActionFor(to)
+	if !ok {
+		return nil, domain.ErrTransitionNotAllowed
+	}
+	// Disbursing hands the funds to the borrower, which needs the officer and signed agreement only the
+	// regular action records
+	if action == "disburse" {
+		return nil, ErrTransitionNotForceable
+	}
+
+	fromStatus := loan.Status
+	now := s.clock.Now()
+	loan.Status = to
+	loan.RecordManualOverride(fromStatus, action, actorID, now, justification)
+	// Investors get their money back as when the loan is cancelled or expires regularly
+	if action == "cancel" || action == "expire" {
+		refundInvestments(loan, now)
+	}
+
+	if err := s.repo.Update(loan); err != nil {
+		return nil, err
+	}
+	s.publishStatusChange(loan, fromStatus)
+
+	return loan, nil
+}
+
+// ReviewLoan puts a proposed loan under review
+func (s *loanService) ReviewLoan(id string, reviewDetails *domain.ReviewDetails) (*domain.Loan, error) {
+	loan, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !loan.CanReview() {
+		return nil, domain.ErrCannotReview
+	}
+
+	fsm := domain.NewFSM()
+	fsm.SetCurrentState(loan.Status)
+	if err := fsm.Transition(domain.StatusUnderReview); err != nil {
+		return nil, err
+	}
+
+	fromStatus := loan.Status
+	now := s.clock.Now()
+	loan.Status = fsm.GetCurrentState()
+	loan.ReviewDetails = reviewDetails
+	loan.ReviewDetails.ReviewDate = now
+	loan.RecordTransition(fromStatus, "review", reviewDetails.ReviewerID, now, nil)
+
+	err = s.repo.Update(loan)
+	if err != nil {
+		return nil, err
+	}
+	s.publishStatusChange(loan, fromStatus)
+
+	return loan, nil
+}
+
+// RejectLoan records that a field validator refused to approve a proposed or under review loan.
+// Rejected loans are final: they cannot be approved, invested in or disbursed afterwards.
+func (s *loanService) RejectLoan(id string, rejectionDetails *domain.RejectionDetails) (*domain.Loan, error) {
+	loan, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !loan.CanReject() {
+		return nil, domain.ErrCannotReject
+	}
+
+	fsm := domain.NewFSM()
+	fsm.SetCurrentState(loan.Status)
+	if err := fsm.Transition(domain.StatusRejected); err != nil {
+		return nil, err
+	}
+
+	fromStatus := loan.Status
+	loan.Status = fsm.GetCurrentState()
+	loan.RejectionDetails = rejectionDetails
+	loan.RejectionDetails.RejectionDate = s.clock.Now()
+	loan.StatusReason = rejectionDetails.Reason
+	loan.RecordTransition(fromStatus, "reject", rejectionDetails.FieldValidatorID, rejectionDetails.RejectionDate,
+		map[string]string{"reason": rejectionDetails.Reason})
+
+	// A concurrent approval or rejection may have moved the loan on since it was read
+	rejected, err := s.repo.Reject(loan, fromStatus)
+	if err != nil {
+		return nil, err
+	}
+	if !rejected {
+		return nil, domain.ErrCannotReject
+	}
+	s.publishStatusChange(loan, fromStatus)
+
+	return loan, nil
+}
+
+// CancelLoan withdraws a proposed or approved loan that is not fully invested, refunding the investments
+// made so far. Each refund is recorded in the loan's history.
+func (s *loanService) CancelLoan(id, reason string) (*domain.Loan, error) {
+	loan, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !loan.CanCancel() {
+		return nil, domain.ErrCannotCancel
+	}
+
+	fsm := domain.NewFSM()
+	fsm.SetCurrentState(loan.Status)
+	if err := fsm.Transition(domain.StatusCancelled); err != nil {
+		return nil, err
+	}
+
+	fromStatus := loan.Status
+	fromInvested := loan.TotalInvested
+	now := s.clock.Now()
+	loan.Status = fsm.GetCurrentState()
+	loan.StatusReason = reason
+	var metadata map[string]string
+	if reason != "" {
+		metadata = map[string]string{"reason": reason}
+	}
+	loan.RecordTransition(fromStatus, "cancel", "", now, metadata)
+	refundInvestments(loan, now)
+
+	// An investment may have landed since the loan was read
+	cancelled, err := s.repo.Cancel(loan, fromStatus, fromInvested)
+	if err != nil {
+		return nil, err
+	}
+	if !cancelled {
+		return nil, domain.ErrCannotCancel
+	}
+	s.publishStatusChange(loan, fromStatus)
+
+	return loan, nil
+}
+
+// refundInvestments refunds every investment of a loan that is being cancelled or expired, recording
+// each refund in the loan's history
+func refundInvestments(loan *domain.Loan, now time.Time) {
+	for _, refund := range loan.RefundInvestments(now) {
+		loan.RecordTransition(loan.Status, "refund", refund.InvestorID, now, map[string]string{
+			"investment_id": refund.InvestmentID,
+			"amount":        refund.Amount.String(),
+		})
+	}
+}
+
+// expiryReason is the status reason of loans expired for missing their investment deadline
+const expiryReason = "investment deadline passed before the loan was fully funded"
+
+// ExpireOverdueLoans expires the approved loans whose investment deadline passed before now, refunding
+// their investments. It returns the number of loans expired. Loans invested in while they were being
+// expired are left for the next run.
+func (s *loanService) ExpireOverdueLoans(now time.Time) (int, error) {
+	loans, err := s.repo.FindOverdue(now)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for i := range loans {
+		loan := &loans[i]
+
+		fsm := domain.NewFSM()
+		fsm.SetCurrentState(loan.Status)
+		if err := fsm.Transition(domain.StatusExpired); err != nil {
+			return expired, err
+		}
+
+		fromStatus := loan.Status
+		fromInvested := loan.TotalInvested
+		loan.Status = fsm.GetCurrentState()
+		loan.StatusReason = expiryReason
+		loan.RecordTransition(fromStatus, "expire", "", now, map[string]string{
+			"investment_deadline": loan.InvestmentDeadline.UTC().Format(time.RFC3339),
+		})
+		refundInvestments(loan, now)
+
+		ok, err := s.repo.Expire(loan, fromInvested)
+		if err != nil {
+			return expired, err
+		}
+		if !ok {
+			continue
+		}
+		expired++
+		s.publishStatusChange(loan, fromStatus)
+	}
+
+	return expired, nil
+}
+
+// ClearReview returns a loan under review to proposed, clearing the review details
+func (s *loanService) ClearReview(id string) (*domain.Loan, error) {
+	loan, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !loan.CanClearReview() {
+		return nil, domain.ErrNotUnderReview
+	}
+
+	fsm := domain.NewFSM()
+	fsm.SetCurrentState(loan.Status)
+	if err := fsm.Transition(domain.StatusProposed); err != nil {
+		return nil, err
+	}
+
+	fromStatus := loan.Status
+	loan.Status = fsm.GetCurrentState()
+	loan.ReviewDetails = nil
+	loan.RecordTransition(fromStatus, "clear_review", "", s.clock.Now(), nil)
+
+	err = s.repo.Update(loan)
+	if err != nil {
+		return nil, err
+	}
+	s.publishStatusChange(loan, fromStatus)
+
+	return loan, nil
+}
+
+// InvestInLoan adds an investment to a loan. Under the clamp overfund policy the recorded
+// amount, the last investment of the returned loan, may be lower than requested.
+func (s *loanService) InvestInLoan(id string, investorID string, amount domain.Money) (*domain.Loan, error) {
+	return s.InvestInLoanWithCurrency(id, investorID, amount, "")
+}
+
+// InvestInLoanWithCurrency adds an investment whose amount is in the given currency, which must be the
+// loan's. An empty currency is taken to be the loan's.
+func (s *loanService) InvestInLoanWithCurrency(id string, investorID string, amount domain.Money, currency string) (*domain.Loan, error) {
+	if err := s.checkFundingWindow(); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkWholeUnits(amount); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkInvestorActive(investorID); err != nil {
+		return nil, err
+	}
+
+	// The read, the investment with its status change and agreement link, and the write are committed
+	// together, so a failed write leaves no part of the investment behind
+	var loan *domain.Loan
+	var investment *domain.Investment
+	var fromStatus domain.LoanStatus
+	err := s.repo.Transaction(func(repo repository.LoanRepository) error {
+		var err error
+		loan, err = repo.FindByID(id)
+		if err != nil {
+			return err
+		}
+
+		if currency != "" && currency != loan.Currency {
+			return &CurrencyMismatchError{Currency: currency, LoanCurrency: loan.Currency}
+		}
+
+		// Overdue loans are expired by a background job, which may not have run yet
+		if loan.IsOverdue(s.clock.Now()) {
+			return domain.ErrInvestmentDeadlinePassed
+		}
+
+		fee := domain.InvestorFee{
+			Rate:  s.cfg.InvestorFeeRate,
+			Basis: domain.FeeBasis(s.cfg.InvestorFeeBasis),
+		}
+		if err := s.checkMinimumInvestment(loan, amount, fee); err != nil {
+			return err
+		}
+
+		if err := s.checkInvestorLimit(loan, investorID); err != nil {
+			return err
+		}
+
+		fromStatus = loan.Status
+		investment, err = loan.Invest(investorID, amount, fee, domain.OverfundPolicy(s.cfg.OverfundPolicy))
+		if err != nil {
+			return err
+		}
+
+		// Checked on the recorded amount, which the clamp overfund policy may have reduced
+		if err := s.checkInvestorShare(loan, investorID); err != nil {
+			return err
+		}
+		if loan.Status != fromStatus {
+			fsm := domain.NewFSM()
+			fsm.SetCurrentState(fromStatus)
+			if err := fsm.Transition(loan.Status); err != nil {
+				return err
+			}
+			loan.RecordTransition(fromStatus, "invest", investorID, s.clock.Now(), map[string]string{"investment_id": investment.ID})
+		}
+
+		// Auto-generate the agreement letter link when the loan becomes invested
+		if loan.Status == domain.StatusInvested {
+			s.generateAgreement(loan)
+		}
+
+		return repo.Update(loan)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.publishInvestment(loan, investment)
+	s.publishStatusChange(loan, fromStatus)
+	s.notifyInvestmentComplete(loan)
+	return loan, nil
+}
+
+// checkMinimumInvestment rejects investments below the configured minimum, except for a top-up
+// that completes the loan, which may be smaller than the minimum
+func (s *loanService) checkMinimumInvestment(loan *domain.Loan, amount domain.Money, fee domain.InvestorFee) error {
+	min := domain.NewMoney(s.cfg.MinInvestmentAmount)
+	if min <= 0 || amount >= min || !loan.CanInvest() {
+		return nil
+	}
+	if amount >= loan.RemainingCapacity(fee) {
+		return nil
+	}
+	return &MinimumInvestmentError{Amount: amount, Min: min}
+}
+
+// checkInvestorLimit rejects investments from new investors once the loan has the configured maximum
+// number of distinct investors. Investors who already invested may keep adding to their share.
+func (s *loanService) checkInvestorLimit(loan *domain.Loan, investorID string) error {
+	max := s.cfg.MaxInvestorsPerLoan
+	if max <= 0 || loan.HasInvestor(investorID) {
+		return nil
+	}
+	if investors := len(loan.InvestorIDs()); investors >= max {
+		return &InvestorLimitError{Investors: investors, Max: max}
+	}
+	return nil
+}
+
+// checkInvestorShare rejects an investment that took the investor's cumulative amount in the loan above
+// the configured maximum share of its principal. Without a maximum below 100% any share is allowed.
+func (s *loanService) checkInvestorShare(loan *domain.Loan, investorID string) error {
+	maxPercent := s.cfg.MaxInvestorSharePercent
+	if maxPercent <= 0 || maxPercent >= 100 {
+		return nil
+	}
+
+	max := domain.NewMoney(loan.PrincipalAmount.Float64() * maxPercent / 100)
+	if invested := loan.InvestedBy(investorID); invested > max {
+		return &InvestorShareError{Amount: invested, Max: max, MaxPercent: maxPercent}
+	}
+	return nil
+}
+
+// checkInvestorActive rejects investments from investors deactivated for compliance reasons
+func (s *loanService) checkInvestorActive(investorID string) error {
+	if s.investors == nil {
+		return nil
+	}
+
+	investor, err := findInvestor(s.investors, investorID)
+	if err != nil {
+		return err
+	}
+	if !investor.IsActive() {
+		return domain.ErrInvestorInactive
+	}
+	return nil
+}
+
+// checkFundingWindow rejects investments made outside the configured funding window
+func (s *loanService) checkFundingWindow() error {
+	cfg := s.cfg.FundingWindow
+	if !cfg.Enabled {
+		return nil
+	}
+
+	window := domain.FundingWindow{
+		Days:      cfg.Days,
+		OpenHour:  cfg.OpenHour,
+		CloseHour: cfg.CloseHour,
+		Location:  cfg.Location,
+	}
+
+	now := s.clock.Now()
+	if !window.IsOpen(now) {
+		return &FundingWindowClosedError{NextOpen: window.NextOpen(now)}
+	}
+	return nil
+}
+
+// WithdrawInvestment removes an investor's commitment from a loan that is still approved and not fully
+// funded, refunding it and recording the withdrawal in the loan's history. Investments of other loans are
+// not found. A non-empty investorID only lets that investor withdraw the investment.
+func (s *loanService) WithdrawInvestment(id, investmentID, investorID string) (*domain.Loan, error) {
+	loan, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.clock.Now()
+	refund, err := loan.WithdrawInvestment(investmentID, investorID, domain.FeeBasis(s.cfg.InvestorFeeBasis), now)
+	if err != nil {
+		return nil, err
+	}
+	loan.RecordTransition(loan.Status, "withdraw", refund.InvestorID, now, map[string]string{
+		"investment_id": refund.InvestmentID,
+		"amount":        refund.Amount.String(),
+	})
+
+	if err := s.repo.WithdrawInvestment(loan, refund); err != nil {
+		return nil, err
+	}
+	return loan, nil
+}
+
+// DisburseLoan disburses a loan
+func (s *loanService) DisburseLoan(id string, disbursementDetails *domain.DisbursementDetails) (*domain.Loan, error) {
+	loan, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := s.disbursementPolicy()
+	if !loan.CanDisburse(policy) {
+		funded := loan.Status == domain.StatusInvested || policy.AllowsPartial() && loan.Status == domain.StatusApproved
+		if funded && !loan.HasMinimumInvestors(policy) {
+			return nil, &MinimumInvestorsError{Investors: len(loan.InvestorIDs()), Min: policy.MinInvestors}
+		}
+		if policy.AllowsPartial() && loan.Status == domain.StatusApproved {
+			return nil, &MinimumFundingError{FundedPercent: loan.FundedPercent(), MinPercent: policy.MinFundingPercent}
+		}
+		return nil, errors.New("can only disburse fully invested loans")
+	}
+
+	if !loan.CovenantsSatisfied() {
+		return nil, domain.ErrCovenantsNotSatisfied
+	}
+
+	// Workflows uploading signed agreements to arbitrary storage leave the check disabled
+	if s.cfg.RequireAgreementMatch && !signedAgreementMatches(disbursementDetails.SignedAgreementLink, loan.ID) {
+		return nil, ErrAgreementMismatch
+	}
+
+	fsm := domain.NewFSMForPolicy(policy)
+	fsm.SetCurrentState(loan.Status)
+	if err := fsm.Transition(domain.StatusDisbursed); err != nil {
+		return nil, err
+	}
+
+	// Partially funded loans skip the invested state, so they have no agreement yet
+	if loan.Status == domain.StatusApproved {
+		s.generateAgreement(loan)
+	}
+
+	fromStatus := loan.Status
+	now := s.clock.Now()
+	loan.Status = fsm.GetCurrentState()
+	loan.DisbursementDetails = disbursementDetails
+	loan.DisbursementDetails.DisbursementDate = now
+	loan.DisbursementDetails.DisbursedAmount = loan.TotalInvested
+	loan.RecordTransition(fromStatus, "disburse", disbursementDetails.FieldOfficerID, now, nil)
+
+	err = s.repo.Update(loan)
+	if err != nil {
+		return nil, err
+	}
+	s.publishStatusChange(loan, fromStatus)
+
+	return loan, nil
+}
+
+// RecordRepayment records a repayment against a disbursed loan, moving the loan to repaid once the
+// principal and expected interest are covered. A zero repaidAt records the repayment as made now.
+func (s *loanService) RecordRepayment(id string, amount domain.Money, repaidAt time.Time) (*domain.Loan, error) {
+	loan, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if repaidAt.IsZero() {
+		repaidAt = s.clock.Now()
+	}
+
+	fromStatus := loan.Status
+	if _, err := loan.Repay(amount, repaidAt); err != nil {
+		return nil, err
+	}
+	if loan.Status != fromStatus {
+		loan.RecordTransition(fromStatus, "repay", loan.BorrowerID, s.clock.Now(), nil)
+	}
+
+	if err := s.repo.Update(loan); err != nil {
+		return nil, err
+	}
+	s.publishStatusChange(loan, fromStatus)
+	return loan, nil
+}
+
+// disbursementPolicy returns the configured rules for disbursing loans that are not fully invested
+func (s *loanService) disbursementPolicy() domain.DisbursementPolicy {
+	return domain.DisbursementPolicy{
+		Mode:              domain.DisburseMode(s.cfg.DisburseMode),
+		MinFundingPercent: s.cfg.MinFundingPercent,
+		MinInvestors:      s.cfg.MinInvestorsForDisbursement,
+	}
+}
+
+// GetLoanTransitions returns valid transitions for a loan
+func (s *loanService) GetLoanTransitions(id string) ([]domain.StateTransition, error) {
+	loan, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	fsm := domain.NewFSMForPolicy(s.disbursementPolicy())
+	fsm.SetCurrentState(loan.Status)
+	return fsm.GetValidTransitions(), nil
+}
+
+// GetLoanDetails assembles a loan together with its related data, its history and its repayment summary.
+// It issues a fixed number of queries regardless of the number of investments:
+// one for the loan, one batched preload each for its investments, covenants, tags, repayments and refunds,
+// and one for its history events.
+func (s *loanService) GetLoanDetails(id string) (*LoanDetails, error) {
+	loan, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := s.repo.FindEvents(id)
+	if err != nil {
+		return nil, err
+	}
+
+	fsm := domain.NewFSMForPolicy(s.disbursementPolicy())
+	fsm.SetCurrentState(loan.Status)
+
+	return &LoanDetails{
+		Loan:        loan,
+		Transitions: fsm.GetValidTransitions(),
+		Events:      events,
+		Repayment:   loan.RepaymentSummary(),
+	}, nil
+}
+
+// GetLedger returns a loan with its chronological funding events and running totals, including the
+// investments withdrawn from it and their refunds
+func (s *loanService) GetLedger(id string) (*domain.Loan, []domain.LedgerEntry, error) {
+	loan, err := s.repo.FindWithWithdrawals(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return loan, loan.Ledger(domain.FeeBasis(s.cfg.InvestorFeeBasis)), nil
+}
+
+// Reconcile recomputes the total invested of a loan from its investments and corrects the stored total
+// when it has drifted. The correction is recorded in the loan's history and fails with
+// domain.ErrConcurrentUpdate when the loan changed since it was read.
+func (s *loanService) Reconcile(id string) (*Reconciliation, error) {
+	loan, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Reconciliation{Loan: loan, PreviousTotalInvested: loan.TotalInvested}
+	total := loan.InvestedTotal(domain.FeeBasis(s.cfg.InvestorFeeBasis))
+	if total == loan.TotalInvested {
+		return result, nil
+	}
+
+	loan.TotalInvested = total
+	loan.RecordTransition(loan.Status, "reconcile", "", s.clock.Now(), map[string]string{
+		"previous_total_invested": result.PreviousTotalInvested.String(),
+		"total_invested":          total.String(),
+	})
+	if err := s.repo.Update(loan); err != nil {
+		return nil, err
+	}
+
+	result.Corrected = true
+	return result, nil
+}
+
+// GetHistory returns the audit trail of a loan's status transitions in chronological order
+func (s *loanService) GetHistory(id string) ([]domain.LoanEvent, error) {
+	if _, err := s.repo.FindByID(id); err != nil {
+		return nil, err
+	}
+	return s.repo.FindEvents(id)
+}
+
+// GetLoanStats returns live aggregate figures across all loans
+func (s *loanService) GetLoanStats() (*domain.LoanAggregate, error) {
+	return s.repo.Stats().LoanAggregate()
+}
+
+// GetAmortization returns a loan with its per-period principal and interest breakdown.
+// Loans that have not been disbursed yet are projected from the current time.
+func (s *loanService) GetAmortization(id string) (*domain.Loan, []domain.Installment, error) {
+	loan, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return loan, loan.Amortization(s.clock.Now()), nil
+}
+
+// GenerateSchedule returns the monthly repayment schedule of an invested or disbursed loan.
+// Invested loans are scheduled as if disbursed now; earlier loans have no committed funding to schedule.
+func (s *loanService) GenerateSchedule(id string) (*domain.Loan, []domain.Installment, error) {
+	loan, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if loan.Status != domain.StatusInvested && loan.Status != domain.StatusDisbursed {
+		return nil, nil, domain.ErrScheduleNotAvailable
+	}
+	return loan, loan.Amortization(s.clock.Now()), nil
+}
+
+// GetInvestments returns one page of a loan's investments, optionally only those of one investor, along
+// with the loan's investments summed per investor
+func (s *loanService) GetInvestments(id, investorID string, offset, limit int) (*LoanInvestments, error) {
+	loan, err := s.repo.FindByIDWithoutAssociations(id)
+	if err != nil {
+		return nil, err
+	}
+
+	investments, total, err := s.repo.Investments().FindByLoan(id, investorID, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	contributions, err := s.repo.Investments().ContributionsByLoan(id)
+	if err != nil {
+		return nil, err
+	}
+	for i := range contributions {
+		contributions[i].Percentage = domain.ContributionPercentage(contributions[i].Amount, loan.PrincipalAmount)
+	}
+
+	return &LoanInvestments{
+		Loan:          loan,
+		Investments:   investments,
+		Total:         total,
+		Contributions: contributions,
+	}, nil
+}
+
+// GetFundingTimeline returns a loan's persisted investments, oldest first, with the total invested and
+// the percentage of the principal funded after each. Each investment counts its gross or net amount
+// under the fee basis it was made with, matching how TotalInvested was accumulated.
+func (s *loanService) GetFundingTimeline(id string) (*FundingTimeline, error) {
+	loan, err := s.repo.FindByIDWithoutAssociations(id)
+	if err != nil {
+		return nil, err
+	}
+
+	investments, _, err := s.repo.Investments().FindByLoan(id, "", 0, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	steps := make([]FundingStep, 0, len(investments))
+	var total domain.Money
+	for _, investment := range investments {
+		total += investment.FundingAmount()
+
+		var percent float64
+		if loan.PrincipalAmount > 0 {
+			percent = math.Round(float64(total)/float64(loan.PrincipalAmount)*10000) / 100
+		}
+		steps = append(steps, FundingStep{Investment: investment, TotalInvested: total, PercentFunded: percent})
+	}
+	return &FundingTimeline{Loan: loan, Steps: steps}, nil
+}
+
+// GetLoanDiff compares two recorded versions of a loan. It returns domain.ErrVersionNotFound when
+// the loan exists but either version was not recorded.
+func (s *loanService) GetLoanDiff(id string, from, to int) (*LoanDiff, error) {
+	if _, err := s.repo.FindByID(id); err != nil {
+		return nil, err
+	}
+
+	fromVersion, err := s.findVersion(id, from)
+	if err != nil {
+		return nil, err
+	}
+	toVersion, err := s.findVersion(id, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LoanDiff{
+		From:    fromVersion,
+		To:      toVersion,
+		Changes: domain.DiffVersions(fromVersion, toVersion),
+	}, nil
+}
+
+// findVersion loads a recorded version of a loan, reporting a missing version as domain.ErrVersionNotFound
+func (s *loanService) findVersion(id string, version int) (*domain.LoanVersion, error) {
+	record, err := s.repo.FindVersion(id, version)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrVersionNotFound
+	}
+	return record, err
+}
+
+// GetBorrowerLoans returns all loans of a borrower with their total principal and the total paid
+// out to the borrower on disbursed and repaid loans
+func (s *loanService) GetBorrowerLoans(borrowerID string) (*BorrowerLoans, error) {
+	loans, err := s.repo.FindAll(map[string]interface{}{"borrower_id": borrowerID})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &BorrowerLoans{Loans: loans}
+	for i := range loans {
+		result.TotalPrincipal += loans[i].PrincipalAmount
+		if loans[i].Status == domain.StatusDisbursed || loans[i].Status == domain.StatusRepaid {
+			result.TotalDisbursed += loans[i].FundedPrincipal()
+		}
+	}
+	return result, nil
+}
+
+// GetBorrowerHistory returns one page of the combined chronological timeline of a
+// borrower's loans, built from their audit events, along with the total number of entries
+func (s *loanService) GetBorrowerHistory(borrowerID string, page, limit int) ([]domain.TimelineEntry, int, error) {
+	events, total, err := s.repo.FindEventsByBorrower(borrowerID, (page-1)*limit, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	entries := make([]domain.TimelineEntry, len(events))
+	for i := range events {
+		if entries[i], err = events[i].TimelineEntry(); err != nil {
+			return nil, 0, err
+		}
+	}
+	return entries, int(total), nil
+}
+
+// GetInvestorLoans returns one page of the loans an investor has invested in, optionally
+// filtered by status, with the investor's contribution to each
+func (s *loanService) GetInvestorLoans(investorID string, status string, page, limit int) ([]domain.InvestorPosition, int64, error) {
+	return s.repo.FindByInvestor(investorID, status, (page-1)*limit, limit)
+}
+
+// GetInvestorCashflows projects an investor's monthly inflows from the repayment schedules of
+// all their disbursed loans, pro-rated by their share of each loan
+func (s *loanService) GetInvestorCashflows(investorID string) ([]domain.CashflowMonth, error) {
+	positions, _, err := s.repo.FindByInvestor(investorID, string(domain.StatusDisbursed), 0, -1)
+	if err != nil {
+		return nil, err
+	}
+	return domain.ProjectCashflows(positions), nil
 }
@@ -1,8 +1,15 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
 	"testing"
+	"time"
 
+	"loan-service/internal/config"
+	"loan-service/internal/database"
 	"loan-service/internal/domain"
 	"loan-service/internal/repository"
 
@@ -14,20 +21,20 @@ import (
 
 func setupTestService() (*loanService, *gorm.DB) {
 	// Create test database
-	database, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
 	if err != nil {
 		panic("failed to connect to test database")
 	}
 
 	// Auto migrate the schema
-	err = database.AutoMigrate(&domain.Loan{}, &domain.Investment{})
+	err = database.Migrate(db)
 	if err != nil {
 		panic("failed to migrate test database")
 	}
 
-	loanRepo := repository.NewLoanRepository(database)
+	loanRepo := repository.NewLoanRepository(db)
 	loanService := NewLoanService(loanRepo).(*loanService)
-	return loanService, database
+	return loanService, db
 }
 
 func TestCreateLoan(t *testing.T) {
@@ -35,7 +42,7 @@ func TestCreateLoan(t *testing.T) {
 
 	loan := &domain.Loan{
 		BorrowerID:      "user123",
-		PrincipalAmount: 25000.00,
+		PrincipalAmount: domain.NewMoney(25000.00),
 		Rate:            4.5,
 		ROI:             6.0,
 	}
@@ -45,7 +52,7 @@ func TestCreateLoan(t *testing.T) {
 
 	assert.NotEmpty(t, loan.ID)
 	assert.Equal(t, domain.StatusProposed, loan.Status)
-	assert.Equal(t, 0.0, loan.TotalInvested)
+	assert.Equal(t, domain.NewMoney(0.0), loan.TotalInvested)
 }
 
 func TestGetLoan(t *testing.T) {
@@ -54,7 +61,7 @@ func TestGetLoan(t *testing.T) {
 	// Create a loan first
 	loan := &domain.Loan{
 		BorrowerID:      "user123",
-		PrincipalAmount: 25000.00,
+		PrincipalAmount: domain.NewMoney(25000.00),
 		Rate:            4.5,
 		ROI:             6.0,
 	}
@@ -84,14 +91,14 @@ func TestGetLoans(t *testing.T) {
 	// Create multiple loans
 	loan1 := &domain.Loan{
 		BorrowerID:      "user123",
-		PrincipalAmount: 25000.00,
+		PrincipalAmount: domain.NewMoney(25000.00),
 		Rate:            4.5,
 		ROI:             6.0,
 	}
 
 	loan2 := &domain.Loan{
 		BorrowerID:      "user456",
-		PrincipalAmount: 30000.00,
+		PrincipalAmount: domain.NewMoney(30000.00),
 		Rate:            5.0,
 		ROI:             7.0,
 	}
@@ -115,7 +122,7 @@ func TestGetLoansWithFilters(t *testing.T) {
 	// Create loans with different statuses
 	loan1 := &domain.Loan{
 		BorrowerID:      "user123",
-		PrincipalAmount: 25000.00,
+		PrincipalAmount: domain.NewMoney(25000.00),
 		Rate:            4.5,
 		ROI:             6.0,
 		Status:          domain.StatusProposed,
@@ -123,7 +130,7 @@ func TestGetLoansWithFilters(t *testing.T) {
 
 	loan2 := &domain.Loan{
 		BorrowerID:      "user456",
-		PrincipalAmount: 30000.00,
+		PrincipalAmount: domain.NewMoney(30000.00),
 		Rate:            5.0,
 		ROI:             7.0,
 		Status:          domain.StatusApproved,
@@ -154,7 +161,7 @@ func TestUpdateLoan(t *testing.T) {
 	// Create a loan first
 	loan := &domain.Loan{
 		BorrowerID:      "user123",
-		PrincipalAmount: 25000.00,
+		PrincipalAmount: domain.NewMoney(25000.00),
 		Rate:            4.5,
 		ROI:             6.0,
 	}
@@ -164,35 +171,89 @@ func TestUpdateLoan(t *testing.T) {
 
 	// Update the loan
 	updates := map[string]interface{}{
-		"principal_amount": 30000.00,
+		"principal_amount": domain.NewMoney(30000.00),
 		"rate":             5.0,
 	}
 
 	updatedLoan, err := service.UpdateLoan(loan.ID, updates)
 	require.NoError(t, err)
 
-	assert.Equal(t, 30000.00, updatedLoan.PrincipalAmount)
+	assert.Equal(t, domain.NewMoney(30000.00), updatedLoan.PrincipalAmount)
 	assert.Equal(t, 5.0, updatedLoan.Rate)
 }
 
+func TestUpdateLoanRecordsTermChanges(t *testing.T) {
+	service, _ := setupTestService()
+
+	loan := &domain.Loan{
+		BorrowerID:      "user123",
+		PrincipalAmount: domain.NewMoney(25000.00),
+		Rate:            4.5,
+		ROI:             6.0,
+	}
+	require.NoError(t, service.CreateLoan(loan))
+
+	_, err := service.UpdateLoan(loan.ID, map[string]interface{}{
+		"rate": 5.25,
+		"roi":  6.0,
+	})
+	require.NoError(t, err)
+
+	events, err := service.GetHistory(loan.ID)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	update := events[1]
+	assert.Equal(t, "update", update.Action)
+	assert.Equal(t, domain.StatusProposed, update.FromStatus)
+	assert.Equal(t, domain.StatusProposed, update.ToStatus)
+	// Only the rate changed; the ROI was sent with its current value
+	assert.Equal(t, map[string]string{"previous_rate": "4.5", "rate": "5.25"}, update.Metadata)
+
+	// Updates that change nothing leave no audit entry
+	_, err = service.UpdateLoan(loan.ID, map[string]interface{}{
+		"principal_amount": domain.NewMoney(25000.00),
+		"rate":             5.25,
+	})
+	require.NoError(t, err)
+
+	events, err = service.GetHistory(loan.ID)
+	require.NoError(t, err)
+	assert.Len(t, events, 2)
+}
+
 func TestUpdateLoanNotFound(t *testing.T) {
 	service, _ := setupTestService()
 
 	updates := map[string]interface{}{
-		"principal_amount": 30000.00,
+		"principal_amount": domain.NewMoney(30000.00),
 	}
 
 	_, err := service.UpdateLoan("nonexistent-id", updates)
 	assert.Error(t, err)
 }
 
+func TestUpdateLoanPrincipalBelowInvested(t *testing.T) {
+	service, db := setupTestService()
+
+	loan := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: 6.0}
+	require.NoError(t, service.CreateLoan(loan))
+	require.NoError(t, db.Model(&domain.Loan{}).Where("id = ?", loan.ID).Update("total_invested", domain.NewMoney(10000.00)).Error)
+
+	_, err := service.UpdateLoan(loan.ID, map[string]interface{}{"principal_amount": domain.NewMoney(9999.00)})
+	assert.ErrorIs(t, err, domain.ErrPrincipalBelowInvested)
+
+	updated, err := service.UpdateLoan(loan.ID, map[string]interface{}{"principal_amount": domain.NewMoney(10000.00)})
+	require.NoError(t, err)
+	assert.Equal(t, domain.NewMoney(10000.00), updated.PrincipalAmount)
+}
+
 func TestUpdateLoanInvalidState(t *testing.T) {
 	service, _ := setupTestService()
 
 	// Create and approve a loan
 	loan := &domain.Loan{
 		BorrowerID:      "user123",
-		PrincipalAmount: 25000.00,
+		PrincipalAmount: domain.NewMoney(25000.00),
 		Rate:            4.5,
 		ROI:             6.0,
 	}
@@ -202,7 +263,7 @@ func TestUpdateLoanInvalidState(t *testing.T) {
 
 	// Approve the loan
 	approvalDetails := &domain.ApprovalDetails{
-		FieldValidatorProof: "proof",
+		FieldValidatorProof: domain.ProofLinks{"proof"},
 		FieldValidatorID:    "validator_001",
 	}
 
@@ -211,7 +272,7 @@ func TestUpdateLoanInvalidState(t *testing.T) {
 
 	// Try to update approved loan (should fail)
 	updates := map[string]interface{}{
-		"principal_amount": 30000.00,
+		"principal_amount": domain.NewMoney(30000.00),
 	}
 
 	_, err = service.UpdateLoan(loan.ID, updates)
@@ -225,7 +286,7 @@ func TestDeleteLoan(t *testing.T) {
 	// Create a loan
 	loan := &domain.Loan{
 		BorrowerID:      "user123",
-		PrincipalAmount: 25000.00,
+		PrincipalAmount: domain.NewMoney(25000.00),
 		Rate:            4.5,
 		ROI:             6.0,
 	}
@@ -242,6 +303,57 @@ func TestDeleteLoan(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestRestoreLoan(t *testing.T) {
+	service, _ := setupTestService()
+
+	loan := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: 6.0}
+	require.NoError(t, service.CreateLoan(loan))
+	require.NoError(t, service.DeleteLoan(loan.ID))
+
+	deleted, err := service.GetDeletedLoans()
+	require.NoError(t, err)
+	require.Len(t, deleted, 1)
+
+	restored, err := service.RestoreLoan(loan.ID)
+	require.NoError(t, err)
+	assert.Equal(t, loan.ID, restored.ID)
+	assert.Equal(t, domain.StatusProposed, restored.Status)
+
+	_, err = service.GetLoan(loan.ID)
+	require.NoError(t, err)
+
+	// A loan that is not deleted cannot be restored
+	_, err = service.RestoreLoan(loan.ID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestRestoreLoanInvalidState(t *testing.T) {
+	service, _ := setupTestService()
+
+	// Loans are only deleted through the API while proposed, but older data may hold others
+	loan := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: 6.0, Status: domain.StatusApproved}
+	require.NoError(t, service.repo.Create(loan))
+	require.NoError(t, service.repo.Delete(loan.ID))
+
+	_, err := service.RestoreLoan(loan.ID)
+	assert.ErrorIs(t, err, domain.ErrCannotRestore)
+}
+
+func TestRestoreLoanActiveLoanLimit(t *testing.T) {
+	service := setupTestServiceWithOptions(WithConfig(config.LoanConfig{MaxActiveLoansPerBorrower: 1}))
+
+	deleted := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: 6.0}
+	require.NoError(t, service.CreateLoan(deleted))
+	require.NoError(t, service.DeleteLoan(deleted.ID))
+
+	// The borrower took out another loan in the meantime
+	require.NoError(t, service.CreateLoan(&domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(10000.00), Rate: 4.5, ROI: 6.0}))
+
+	_, err := service.RestoreLoan(deleted.ID)
+	var limitErr *ActiveLoanLimitError
+	assert.ErrorAs(t, err, &limitErr)
+}
+
 func TestDeleteLoanNotFound(t *testing.T) {
 	service, _ := setupTestService()
 
@@ -249,13 +361,42 @@ func TestDeleteLoanNotFound(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestDeleteLoanWithHistory(t *testing.T) {
+	service, db := setupTestService()
+
+	// Creating and editing a proposed loan leaves it deletable
+	edited := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: 6.0}
+	require.NoError(t, service.CreateLoan(edited))
+	_, err := service.UpdateLoan(edited.ID, map[string]interface{}{"principal_amount": domain.NewMoney(20000.00)})
+	require.NoError(t, err)
+	assert.NoError(t, service.DeleteLoan(edited.ID))
+
+	// A proposed loan that somehow has an investment
+	invested := &domain.Loan{BorrowerID: "user456", PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: 6.0}
+	require.NoError(t, service.CreateLoan(invested))
+	require.NoError(t, db.Create(&domain.Investment{LoanID: invested.ID, InvestorID: "investor_001", Amount: domain.NewMoney(1000.00)}).Error)
+	assert.ErrorIs(t, service.DeleteLoan(invested.ID), domain.ErrLoanHasHistory)
+
+	// A proposed loan that was approved before
+	revoked := createApprovedLoan(t, service, 25000.00)
+	_, err = service.RevokeApproval(revoked.ID, "wrong validator")
+	require.NoError(t, err)
+	assert.ErrorIs(t, service.DeleteLoan(revoked.ID), domain.ErrLoanHasHistory)
+
+	for _, id := range []string{invested.ID, revoked.ID} {
+		stored, err := service.GetLoan(id)
+		require.NoError(t, err)
+		assert.Equal(t, domain.StatusProposed, stored.Status)
+	}
+}
+
 func TestDeleteLoanInvalidState(t *testing.T) {
 	service, _ := setupTestService()
 
 	// Create and approve a loan
 	loan := &domain.Loan{
 		BorrowerID:      "user123",
-		PrincipalAmount: 25000.00,
+		PrincipalAmount: domain.NewMoney(25000.00),
 		Rate:            4.5,
 		ROI:             6.0,
 	}
@@ -265,7 +406,7 @@ func TestDeleteLoanInvalidState(t *testing.T) {
 
 	// Approve the loan
 	approvalDetails := &domain.ApprovalDetails{
-		FieldValidatorProof: "proof",
+		FieldValidatorProof: domain.ProofLinks{"proof"},
 		FieldValidatorID:    "validator_001",
 	}
 
@@ -284,7 +425,7 @@ func TestApproveLoan(t *testing.T) {
 	// Create a loan
 	loan := &domain.Loan{
 		BorrowerID:      "user123",
-		PrincipalAmount: 25000.00,
+		PrincipalAmount: domain.NewMoney(25000.00),
 		Rate:            4.5,
 		ROI:             6.0,
 	}
@@ -294,7 +435,7 @@ func TestApproveLoan(t *testing.T) {
 
 	// Approve the loan
 	approvalDetails := &domain.ApprovalDetails{
-		FieldValidatorProof: "proof",
+		FieldValidatorProof: domain.ProofLinks{"proof"},
 		FieldValidatorID:    "validator_001",
 	}
 
@@ -303,7 +444,7 @@ func TestApproveLoan(t *testing.T) {
 
 	assert.Equal(t, domain.StatusApproved, approvedLoan.Status)
 	assert.NotNil(t, approvedLoan.ApprovalDetails)
-	assert.Equal(t, "proof", approvedLoan.ApprovalDetails.FieldValidatorProof)
+	assert.Equal(t, domain.ProofLinks{"proof"}, approvedLoan.ApprovalDetails.FieldValidatorProof)
 	assert.Equal(t, "validator_001", approvedLoan.ApprovalDetails.FieldValidatorID)
 }
 
@@ -311,7 +452,7 @@ func TestApproveLoanNotFound(t *testing.T) {
 	service, _ := setupTestService()
 
 	approvalDetails := &domain.ApprovalDetails{
-		FieldValidatorProof: "proof",
+		FieldValidatorProof: domain.ProofLinks{"proof"},
 		FieldValidatorID:    "validator_001",
 	}
 
@@ -325,7 +466,7 @@ func TestApproveLoanInvalidState(t *testing.T) {
 	// Create and approve a loan
 	loan := &domain.Loan{
 		BorrowerID:      "user123",
-		PrincipalAmount: 25000.00,
+		PrincipalAmount: domain.NewMoney(25000.00),
 		Rate:            4.5,
 		ROI:             6.0,
 	}
@@ -335,7 +476,7 @@ func TestApproveLoanInvalidState(t *testing.T) {
 
 	// Approve the loan
 	approvalDetails := &domain.ApprovalDetails{
-		FieldValidatorProof: "proof",
+		FieldValidatorProof: domain.ProofLinks{"proof"},
 		FieldValidatorID:    "validator_001",
 	}
 
@@ -344,8 +485,9 @@ func TestApproveLoanInvalidState(t *testing.T) {
 
 	// Try to approve again (should fail)
 	_, err = service.ApproveLoan(loan.ID, approvalDetails)
-	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "can only approve loans in proposed status")
+	var approvedErr *AlreadyApprovedError
+	require.ErrorAs(t, err, &approvedErr)
+	assert.Equal(t, "validator_001", approvedErr.FieldValidatorID)
 }
 
 func TestInvestInLoan(t *testing.T) {
@@ -354,7 +496,7 @@ func TestInvestInLoan(t *testing.T) {
 	// Create and approve a loan
 	loan := &domain.Loan{
 		BorrowerID:      "user123",
-		PrincipalAmount: 25000.00,
+		PrincipalAmount: domain.NewMoney(25000.00),
 		Rate:            4.5,
 		ROI:             6.0,
 	}
@@ -363,7 +505,7 @@ func TestInvestInLoan(t *testing.T) {
 	require.NoError(t, err)
 
 	approvalDetails := &domain.ApprovalDetails{
-		FieldValidatorProof: "proof",
+		FieldValidatorProof: domain.ProofLinks{"proof"},
 		FieldValidatorID:    "validator_001",
 	}
 
@@ -371,19 +513,19 @@ func TestInvestInLoan(t *testing.T) {
 	require.NoError(t, err)
 
 	// Invest in the loan
-	investedLoan, err := service.InvestInLoan(loan.ID, "investor_001", 10000.00)
+	investedLoan, err := service.InvestInLoan(loan.ID, "investor_001", domain.NewMoney(10000.00))
 	require.NoError(t, err)
 
-	assert.Equal(t, 10000.00, investedLoan.TotalInvested)
+	assert.Equal(t, domain.NewMoney(10000.00), investedLoan.TotalInvested)
 	assert.Len(t, investedLoan.Investments, 1)
 	assert.Equal(t, "investor_001", investedLoan.Investments[0].InvestorID)
-	assert.Equal(t, 10000.00, investedLoan.Investments[0].Amount)
+	assert.Equal(t, domain.NewMoney(10000.00), investedLoan.Investments[0].Amount)
 }
 
 func TestInvestInLoanNotFound(t *testing.T) {
 	service, _ := setupTestService()
 
-	_, err := service.InvestInLoan("nonexistent-id", "investor_001", 10000.00)
+	_, err := service.InvestInLoan("nonexistent-id", "investor_001", domain.NewMoney(10000.00))
 	assert.Error(t, err)
 }
 
@@ -393,7 +535,7 @@ func TestInvestInLoanInvalidState(t *testing.T) {
 	// Create a loan (not approved)
 	loan := &domain.Loan{
 		BorrowerID:      "user123",
-		PrincipalAmount: 25000.00,
+		PrincipalAmount: domain.NewMoney(25000.00),
 		Rate:            4.5,
 		ROI:             6.0,
 	}
@@ -402,9 +544,10 @@ func TestInvestInLoanInvalidState(t *testing.T) {
 	require.NoError(t, err)
 
 	// Try to invest in unapproved loan (should fail)
-	_, err = service.InvestInLoan(loan.ID, "investor_001", 10000.00)
+	_, err = service.InvestInLoan(loan.ID, "investor_001", domain.NewMoney(10000.00))
 	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "loan is not in approved status")
+	assert.ErrorIs(t, err, domain.ErrLoanNotApproved)
+	assert.EqualError(t, err, "cannot invest: loan is not approved yet")
 }
 
 func TestInvestInLoanExceedsLimit(t *testing.T) {
@@ -413,7 +556,7 @@ func TestInvestInLoanExceedsLimit(t *testing.T) {
 	// Create and approve a loan
 	loan := &domain.Loan{
 		BorrowerID:      "user123",
-		PrincipalAmount: 25000.00,
+		PrincipalAmount: domain.NewMoney(25000.00),
 		Rate:            4.5,
 		ROI:             6.0,
 	}
@@ -422,7 +565,7 @@ func TestInvestInLoanExceedsLimit(t *testing.T) {
 	require.NoError(t, err)
 
 	approvalDetails := &domain.ApprovalDetails{
-		FieldValidatorProof: "proof",
+		FieldValidatorProof: domain.ProofLinks{"proof"},
 		FieldValidatorID:    "validator_001",
 	}
 
@@ -430,7 +573,7 @@ func TestInvestInLoanExceedsLimit(t *testing.T) {
 	require.NoError(t, err)
 
 	// Try to invest more than principal amount (should fail)
-	_, err = service.InvestInLoan(loan.ID, "investor_001", 30000.00)
+	_, err = service.InvestInLoan(loan.ID, "investor_001", domain.NewMoney(30000.00))
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "total investment amount would exceed loan principal")
 }
@@ -441,7 +584,7 @@ func TestDisburseLoan(t *testing.T) {
 	// Create, approve, and fully invest in a loan
 	loan := &domain.Loan{
 		BorrowerID:      "user123",
-		PrincipalAmount: 25000.00,
+		PrincipalAmount: domain.NewMoney(25000.00),
 		Rate:            4.5,
 		ROI:             6.0,
 	}
@@ -450,14 +593,14 @@ func TestDisburseLoan(t *testing.T) {
 	require.NoError(t, err)
 
 	approvalDetails := &domain.ApprovalDetails{
-		FieldValidatorProof: "proof",
+		FieldValidatorProof: domain.ProofLinks{"proof"},
 		FieldValidatorID:    "validator_001",
 	}
 
 	_, err = service.ApproveLoan(loan.ID, approvalDetails)
 	require.NoError(t, err)
 
-	_, err = service.InvestInLoan(loan.ID, "investor_001", 25000.00)
+	_, err = service.InvestInLoan(loan.ID, "investor_001", domain.NewMoney(25000.00))
 	require.NoError(t, err)
 
 	// Disburse the loan
@@ -493,7 +636,7 @@ func TestDisburseLoanNotFullyInvested(t *testing.T) {
 	// Create and approve a loan
 	loan := &domain.Loan{
 		BorrowerID:      "user123",
-		PrincipalAmount: 25000.00,
+		PrincipalAmount: domain.NewMoney(25000.00),
 		Rate:            4.5,
 		ROI:             6.0,
 	}
@@ -502,7 +645,7 @@ func TestDisburseLoanNotFullyInvested(t *testing.T) {
 	require.NoError(t, err)
 
 	approvalDetails := &domain.ApprovalDetails{
-		FieldValidatorProof: "proof",
+		FieldValidatorProof: domain.ProofLinks{"proof"},
 		FieldValidatorID:    "validator_001",
 	}
 
@@ -510,7 +653,7 @@ func TestDisburseLoanNotFullyInvested(t *testing.T) {
 	require.NoError(t, err)
 
 	// Invest partially
-	_, err = service.InvestInLoan(loan.ID, "investor_001", 10000.00)
+	_, err = service.InvestInLoan(loan.ID, "investor_001", domain.NewMoney(10000.00))
 	require.NoError(t, err)
 
 	// Try to disburse partially invested loan (should fail)
@@ -524,13 +667,109 @@ func TestDisburseLoanNotFullyInvested(t *testing.T) {
 	assert.Contains(t, err.Error(), "can only disburse fully invested loans")
 }
 
+func TestDisburseLoanPartialFunding(t *testing.T) {
+	service := setupTestServiceWithOptions(WithConfig(config.LoanConfig{DisburseMode: "partial_allowed", MinFundingPercent: 60}))
+
+	loan := createApprovedLoan(t, service, 10000.00)
+	_, err := service.InvestInLoan(loan.ID, "investor_001", domain.NewMoney(3000.00))
+	require.NoError(t, err)
+
+	disbursementDetails := &domain.DisbursementDetails{
+		SignedAgreementLink: "https://example.com/signed-agreement.pdf",
+		FieldOfficerID:      "officer_001",
+	}
+
+	// Below the minimum funding
+	_, err = service.DisburseLoan(loan.ID, disbursementDetails)
+	var fundingErr *MinimumFundingError
+	require.ErrorAs(t, err, &fundingErr)
+	assert.Equal(t, 30.0, fundingErr.FundedPercent)
+
+	_, err = service.InvestInLoan(loan.ID, "investor_002", domain.NewMoney(4000.00))
+	require.NoError(t, err)
+
+	transitions, err := service.GetLoanTransitions(loan.ID)
+	require.NoError(t, err)
+	assert.Contains(t, transitions, domain.StateTransition{From: domain.StatusApproved, To: domain.StatusDisbursed, Action: "disburse"})
+
+	disbursed, err := service.DisburseLoan(loan.ID, disbursementDetails)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusDisbursed, disbursed.Status)
+	assert.Equal(t, domain.NewMoney(7000.00), disbursed.DisbursementDetails.DisbursedAmount)
+	assert.NotEmpty(t, disbursed.AgreementLetterLink)
+
+	// The repayment schedule covers only the funded principal
+	repaid := 0.0
+	for _, installment := range disbursed.RepaymentSchedule() {
+		repaid += installment.Principal
+	}
+	assert.InDelta(t, 7000.00, repaid, 0.001)
+
+	// Investors are repaid pro-rata to their share of the funded principal
+	cashflows, err := service.GetInvestorCashflows("investor_002")
+	require.NoError(t, err)
+	total := 0.0
+	for _, month := range cashflows {
+		total += month.Principal
+	}
+	assert.InDelta(t, 4000.00, total, 0.05)
+}
+
+func TestDisburseLoanMinimumInvestors(t *testing.T) {
+	service := setupTestServiceWithOptions(WithConfig(config.LoanConfig{MinInvestorsForDisbursement: 3}))
+	disbursementDetails := &domain.DisbursementDetails{
+		SignedAgreementLink: "https://example.com/signed-agreement.pdf",
+		FieldOfficerID:      "officer_001",
+	}
+
+	// A single investor funding the whole loan is not enough
+	single := createApprovedLoan(t, service, 9000.00)
+	_, err := service.InvestInLoan(single.ID, "investor_001", domain.NewMoney(9000.00))
+	require.NoError(t, err)
+
+	_, err = service.DisburseLoan(single.ID, disbursementDetails)
+	var investorsErr *MinimumInvestorsError
+	require.ErrorAs(t, err, &investorsErr)
+	assert.Equal(t, 1, investorsErr.Investors)
+	assert.Equal(t, 3, investorsErr.Min)
+
+	// Three investors meet the minimum
+	spread := createApprovedLoan(t, service, 9000.00)
+	for _, investorID := range []string{"investor_001", "investor_002", "investor_003"} {
+		_, err := service.InvestInLoan(spread.ID, investorID, domain.NewMoney(3000.00))
+		require.NoError(t, err)
+	}
+
+	disbursed, err := service.DisburseLoan(spread.ID, disbursementDetails)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusDisbursed, disbursed.Status)
+}
+
+func TestDisburseLoanPartialFundingDisabledByDefault(t *testing.T) {
+	service, _ := setupTestService()
+
+	loan := createApprovedLoan(t, service, 10000.00)
+	_, err := service.InvestInLoan(loan.ID, "investor_001", domain.NewMoney(9000.00))
+	require.NoError(t, err)
+
+	_, err = service.DisburseLoan(loan.ID, &domain.DisbursementDetails{FieldOfficerID: "officer_001"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "can only disburse fully invested loans")
+
+	transitions, err := service.GetLoanTransitions(loan.ID)
+	require.NoError(t, err)
+	for _, transition := range transitions {
+		assert.NotEqual(t, domain.StatusDisbursed, transition.To)
+	}
+}
+
 func TestGetLoanTransitions(t *testing.T) {
 	service, _ := setupTestService()
 
 	// Create a loan
 	loan := &domain.Loan{
 		BorrowerID:      "user123",
-		PrincipalAmount: 25000.00,
+		PrincipalAmount: domain.NewMoney(25000.00),
 		Rate:            4.5,
 		ROI:             6.0,
 	}
@@ -542,7 +781,7 @@ func TestGetLoanTransitions(t *testing.T) {
 	transitions, err := service.GetLoanTransitions(loan.ID)
 	require.NoError(t, err)
 
-	assert.Len(t, transitions, 1)
+	assert.Len(t, transitions, 4)
 	assert.Equal(t, domain.StatusApproved, transitions[0].To)
 	assert.Equal(t, "approve", transitions[0].Action)
 }
@@ -560,7 +799,7 @@ func TestInvestInLoanAutoGeneratesAgreementLink(t *testing.T) {
 	// Create and approve a loan
 	loan := &domain.Loan{
 		BorrowerID:      "user123",
-		PrincipalAmount: 10000.00,
+		PrincipalAmount: domain.NewMoney(10000.00),
 		Rate:            4.5,
 		ROI:             6.0,
 	}
@@ -569,7 +808,7 @@ func TestInvestInLoanAutoGeneratesAgreementLink(t *testing.T) {
 	require.NoError(t, err)
 
 	approvalDetails := &domain.ApprovalDetails{
-		FieldValidatorProof: "proof",
+		FieldValidatorProof: domain.ProofLinks{"proof"},
 		FieldValidatorID:    "validator_001",
 	}
 
@@ -577,13 +816,2183 @@ func TestInvestInLoanAutoGeneratesAgreementLink(t *testing.T) {
 	require.NoError(t, err)
 
 	// Invest fully in the loan (should auto-generate agreement letter link)
-	investedLoan, err := service.InvestInLoan(loan.ID, "investor_001", 10000.00)
+	investedLoan, err := service.InvestInLoan(loan.ID, "investor_001", domain.NewMoney(10000.00))
 	require.NoError(t, err)
 
 	// Verify the loan is now invested and has auto-generated agreement letter link
 	assert.Equal(t, domain.StatusInvested, investedLoan.Status)
-	assert.Equal(t, 10000.00, investedLoan.TotalInvested)
+	assert.Equal(t, domain.NewMoney(10000.00), investedLoan.TotalInvested)
 	assert.NotEmpty(t, investedLoan.AgreementLetterLink)
 	assert.Contains(t, investedLoan.AgreementLetterLink, "https://example.com/agreements/loan_")
 	assert.Contains(t, investedLoan.AgreementLetterLink, "_agreement.pdf")
 }
+
+func TestInvestInLoanAgreementLinkTemplate(t *testing.T) {
+	for _, template := range []string{
+		"https://docs.lender.test/agreements/{id}.pdf",
+		"https://docs.lender.test/agreements/%s.pdf",
+	} {
+		service := setupTestServiceWithOptions(WithConfig(config.LoanConfig{AgreementLinkTemplate: template}))
+		loan := createApprovedLoan(t, service, 10000.00)
+
+		investedLoan, err := service.InvestInLoan(loan.ID, "investor_001", domain.NewMoney(10000.00))
+		require.NoError(t, err)
+		assert.Equal(t, "https://docs.lender.test/agreements/"+loan.ID+".pdf", investedLoan.AgreementLetterLink)
+	}
+}
+
+// fixedClock is a Clock that always returns the same time
+type fixedClock struct {
+	now time.Time
+}
+
+func (c fixedClock) Now() time.Time {
+	return c.now
+}
+
+func setupTestServiceWithOptions(opts ...Option) *loanService {
+	_, db := setupTestService()
+	return NewLoanService(repository.NewLoanRepository(db), opts...).(*loanService)
+}
+
+func fundingWindowConfig() config.LoanConfig {
+	return config.LoanConfig{
+		FundingWindow: config.FundingWindowConfig{
+			Enabled:   true,
+			Days:      []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+			OpenHour:  9,
+			CloseHour: 17,
+			Location:  time.UTC,
+		},
+	}
+}
+
+func createApprovedLoan(t *testing.T, service *loanService, principal float64) *domain.Loan {
+	loan := &domain.Loan{
+		BorrowerID:      "user123",
+		PrincipalAmount: domain.NewMoney(principal),
+		Rate:            4.5,
+		ROI:             6.0,
+	}
+	require.NoError(t, service.CreateLoan(loan))
+
+	approvalDetails := &domain.ApprovalDetails{
+		FieldValidatorProof: domain.ProofLinks{"proof"},
+		FieldValidatorID:    "validator_001",
+	}
+	approvedLoan, err := service.ApproveLoan(loan.ID, approvalDetails)
+	require.NoError(t, err)
+	return approvedLoan
+}
+
+func TestInvestInLoanInsideFundingWindow(t *testing.T) {
+	// Wednesday 10:00 UTC
+	clock := fixedClock{now: time.Date(2024, 1, 10, 10, 0, 0, 0, time.UTC)}
+	service := setupTestServiceWithOptions(WithConfig(fundingWindowConfig()), WithClock(clock))
+
+	loan := createApprovedLoan(t, service, 25000.00)
+
+	investedLoan, err := service.InvestInLoan(loan.ID, "investor_001", domain.NewMoney(10000.00))
+	require.NoError(t, err)
+	assert.Equal(t, domain.NewMoney(10000.00), investedLoan.TotalInvested)
+}
+
+func TestInvestInLoanOutsideFundingWindow(t *testing.T) {
+	// Saturday 10:00 UTC
+	clock := fixedClock{now: time.Date(2024, 1, 13, 10, 0, 0, 0, time.UTC)}
+	service := setupTestServiceWithOptions(WithConfig(fundingWindowConfig()), WithClock(clock))
+
+	loan := createApprovedLoan(t, service, 25000.00)
+
+	_, err := service.InvestInLoan(loan.ID, "investor_001", domain.NewMoney(10000.00))
+	require.Error(t, err)
+
+	var windowErr *FundingWindowClosedError
+	require.ErrorAs(t, err, &windowErr)
+	assert.Equal(t, time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC), windowErr.NextOpen)
+
+	// No investment should have been recorded
+	storedLoan, err := service.GetLoan(loan.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.NewMoney(0.0), storedLoan.TotalInvested)
+}
+
+func TestGetLoanDetails(t *testing.T) {
+	service, db := setupTestService()
+
+	loan := createApprovedLoan(t, service, 30000.00)
+	for _, investorID := range []string{"investor_001", "investor_002", "investor_003"} {
+		_, err := service.InvestInLoan(loan.ID, investorID, domain.NewMoney(5000.00))
+		require.NoError(t, err)
+	}
+
+	// Count the queries issued while assembling the details
+	queries := 0
+	err := db.Callback().Query().After("gorm:query").Register("test:count_queries", func(*gorm.DB) {
+		queries++
+	})
+	require.NoError(t, err)
+
+	details, err := service.GetLoanDetails(loan.ID)
+	require.NoError(t, err)
+
+	assert.Equal(t, loan.ID, details.Loan.ID)
+	assert.Len(t, details.Loan.Investments, 3)
+	assert.Len(t, details.Transitions, 4)
+	assert.Equal(t, "invest", details.Transitions[0].Action)
+	require.Len(t, details.Events, 2)
+	assert.Equal(t, "create", details.Events[0].Action)
+	assert.Equal(t, "approve", details.Events[1].Action)
+	assert.Equal(t, domain.Money(0), details.Repayment.TotalRepaid)
+	assert.Empty(t, details.Repayment.Repayments)
+	assert.Equal(t, 7, queries)
+}
+
+func TestGetLoanDetailsRepaymentSummary(t *testing.T) {
+	service, _ := setupTestService()
+
+	loan := createDisbursedLoan(t, service, 12000.00)
+	due := loan.AmountDue()
+	_, err := service.RecordRepayment(loan.ID, domain.NewMoney(1000.00), time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	details, err := service.GetLoanDetails(loan.ID)
+	require.NoError(t, err)
+	assert.Equal(t, due, details.Repayment.AmountDue)
+	assert.Equal(t, domain.NewMoney(1000.00), details.Repayment.TotalRepaid)
+	assert.Equal(t, due-domain.NewMoney(1000.00), details.Repayment.RemainingBalance)
+	require.Len(t, details.Repayment.Repayments, 1)
+	assert.Equal(t, domain.NewMoney(1000.00), details.Repayment.Repayments[0].Amount)
+	assert.Equal(t, "disburse", details.Events[len(details.Events)-1].Action)
+}
+
+func TestGetLoanDetailsNotFound(t *testing.T) {
+	service, _ := setupTestService()
+
+	_, err := service.GetLoanDetails("nonexistent-id")
+	assert.Error(t, err)
+}
+
+func TestCreateLoanActiveLoanLimit(t *testing.T) {
+	service := setupTestServiceWithOptions(WithConfig(config.LoanConfig{MaxActiveLoansPerBorrower: 2}))
+
+	for i := 0; i < 2; i++ {
+		loan := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: 6.0}
+		require.NoError(t, service.CreateLoan(loan))
+	}
+
+	loan := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: 6.0}
+	err := service.CreateLoan(loan)
+	require.Error(t, err)
+
+	var limitErr *ActiveLoanLimitError
+	require.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, int64(2), limitErr.ActiveLoans)
+
+	// Other borrowers are not affected
+	otherLoan := &domain.Loan{BorrowerID: "user456", PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: 6.0}
+	assert.NoError(t, service.CreateLoan(otherLoan))
+}
+
+func TestCreateLoanActiveLoanLimitIgnoresTerminalLoans(t *testing.T) {
+	service := setupTestServiceWithOptions(WithConfig(config.LoanConfig{MaxActiveLoansPerBorrower: 1}))
+
+	repaid := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: 6.0}
+	require.NoError(t, service.CreateLoan(repaid))
+	repaid.Status = domain.StatusRepaid
+	require.NoError(t, service.repo.Update(repaid))
+
+	loan := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: 6.0}
+	assert.NoError(t, service.CreateLoan(loan))
+}
+
+func TestCreateLoanActiveLoanLimitCountsDisbursedLoans(t *testing.T) {
+	service := setupTestServiceWithOptions(WithConfig(config.LoanConfig{MaxActiveLoansPerBorrower: 1}))
+
+	// Disbursed loans are still being repaid, so they are not terminal
+	disbursed := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: 6.0}
+	require.NoError(t, service.CreateLoan(disbursed))
+	disbursed.Status = domain.StatusDisbursed
+	require.NoError(t, service.repo.Update(disbursed))
+
+	loan := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: 6.0}
+	var limitErr *ActiveLoanLimitError
+	assert.ErrorAs(t, service.CreateLoan(loan), &limitErr)
+}
+
+func TestCreateLoanDuplicate(t *testing.T) {
+	newLoan := func() *domain.Loan {
+		return &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Rate: 6.0, ROI: 4.5}
+	}
+
+	t.Run("rejected", func(t *testing.T) {
+		service := setupTestServiceWithOptions(WithConfig(config.LoanConfig{RejectDuplicateLoans: true}))
+
+		first := newLoan()
+		require.NoError(t, service.CreateLoan(first))
+
+		err := service.CreateLoan(newLoan())
+		var duplicateErr *DuplicateLoanError
+		require.ErrorAs(t, err, &duplicateErr)
+		assert.Equal(t, first.ID, duplicateErr.LoanID)
+
+		// Different terms or another borrower are not duplicates
+		different := newLoan()
+		different.PrincipalAmount = domain.NewMoney(20000.00)
+		assert.NoError(t, service.CreateLoan(different))
+		other := newLoan()
+		other.BorrowerID = "user456"
+		assert.NoError(t, service.CreateLoan(other))
+
+		// Only proposed loans count
+		first.Status = domain.StatusApproved
+		require.NoError(t, service.repo.Update(first))
+		assert.NoError(t, service.CreateLoan(newLoan()))
+	})
+
+	t.Run("allowed", func(t *testing.T) {
+		service := setupTestServiceWithOptions()
+
+		require.NoError(t, service.CreateLoan(newLoan()))
+		assert.NoError(t, service.CreateLoan(newLoan()))
+	})
+}
+
+func TestCreateLoanROIBounds(t *testing.T) {
+	service := setupTestServiceWithOptions(WithConfig(config.LoanConfig{MinROI: 2, MaxROI: 12}))
+
+	tests := []struct {
+		roi   float64
+		valid bool
+	}{
+		{roi: 1.99, valid: false},
+		{roi: 2, valid: true},
+		{roi: 12, valid: true},
+		{roi: 12.01, valid: false},
+	}
+
+	for _, tt := range tests {
+		loan := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: tt.roi}
+		err := service.CreateLoan(loan)
+		if tt.valid {
+			assert.NoError(t, err, "roi %v", tt.roi)
+			continue
+		}
+		var roiErr *ROIOutOfRangeError
+		assert.ErrorAs(t, err, &roiErr, "roi %v", tt.roi)
+	}
+}
+
+func TestCreateLoanCurrency(t *testing.T) {
+	service := setupTestServiceWithOptions(WithConfig(config.LoanConfig{BaseCurrency: "EUR", AllowedCurrencies: []string{"EUR", "USD"}}))
+
+	// Loans without a currency are created in the base currency
+	loan := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: 6.0}
+	require.NoError(t, service.CreateLoan(loan))
+	stored, err := service.GetLoan(loan.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "EUR", stored.Currency)
+
+	loan = &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Currency: "USD", Rate: 4.5, ROI: 6.0}
+	require.NoError(t, service.CreateLoan(loan))
+	assert.Equal(t, "USD", loan.Currency)
+
+	loan = &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Currency: "IDR", Rate: 4.5, ROI: 6.0}
+	var currencyErr *UnsupportedCurrencyError
+	require.ErrorAs(t, service.CreateLoan(loan), &currencyErr)
+	assert.Equal(t, "IDR", currencyErr.Currency)
+	assert.Equal(t, []string{"EUR", "USD"}, currencyErr.Allowed)
+
+	// Without configuration loans default to USD and any currency is accepted
+	service, _ = setupTestService()
+	loan = &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: 6.0}
+	require.NoError(t, service.CreateLoan(loan))
+	assert.Equal(t, domain.DefaultCurrency, loan.Currency)
+
+	loan = &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Currency: "IDR", Rate: 4.5, ROI: 6.0}
+	assert.NoError(t, service.CreateLoan(loan))
+}
+
+func TestInvestInLoanCurrency(t *testing.T) {
+	service, _ := setupTestService()
+	loan := createApprovedLoan(t, service, 25000.00)
+
+	_, err := service.InvestInLoanWithCurrency(loan.ID, "investor_001", domain.NewMoney(5000.00), "EUR")
+	var mismatchErr *CurrencyMismatchError
+	require.ErrorAs(t, err, &mismatchErr)
+	assert.Equal(t, "EUR", mismatchErr.Currency)
+	assert.Equal(t, "USD", mismatchErr.LoanCurrency)
+
+	stored, err := service.GetLoan(loan.ID)
+	require.NoError(t, err)
+	assert.Empty(t, stored.Investments)
+
+	investedLoan, err := service.InvestInLoanWithCurrency(loan.ID, "investor_001", domain.NewMoney(5000.00), "USD")
+	require.NoError(t, err)
+	assert.Equal(t, domain.NewMoney(5000.00), investedLoan.TotalInvested)
+
+	// Without a currency the amount is taken to be in the loan's currency
+	investedLoan, err = service.InvestInLoanWithCurrency(loan.ID, "investor_002", domain.NewMoney(5000.00), "")
+	require.NoError(t, err)
+	assert.Equal(t, domain.NewMoney(10000.00), investedLoan.TotalInvested)
+}
+
+func TestUpdateLoanROIBounds(t *testing.T) {
+	service := setupTestServiceWithOptions(WithConfig(config.LoanConfig{MaxROI: 10}))
+
+	loan := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: 6.0}
+	require.NoError(t, service.CreateLoan(loan))
+
+	_, err := service.UpdateLoan(loan.ID, map[string]interface{}{"roi": 10.5})
+	var roiErr *ROIOutOfRangeError
+	require.ErrorAs(t, err, &roiErr)
+	assert.Equal(t, "roi 10.5 is above the maximum of 10", err.Error())
+
+	updated, err := service.UpdateLoan(loan.ID, map[string]interface{}{"roi": 10.0})
+	require.NoError(t, err)
+	assert.Equal(t, 10.0, updated.ROI)
+}
+
+func TestCreateLoanROIAgainstRate(t *testing.T) {
+	tests := []struct {
+		policy string
+		roi    float64
+		valid  bool
+	}{
+		{policy: "allow_equal", roi: 5.0, valid: true},
+		{policy: "allow_equal", roi: 6.0, valid: true},
+		{policy: "allow_equal", roi: 6.5, valid: false},
+		{policy: "strict", roi: 5.0, valid: true},
+		{policy: "strict", roi: 6.0, valid: false},
+		{policy: "strict", roi: 6.5, valid: false},
+		{policy: "off", roi: 6.5, valid: true},
+	}
+
+	for _, tt := range tests {
+		service := setupTestServiceWithOptions(WithConfig(config.LoanConfig{ROIRatePolicy: tt.policy}))
+		loan := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Rate: 6.0, ROI: tt.roi}
+		err := service.CreateLoan(loan)
+		if tt.valid {
+			assert.NoError(t, err, "%s roi %v", tt.policy, tt.roi)
+			continue
+		}
+		var rateErr *ROIAboveRateError
+		assert.ErrorAs(t, err, &rateErr, "%s roi %v", tt.policy, tt.roi)
+	}
+}
+
+func TestUpdateLoanROIAgainstRate(t *testing.T) {
+	service := setupTestServiceWithOptions(WithConfig(config.LoanConfig{ROIRatePolicy: "allow_equal"}))
+
+	loan := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Rate: 6.0, ROI: 4.5}
+	require.NoError(t, service.CreateLoan(loan))
+
+	_, err := service.UpdateLoan(loan.ID, map[string]interface{}{"roi": 6.5})
+	var rateErr *ROIAboveRateError
+	require.ErrorAs(t, err, &rateErr)
+	assert.Equal(t, "roi 6.5 must not exceed the rate of 6", err.Error())
+
+	// Lowering the rate below the current ROI is rejected as well
+	_, err = service.UpdateLoan(loan.ID, map[string]interface{}{"rate": 4.0})
+	assert.ErrorAs(t, err, &rateErr)
+
+	updated, err := service.UpdateLoan(loan.ID, map[string]interface{}{"rate": 7.0, "roi": 7.0})
+	require.NoError(t, err)
+	assert.Equal(t, 7.0, updated.ROI)
+}
+
+func TestValidateNewLoans(t *testing.T) {
+	service, db := setupTestService()
+	service.cfg = config.LoanConfig{MaxActiveLoansPerBorrower: 2, WholeUnitsOnly: true}
+
+	existing := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: 6.0}
+	require.NoError(t, service.CreateLoan(existing))
+
+	loans := []domain.Loan{
+		{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.50), Rate: 4.5, ROI: 6.0},
+		{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: 6.0},
+		{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: 6.0},
+		{BorrowerID: "user456", PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: 6.0},
+	}
+
+	results, err := service.ValidateNewLoans(loans)
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+	assert.ErrorIs(t, results[0], domain.ErrFractionalAmount)
+	assert.NoError(t, results[1])
+
+	// The valid row before it uses up the borrower's remaining active loan
+	var limitErr *ActiveLoanLimitError
+	assert.ErrorAs(t, results[2], &limitErr)
+	assert.NoError(t, results[3])
+
+	var count int64
+	require.NoError(t, db.Model(&domain.Loan{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestCreateLoans(t *testing.T) {
+	service, db := setupTestService()
+	service.cfg = config.LoanConfig{WholeUnitsOnly: true, MaxLoanBatchSize: 3}
+
+	loans := []domain.Loan{
+		{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: 6.0},
+		{BorrowerID: "user456", PrincipalAmount: domain.NewMoney(10000.00), Rate: 4.5, ROI: 6.0, TermMonths: 6},
+	}
+	results, err := service.CreateLoans(loans)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	for i, loan := range loans {
+		assert.NoError(t, results[i])
+		assert.NotEmpty(t, loan.ID)
+		assert.Equal(t, domain.StatusProposed, loan.Status)
+	}
+	assert.Equal(t, domain.DefaultTermMonths, loans[0].TermMonths)
+
+	events, err := service.GetHistory(loans[1].ID)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "create", events[0].Action)
+
+	// A single invalid loan keeps the whole batch from being created
+	results, err = service.CreateLoans([]domain.Loan{
+		{BorrowerID: "user789", PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: 6.0},
+		{BorrowerID: "user789", PrincipalAmount: domain.NewMoney(25000.50), Rate: 4.5, ROI: 6.0},
+	})
+	require.NoError(t, err)
+	assert.NoError(t, results[0])
+	assert.ErrorIs(t, results[1], domain.ErrFractionalAmount)
+
+	var count int64
+	require.NoError(t, db.Model(&domain.Loan{}).Count(&count).Error)
+	assert.Equal(t, int64(2), count)
+
+	// Batches larger than the configured size are refused
+	_, err = service.CreateLoans(make([]domain.Loan, 4))
+	var batchErr *LoanBatchTooLargeError
+	require.ErrorAs(t, err, &batchErr)
+	assert.Equal(t, 3, batchErr.Max)
+}
+
+func TestCreateLoansDuplicate(t *testing.T) {
+	service := setupTestServiceWithOptions(WithConfig(config.LoanConfig{RejectDuplicateLoans: true}))
+
+	existing := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: 6.0}
+	require.NoError(t, service.CreateLoan(existing))
+
+	loans := []domain.Loan{
+		{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: 6.0},
+		{BorrowerID: "user456", PrincipalAmount: domain.NewMoney(10000.00), Rate: 4.5, ROI: 6.0},
+		{BorrowerID: "user456", PrincipalAmount: domain.NewMoney(10000.00), Rate: 4.5, ROI: 6.0},
+	}
+	results, err := service.CreateLoans(loans)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	// Identical to a proposed loan of the borrower
+	var duplicateErr *DuplicateLoanError
+	require.ErrorAs(t, results[0], &duplicateErr)
+	assert.Equal(t, existing.ID, duplicateErr.LoanID)
+
+	// Identical to an earlier loan of the batch
+	assert.NoError(t, results[1])
+	assert.ErrorIs(t, results[2], ErrDuplicateLoanInBatch)
+
+	// Without the setting duplicates are created
+	service.cfg.RejectDuplicateLoans = false
+	results, err = service.CreateLoans(loans)
+	require.NoError(t, err)
+	for _, result := range results {
+		assert.NoError(t, result)
+	}
+}
+
+func TestUpdateLoanTags(t *testing.T) {
+	service, _ := setupTestService()
+
+	loan := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: 6.0}
+	loan.SetTags([]string{"agriculture", "pilot"})
+	require.NoError(t, service.CreateLoan(loan))
+
+	storedLoan, err := service.GetLoan(loan.ID)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"agriculture", "pilot"}, storedLoan.TagNames())
+
+	// Updates without tags leave them untouched
+	_, err = service.UpdateLoan(loan.ID, map[string]interface{}{"rate": 5.0})
+	require.NoError(t, err)
+
+	_, err = service.UpdateLoan(loan.ID, map[string]interface{}{"tags": []string{"pilot", "high-risk"}})
+	require.NoError(t, err)
+
+	storedLoan, err = service.GetLoan(loan.ID)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"pilot", "high-risk"}, storedLoan.TagNames())
+
+	_, err = service.UpdateLoan(loan.ID, map[string]interface{}{"tags": []string{}})
+	require.NoError(t, err)
+
+	storedLoan, err = service.GetLoan(loan.ID)
+	require.NoError(t, err)
+	assert.Empty(t, storedLoan.Tags)
+}
+
+func TestGetLoansByTags(t *testing.T) {
+	service, _ := setupTestService()
+
+	for _, tags := range [][]string{{"agriculture", "pilot"}, {"agriculture"}, {"high-risk"}} {
+		loan := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: 6.0}
+		loan.SetTags(tags)
+		require.NoError(t, service.CreateLoan(loan))
+	}
+
+	loans, err := service.GetLoans(map[string]interface{}{"tags": []string{"pilot", "high-risk"}, "tag_match": domain.TagMatchAny})
+	require.NoError(t, err)
+	assert.Len(t, loans, 2)
+
+	loans, err = service.GetLoans(map[string]interface{}{"tags": []string{"agriculture", "pilot"}, "tag_match": domain.TagMatchAll})
+	require.NoError(t, err)
+	require.Len(t, loans, 1)
+	assert.ElementsMatch(t, []string{"agriculture", "pilot"}, loans[0].TagNames())
+}
+
+// failingAgreementGenerator fails a configurable number of times before succeeding
+type failingAgreementGenerator struct {
+	failures int
+}
+
+func (g *failingAgreementGenerator) Generate(loan *domain.Loan) (string, error) {
+	if g.failures > 0 {
+		g.failures--
+		return "", errors.New("pdf renderer unavailable")
+	}
+	return "https://example.com/agreements/loan_" + loan.ID + "_agreement.pdf", nil
+}
+
+func TestInvestInLoanAgreementGenerationFailure(t *testing.T) {
+	service := setupTestServiceWithOptions(WithAgreementGenerator(&failingAgreementGenerator{failures: 1}))
+	loan := createApprovedLoan(t, service, 10000.00)
+
+	investedLoan, err := service.InvestInLoan(loan.ID, "investor_001", domain.NewMoney(10000.00))
+	require.NoError(t, err)
+
+	// The loan stays invested without an agreement and records the failure
+	assert.Equal(t, domain.StatusInvested, investedLoan.Status)
+	assert.Empty(t, investedLoan.AgreementLetterLink)
+	assert.Equal(t, 1, investedLoan.AgreementAttempts)
+	assert.Equal(t, "pdf renderer unavailable", investedLoan.AgreementLastError)
+}
+
+// failingUpdateRepository writes loans and then fails, as when a write breaks part way through
+type failingUpdateRepository struct {
+	repository.LoanRepository
+}
+
+func (r failingUpdateRepository) Update(loan *domain.Loan) error {
+	if err := r.LoanRepository.Update(loan); err != nil {
+		return err
+	}
+	return errors.New("connection lost")
+}
+
+func (r failingUpdateRepository) Transaction(fn func(repo repository.LoanRepository) error) error {
+	return r.LoanRepository.Transaction(func(repo repository.LoanRepository) error {
+		return fn(failingUpdateRepository{repo})
+	})
+}
+
+func TestInvestInLoanUpdateFailureRollsBack(t *testing.T) {
+	service, db := setupTestService()
+	loan := createApprovedLoan(t, service, 10000.00)
+
+	failing := NewLoanService(failingUpdateRepository{repository.NewLoanRepository(db)})
+	_, err := failing.InvestInLoan(loan.ID, "investor_001", domain.NewMoney(10000.00))
+	assert.EqualError(t, err, "connection lost")
+
+	// Neither the investment, the status change, the agreement link nor the audit event was kept
+	storedLoan, err := service.GetLoan(loan.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusApproved, storedLoan.Status)
+	assert.Equal(t, domain.Money(0), storedLoan.TotalInvested)
+	assert.Empty(t, storedLoan.Investments)
+	assert.Empty(t, storedLoan.AgreementLetterLink)
+	assert.Zero(t, storedLoan.AgreementAttempts)
+	assert.Equal(t, loan.Version, storedLoan.Version)
+
+	events, err := service.GetHistory(loan.ID)
+	require.NoError(t, err)
+	for _, event := range events {
+		assert.NotEqual(t, "invest", event.Action)
+	}
+
+	// The loan can still be invested in once writes succeed
+	investedLoan, err := service.InvestInLoan(loan.ID, "investor_001", domain.NewMoney(10000.00))
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusInvested, investedLoan.Status)
+	assert.NotEmpty(t, investedLoan.AgreementLetterLink)
+}
+
+func TestWithdrawInvestment(t *testing.T) {
+	service, db := setupTestService()
+	loan := createApprovedLoan(t, service, 10000.00)
+
+	_, err := service.InvestInLoan(loan.ID, "investor_001", domain.NewMoney(3000.00))
+	require.NoError(t, err)
+	invested, err := service.InvestInLoan(loan.ID, "investor_002", domain.NewMoney(2000.00))
+	require.NoError(t, err)
+
+	// Investors can only withdraw their own investments
+	_, err = service.WithdrawInvestment(loan.ID, invested.Investments[0].ID, "investor_002")
+	assert.ErrorIs(t, err, domain.ErrNotInvestmentOwner)
+
+	withdrawnLoan, err := service.WithdrawInvestment(loan.ID, invested.Investments[0].ID, "investor_001")
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusApproved, withdrawnLoan.Status)
+	assert.Equal(t, domain.NewMoney(2000.00), withdrawnLoan.TotalInvested)
+
+	storedLoan, err := service.GetLoan(loan.ID)
+	require.NoError(t, err)
+	require.Len(t, storedLoan.Investments, 1)
+	assert.Equal(t, "investor_002", storedLoan.Investments[0].InvestorID)
+	assert.Equal(t, domain.NewMoney(2000.00), storedLoan.TotalInvested)
+
+	// The withdrawal is refunded and the investment kept for the audit trail
+	require.Len(t, storedLoan.Refunds, 1)
+	assert.Equal(t, invested.Investments[0].ID, storedLoan.Refunds[0].InvestmentID)
+	assert.Equal(t, domain.NewMoney(3000.00), storedLoan.Refunds[0].Amount)
+	var withdrawn domain.Investment
+	require.NoError(t, db.Unscoped().First(&withdrawn, "id = ?", invested.Investments[0].ID).Error)
+	assert.True(t, withdrawn.DeletedAt.Valid)
+
+	events, err := service.GetHistory(loan.ID)
+	require.NoError(t, err)
+	last := events[len(events)-1]
+	assert.Equal(t, "withdraw", last.Action)
+	assert.Equal(t, "investor_001", last.ActorID)
+	assert.Equal(t, "3000.00", last.Metadata["amount"])
+
+	// The ledger keeps the withdrawn investment and takes its refund back off
+	_, entries, err := service.GetLedger(loan.ID)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, domain.LedgerRefund, entries[2].Type)
+	assert.Equal(t, domain.NewMoney(-3000.00), entries[2].Amount)
+	assert.Equal(t, storedLoan.TotalInvested, entries[2].TotalInvested)
+
+	// Investments of another loan are not found
+	other := createApprovedLoan(t, service, 10000.00)
+	_, err = service.WithdrawInvestment(other.ID, storedLoan.Investments[0].ID, "")
+	assert.ErrorIs(t, err, domain.ErrInvestmentNotFound)
+
+	// Once fully funded the investments can no longer be withdrawn
+	_, err = service.InvestInLoan(loan.ID, "investor_003", domain.NewMoney(8000.00))
+	require.NoError(t, err)
+	_, err = service.WithdrawInvestment(loan.ID, storedLoan.Investments[0].ID, "")
+	assert.ErrorIs(t, err, domain.ErrCannotWithdraw)
+}
+
+func TestRegenerateAgreement(t *testing.T) {
+	service := setupTestServiceWithOptions(WithAgreementGenerator(&failingAgreementGenerator{failures: 1}))
+	loan := createApprovedLoan(t, service, 10000.00)
+
+	_, err := service.InvestInLoan(loan.ID, "investor_001", domain.NewMoney(10000.00))
+	require.NoError(t, err)
+
+	regenerated, err := service.RegenerateAgreement(loan.ID, false)
+	require.NoError(t, err)
+	assert.Contains(t, regenerated.AgreementLetterLink, "_agreement.pdf")
+	assert.Equal(t, 2, regenerated.AgreementAttempts)
+	assert.Empty(t, regenerated.AgreementLastError)
+
+	// A valid agreement is only regenerated when forced
+	_, err = service.RegenerateAgreement(loan.ID, false)
+	assert.ErrorIs(t, err, domain.ErrAgreementExists)
+
+	regenerated, err = service.RegenerateAgreement(loan.ID, true)
+	require.NoError(t, err)
+	assert.Equal(t, 3, regenerated.AgreementAttempts)
+}
+
+func TestRegenerateAgreementInvalidState(t *testing.T) {
+	service, _ := setupTestService()
+	loan := createApprovedLoan(t, service, 10000.00)
+
+	_, err := service.RegenerateAgreement(loan.ID, false)
+	assert.ErrorIs(t, err, domain.ErrAgreementNotAvailable)
+}
+
+func TestRegenerateAgreementFailure(t *testing.T) {
+	service := setupTestServiceWithOptions(WithAgreementGenerator(&failingAgreementGenerator{failures: 2}))
+	loan := createApprovedLoan(t, service, 10000.00)
+
+	_, err := service.InvestInLoan(loan.ID, "investor_001", domain.NewMoney(10000.00))
+	require.NoError(t, err)
+
+	_, err = service.RegenerateAgreement(loan.ID, false)
+	assert.ErrorIs(t, err, ErrAgreementGenerationFailed)
+
+	storedLoan, err := service.GetLoan(loan.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, storedLoan.AgreementAttempts)
+}
+
+func TestRetryMissingAgreements(t *testing.T) {
+	service := setupTestServiceWithOptions(WithAgreementGenerator(&failingAgreementGenerator{failures: 2}))
+
+	first := createApprovedLoan(t, service, 10000.00)
+	second := createApprovedLoan(t, service, 20000.00)
+	_, err := service.InvestInLoan(first.ID, "investor_001", domain.NewMoney(10000.00))
+	require.NoError(t, err)
+	_, err = service.InvestInLoan(second.ID, "investor_002", domain.NewMoney(20000.00))
+	require.NoError(t, err)
+
+	generated, err := service.RetryMissingAgreements()
+	require.NoError(t, err)
+	assert.Equal(t, 2, generated)
+
+	// Nothing left to retry
+	generated, err = service.RetryMissingAgreements()
+	require.NoError(t, err)
+	assert.Equal(t, 0, generated)
+}
+
+// conflictingUpdateRepository rejects updates of one loan as if another request had modified it
+type conflictingUpdateRepository struct {
+	repository.LoanRepository
+	loanID string
+}
+
+func (r conflictingUpdateRepository) Update(loan *domain.Loan) error {
+	if loan.ID == r.loanID {
+		return domain.ErrConcurrentUpdate
+	}
+	return r.LoanRepository.Update(loan)
+}
+
+func TestRetryMissingAgreementsContinuesPastUpdateErrors(t *testing.T) {
+	_, db := setupTestService()
+	generator := &failingAgreementGenerator{failures: 2}
+	service := NewLoanService(repository.NewLoanRepository(db), WithAgreementGenerator(generator)).(*loanService)
+
+	first := createApprovedLoan(t, service, 10000.00)
+	second := createApprovedLoan(t, service, 20000.00)
+	_, err := service.InvestInLoan(first.ID, "investor_001", domain.NewMoney(10000.00))
+	require.NoError(t, err)
+	_, err = service.InvestInLoan(second.ID, "investor_002", domain.NewMoney(20000.00))
+	require.NoError(t, err)
+
+	retrying := NewLoanService(conflictingUpdateRepository{repository.NewLoanRepository(db), first.ID}, WithAgreementGenerator(generator))
+	generated, err := retrying.RetryMissingAgreements()
+	require.NoError(t, err)
+	assert.Equal(t, 1, generated)
+
+	// The loan that failed to save is left for the next sweep
+	storedLoan, err := service.GetLoan(first.ID)
+	require.NoError(t, err)
+	assert.Empty(t, storedLoan.AgreementLetterLink)
+	storedLoan, err = service.GetLoan(second.ID)
+	require.NoError(t, err)
+	assert.NotEmpty(t, storedLoan.AgreementLetterLink)
+}
+
+func TestRetryMissingAgreementsMaxAttempts(t *testing.T) {
+	service := setupTestServiceWithOptions(
+		WithConfig(config.LoanConfig{MaxAgreementAttempts: 2}),
+		WithAgreementGenerator(&failingAgreementGenerator{failures: 3}),
+	)
+
+	loan := createApprovedLoan(t, service, 10000.00)
+	_, err := service.InvestInLoan(loan.ID, "investor_001", domain.NewMoney(10000.00))
+	require.NoError(t, err)
+
+	// The second attempt fails too and uses up the limit
+	generated, err := service.RetryMissingAgreements()
+	require.NoError(t, err)
+	assert.Equal(t, 0, generated)
+
+	generated, err = service.RetryMissingAgreements()
+	require.NoError(t, err)
+	assert.Equal(t, 0, generated)
+
+	storedLoan, err := service.GetLoan(loan.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, storedLoan.AgreementAttempts)
+	assert.Equal(t, "pdf renderer unavailable", storedLoan.AgreementLastError)
+}
+
+func setupAutoInvestService() (*loanService, AutoInvestService) {
+	_, db := setupTestService()
+	autoInvestRepo := repository.NewAutoInvestRepository(db)
+	loanService := NewLoanService(repository.NewLoanRepository(db), WithAutoInvest(autoInvestRepo)).(*loanService)
+	return loanService, NewAutoInvestService(autoInvestRepo)
+}
+
+func TestApproveLoanRunsAutoInvestRules(t *testing.T) {
+	service, autoInvest := setupAutoInvestService()
+
+	matching := &domain.AutoInvestRule{InvestorID: "investor_001", MinROI: 5.0, MaxPerLoan: 10000.00, TotalBudget: 15000.00}
+	tooLowROI := &domain.AutoInvestRule{InvestorID: "investor_002", MinROI: 8.0, MaxPerLoan: 10000.00, TotalBudget: 50000.00}
+	require.NoError(t, autoInvest.CreateRule(matching))
+	require.NoError(t, autoInvest.CreateRule(tooLowROI))
+
+	approved := createApprovedLoan(t, service, 25000.00)
+	assert.Equal(t, domain.NewMoney(10000.00), approved.TotalInvested)
+
+	storedLoan, err := service.GetLoan(approved.ID)
+	require.NoError(t, err)
+	require.Len(t, storedLoan.Investments, 1)
+	assert.Equal(t, "investor_001", storedLoan.Investments[0].InvestorID)
+
+	// The second loan only gets what is left of the budget
+	second := createApprovedLoan(t, service, 25000.00)
+	assert.Equal(t, domain.NewMoney(5000.00), second.TotalInvested)
+
+	rules, err := autoInvest.GetRules("investor_001")
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, rules[0].RemainingBudget)
+
+	actions, err := autoInvest.GetActions("investor_001")
+	require.NoError(t, err)
+	assert.Len(t, actions, 2)
+
+	actions, err = autoInvest.GetActions("investor_002")
+	require.NoError(t, err)
+	assert.Empty(t, actions)
+}
+
+func TestApproveLoanAutoInvestFullyFunds(t *testing.T) {
+	service, autoInvest := setupAutoInvestService()
+
+	require.NoError(t, autoInvest.CreateRule(&domain.AutoInvestRule{InvestorID: "investor_001", MaxPerLoan: 6000.00, TotalBudget: 50000.00}))
+	require.NoError(t, autoInvest.CreateRule(&domain.AutoInvestRule{InvestorID: "investor_002", MaxPerLoan: 6000.00, TotalBudget: 50000.00}))
+
+	// Only the remaining capacity is invested by the second rule
+	loan := createApprovedLoan(t, service, 10000.00)
+	assert.Equal(t, domain.StatusInvested, loan.Status)
+	assert.Equal(t, domain.NewMoney(10000.00), loan.TotalInvested)
+
+	rules, err := autoInvest.GetRules("investor_002")
+	require.NoError(t, err)
+	assert.Equal(t, 46000.00, rules[0].RemainingBudget)
+}
+
+func TestUpdateAutoInvestRule(t *testing.T) {
+	service, autoInvest := setupAutoInvestService()
+
+	rule := &domain.AutoInvestRule{InvestorID: "investor_001", MaxPerLoan: 5000.00, TotalBudget: 10000.00}
+	require.NoError(t, autoInvest.CreateRule(rule))
+	createApprovedLoan(t, service, 25000.00)
+
+	// 5000 already spent, so the budget cannot drop below it
+	_, err := autoInvest.UpdateRule("investor_001", rule.ID, map[string]interface{}{"total_budget": 4000.00})
+	assert.ErrorIs(t, err, ErrBudgetBelowSpent)
+
+	updated, err := autoInvest.UpdateRule("investor_001", rule.ID, map[string]interface{}{"total_budget": 20000.00})
+	require.NoError(t, err)
+	assert.Equal(t, 15000.00, updated.RemainingBudget)
+
+	// Rules of other investors are not visible
+	_, err = autoInvest.UpdateRule("investor_002", rule.ID, map[string]interface{}{"active": false})
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestReviewLoan(t *testing.T) {
+	service, _ := setupTestService()
+
+	loan := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: 6.0}
+	require.NoError(t, service.CreateLoan(loan))
+
+	reviewDetails := &domain.ReviewDetails{
+		ReviewerID:  "reviewer_001",
+		ReviewNotes: "Income documents need verification",
+	}
+
+	reviewedLoan, err := service.ReviewLoan(loan.ID, reviewDetails)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusUnderReview, reviewedLoan.Status)
+	assert.Equal(t, "reviewer_001", reviewedLoan.ReviewDetails.ReviewerID)
+	assert.False(t, reviewedLoan.ReviewDetails.ReviewDate.IsZero())
+
+	// Under-review loans cannot be invested in or deleted
+	_, err = service.InvestInLoan(loan.ID, "investor_001", domain.NewMoney(1000.00))
+	assert.Error(t, err)
+	err = service.DeleteLoan(loan.ID)
+	assert.Error(t, err)
+
+	// Reviewing twice is not allowed
+	_, err = service.ReviewLoan(loan.ID, reviewDetails)
+	assert.ErrorIs(t, err, domain.ErrCannotReview)
+}
+
+func TestReviewAndDisburseUseServiceClock(t *testing.T) {
+	now := time.Date(2024, 1, 10, 10, 0, 0, 0, time.UTC)
+	service := setupTestServiceWithOptions(WithClock(fixedClock{now: now}))
+
+	loan := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: 6.0}
+	require.NoError(t, service.CreateLoan(loan))
+	reviewedLoan, err := service.ReviewLoan(loan.ID, &domain.ReviewDetails{ReviewerID: "reviewer_001"})
+	require.NoError(t, err)
+	assert.True(t, now.Equal(reviewedLoan.ReviewDetails.ReviewDate))
+
+	disbursedLoan := createDisbursedLoan(t, service, 10000.00)
+	assert.True(t, now.Equal(disbursedLoan.DisbursementDetails.DisbursementDate))
+}
+
+func TestRejectLoan(t *testing.T) {
+	service, _ := setupTestService()
+
+	loan := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: 6.0}
+	require.NoError(t, service.CreateLoan(loan))
+
+	_, err := service.ReviewLoan(loan.ID, &domain.ReviewDetails{ReviewerID: "reviewer_001", ReviewNotes: "Check"})
+	require.NoError(t, err)
+
+	rejectedLoan, err := service.RejectLoan(loan.ID, &domain.RejectionDetails{
+		FieldValidatorID: "validator_001",
+		Reason:           "Collateral could not be verified",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusRejected, rejectedLoan.Status)
+	assert.False(t, rejectedLoan.RejectionDetails.RejectionDate.IsZero())
+
+	storedLoan, err := service.GetLoan(loan.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusRejected, storedLoan.Status)
+	assert.Equal(t, "Collateral could not be verified", storedLoan.RejectionDetails.Reason)
+	assert.Equal(t, "Collateral could not be verified", storedLoan.StatusReason)
+
+	// Rejected loans cannot be approved, invested in or rejected again
+	_, err = service.ApproveLoan(loan.ID, &domain.ApprovalDetails{
+		FieldValidatorProof: domain.ProofLinks{"https://example.com/proof.jpg"},
+		FieldValidatorID:    "validator_001",
+	})
+	assert.Error(t, err)
+	_, err = service.InvestInLoan(loan.ID, "investor_001", domain.NewMoney(1000.00))
+	assert.Error(t, err)
+	_, err = service.RejectLoan(loan.ID, &domain.RejectionDetails{FieldValidatorID: "validator_001", Reason: "Again"})
+	assert.ErrorIs(t, err, domain.ErrCannotReject)
+}
+
+func TestRejectLoanInvalidState(t *testing.T) {
+	service, _ := setupTestService()
+
+	loan := createApprovedLoan(t, service, 25000.00)
+
+	_, err := service.RejectLoan(loan.ID, &domain.RejectionDetails{FieldValidatorID: "validator_001", Reason: "Too late"})
+	assert.ErrorIs(t, err, domain.ErrCannotReject)
+
+	_, err = service.RejectLoan("nonexistent-id", &domain.RejectionDetails{FieldValidatorID: "validator_001", Reason: "Missing"})
+	assert.Error(t, err)
+}
+
+func TestCancelLoan(t *testing.T) {
+	service, _ := setupTestService()
+
+	loan := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: 6.0}
+	require.NoError(t, service.CreateLoan(loan))
+
+	cancelledLoan, err := service.CancelLoan(loan.ID, "Borrower withdrew the application")
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusCancelled, cancelledLoan.Status)
+
+	storedLoan, err := service.GetLoan(loan.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusCancelled, storedLoan.Status)
+	assert.Equal(t, "Borrower withdrew the application", storedLoan.StatusReason)
+
+	// Cancelled loans cannot be approved or cancelled again
+	_, err = service.ApproveLoan(loan.ID, &domain.ApprovalDetails{
+		FieldValidatorProof: domain.ProofLinks{"https://example.com/proof.jpg"},
+		FieldValidatorID:    "validator_001",
+	})
+	assert.Error(t, err)
+	_, err = service.CancelLoan(loan.ID, "")
+	assert.ErrorIs(t, err, domain.ErrCannotCancel)
+
+	// Other transitions leave the reason empty, as does a cancellation without one
+	approvedLoan := createApprovedLoan(t, service, 10000.00)
+	assert.Empty(t, approvedLoan.StatusReason)
+	cancelledLoan, err = service.CancelLoan(approvedLoan.ID, "")
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusCancelled, cancelledLoan.Status)
+	assert.Empty(t, cancelledLoan.StatusReason)
+}
+
+func TestCancelLoanAfterPartialInvestment(t *testing.T) {
+	service, _ := setupTestService()
+
+	loan := createApprovedLoan(t, service, 10000.00)
+	_, err := service.InvestInLoan(loan.ID, "investor_001", domain.NewMoney(2500.00))
+	require.NoError(t, err)
+	_, err = service.InvestInLoan(loan.ID, "investor_002", domain.NewMoney(1000.00))
+	require.NoError(t, err)
+	invested, err := service.InvestInLoan(loan.ID, "investor_001", domain.NewMoney(500.00))
+	require.NoError(t, err)
+
+	cancelledLoan, err := service.CancelLoan(loan.ID, "")
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusCancelled, cancelledLoan.Status)
+	assert.Len(t, cancelledLoan.Refunds, 3)
+
+	// Every investment is refunded, adding up to what was invested before the cancellation
+	storedLoan, err := service.GetLoan(loan.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusCancelled, storedLoan.Status)
+	assert.Equal(t, domain.Money(0), storedLoan.TotalInvested)
+	require.Len(t, storedLoan.Refunds, 3)
+	assert.Equal(t, invested.TotalInvested, storedLoan.TotalRefunded())
+
+	// One history event per refund follows the cancellation
+	events, err := service.GetHistory(loan.ID)
+	require.NoError(t, err)
+	var refundEvents []domain.LoanEvent
+	for _, event := range events {
+		if event.Action == "refund" {
+			refundEvents = append(refundEvents, event)
+		}
+	}
+	require.Len(t, refundEvents, 3)
+	assert.Equal(t, "investor_002", refundEvents[1].ActorID)
+	assert.Equal(t, domain.NewMoney(1000.00).String(), refundEvents[1].Metadata["amount"])
+}
+
+func TestCancelLoanFullyInvested(t *testing.T) {
+	service, _ := setupTestService()
+
+	loan := createApprovedLoan(t, service, 10000.00)
+	_, err := service.InvestInLoan(loan.ID, "investor_001", domain.NewMoney(10000.00))
+	require.NoError(t, err)
+
+	_, err = service.CancelLoan(loan.ID, "")
+	assert.ErrorIs(t, err, domain.ErrCannotCancel)
+
+	storedLoan, err := service.GetLoan(loan.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusInvested, storedLoan.Status)
+	assert.Empty(t, storedLoan.Refunds)
+}
+
+func TestExpireOverdueLoans(t *testing.T) {
+	approvedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	service := setupTestServiceWithOptions(
+		WithConfig(config.LoanConfig{InvestmentWindow: 48 * time.Hour}),
+		WithClock(fixedClock{now: approvedAt}),
+	)
+	deadline := approvedAt.Add(48 * time.Hour)
+
+	partial := createApprovedLoan(t, service, 10000.00)
+	require.NotNil(t, partial.InvestmentDeadline)
+	assert.Equal(t, deadline, partial.InvestmentDeadline.UTC())
+	_, err := service.InvestInLoan(partial.ID, "investor_001", domain.NewMoney(2500.00))
+	require.NoError(t, err)
+
+	unfunded := createApprovedLoan(t, service, 10000.00)
+	funded := createApprovedLoan(t, service, 5000.00)
+	_, err = service.InvestInLoan(funded.ID, "investor_002", domain.NewMoney(5000.00))
+	require.NoError(t, err)
+
+	// Nothing expires before the deadline
+	expired, err := service.ExpireOverdueLoans(deadline)
+	require.NoError(t, err)
+	assert.Equal(t, 0, expired)
+
+	// Investments are refused once the deadline passed, even before the loan is expired
+	service.clock = fixedClock{now: deadline.Add(time.Minute)}
+	_, err = service.InvestInLoan(unfunded.ID, "investor_003", domain.NewMoney(1000.00))
+	assert.ErrorIs(t, err, domain.ErrInvestmentDeadlinePassed)
+
+	expired, err = service.ExpireOverdueLoans(deadline.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 2, expired)
+
+	storedLoan, err := service.GetLoan(partial.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusExpired, storedLoan.Status)
+	assert.NotEmpty(t, storedLoan.StatusReason)
+	assert.Equal(t, domain.Money(0), storedLoan.TotalInvested)
+	require.Len(t, storedLoan.Refunds, 1)
+	assert.Equal(t, domain.NewMoney(2500.00), storedLoan.TotalRefunded())
+
+	events, err := service.GetHistory(partial.ID)
+	require.NoError(t, err)
+	var actions []string
+	for _, event := range events {
+		actions = append(actions, event.Action)
+	}
+	assert.Equal(t, []string{"create", "approve", "expire", "refund"}, actions)
+
+	// Fully invested loans are past their deadline but no longer approved
+	storedLoan, err = service.GetLoan(funded.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusInvested, storedLoan.Status)
+
+	// Expired loans are not expired again
+	expired, err = service.ExpireOverdueLoans(deadline.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 0, expired)
+}
+
+func TestApproveLoanWithoutInvestmentWindow(t *testing.T) {
+	service, _ := setupTestService()
+
+	loan := createApprovedLoan(t, service, 10000.00)
+	assert.Nil(t, loan.InvestmentDeadline)
+
+	expired, err := service.ExpireOverdueLoans(time.Now().AddDate(10, 0, 0))
+	require.NoError(t, err)
+	assert.Equal(t, 0, expired)
+}
+
+func TestGenerateSchedule(t *testing.T) {
+	service, _ := setupTestService()
+
+	loan := createApprovedLoan(t, service, 24000.00)
+	_, _, err := service.GenerateSchedule(loan.ID)
+	assert.ErrorIs(t, err, domain.ErrScheduleNotAvailable)
+
+	_, err = service.InvestInLoan(loan.ID, "investor_001", domain.NewMoney(24000.00))
+	require.NoError(t, err)
+
+	_, schedule, err := service.GenerateSchedule(loan.ID)
+	require.NoError(t, err)
+	require.Len(t, schedule, domain.DefaultTermMonths)
+
+	principal, interest, payments := 0.0, 0.0, 0.0
+	for _, installment := range schedule {
+		principal += installment.Principal
+		interest += installment.Interest
+		payments += installment.Payment
+	}
+	assert.InDelta(t, 24000.00, principal, 0.005)
+	assert.InDelta(t, principal+interest, payments, 0.01)
+
+	_, _, err = service.GenerateSchedule("nonexistent-id")
+	assert.Error(t, err)
+}
+
+func TestGetInvestments(t *testing.T) {
+	service, _ := setupTestService()
+
+	loan := createApprovedLoan(t, service, 20000.00)
+	for _, investment := range []struct {
+		investorID string
+		amount     float64
+	}{{"investor_001", 5000.00}, {"investor_002", 3000.00}, {"investor_001", 2000.00}} {
+		_, err := service.InvestInLoan(loan.ID, investment.investorID, domain.NewMoney(investment.amount))
+		require.NoError(t, err)
+	}
+
+	result, err := service.GetInvestments(loan.ID, "", 0, 20)
+	require.NoError(t, err)
+	assert.Equal(t, loan.ID, result.Loan.ID)
+	assert.Len(t, result.Investments, 3)
+	assert.Equal(t, int64(3), result.Total)
+	contributions := result.Contributions
+	require.Len(t, contributions, 2)
+	assert.Equal(t, "investor_001", contributions[0].InvestorID)
+	assert.Equal(t, domain.NewMoney(7000.00), contributions[0].Amount)
+	assert.Equal(t, 35.0, contributions[0].Percentage)
+	assert.Equal(t, domain.NewMoney(3000.00), contributions[1].Amount)
+	assert.Equal(t, 15.0, contributions[1].Percentage)
+
+	// Contributions cover the whole loan whatever the page or filter
+	result, err = service.GetInvestments(loan.ID, "investor_002", 0, 20)
+	require.NoError(t, err)
+	require.Len(t, result.Investments, 1)
+	assert.Equal(t, domain.NewMoney(3000.00), result.Investments[0].Amount)
+	assert.Equal(t, int64(1), result.Total)
+	assert.Len(t, result.Contributions, 2)
+
+	_, err = service.GetInvestments("nonexistent-id", "", 0, 20)
+	assert.Error(t, err)
+}
+
+func TestClearReview(t *testing.T) {
+	service, _ := setupTestService()
+
+	loan := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: 6.0}
+	require.NoError(t, service.CreateLoan(loan))
+
+	_, err := service.ClearReview(loan.ID)
+	assert.ErrorIs(t, err, domain.ErrNotUnderReview)
+
+	_, err = service.ReviewLoan(loan.ID, &domain.ReviewDetails{ReviewerID: "reviewer_001", ReviewNotes: "Check"})
+	require.NoError(t, err)
+
+	_, err = service.ClearReview(loan.ID)
+	require.NoError(t, err)
+
+	storedLoan, err := service.GetLoan(loan.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusProposed, storedLoan.Status)
+	if storedLoan.ReviewDetails != nil {
+		assert.Empty(t, storedLoan.ReviewDetails.ReviewerID)
+	}
+}
+
+func TestApproveLoanUnderReview(t *testing.T) {
+	service, _ := setupTestService()
+
+	loan := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: 6.0}
+	require.NoError(t, service.CreateLoan(loan))
+
+	_, err := service.ReviewLoan(loan.ID, &domain.ReviewDetails{ReviewerID: "reviewer_001", ReviewNotes: "Check"})
+	require.NoError(t, err)
+
+	approvedLoan, err := service.ApproveLoan(loan.ID, &domain.ApprovalDetails{
+		FieldValidatorProof: domain.ProofLinks{"proof"},
+		FieldValidatorID:    "validator_001",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusApproved, approvedLoan.Status)
+}
+
+func TestUniqueProofPerLoan(t *testing.T) {
+	service := setupTestServiceWithOptions(WithConfig(config.LoanConfig{UniqueProofPerLoan: true, ApprovalCorrectionWindow: time.Hour}))
+
+	first := createApprovedLoan(t, service, 10000.00)
+
+	second := &domain.Loan{BorrowerID: "user456", PrincipalAmount: domain.NewMoney(10000.00), Rate: 4.5, ROI: 6.0}
+	require.NoError(t, service.CreateLoan(second))
+
+	_, err := service.ApproveLoan(second.ID, &domain.ApprovalDetails{FieldValidatorProof: domain.ProofLinks{"proof"}, FieldValidatorID: "validator_002"})
+	var proofErr *DuplicateProofError
+	require.ErrorAs(t, err, &proofErr)
+	assert.Equal(t, first.ID, proofErr.LoanID)
+
+	_, err = service.ApproveLoan(second.ID, &domain.ApprovalDetails{FieldValidatorProof: domain.ProofLinks{"proof-2"}, FieldValidatorID: "validator_002"})
+	require.NoError(t, err)
+
+	// Corrections cannot switch to a proof used elsewhere, but keeping the loan's own proof is fine
+	_, err = service.CorrectApproval(second.ID, &domain.ApprovalDetails{FieldValidatorProof: domain.ProofLinks{"proof"}}, false)
+	assert.ErrorAs(t, err, &proofErr)
+
+	_, err = service.CorrectApproval(second.ID, &domain.ApprovalDetails{FieldValidatorProof: domain.ProofLinks{"proof-2"}, FieldValidatorID: "validator_003"}, false)
+	assert.NoError(t, err)
+}
+
+func TestProofReuseAllowedByDefault(t *testing.T) {
+	service, _ := setupTestService()
+
+	createApprovedLoan(t, service, 10000.00)
+	createApprovedLoan(t, service, 10000.00)
+}
+
+func TestWholeUnitsOnly(t *testing.T) {
+	service := setupTestServiceWithOptions(WithConfig(config.LoanConfig{WholeUnitsOnly: true}))
+
+	fractional := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.50), Rate: 4.5, ROI: 6.0}
+	assert.ErrorIs(t, service.CreateLoan(fractional), domain.ErrFractionalAmount)
+
+	loan := createApprovedLoan(t, service, 25000.00)
+
+	_, err := service.InvestInLoan(loan.ID, "investor_001", domain.NewMoney(100.25))
+	assert.ErrorIs(t, err, domain.ErrFractionalAmount)
+
+	investedLoan, err := service.InvestInLoan(loan.ID, "investor_001", domain.NewMoney(100.00))
+	require.NoError(t, err)
+	assert.Equal(t, domain.NewMoney(100.00), investedLoan.TotalInvested)
+
+	proposed := &domain.Loan{BorrowerID: "user456", PrincipalAmount: domain.NewMoney(10000.00), Rate: 4.5, ROI: 6.0}
+	require.NoError(t, service.CreateLoan(proposed))
+
+	_, err = service.UpdateLoan(proposed.ID, map[string]interface{}{"principal_amount": domain.NewMoney(10000.01)})
+	assert.ErrorIs(t, err, domain.ErrFractionalAmount)
+
+	storedLoan, err := service.GetLoan(proposed.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.NewMoney(10000.00), storedLoan.PrincipalAmount)
+}
+
+func TestFractionalAmountsAllowedByDefault(t *testing.T) {
+	service, _ := setupTestService()
+
+	loan := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.50), Rate: 4.5, ROI: 6.0}
+	assert.NoError(t, service.CreateLoan(loan))
+}
+
+func TestGetBorrowerHistory(t *testing.T) {
+	service, _ := setupTestService()
+
+	first := createApprovedLoan(t, service, 10000.00)
+	_, err := service.InvestInLoan(first.ID, "investor_001", domain.NewMoney(10000.00))
+	require.NoError(t, err)
+
+	second := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(5000.00), Rate: 4.5, ROI: 6.0}
+	require.NoError(t, service.CreateLoan(second))
+
+	other := &domain.Loan{BorrowerID: "user456", PrincipalAmount: domain.NewMoney(5000.00), Rate: 4.5, ROI: 6.0}
+	require.NoError(t, service.CreateLoan(other))
+
+	entries, total, err := service.GetBorrowerHistory("user123", 1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 4, total)
+	require.Len(t, entries, 4)
+
+	for i := 1; i < len(entries); i++ {
+		assert.False(t, entries[i].Timestamp.Before(entries[i-1].Timestamp))
+	}
+	assert.Equal(t, first.ID, entries[0].LoanID)
+	assert.Equal(t, second.ID, entries[3].LoanID)
+	for i, event := range []string{"create", "approve", "invest", "create"} {
+		assert.Equal(t, event, entries[i].Event)
+	}
+	assert.Equal(t, domain.StatusInvested, entries[2].Status)
+	assert.Equal(t, "investor_001", entries[2].ActorID)
+
+	// Pagination
+	entries, total, err = service.GetBorrowerHistory("user123", 2, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 4, total)
+	assert.Len(t, entries, 1)
+
+	entries, _, err = service.GetBorrowerHistory("user123", 3, 3)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	// Cancelling a loan adds its cancellation and refunds
+	third := createApprovedLoan(t, service, 10000.00)
+	_, err = service.InvestInLoan(third.ID, "investor_002", domain.NewMoney(4000.00))
+	require.NoError(t, err)
+	_, err = service.CancelLoan(third.ID, "")
+	require.NoError(t, err)
+
+	entries, total, err = service.GetBorrowerHistory("user123", 1, 20)
+	require.NoError(t, err)
+	assert.Equal(t, 8, total)
+	require.Len(t, entries, 8)
+	events := make(map[string]domain.TimelineEntry)
+	for _, entry := range entries[6:] {
+		events[entry.Event] = entry
+	}
+	require.Contains(t, events, "cancel")
+	require.Contains(t, events, "refund")
+	assert.Equal(t, domain.StatusCancelled, events["cancel"].Status)
+	assert.Equal(t, "investor_002", events["refund"].ActorID)
+	assert.Equal(t, domain.NewMoney(4000.00), events["refund"].Amount)
+}
+
+func TestInvestInLoanWithInvestorFee(t *testing.T) {
+	service := setupTestServiceWithOptions(WithConfig(config.LoanConfig{InvestorFeeRate: 1.0, InvestorFeeBasis: "net"}))
+	loan := createApprovedLoan(t, service, 9900.00)
+
+	investedLoan, err := service.InvestInLoan(loan.ID, "investor_001", domain.NewMoney(10000.00))
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusInvested, investedLoan.Status)
+
+	storedLoan, err := service.GetLoan(loan.ID)
+	require.NoError(t, err)
+	require.Len(t, storedLoan.Investments, 1)
+	assert.Equal(t, domain.NewMoney(100.00), storedLoan.Investments[0].FeeAmount)
+	assert.Equal(t, domain.NewMoney(9900.00), storedLoan.Investments[0].NetAmount)
+	assert.Equal(t, domain.NewMoney(9900.00), storedLoan.TotalInvested)
+}
+
+func TestInvestInLoanClampPolicy(t *testing.T) {
+	service := setupTestServiceWithOptions(WithConfig(config.LoanConfig{OverfundPolicy: "clamp"}))
+	loan := createApprovedLoan(t, service, 10000.00)
+
+	_, err := service.InvestInLoan(loan.ID, "investor_001", domain.NewMoney(9000.00))
+	require.NoError(t, err)
+
+	investedLoan, err := service.InvestInLoan(loan.ID, "investor_002", domain.NewMoney(2500.00))
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusInvested, investedLoan.Status)
+
+	storedLoan, err := service.GetLoan(loan.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.NewMoney(10000.00), storedLoan.TotalInvested)
+	for _, investment := range storedLoan.Investments {
+		if investment.InvestorID == "investor_002" {
+			assert.Equal(t, domain.NewMoney(1000.00), investment.Amount)
+		}
+	}
+}
+
+func TestInvestInLoanMinimumInvestment(t *testing.T) {
+	service := setupTestServiceWithOptions(WithConfig(config.LoanConfig{MinInvestmentAmount: 100.00}))
+	loan := createApprovedLoan(t, service, 10000.00)
+
+	_, err := service.InvestInLoan(loan.ID, "investor_001", domain.NewMoney(99.99))
+	var minimumErr *MinimumInvestmentError
+	require.ErrorAs(t, err, &minimumErr)
+	assert.Equal(t, domain.NewMoney(100.00), minimumErr.Min)
+
+	_, err = service.InvestInLoan(loan.ID, "investor_001", domain.NewMoney(100.00))
+	require.NoError(t, err)
+
+	storedLoan, err := service.GetLoan(loan.ID)
+	require.NoError(t, err)
+	assert.Len(t, storedLoan.Investments, 1)
+}
+
+func TestInvestInLoanMinimumInvestmentTopUp(t *testing.T) {
+	service := setupTestServiceWithOptions(WithConfig(config.LoanConfig{MinInvestmentAmount: 100.00}))
+	loan := createApprovedLoan(t, service, 10000.00)
+
+	_, err := service.InvestInLoan(loan.ID, "investor_001", domain.NewMoney(9950.00))
+	require.NoError(t, err)
+
+	// Less than the remainder is still too small
+	_, err = service.InvestInLoan(loan.ID, "investor_002", domain.NewMoney(40.00))
+	var minimumErr *MinimumInvestmentError
+	assert.ErrorAs(t, err, &minimumErr)
+
+	// The final top-up completing the loan is exempt
+	investedLoan, err := service.InvestInLoan(loan.ID, "investor_002", domain.NewMoney(50.00))
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusInvested, investedLoan.Status)
+}
+
+func TestInvestInLoanMaxInvestors(t *testing.T) {
+	service := setupTestServiceWithOptions(WithConfig(config.LoanConfig{MaxInvestorsPerLoan: 2}))
+	loan := createApprovedLoan(t, service, 10000.00)
+
+	_, err := service.InvestInLoan(loan.ID, "investor_001", domain.NewMoney(1000.00))
+	require.NoError(t, err)
+	_, err = service.InvestInLoan(loan.ID, "investor_002", domain.NewMoney(1000.00))
+	require.NoError(t, err)
+
+	// A third investor would exceed the cap
+	_, err = service.InvestInLoan(loan.ID, "investor_003", domain.NewMoney(1000.00))
+	var limitErr *InvestorLimitError
+	require.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, 2, limitErr.Investors)
+	assert.Equal(t, 2, limitErr.Max)
+
+	// Existing investors can keep adding to their share
+	_, err = service.InvestInLoan(loan.ID, "investor_001", domain.NewMoney(2000.00))
+	require.NoError(t, err)
+
+	storedLoan, err := service.GetLoan(loan.ID)
+	require.NoError(t, err)
+	assert.Len(t, storedLoan.Investments, 3)
+	assert.Equal(t, []string{"investor_001", "investor_002"}, storedLoan.InvestorIDs())
+	assert.Equal(t, domain.NewMoney(4000.00), storedLoan.TotalInvested)
+}
+
+func TestInvestInLoanMaxInvestorShare(t *testing.T) {
+	service := setupTestServiceWithOptions(WithConfig(config.LoanConfig{MaxInvestorSharePercent: 40}))
+	loan := createApprovedLoan(t, service, 10000.00)
+
+	_, err := service.InvestInLoan(loan.ID, "investor_001", domain.NewMoney(3000.00))
+	require.NoError(t, err)
+
+	// The second investment would bring the investor to 50% of the principal
+	_, err = service.InvestInLoan(loan.ID, "investor_001", domain.NewMoney(2000.00))
+	var shareErr *InvestorShareError
+	require.ErrorAs(t, err, &shareErr)
+	assert.Equal(t, domain.NewMoney(5000.00), shareErr.Amount)
+	assert.Equal(t, domain.NewMoney(4000.00), shareErr.Max)
+	assert.Equal(t, 40.0, shareErr.MaxPercent)
+
+	// Topping up to exactly the maximum share is allowed, as are other investors
+	_, err = service.InvestInLoan(loan.ID, "investor_001", domain.NewMoney(1000.00))
+	require.NoError(t, err)
+	_, err = service.InvestInLoan(loan.ID, "investor_002", domain.NewMoney(4000.00))
+	require.NoError(t, err)
+
+	storedLoan, err := service.GetLoan(loan.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.NewMoney(4000.00), storedLoan.InvestedBy("investor_001"))
+	assert.Equal(t, domain.NewMoney(8000.00), storedLoan.TotalInvested)
+
+	// Without a maximum a single investor may fund the whole loan
+	unlimited := setupTestServiceWithOptions()
+	whole := createApprovedLoan(t, unlimited, 10000.00)
+	_, err = unlimited.InvestInLoan(whole.ID, "investor_001", domain.NewMoney(6000.00))
+	require.NoError(t, err)
+	invested, err := unlimited.InvestInLoan(whole.ID, "investor_001", domain.NewMoney(4000.00))
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusInvested, invested.Status)
+}
+
+func TestCorrectApproval(t *testing.T) {
+	approvedAt := time.Date(2024, 1, 10, 10, 0, 0, 0, time.UTC)
+	service := setupTestServiceWithOptions(
+		WithConfig(config.LoanConfig{ApprovalCorrectionWindow: 15 * time.Minute}),
+		WithClock(fixedClock{now: approvedAt}),
+	)
+	loan := createApprovedLoan(t, service, 25000.00)
+
+	// Inside the window
+	service.clock = fixedClock{now: approvedAt.Add(10 * time.Minute)}
+	correctedLoan, err := service.CorrectApproval(loan.ID, &domain.ApprovalDetails{FieldValidatorProof: domain.ProofLinks{"https://example.com/proof-fixed.jpg"}}, false)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ProofLinks{"https://example.com/proof-fixed.jpg"}, correctedLoan.ApprovalDetails.FieldValidatorProof)
+	assert.Equal(t, "validator_001", correctedLoan.ApprovalDetails.FieldValidatorID)
+	assert.True(t, correctedLoan.ApprovalDetails.ApprovalDate.Equal(approvedAt))
+
+	// Outside the window without an override
+	service.clock = fixedClock{now: approvedAt.Add(time.Hour)}
+	_, err = service.CorrectApproval(loan.ID, &domain.ApprovalDetails{FieldValidatorID: "validator_002"}, false)
+	assert.ErrorIs(t, err, ErrCorrectionWindowClosed)
+
+	// Outside the window with an override
+	correctedLoan, err = service.CorrectApproval(loan.ID, &domain.ApprovalDetails{FieldValidatorID: "validator_002"}, true)
+	require.NoError(t, err)
+	assert.Equal(t, "validator_002", correctedLoan.ApprovalDetails.FieldValidatorID)
+
+	amendments, err := service.repo.FindApprovalAmendments(loan.ID)
+	require.NoError(t, err)
+	require.Len(t, amendments, 2)
+	assert.Equal(t, domain.ProofLinks{"proof"}, amendments[0].PreviousFieldValidatorProof)
+	assert.Equal(t, domain.ProofLinks{"https://example.com/proof-fixed.jpg"}, amendments[0].FieldValidatorProof)
+	assert.False(t, amendments[0].AdminOverride)
+	assert.Equal(t, "validator_001", amendments[1].PreviousFieldValidatorID)
+	assert.True(t, amendments[1].AdminOverride)
+}
+
+func TestCorrectApprovalNotApproved(t *testing.T) {
+	service, _ := setupTestService()
+
+	loan := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: 6.0}
+	require.NoError(t, service.CreateLoan(loan))
+
+	_, err := service.CorrectApproval(loan.ID, &domain.ApprovalDetails{FieldValidatorID: "validator_002"}, true)
+	assert.ErrorIs(t, err, domain.ErrNoApproval)
+
+	// Invested loans keep the approval their investors relied on
+	approved := createApprovedLoan(t, service, 10000.00)
+	_, err = service.InvestInLoan(approved.ID, "investor_001", domain.NewMoney(10000.00))
+	require.NoError(t, err)
+
+	_, err = service.CorrectApproval(approved.ID, &domain.ApprovalDetails{FieldValidatorID: "validator_002"}, true)
+	assert.ErrorIs(t, err, domain.ErrApprovalNotCorrectable)
+}
+
+func TestDisburseLoanRequiresSatisfiedCovenants(t *testing.T) {
+	service, _ := setupTestService()
+
+	loan := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(10000.00), Rate: 4.5, ROI: 6.0}
+	require.NoError(t, service.CreateLoan(loan))
+
+	approvedLoan, err := service.ApproveLoan(loan.ID, &domain.ApprovalDetails{
+		FieldValidatorProof: domain.ProofLinks{"proof"},
+		FieldValidatorID:    "validator_001",
+	}, "Provide land title", "Confirm insurance")
+	require.NoError(t, err)
+	require.Len(t, approvedLoan.Covenants, 2)
+	assert.False(t, approvedLoan.CovenantsSatisfied())
+
+	_, err = service.InvestInLoan(loan.ID, "investor_001", domain.NewMoney(10000.00))
+	require.NoError(t, err)
+
+	disbursementDetails := func() *domain.DisbursementDetails {
+		return &domain.DisbursementDetails{SignedAgreementLink: "https://example.com/signed.pdf", FieldOfficerID: "officer_001"}
+	}
+
+	_, err = service.DisburseLoan(loan.ID, disbursementDetails())
+	assert.ErrorIs(t, err, domain.ErrCovenantsNotSatisfied)
+
+	updatedLoan, err := service.SatisfyCovenant(loan.ID, approvedLoan.Covenants[0].ID)
+	require.NoError(t, err)
+	assert.False(t, updatedLoan.CovenantsSatisfied())
+
+	_, err = service.DisburseLoan(loan.ID, disbursementDetails())
+	assert.ErrorIs(t, err, domain.ErrCovenantsNotSatisfied)
+
+	updatedLoan, err = service.SatisfyCovenant(loan.ID, approvedLoan.Covenants[1].ID)
+	require.NoError(t, err)
+	assert.True(t, updatedLoan.CovenantsSatisfied())
+	assert.NotNil(t, updatedLoan.Covenants[1].SatisfiedAt)
+
+	disbursedLoan, err := service.DisburseLoan(loan.ID, disbursementDetails())
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusDisbursed, disbursedLoan.Status)
+}
+
+func TestDisburseLoanAgreementMatch(t *testing.T) {
+	service := setupTestServiceWithOptions(WithConfig(config.LoanConfig{RequireAgreementMatch: true}))
+	loan := createApprovedLoan(t, service, 10000.00)
+	other := createApprovedLoan(t, service, 10000.00)
+
+	invested, err := service.InvestInLoan(loan.ID, "investor_001", domain.NewMoney(10000.00))
+	require.NoError(t, err)
+	require.NotEmpty(t, invested.AgreementLetterLink)
+
+	disbursementDetails := func(link string) *domain.DisbursementDetails {
+		return &domain.DisbursementDetails{SignedAgreementLink: link, FieldOfficerID: "officer_001"}
+	}
+
+	// Links that do not reference the loan, or reference another loan, are rejected
+	for _, link := range []string{
+		"https://example.com/signed-agreement.pdf",
+		"https://example.com/agreements/loan_" + other.ID + "_agreement.pdf",
+		"https://example.com/signed.pdf?loan=" + loan.ID,
+	} {
+		_, err = service.DisburseLoan(loan.ID, disbursementDetails(link))
+		assert.ErrorIs(t, err, ErrAgreementMismatch, link)
+	}
+
+	storedLoan, err := service.GetLoan(loan.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusInvested, storedLoan.Status)
+
+	// The signed copy of the generated agreement references the same loan
+	disbursed, err := service.DisburseLoan(loan.ID, disbursementDetails(invested.AgreementLetterLink))
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusDisbursed, disbursed.Status)
+
+	// Without the check any signed agreement link is accepted
+	lenient := setupTestServiceWithOptions()
+	loan = createApprovedLoan(t, lenient, 10000.00)
+	_, err = lenient.InvestInLoan(loan.ID, "investor_001", domain.NewMoney(10000.00))
+	require.NoError(t, err)
+	_, err = lenient.DisburseLoan(loan.ID, disbursementDetails("https://storage.example.com/uploads/scan.pdf"))
+	require.NoError(t, err)
+}
+
+func TestGetFundingTimeline(t *testing.T) {
+	service, _ := setupTestService()
+
+	loan := createApprovedLoan(t, service, 10000.00)
+	for _, investment := range []struct {
+		investorID string
+		amount     float64
+	}{{"investor_001", 2500.00}, {"investor_002", 4000.00}, {"investor_001", 3500.00}} {
+		_, err := service.InvestInLoan(loan.ID, investment.investorID, domain.NewMoney(investment.amount))
+		require.NoError(t, err)
+	}
+
+	timeline, err := service.GetFundingTimeline(loan.ID)
+	require.NoError(t, err)
+	assert.Equal(t, loan.ID, timeline.Loan.ID)
+	require.Len(t, timeline.Steps, 3)
+
+	expected := []struct {
+		investorID string
+		total      float64
+		percent    float64
+	}{{"investor_001", 2500.00, 25.0}, {"investor_002", 6500.00, 65.0}, {"investor_001", 10000.00, 100.0}}
+	for i, step := range timeline.Steps {
+		assert.Equal(t, expected[i].investorID, step.Investment.InvestorID)
+		assert.Equal(t, domain.NewMoney(expected[i].total), step.TotalInvested)
+		assert.Equal(t, expected[i].percent, step.PercentFunded)
+	}
+	assert.Equal(t, timeline.Loan.TotalInvested, timeline.Steps[2].TotalInvested)
+
+	// Loans without investments have an empty timeline
+	empty := createApprovedLoan(t, service, 10000.00)
+	timeline, err = service.GetFundingTimeline(empty.ID)
+	require.NoError(t, err)
+	assert.Empty(t, timeline.Steps)
+
+	_, err = service.GetFundingTimeline("missing")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestGetFundingTimelineStoredFeeBasis(t *testing.T) {
+	service := setupTestServiceWithOptions(WithConfig(config.LoanConfig{InvestorFeeRate: 1.0, InvestorFeeBasis: "net"}))
+
+	loan := createApprovedLoan(t, service, 10000.00)
+	_, err := service.InvestInLoan(loan.ID, "investor_001", domain.NewMoney(5000.00))
+	require.NoError(t, err)
+
+	// Investments keep counting the amount they were made with after the fee basis changes
+	service.cfg.InvestorFeeBasis = "gross"
+	_, err = service.InvestInLoan(loan.ID, "investor_002", domain.NewMoney(2000.00))
+	require.NoError(t, err)
+
+	timeline, err := service.GetFundingTimeline(loan.ID)
+	require.NoError(t, err)
+	require.Len(t, timeline.Steps, 2)
+	assert.Equal(t, domain.FeeBasisNet, timeline.Steps[0].Investment.FeeBasis)
+	assert.Equal(t, domain.NewMoney(4950.00), timeline.Steps[0].TotalInvested)
+	assert.Equal(t, domain.NewMoney(6950.00), timeline.Steps[1].TotalInvested)
+	assert.Equal(t, timeline.Loan.TotalInvested, timeline.Steps[1].TotalInvested)
+}
+
+func TestSatisfyCovenantNotFound(t *testing.T) {
+	service, _ := setupTestService()
+	loan := createApprovedLoan(t, service, 10000.00)
+
+	_, err := service.SatisfyCovenant(loan.ID, "nonexistent-id")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestCreateLoanWithIdempotencyKey(t *testing.T) {
+	now := time.Date(2024, 1, 10, 10, 0, 0, 0, time.UTC)
+	service := setupTestServiceWithOptions(
+		WithConfig(config.LoanConfig{IdempotencyKeyTTL: time.Hour}),
+		WithClock(fixedClock{now: now}),
+	)
+
+	newLoan := func(borrowerID string) *domain.Loan {
+		return &domain.Loan{BorrowerID: borrowerID, PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: 6.0}
+	}
+
+	created, replayed, err := service.CreateLoanWithIdempotencyKey(newLoan("user123"), "form-1")
+	require.NoError(t, err)
+	assert.False(t, replayed)
+
+	// Replay returns the same loan
+	replay, replayed, err := service.CreateLoanWithIdempotencyKey(newLoan("user123"), "form-1")
+	require.NoError(t, err)
+	assert.True(t, replayed)
+	assert.Equal(t, created.ID, replay.ID)
+
+	// Keys are scoped per borrower
+	other, replayed, err := service.CreateLoanWithIdempotencyKey(newLoan("user456"), "form-1")
+	require.NoError(t, err)
+	assert.False(t, replayed)
+	assert.NotEqual(t, created.ID, other.ID)
+
+	// Expired keys create a new loan
+	service.clock = fixedClock{now: now.Add(2 * time.Hour)}
+	fresh, replayed, err := service.CreateLoanWithIdempotencyKey(newLoan("user123"), "form-1")
+	require.NoError(t, err)
+	assert.False(t, replayed)
+	assert.NotEqual(t, created.ID, fresh.ID)
+}
+
+func TestCreateLoanWithIdempotencyKeyConcurrent(t *testing.T) {
+	service, db := setupTestService()
+
+	// Serialize access so all goroutines share the same in-memory database
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+
+	const requests = 10
+	ids := make(chan string, requests)
+	errs := make(chan error, requests)
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			loan := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: 6.0}
+			created, _, err := service.CreateLoanWithIdempotencyKey(loan, "form-1")
+			if err != nil {
+				errs <- err
+				return
+			}
+			ids <- created.ID
+		}()
+	}
+	wg.Wait()
+	close(ids)
+	close(errs)
+
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	unique := make(map[string]bool)
+	for id := range ids {
+		unique[id] = true
+	}
+	assert.Len(t, unique, 1)
+
+	var count int64
+	require.NoError(t, db.Model(&domain.Loan{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestRevokeApproval(t *testing.T) {
+	service, db := setupTestService()
+
+	loan := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(10000.00), Rate: 4.5, ROI: 6.0}
+	require.NoError(t, service.CreateLoan(loan))
+	_, err := service.ApproveLoan(loan.ID, &domain.ApprovalDetails{
+		FieldValidatorProof: domain.ProofLinks{"proof"},
+		FieldValidatorID:    "validator_001",
+	}, "Provide land title")
+	require.NoError(t, err)
+
+	revokedLoan, err := service.RevokeApproval(loan.ID, "wrong validator")
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusProposed, revokedLoan.Status)
+
+	storedLoan, err := service.GetLoan(loan.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusProposed, storedLoan.Status)
+	if storedLoan.ApprovalDetails != nil {
+		assert.Empty(t, storedLoan.ApprovalDetails.FieldValidatorID)
+	}
+	assert.Empty(t, storedLoan.Covenants)
+
+	var revocations []domain.ApprovalRevocation
+	require.NoError(t, db.Where("loan_id = ?", loan.ID).Find(&revocations).Error)
+	require.Len(t, revocations, 1)
+	assert.Equal(t, "validator_001", revocations[0].FieldValidatorID)
+	assert.Equal(t, "wrong validator", revocations[0].Reason)
+
+	// The loan can be approved again
+	_, err = service.ApproveLoan(loan.ID, &domain.ApprovalDetails{FieldValidatorProof: domain.ProofLinks{"proof"}, FieldValidatorID: "validator_002"})
+	assert.NoError(t, err)
+}
+
+func TestRevokeApprovalWithInvestments(t *testing.T) {
+	service, _ := setupTestService()
+	loan := createApprovedLoan(t, service, 10000.00)
+
+	_, err := service.InvestInLoan(loan.ID, "investor_001", domain.NewMoney(1000.00))
+	require.NoError(t, err)
+
+	_, err = service.RevokeApproval(loan.ID, "wrong validator")
+	assert.ErrorIs(t, err, domain.ErrCannotRevokeApproval)
+
+	proposed := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(10000.00), Rate: 4.5, ROI: 6.0}
+	require.NoError(t, service.CreateLoan(proposed))
+	_, err = service.RevokeApproval(proposed.ID, "wrong validator")
+	assert.ErrorIs(t, err, domain.ErrCannotRevokeApproval)
+}
+
+func TestForceTransition(t *testing.T) {
+	service, _ := setupTestService()
+	loan := createApprovedLoan(t, service, 10000.00)
+
+	_, err := service.ForceTransition(loan.ID, domain.StatusInvested, "admin_001", "  ")
+	assert.ErrorIs(t, err, ErrJustificationRequired)
+
+	forced, err := service.ForceTransition(loan.ID, domain.StatusInvested, "admin_001", "funds received off-platform")
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusInvested, forced.Status)
+
+	events, err := service.GetHistory(loan.ID)
+	require.NoError(t, err)
+	last := events[len(events)-1]
+	assert.Equal(t, domain.StatusApproved, last.FromStatus)
+	assert.Equal(t, domain.StatusInvested, last.ToStatus)
+	assert.Equal(t, "invest", last.Action)
+	assert.Equal(t, "admin_001", last.ActorID)
+	assert.True(t, last.ManualOverride)
+	assert.Equal(t, "funds received off-platform", last.Metadata["justification"])
+	assert.False(t, events[0].ManualOverride)
+
+	// Transitions the state machine forbids are rejected
+	proposed := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(10000.00), Rate: 4.5, ROI: 6.0}
+	require.NoError(t, service.CreateLoan(proposed))
+	_, err = service.ForceTransition(proposed.ID, domain.StatusDisbursed, "admin_001", "skip ahead")
+	assert.ErrorIs(t, err, domain.ErrTransitionNotAllowed)
+
+	stored, err := service.GetLoan(proposed.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusProposed, stored.Status)
+}
+
+func TestForceTransitionSideEffects(t *testing.T) {
+	service, _ := setupTestService()
+
+	// A forced cancellation refunds the investments like the regular one
+	partial := createApprovedLoan(t, service, 10000.00)
+	_, err := service.InvestInLoan(partial.ID, "investor_001", domain.NewMoney(4000.00))
+	require.NoError(t, err)
+	_, err = service.ForceTransition(partial.ID, domain.StatusCancelled, "admin_001", "borrower withdrew by phone")
+	require.NoError(t, err)
+
+	stored, err := service.GetLoan(partial.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusCancelled, stored.Status)
+	assert.Equal(t, domain.Money(0), stored.TotalInvested)
+	require.Len(t, stored.Refunds, 1)
+	assert.Equal(t, "investor_001", stored.Refunds[0].InvestorID)
+	assert.Equal(t, domain.NewMoney(4000.00), stored.Refunds[0].Amount)
+
+	events, err := service.GetHistory(partial.ID)
+	require.NoError(t, err)
+	last := events[len(events)-1]
+	assert.Equal(t, "refund", last.Action)
+	assert.Equal(t, "investor_001", last.ActorID)
+
+	// Disbursing needs the details only the regular action takes
+	invested := createApprovedLoan(t, service, 10000.00)
+	_, err = service.InvestInLoan(invested.ID, "investor_001", domain.NewMoney(10000.00))
+	require.NoError(t, err)
+	_, err = service.ForceTransition(invested.ID, domain.StatusDisbursed, "admin_001", "paid out by bank transfer")
+	assert.ErrorIs(t, err, ErrTransitionNotForceable)
+
+	stored, err = service.GetLoan(invested.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusInvested, stored.Status)
+}
+
+func TestApproveLoanConcurrent(t *testing.T) {
+	service, db := setupTestService()
+
+	// Serialize access so all goroutines share the same in-memory database
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+
+	loan := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: 6.0}
+	require.NoError(t, service.CreateLoan(loan))
+
+	const requests = 10
+	winners := make(chan string, requests)
+	conflicts := make(chan *AlreadyApprovedError, requests)
+	errs := make(chan error, requests)
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func(validatorID string) {
+			defer wg.Done()
+			_, err := service.ApproveLoan(loan.ID, &domain.ApprovalDetails{
+				FieldValidatorProof: domain.ProofLinks{"proof"},
+				FieldValidatorID:    validatorID,
+			}, "covenant by "+validatorID)
+
+			var approvedErr *AlreadyApprovedError
+			switch {
+			case err == nil:
+				winners <- validatorID
+			case errors.As(err, &approvedErr):
+				conflicts <- approvedErr
+			default:
+				errs <- err
+			}
+		}(fmt.Sprintf("validator_%03d", i))
+	}
+	wg.Wait()
+	close(winners)
+	close(conflicts)
+	close(errs)
+
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	require.Len(t, winners, 1)
+	winner := <-winners
+	assert.Len(t, conflicts, requests-1)
+	for conflict := range conflicts {
+		assert.Equal(t, winner, conflict.FieldValidatorID)
+	}
+
+	storedLoan, err := service.GetLoan(loan.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusApproved, storedLoan.Status)
+	assert.Equal(t, winner, storedLoan.ApprovalDetails.FieldValidatorID)
+	require.Len(t, storedLoan.Covenants, 1)
+	assert.Equal(t, "covenant by "+winner, storedLoan.Covenants[0].Description)
+}
+
+func TestInvestInLoanInactiveInvestor(t *testing.T) {
+	_, db := setupTestService()
+	investorRepo := repository.NewInvestorRepository(db)
+	service := NewLoanService(repository.NewLoanRepository(db), WithInvestors(investorRepo)).(*loanService)
+	investors := NewInvestorService(investorRepo, repository.NewInvestmentRepository(db))
+
+	loan := createApprovedLoan(t, service, 10000.00)
+	_, err := service.InvestInLoan(loan.ID, "investor_001", domain.NewMoney(2000.00))
+	require.NoError(t, err)
+
+	_, err = investors.SetStatus("investor_001", domain.InvestorInactive, "KYC expired")
+	require.NoError(t, err)
+
+	_, err = service.InvestInLoan(loan.ID, "investor_001", domain.NewMoney(2000.00))
+	assert.ErrorIs(t, err, domain.ErrInvestorInactive)
+
+	// Existing stakes are kept and other investors are not affected
+	updated, err := service.InvestInLoan(loan.ID, "investor_002", domain.NewMoney(2000.00))
+	require.NoError(t, err)
+	assert.Equal(t, domain.NewMoney(4000.00), updated.TotalInvested)
+
+	investor, err := investors.SetStatus("investor_001", domain.InvestorActive, "")
+	require.NoError(t, err)
+	assert.Empty(t, investor.StatusReason)
+
+	_, err = service.InvestInLoan(loan.ID, "investor_001", domain.NewMoney(2000.00))
+	assert.NoError(t, err)
+}
+
+func TestGetHistory(t *testing.T) {
+	service, _ := setupTestService()
+
+	loan := &domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(25000.00), Rate: 4.5, ROI: 6.0}
+	require.NoError(t, service.CreateLoan(loan))
+
+	_, err := service.ApproveLoan(loan.ID, &domain.ApprovalDetails{
+		FieldValidatorProof: domain.ProofLinks{"https://example.com/proof.jpg"},
+		FieldValidatorID:    "validator_001",
+	})
+	require.NoError(t, err)
+
+	// A failed transition leaves no trace
+	_, err = service.RejectLoan(loan.ID, &domain.RejectionDetails{FieldValidatorID: "validator_002", Reason: "Too late"})
+	require.ErrorIs(t, err, domain.ErrCannotReject)
+
+	// Only the investment completing the loan changes its status
+	_, err = service.InvestInLoan(loan.ID, "investor_001", domain.NewMoney(10000.00))
+	require.NoError(t, err)
+	_, err = service.InvestInLoan(loan.ID, "investor_002", domain.NewMoney(15000.00))
+	require.NoError(t, err)
+
+	_, err = service.DisburseLoan(loan.ID, &domain.DisbursementDetails{
+		SignedAgreementLink: "https://example.com/signed.pdf",
+		FieldOfficerID:      "officer_001",
+	})
+	require.NoError(t, err)
+
+	events, err := service.GetHistory(loan.ID)
+	require.NoError(t, err)
+	require.Len(t, events, 4)
+
+	assert.Equal(t, "create", events[0].Action)
+	assert.Equal(t, domain.LoanStatus(""), events[0].FromStatus)
+	assert.Equal(t, domain.StatusProposed, events[0].ToStatus)
+	assert.Equal(t, "user123", events[0].ActorID)
+
+	assert.Equal(t, "approve", events[1].Action)
+	assert.Equal(t, domain.StatusProposed, events[1].FromStatus)
+	assert.Equal(t, "validator_001", events[1].ActorID)
+
+	assert.Equal(t, "invest", events[2].Action)
+	assert.Equal(t, domain.StatusApproved, events[2].FromStatus)
+	assert.Equal(t, domain.StatusInvested, events[2].ToStatus)
+	assert.Equal(t, "investor_002", events[2].ActorID)
+	assert.NotEmpty(t, events[2].Metadata["investment_id"])
+
+	assert.Equal(t, "disburse", events[3].Action)
+	assert.Equal(t, domain.StatusDisbursed, events[3].ToStatus)
+	assert.Equal(t, "officer_001", events[3].ActorID)
+
+	for i := 1; i < len(events); i++ {
+		assert.Greater(t, events[i].Version, events[i-1].Version)
+	}
+
+	_, err = service.GetHistory("nonexistent-id")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func createDisbursedLoan(t *testing.T, service *loanService, principal float64) *domain.Loan {
+	loan := createApprovedLoan(t, service, principal)
+
+	_, err := service.InvestInLoan(loan.ID, "investor_001", domain.NewMoney(principal))
+	require.NoError(t, err)
+
+	disbursedLoan, err := service.DisburseLoan(loan.ID, &domain.DisbursementDetails{
+		SignedAgreementLink: "https://example.com/signed-agreement.pdf",
+		FieldOfficerID:      "officer_001",
+	})
+	require.NoError(t, err)
+	return disbursedLoan
+}
+
+func TestRecordRepaymentPartial(t *testing.T) {
+	service, _ := setupTestService()
+
+	loan := createDisbursedLoan(t, service, 12000.00)
+	due := loan.AmountDue()
+	assert.Greater(t, due, loan.PrincipalAmount, "the amount due includes interest")
+
+	repaidAt := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	repaidLoan, err := service.RecordRepayment(loan.ID, domain.NewMoney(1000.00), repaidAt)
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusDisbursed, repaidLoan.Status)
+	assert.Equal(t, domain.NewMoney(1000.00), repaidLoan.TotalRepaid)
+	assert.Equal(t, due-domain.NewMoney(1000.00), repaidLoan.RemainingBalance())
+
+	storedLoan, err := service.GetLoan(loan.ID)
+	require.NoError(t, err)
+	require.Len(t, storedLoan.Repayments, 1)
+	assert.Equal(t, domain.NewMoney(1000.00), storedLoan.Repayments[0].Amount)
+	assert.True(t, repaidAt.Equal(storedLoan.Repayments[0].RepaymentDate))
+	assert.Equal(t, domain.NewMoney(1000.00), storedLoan.TotalRepaid)
+}
+
+func TestRecordRepaymentFull(t *testing.T) {
+	service, _ := setupTestService()
+
+	loan := createDisbursedLoan(t, service, 12000.00)
+	due := loan.AmountDue()
+
+	_, err := service.RecordRepayment(loan.ID, domain.NewMoney(5000.00), time.Time{})
+	require.NoError(t, err)
+
+	// The final repayment covers principal and interest and completes the loan
+	repaidLoan, err := service.RecordRepayment(loan.ID, due-domain.NewMoney(5000.00), time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, domain.StatusRepaid, repaidLoan.Status)
+	assert.Equal(t, due, repaidLoan.TotalRepaid)
+	assert.Equal(t, domain.Money(0), repaidLoan.RemainingBalance())
+
+	events, err := service.GetHistory(loan.ID)
+	require.NoError(t, err)
+	last := events[len(events)-1]
+	assert.Equal(t, "repay", last.Action)
+	assert.Equal(t, domain.StatusRepaid, last.ToStatus)
+
+	// Repaid loans take no further repayments
+	_, err = service.RecordRepayment(loan.ID, domain.NewMoney(1.00), time.Time{})
+	assert.ErrorIs(t, err, domain.ErrLoanNotDisbursed)
+}
+
+func TestRecordRepaymentOverpayment(t *testing.T) {
+	service, _ := setupTestService()
+
+	loan := createDisbursedLoan(t, service, 12000.00)
+
+	_, err := service.RecordRepayment(loan.ID, loan.AmountDue()+1, time.Time{})
+	assert.ErrorIs(t, err, domain.ErrRepaymentExceedsBalance)
+
+	storedLoan, err := service.GetLoan(loan.ID)
+	require.NoError(t, err)
+	assert.Empty(t, storedLoan.Repayments)
+	assert.Equal(t, domain.Money(0), storedLoan.TotalRepaid)
+}
+
+func TestRecordRepaymentNotDisbursed(t *testing.T) {
+	service, _ := setupTestService()
+
+	loan := createApprovedLoan(t, service, 12000.00)
+
+	_, err := service.RecordRepayment(loan.ID, domain.NewMoney(1000.00), time.Time{})
+	assert.ErrorIs(t, err, domain.ErrLoanNotDisbursed)
+}
+
+func TestReconcile(t *testing.T) {
+	service, db := setupTestService()
+
+	loan := createApprovedLoan(t, service, 10000.00)
+	_, err := service.InvestInLoan(loan.ID, "investor_001", domain.NewMoney(4000.00))
+	require.NoError(t, err)
+
+	// A consistent loan is left alone
+	result, err := service.Reconcile(loan.ID)
+	require.NoError(t, err)
+	assert.False(t, result.Corrected)
+	assert.Equal(t, domain.NewMoney(4000.00), result.Loan.TotalInvested)
+
+	// Simulate a manual edit that made the total drift from the investments
+	require.NoError(t, db.Model(&domain.Loan{}).Where("id = ?", loan.ID).UpdateColumn("total_invested", domain.NewMoney(7000.00)).Error)
+
+	result, err = service.Reconcile(loan.ID)
+	require.NoError(t, err)
+	assert.True(t, result.Corrected)
+	assert.Equal(t, domain.NewMoney(7000.00), result.PreviousTotalInvested)
+	assert.Equal(t, domain.NewMoney(4000.00), result.Loan.TotalInvested)
+
+	stored, err := service.GetLoan(loan.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.NewMoney(4000.00), stored.TotalInvested)
+
+	// The correction is part of the loan's history
+	events, err := service.GetHistory(loan.ID)
+	require.NoError(t, err)
+	last := events[len(events)-1]
+	assert.Equal(t, "reconcile", last.Action)
+	assert.Equal(t, "7000.00", last.Metadata["previous_total_invested"])
+
+	// The remaining capacity is based on the corrected total
+	_, err = service.InvestInLoan(loan.ID, "investor_002", domain.NewMoney(6000.00))
+	require.NoError(t, err)
+}
+
+func TestWithContextCancelled(t *testing.T) {
+	service, _ := setupTestService()
+	loan := createApprovedLoan(t, service, 10000.00)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := service.WithContext(ctx).InvestInLoan(loan.ID, "investor_001", domain.NewMoney(1000.00))
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// The unbound service keeps working and nothing was invested
+	found, err := service.GetLoan(loan.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.Money(0), found.TotalInvested)
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/smtp"
+	"strings"
+
+	"loan-service/internal/config"
+	"loan-service/internal/domain"
+	"loan-service/internal/repository"
+)
+
+// Notifier tells investors about changes to the loans they invested in
+type Notifier interface {
+	SendInvestmentComplete(loan domain.Loan, investors []string) error
+}
+
+// sendMailFunc matches smtp.SendMail so tests can capture outgoing mail
+type sendMailFunc func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error
+
+// SMTPNotifier emails investors at the address recorded on their investor record
+type SMTPNotifier struct {
+	cfg       config.SMTPConfig
+	investors repository.InvestorRepository
+	sendMail  sendMailFunc
+}
+
+// NewSMTPNotifier creates a notifier that sends mail through the configured SMTP server
+func NewSMTPNotifier(cfg config.SMTPConfig, investors repository.InvestorRepository) *SMTPNotifier {
+	return &SMTPNotifier{cfg: cfg, investors: investors, sendMail: smtp.SendMail}
+}
+
+// SendInvestmentComplete emails each investor the agreement letter of the fully invested loan.
+// Investors without an email address are skipped; failed sends are collected and returned together.
+func (n *SMTPNotifier) SendInvestmentComplete(loan domain.Loan, investors []string) error {
+	var auth smtp.Auth
+	if n.cfg.Username != "" {
+		auth = smtp.PlainAuth("", n.cfg.Username, n.cfg.Password, n.cfg.Host)
+	}
+	addr := fmt.Sprintf("%s:%d", n.cfg.Host, n.cfg.Port)
+
+	var errs []error
+	for _, investorID := range investors {
+		investor, err := findInvestor(n.investors, investorID)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if investor.Email == "" {
+			log.Printf("Investor %s has no email address, skipping investment notification for loan %s", investorID, loan.ID)
+			continue
+		}
+
+		msg := investmentCompleteMessage(n.cfg.From, investor.Email, loan)
+		if err := n.sendMail(addr, auth, n.cfg.From, []string{investor.Email}, msg); err != nil {
+			errs = append(errs, fmt.Errorf("notify investor %s: %w", investorID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// investmentCompleteMessage builds the email announcing that a loan is fully invested
+func investmentCompleteMessage(from, to string, loan domain.Loan) []byte {
+	agreement := "Your agreement letter is still being prepared and will follow shortly."
+	if loan.AgreementLetterLink != "" {
+		agreement = "Your agreement letter is available at " + loan.AgreementLetterLink
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", to)
+	fmt.Fprintf(&b, "Subject: Loan %s is fully invested\r\n", loan.ID)
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	fmt.Fprintf(&b, "Loan %s has reached its principal of %s and is now fully invested.\r\n\r\n", loan.ID, loan.PrincipalAmount)
+	fmt.Fprintf(&b, "%s\r\n", agreement)
+	return []byte(b.String())
+}
+
+// notifyInvestmentComplete tells every investor of a loan that just became fully invested.
+// The investment is already recorded, so a failed notification is logged rather than returned.
+func (s *loanService) notifyInvestmentComplete(loan *domain.Loan) {
+	if s.notifier == nil || loan.Status != domain.StatusInvested {
+		return
+	}
+
+	if err := s.notifier.SendInvestmentComplete(*loan, loan.InvestorIDs()); err != nil {
+		log.Printf("Failed to notify investors of loan %s: %v", loan.ID, err)
+	}
+}
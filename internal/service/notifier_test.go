@@ -0,0 +1,80 @@
+package service
+
+import (
+	"errors"
+	"net/smtp"
+	"testing"
+
+	"loan-service/internal/config"
+	"loan-service/internal/domain"
+	"loan-service/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingNotifier collects the investors notified of each completed loan
+type recordingNotifier struct {
+	calls map[string][]string
+}
+
+func (n *recordingNotifier) SendInvestmentComplete(loan domain.Loan, investors []string) error {
+	if n.calls == nil {
+		n.calls = make(map[string][]string)
+	}
+	n.calls[loan.ID] = append(n.calls[loan.ID], investors...)
+	return nil
+}
+
+func TestInvestInLoanNotifiesInvestors(t *testing.T) {
+	notifier := &recordingNotifier{}
+	service := setupTestServiceWithOptions(WithNotifier(notifier))
+
+	loan := createApprovedLoan(t, service, 10000.00)
+	_, err := service.InvestInLoan(loan.ID, "investor_001", domain.NewMoney(4000.00))
+	require.NoError(t, err)
+	_, err = service.InvestInLoan(loan.ID, "investor_002", domain.NewMoney(3000.00))
+	require.NoError(t, err)
+
+	// Nobody is notified before the loan is fully invested
+	assert.Empty(t, notifier.calls)
+
+	_, err = service.InvestInLoan(loan.ID, "investor_001", domain.NewMoney(3000.00))
+	require.NoError(t, err)
+
+	// Each investor is notified exactly once
+	assert.Equal(t, []string{"investor_001", "investor_002"}, notifier.calls[loan.ID])
+}
+
+func TestSMTPNotifierSendInvestmentComplete(t *testing.T) {
+	_, db := setupTestService()
+	investors := repository.NewInvestorRepository(db)
+	require.NoError(t, investors.Save(&domain.Investor{ID: "investor_001", Status: domain.InvestorActive, Email: "one@example.com"}))
+
+	notifier := NewSMTPNotifier(config.SMTPConfig{Host: "smtp.example.com", Port: 587, From: "loans@example.com"}, investors)
+	var sent []string
+	var messages []string
+	notifier.sendMail = func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error {
+		assert.Equal(t, "smtp.example.com:587", addr)
+		assert.Nil(t, auth)
+		sent = append(sent, to...)
+		messages = append(messages, string(msg))
+		return nil
+	}
+
+	loan := domain.Loan{ID: "loan-1", PrincipalAmount: domain.NewMoney(10000.00), AgreementLetterLink: "https://example.com/agreements/loan-1.pdf"}
+
+	// Investors without an email address are skipped
+	err := notifier.SendInvestmentComplete(loan, []string{"investor_001", "investor_002"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"one@example.com"}, sent)
+	require.Len(t, messages, 1)
+	assert.Contains(t, messages[0], "Subject: Loan loan-1 is fully invested")
+	assert.Contains(t, messages[0], "https://example.com/agreements/loan-1.pdf")
+
+	notifier.sendMail = func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error {
+		return errors.New("connection refused")
+	}
+	err = notifier.SendInvestmentComplete(loan, []string{"investor_001"})
+	assert.ErrorContains(t, err, "investor_001")
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"sync"
+
+	"loan-service/internal/domain"
+	"loan-service/internal/repository"
+)
+
+// OutstandingReport is the outstanding balance across disbursed loans with its breakdown
+type OutstandingReport struct {
+	GroupBy string
+	Total   domain.OutstandingBalance
+	Groups  []domain.OutstandingBalance
+}
+
+// StatsService defines the interface for portfolio statistics
+type StatsService interface {
+	GetOutstanding(groupBy string) (*OutstandingReport, error)
+	GetPlatformStats(fresh bool) (*domain.PlatformStats, error)
+	RefreshPlatformStats() (*domain.PlatformStats, error)
+}
+
+// statsService implements StatsService
+type statsService struct {
+	repo  repository.StatsRepository
+	clock Clock
+
+	mu       sync.RWMutex
+	snapshot *domain.PlatformStats
+}
+
+// NewStatsService creates a new stats service
+func NewStatsService(repo repository.StatsRepository) StatsService {
+	return &statsService{repo: repo, clock: systemClock{}}
+}
+
+// GetPlatformStats returns the cached platform statistics snapshot. The snapshot is computed
+// when fresh is set or when no snapshot has been computed yet.
+func (s *statsService) GetPlatformStats(fresh bool) (*domain.PlatformStats, error) {
+	if !fresh {
+		s.mu.RLock()
+		snapshot := s.snapshot
+		s.mu.RUnlock()
+		if snapshot != nil {
+			return snapshot, nil
+		}
+	}
+	return s.RefreshPlatformStats()
+}
+
+// RefreshPlatformStats recomputes the platform statistics and replaces the cached snapshot
+func (s *statsService) RefreshPlatformStats() (*domain.PlatformStats, error) {
+	stats, err := s.repo.PlatformStats()
+	if err != nil {
+		return nil, err
+	}
+	stats.ComputedAt = s.clock.Now()
+
+	s.mu.Lock()
+	s.snapshot = stats
+	s.mu.Unlock()
+
+	return stats, nil
+}
+
+// GetOutstanding returns the outstanding balance across disbursed loans, optionally grouped
+// by borrower or by month of disbursement
+func (s *statsService) GetOutstanding(groupBy string) (*OutstandingReport, error) {
+	if groupBy != repository.GroupByNone && groupBy != repository.GroupByBorrower && groupBy != repository.GroupByMonth {
+		return nil, ErrInvalidGroupBy
+	}
+
+	balances, err := s.repo.OutstandingBalances(groupBy)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &OutstandingReport{GroupBy: groupBy, Groups: []domain.OutstandingBalance{}}
+	for _, balance := range balances {
+		report.Total.Loans += balance.Loans
+		report.Total.Principal += balance.Principal
+		report.Total.Outstanding += balance.Outstanding
+	}
+	if groupBy != repository.GroupByNone {
+		report.Groups = balances
+	}
+
+	return report, nil
+}
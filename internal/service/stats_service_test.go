@@ -0,0 +1,67 @@
+package service
+
+import (
+	"sync"
+	"testing"
+
+	"loan-service/internal/domain"
+	"loan-service/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPlatformStatsCaching(t *testing.T) {
+	_, db := setupTestService()
+	service := NewStatsService(repository.NewStatsRepository(db))
+
+	require.NoError(t, db.Create(&domain.Loan{BorrowerID: "user123", PrincipalAmount: domain.NewMoney(10000.00), Rate: 4.5, ROI: 6.0}).Error)
+
+	// The first request computes the snapshot
+	stats, err := service.GetPlatformStats(false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stats.TotalLoans)
+	assert.False(t, stats.ComputedAt.IsZero())
+
+	require.NoError(t, db.Create(&domain.Loan{BorrowerID: "user456", PrincipalAmount: domain.NewMoney(10000.00), Rate: 4.5, ROI: 6.0}).Error)
+
+	// Later requests are served from the cache
+	cached, err := service.GetPlatformStats(false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), cached.TotalLoans)
+	assert.Equal(t, stats.ComputedAt, cached.ComputedAt)
+
+	// Fresh requests recompute the snapshot and update the cache
+	fresh, err := service.GetPlatformStats(true)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), fresh.TotalLoans)
+
+	cached, err = service.GetPlatformStats(false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), cached.TotalLoans)
+}
+
+func TestPlatformStatsConcurrentAccess(t *testing.T) {
+	_, db := setupTestService()
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+
+	service := NewStatsService(repository.NewStatsRepository(db))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(refresh bool) {
+			defer wg.Done()
+			if refresh {
+				_, err := service.RefreshPlatformStats()
+				assert.NoError(t, err)
+				return
+			}
+			_, err := service.GetPlatformStats(false)
+			assert.NoError(t, err)
+		}(i%2 == 0)
+	}
+	wg.Wait()
+}
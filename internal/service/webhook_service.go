@@ -0,0 +1,47 @@
+package service
+
+import (
+	"loan-service/internal/domain"
+	"loan-service/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// WebhookService defines the interface for managing webhook subscriptions
+type WebhookService interface {
+	CreateSubscription(subscription *domain.WebhookSubscription) error
+	GetSubscriptions() ([]domain.WebhookSubscription, error)
+	DeleteSubscription(id string) error
+}
+
+// webhookService implements WebhookService
+type webhookService struct {
+	repo repository.WebhookRepository
+}
+
+// NewWebhookService creates a new webhook service
+func NewWebhookService(repo repository.WebhookRepository) WebhookService {
+	return &webhookService{repo: repo}
+}
+
+// CreateSubscription registers a URL to receive events
+func (s *webhookService) CreateSubscription(subscription *domain.WebhookSubscription) error {
+	return s.repo.CreateSubscription(subscription)
+}
+
+// GetSubscriptions retrieves all webhook subscriptions
+func (s *webhookService) GetSubscriptions() ([]domain.WebhookSubscription, error) {
+	return s.repo.FindSubscriptions()
+}
+
+// DeleteSubscription removes a webhook subscription
+func (s *webhookService) DeleteSubscription(id string) error {
+	deleted, err := s.repo.DeleteSubscription(id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
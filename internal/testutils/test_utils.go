@@ -6,7 +6,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 
-	"loan-service/internal/domain"
+	v1 "loan-service/api/v1"
+	"loan-service/internal/config"
+	"loan-service/internal/database"
 	"loan-service/internal/dto"
 	"loan-service/internal/handler"
 	"loan-service/internal/middleware"
@@ -27,18 +29,18 @@ type TestSetup struct {
 
 // SetupTestDB creates a test database
 func SetupTestDB() *gorm.DB {
-	database, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
 	if err != nil {
 		panic("failed to connect to test database")
 	}
 
 	// Auto migrate the schema
-	err = database.AutoMigrate(&domain.Loan{}, &domain.Investment{})
+	err = database.Migrate(db)
 	if err != nil {
 		panic("failed to migrate test database")
 	}
 
-	return database
+	return db
 }
 
 // SetupTestRouter creates a test router without setting up routes
@@ -48,52 +50,53 @@ func SetupTestRouter() *gin.Engine {
 	return router
 }
 
+// TestConfig returns the default configuration used by test servers
+func TestConfig() *config.Config {
+	cfg, err := config.Load()
+	if err != nil {
+		panic("failed to load test configuration")
+	}
+	return cfg
+}
+
 // SetupTestServer creates a test server with in-memory database (for integration tests)
 func SetupTestServer() *TestSetup {
+	return SetupTestServerWithConfig(TestConfig())
+}
+
+// SetupTestServerWithConfig creates a test server using the given configuration
+func SetupTestServerWithConfig(cfg *config.Config) *TestSetup {
 	// Set Gin to test mode
 	gin.SetMode(gin.TestMode)
 
 	// Register custom validations
 	dto.RegisterCustomValidations()
+	handler.SetStrictJSON(cfg.Server.StrictJSON)
+	handler.SetMaxEmbeddedInvestments(cfg.Server.MaxEmbeddedInvestments)
+	middleware.SetJWTSecret(cfg.Auth.JWTSecret)
+	middleware.SetIdempotencyKeyTTL(cfg.Loan.IdempotencyKeyTTL)
+	middleware.SetRateLimit(cfg.Server.RateLimitRPS, cfg.Server.RateLimitBurst)
+	middleware.SetCORS(cfg.Server.CORSAllowedOrigins, cfg.Server.CORSAllowedMethods, cfg.Server.CORSAllowedHeaders)
 
 	// Create test database
 	testDB := SetupTestDB()
 
-	// Create router
-	router := gin.New()
-
-	// Add middleware
-	router.Use(middleware.Recovery())
-	router.Use(middleware.Logger())
-	router.Use(middleware.CORS())
-
-	// Health check
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "ok", "service": "loan-service"})
-	})
-
-	// Initialize dependencies
+	// Initialize services
 	loanRepo := repository.NewLoanRepository(testDB)
-	loanService := service.NewLoanService(loanRepo)
-	loanHandler := handler.NewLoanHandler(loanService)
-
-	// API routes
-	api := router.Group("/api/v1")
-	{
-		// Loan routes
-		loans := api.Group("/loans")
-		{
-			loans.GET("/", loanHandler.GetLoans)
-			loans.GET("/:id", loanHandler.GetLoan)
-			loans.POST("/", loanHandler.CreateLoan)
-			loans.PUT("/:id", loanHandler.UpdateLoan)
-			loans.DELETE("/:id", loanHandler.DeleteLoan)
-			loans.PUT("/:id/approve", loanHandler.ApproveLoan)
-			loans.PUT("/:id/invest", loanHandler.InvestLoan)
-			loans.PUT("/:id/disburse", loanHandler.DisburseLoan)
-			loans.GET("/:id/transitions", loanHandler.GetLoanTransitions)
-		}
+	autoInvestRepo := repository.NewAutoInvestRepository(testDB)
+	investorRepo := repository.NewInvestorRepository(testDB)
+	loanService := service.NewLoanService(loanRepo, service.WithConfig(cfg.Loan), service.WithAutoInvest(autoInvestRepo), service.WithInvestors(investorRepo))
+	autoInvestService := service.NewAutoInvestService(autoInvestRepo)
+	statsService := service.NewStatsService(repository.NewStatsRepository(testDB))
+	investorService := service.NewInvestorService(investorRepo, repository.NewInvestmentRepository(testDB))
+	webhookService := service.NewWebhookService(repository.NewWebhookRepository(testDB))
+
+	// Create router with the same routes, middleware and trusted proxies as the server
+	router := gin.New()
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		panic("failed to configure trusted proxies")
 	}
+	v1.SetupRoutes(router, testDB, loanService, autoInvestService, statsService, investorService, webhookService)
 
 	// Create test server
 	server := httptest.NewServer(router)
@@ -107,6 +110,20 @@ func SetupTestServer() *TestSetup {
 
 // MakeRequest is a helper function to make HTTP requests
 func MakeRequest(method, url string, body interface{}) (*http.Response, error) {
+	return MakeRequestWithToken(method, url, "", body)
+}
+
+// MakeRequestWithToken makes an HTTP request authenticated with the given bearer token
+func MakeRequestWithToken(method, url, token string, body interface{}) (*http.Response, error) {
+	headers := map[string]string{}
+	if token != "" {
+		headers["Authorization"] = "Bearer " + token
+	}
+	return MakeRequestWithHeaders(method, url, headers, body)
+}
+
+// MakeRequestWithHeaders makes an HTTP request with additional headers
+func MakeRequestWithHeaders(method, url string, headers map[string]string, body interface{}) (*http.Response, error) {
 	var reqBody []byte
 	var err error
 
@@ -123,6 +140,9 @@ func MakeRequest(method, url string, body interface{}) (*http.Response, error) {
 	}
 
 	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
 
 	client := &http.Client{}
 	return client.Do(req)
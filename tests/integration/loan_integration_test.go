@@ -2,10 +2,15 @@ package integration
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
+	"regexp"
+	"strings"
 	"testing"
 
+	"loan-service/internal/domain"
 	"loan-service/internal/dto"
+	"loan-service/internal/middleware"
 	"loan-service/internal/testutils"
 
 	"github.com/stretchr/testify/assert"
@@ -26,9 +31,9 @@ func TestCompleteLoanLifecycle(t *testing.T) {
 	t.Log("Step 1: Creating loan application...")
 	createReq := dto.CreateLoanRequest{
 		BorrowerID:      "borrower_001",
-		PrincipalAmount: 50000.00,
-		Rate:            5.5,
-		ROI:             7.2,
+		PrincipalAmount: domain.NewMoney(50000.00),
+		Rate:            7.2,
+		ROI:             5.5,
 	}
 
 	createResp, err := testutils.MakeRequest("POST", baseURL+"/api/v1/loans/", createReq)
@@ -51,7 +56,7 @@ func TestCompleteLoanLifecycle(t *testing.T) {
 	// Step 2: Field validation and loan approval
 	t.Log("Step 2: Processing field validation and approval...")
 	approveReq := dto.ApproveLoanRequest{
-		FieldValidatorProof: "https://example.com/field-validation/proof_123.jpg",
+		FieldValidatorProof: domain.ProofLinks{"https://example.com/field-validation/proof_123.jpg"},
 		FieldValidatorID:    "validator_001",
 	}
 
@@ -70,7 +75,7 @@ func TestCompleteLoanLifecycle(t *testing.T) {
 
 	// Verify approval details
 	approvalDetails := approvedLoan["approval_details"].(map[string]interface{})
-	assert.Equal(t, "https://example.com/field-validation/proof_123.jpg", approvalDetails["field_validator_proof"])
+	assert.Equal(t, []interface{}{"https://example.com/field-validation/proof_123.jpg"}, approvalDetails["field_validator_proof"])
 	assert.Equal(t, "validator_001", approvalDetails["field_validator_id"])
 	assert.NotEmpty(t, approvalDetails["approval_date"])
 
@@ -80,7 +85,7 @@ func TestCompleteLoanLifecycle(t *testing.T) {
 	t.Log("Step 3: Processing investment...")
 	investReq := dto.InvestLoanRequest{
 		InvestorID: "investor_001",
-		Amount:     50000.00,
+		Amount:     domain.NewMoney(50000.00),
 	}
 
 	investResp, err := testutils.MakeRequest("PUT", baseURL+"/api/v1/loans/"+loanID+"/invest", investReq)
@@ -132,6 +137,40 @@ func TestCompleteLoanLifecycle(t *testing.T) {
 	assert.NotEmpty(t, disbursementDetails["disbursement_date"])
 
 	t.Log("✓ Loan successfully disbursed with signed agreement and disbursement date recorded")
+
+	// Step 5: Audit trail
+	t.Log("Step 5: Checking the audit trail...")
+	historyResp, err := testutils.MakeRequest("GET", baseURL+"/api/v1/loans/"+loanID+"/history", nil)
+	require.NoError(t, err)
+	defer historyResp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, historyResp.StatusCode)
+
+	var historyResponse struct {
+		Data dto.LoanHistoryResponse `json:"data"`
+	}
+	err = json.NewDecoder(historyResp.Body).Decode(&historyResponse)
+	require.NoError(t, err)
+
+	events := historyResponse.Data.Events
+	require.Len(t, events, 4)
+	expected := []struct {
+		action string
+		to     domain.LoanStatus
+		actor  string
+	}{
+		{"create", domain.StatusProposed, "borrower_001"},
+		{"approve", domain.StatusApproved, "validator_001"},
+		{"invest", domain.StatusInvested, "investor_001"},
+		{"disburse", domain.StatusDisbursed, "officer_001"},
+	}
+	for i, want := range expected {
+		assert.Equal(t, want.action, events[i].Action)
+		assert.Equal(t, want.to, events[i].ToStatus)
+		assert.Equal(t, want.actor, events[i].ActorID)
+	}
+
+	t.Log("✓ Every transition recorded with its actor")
 	t.Log("=== Complete loan lifecycle test passed ===")
 }
 
@@ -147,9 +186,9 @@ func TestMultipleInvestorScenario(t *testing.T) {
 	t.Log("Step 1: Creating loan application...")
 	createReq := dto.CreateLoanRequest{
 		BorrowerID:      "borrower_002",
-		PrincipalAmount: 100000.00,
-		Rate:            6.0,
-		ROI:             8.5,
+		PrincipalAmount: domain.NewMoney(100000.00),
+		Rate:            8.5,
+		ROI:             6.0,
 	}
 
 	createResp, err := testutils.MakeRequest("POST", baseURL+"/api/v1/loans/", createReq)
@@ -166,7 +205,7 @@ func TestMultipleInvestorScenario(t *testing.T) {
 	// Step 2: Approve loan
 	t.Log("Step 2: Approving loan...")
 	approveReq := dto.ApproveLoanRequest{
-		FieldValidatorProof: "https://example.com/field-validation/proof_456.png",
+		FieldValidatorProof: domain.ProofLinks{"https://example.com/field-validation/proof_456.png"},
 		FieldValidatorID:    "validator_002",
 	}
 
@@ -180,7 +219,7 @@ func TestMultipleInvestorScenario(t *testing.T) {
 	// First investor: 40% of loan
 	investReq1 := dto.InvestLoanRequest{
 		InvestorID: "investor_001",
-		Amount:     40000.00,
+		Amount:     domain.NewMoney(40000.00),
 	}
 
 	investResp1, err := testutils.MakeRequest("PUT", baseURL+"/api/v1/loans/"+loanID+"/invest", investReq1)
@@ -202,7 +241,7 @@ func TestMultipleInvestorScenario(t *testing.T) {
 	// Second investor: 35% of loan
 	investReq2 := dto.InvestLoanRequest{
 		InvestorID: "investor_002",
-		Amount:     35000.00,
+		Amount:     domain.NewMoney(35000.00),
 	}
 
 	investResp2, err := testutils.MakeRequest("PUT", baseURL+"/api/v1/loans/"+loanID+"/invest", investReq2)
@@ -224,7 +263,7 @@ func TestMultipleInvestorScenario(t *testing.T) {
 	// Third investor: 25% of loan (completes the investment)
 	investReq3 := dto.InvestLoanRequest{
 		InvestorID: "investor_003",
-		Amount:     25000.00,
+		Amount:     domain.NewMoney(25000.00),
 	}
 
 	investResp3, err := testutils.MakeRequest("PUT", baseURL+"/api/v1/loans/"+loanID+"/invest", investReq3)
@@ -267,6 +306,11 @@ func TestHealthCheckEndpoint(t *testing.T) {
 	assert.Equal(t, "ok", response["status"])
 	assert.Equal(t, "loan-service", response["service"])
 
+	ready, err := testutils.MakeRequest("GET", setup.Server.URL+"/health/ready", nil)
+	require.NoError(t, err)
+	defer ready.Body.Close()
+	assert.Equal(t, http.StatusOK, ready.StatusCode)
+
 	t.Log("✓ Health check endpoint responding correctly")
 }
 
@@ -285,9 +329,9 @@ func TestInputValidationScenarios(t *testing.T) {
 
 		t.Run("Missing required fields", func(t *testing.T) {
 			createReq := dto.CreateLoanRequest{
-				PrincipalAmount: 25000.00,
-				Rate:            4.5,
-				ROI:             6.0,
+				PrincipalAmount: domain.NewMoney(25000.00),
+				Rate:            6.0,
+				ROI:             4.5,
 				// Missing BorrowerID
 			}
 
@@ -303,8 +347,8 @@ func TestInputValidationScenarios(t *testing.T) {
 			createReq := dto.CreateLoanRequest{
 				BorrowerID:      "borrower_001",
 				PrincipalAmount: 0, // Invalid: must be greater than 0
-				Rate:            4.5,
-				ROI:             6.0,
+				Rate:            6.0,
+				ROI:             4.5,
 			}
 
 			resp, err := testutils.MakeRequest("POST", baseURL+"/api/v1/loans/", createReq)
@@ -318,7 +362,7 @@ func TestInputValidationScenarios(t *testing.T) {
 		t.Run("Negative interest rate", func(t *testing.T) {
 			createReq := dto.CreateLoanRequest{
 				BorrowerID:      "borrower_001",
-				PrincipalAmount: 25000.00,
+				PrincipalAmount: domain.NewMoney(25000.00),
 				Rate:            -1.0, // Invalid: must be greater than 0
 				ROI:             6.0,
 			}
@@ -338,9 +382,9 @@ func TestInputValidationScenarios(t *testing.T) {
 		// Create a loan for approval tests
 		createReq := dto.CreateLoanRequest{
 			BorrowerID:      "borrower_001",
-			PrincipalAmount: 25000.00,
-			Rate:            4.5,
-			ROI:             6.0,
+			PrincipalAmount: domain.NewMoney(25000.00),
+			Rate:            6.0,
+			ROI:             4.5,
 		}
 
 		createResp, err := testutils.MakeRequest("POST", baseURL+"/api/v1/loans/", createReq)
@@ -370,7 +414,7 @@ func TestInputValidationScenarios(t *testing.T) {
 
 		t.Run("Invalid image link format", func(t *testing.T) {
 			approveReq := dto.ApproveLoanRequest{
-				FieldValidatorProof: "https://example.com/document.pdf", // Invalid: not an image
+				FieldValidatorProof: domain.ProofLinks{"https://example.com/document.pdf"}, // Invalid: not an image
 				FieldValidatorID:    "validator_001",
 			}
 
@@ -390,7 +434,7 @@ func TestInputValidationScenarios(t *testing.T) {
 
 		t.Run("Valid image link and approval date verification", func(t *testing.T) {
 			approveReq := dto.ApproveLoanRequest{
-				FieldValidatorProof: "https://example.com/field-validation/proof_789.jpg", // Valid image
+				FieldValidatorProof: domain.ProofLinks{"https://example.com/field-validation/proof_789.jpg"}, // Valid image
 				FieldValidatorID:    "validator_003",
 			}
 
@@ -407,7 +451,7 @@ func TestInputValidationScenarios(t *testing.T) {
 			approvedLoan := approveResponse.Data.(map[string]interface{})
 			approvalDetails := approvedLoan["approval_details"].(map[string]interface{})
 			assert.NotEmpty(t, approvalDetails["approval_date"])
-			assert.Equal(t, "https://example.com/field-validation/proof_789.jpg", approvalDetails["field_validator_proof"])
+			assert.Equal(t, []interface{}{"https://example.com/field-validation/proof_789.jpg"}, approvalDetails["field_validator_proof"])
 
 			t.Log("✓ Correctly approved loan with valid image link and recorded approval date")
 		})
@@ -419,9 +463,9 @@ func TestInputValidationScenarios(t *testing.T) {
 		// Create and approve a loan for investment tests
 		createReq := dto.CreateLoanRequest{
 			BorrowerID:      "borrower_002",
-			PrincipalAmount: 30000.00,
-			Rate:            4.5,
-			ROI:             6.0,
+			PrincipalAmount: domain.NewMoney(30000.00),
+			Rate:            6.0,
+			ROI:             4.5,
 		}
 
 		createResp, err := testutils.MakeRequest("POST", baseURL+"/api/v1/loans/", createReq)
@@ -437,7 +481,7 @@ func TestInputValidationScenarios(t *testing.T) {
 
 		// Approve the loan
 		approveReq := dto.ApproveLoanRequest{
-			FieldValidatorProof: "https://example.com/field-validation/proof_999.png",
+			FieldValidatorProof: domain.ProofLinks{"https://example.com/field-validation/proof_999.png"},
 			FieldValidatorID:    "validator_004",
 		}
 
@@ -447,7 +491,7 @@ func TestInputValidationScenarios(t *testing.T) {
 
 		t.Run("Missing investor ID", func(t *testing.T) {
 			investReq := dto.InvestLoanRequest{
-				Amount: 30000.00,
+				Amount: domain.NewMoney(30000.00),
 				// Missing InvestorID
 			}
 
@@ -476,7 +520,7 @@ func TestInputValidationScenarios(t *testing.T) {
 		t.Run("Investment exceeding principal amount", func(t *testing.T) {
 			investReq := dto.InvestLoanRequest{
 				InvestorID: "investor_001",
-				Amount:     35000.00, // Exceeds principal amount of 30000
+				Amount:     domain.NewMoney(35000.00), // Exceeds principal amount of 30000
 			}
 
 			resp, err := testutils.MakeRequest("PUT", baseURL+"/api/v1/loans/"+loanID+"/invest", investReq)
@@ -494,9 +538,9 @@ func TestInputValidationScenarios(t *testing.T) {
 		// Create, approve, and invest in a loan for disbursement tests
 		createReq := dto.CreateLoanRequest{
 			BorrowerID:      "borrower_003",
-			PrincipalAmount: 20000.00,
-			Rate:            4.5,
-			ROI:             6.0,
+			PrincipalAmount: domain.NewMoney(20000.00),
+			Rate:            6.0,
+			ROI:             4.5,
 		}
 
 		createResp, err := testutils.MakeRequest("POST", baseURL+"/api/v1/loans/", createReq)
@@ -512,7 +556,7 @@ func TestInputValidationScenarios(t *testing.T) {
 
 		// Approve the loan
 		approveReq := dto.ApproveLoanRequest{
-			FieldValidatorProof: "https://example.com/field-validation/proof_888.jpg",
+			FieldValidatorProof: domain.ProofLinks{"https://example.com/field-validation/proof_888.jpg"},
 			FieldValidatorID:    "validator_005",
 		}
 
@@ -523,7 +567,7 @@ func TestInputValidationScenarios(t *testing.T) {
 		// Invest in the loan
 		investReq := dto.InvestLoanRequest{
 			InvestorID: "investor_001",
-			Amount:     20000.00,
+			Amount:     domain.NewMoney(20000.00),
 		}
 
 		investResp, err := testutils.MakeRequest("PUT", baseURL+"/api/v1/loans/"+loanID+"/invest", investReq)
@@ -579,9 +623,9 @@ func TestBusinessRuleEnforcement(t *testing.T) {
 			// Create a loan without approving
 			createReq := dto.CreateLoanRequest{
 				BorrowerID:      "borrower_004",
-				PrincipalAmount: 15000.00,
-				Rate:            4.5,
-				ROI:             6.0,
+				PrincipalAmount: domain.NewMoney(15000.00),
+				Rate:            6.0,
+				ROI:             4.5,
 			}
 
 			createResp, err := testutils.MakeRequest("POST", baseURL+"/api/v1/loans/", createReq)
@@ -598,7 +642,7 @@ func TestBusinessRuleEnforcement(t *testing.T) {
 			// Try to invest in proposed loan
 			investReq := dto.InvestLoanRequest{
 				InvestorID: "investor_001",
-				Amount:     15000.00,
+				Amount:     domain.NewMoney(15000.00),
 			}
 
 			resp, err := testutils.MakeRequest("PUT", baseURL+"/api/v1/loans/"+loanID+"/invest", investReq)
@@ -613,9 +657,9 @@ func TestBusinessRuleEnforcement(t *testing.T) {
 			// Create and approve a loan
 			createReq := dto.CreateLoanRequest{
 				BorrowerID:      "borrower_005",
-				PrincipalAmount: 25000.00,
-				Rate:            4.5,
-				ROI:             6.0,
+				PrincipalAmount: domain.NewMoney(25000.00),
+				Rate:            6.0,
+				ROI:             4.5,
 			}
 
 			createResp, err := testutils.MakeRequest("POST", baseURL+"/api/v1/loans/", createReq)
@@ -631,7 +675,7 @@ func TestBusinessRuleEnforcement(t *testing.T) {
 
 			// Approve the loan
 			approveReq := dto.ApproveLoanRequest{
-				FieldValidatorProof: "https://example.com/field-validation/proof_777.png",
+				FieldValidatorProof: domain.ProofLinks{"https://example.com/field-validation/proof_777.png"},
 				FieldValidatorID:    "validator_006",
 			}
 
@@ -642,7 +686,7 @@ func TestBusinessRuleEnforcement(t *testing.T) {
 			// Invest partially
 			investReq := dto.InvestLoanRequest{
 				InvestorID: "investor_001",
-				Amount:     15000.00, // Only 60% of principal
+				Amount:     domain.NewMoney(15000.00), // Only 60% of principal
 			}
 
 			investResp, err := testutils.MakeRequest("PUT", baseURL+"/api/v1/loans/"+loanID+"/invest", investReq)
@@ -667,9 +711,9 @@ func TestBusinessRuleEnforcement(t *testing.T) {
 			// Create a loan
 			createReq := dto.CreateLoanRequest{
 				BorrowerID:      "borrower_006",
-				PrincipalAmount: 10000.00,
-				Rate:            4.5,
-				ROI:             6.0,
+				PrincipalAmount: domain.NewMoney(10000.00),
+				Rate:            6.0,
+				ROI:             4.5,
 			}
 
 			createResp, err := testutils.MakeRequest("POST", baseURL+"/api/v1/loans/", createReq)
@@ -685,7 +729,7 @@ func TestBusinessRuleEnforcement(t *testing.T) {
 
 			// First approval
 			approveReq := dto.ApproveLoanRequest{
-				FieldValidatorProof: "https://example.com/field-validation/proof_666.jpg",
+				FieldValidatorProof: domain.ProofLinks{"https://example.com/field-validation/proof_666.jpg"},
 				FieldValidatorID:    "validator_007",
 			}
 
@@ -700,7 +744,7 @@ func TestBusinessRuleEnforcement(t *testing.T) {
 			require.NoError(t, err)
 			defer approveResp2.Body.Close()
 
-			assert.Equal(t, http.StatusBadRequest, approveResp2.StatusCode)
+			assert.Equal(t, http.StatusConflict, approveResp2.StatusCode)
 			t.Log("✓ Correctly enforced rule: cannot approve already approved loan")
 		})
 	})
@@ -711,9 +755,9 @@ func TestBusinessRuleEnforcement(t *testing.T) {
 		// Create and approve a loan
 		createReq := dto.CreateLoanRequest{
 			BorrowerID:      "borrower_007",
-			PrincipalAmount: 50000.00,
-			Rate:            4.5,
-			ROI:             6.0,
+			PrincipalAmount: domain.NewMoney(50000.00),
+			Rate:            6.0,
+			ROI:             4.5,
 		}
 
 		createResp, err := testutils.MakeRequest("POST", baseURL+"/api/v1/loans/", createReq)
@@ -729,7 +773,7 @@ func TestBusinessRuleEnforcement(t *testing.T) {
 
 		// Approve the loan
 		approveReq := dto.ApproveLoanRequest{
-			FieldValidatorProof: "https://example.com/field-validation/proof_555.png",
+			FieldValidatorProof: domain.ProofLinks{"https://example.com/field-validation/proof_555.png"},
 			FieldValidatorID:    "validator_008",
 		}
 
@@ -740,7 +784,7 @@ func TestBusinessRuleEnforcement(t *testing.T) {
 		// First investment: 30000
 		investReq1 := dto.InvestLoanRequest{
 			InvestorID: "investor_001",
-			Amount:     30000.00,
+			Amount:     domain.NewMoney(30000.00),
 		}
 
 		investResp1, err := testutils.MakeRequest("PUT", baseURL+"/api/v1/loans/"+loanID+"/invest", investReq1)
@@ -752,7 +796,7 @@ func TestBusinessRuleEnforcement(t *testing.T) {
 		// Second investment: 25000 (total would be 55000, exceeding 50000)
 		investReq2 := dto.InvestLoanRequest{
 			InvestorID: "investor_002",
-			Amount:     25000.00,
+			Amount:     domain.NewMoney(25000.00),
 		}
 
 		investResp2, err := testutils.MakeRequest("PUT", baseURL+"/api/v1/loans/"+loanID+"/invest", investReq2)
@@ -766,6 +810,88 @@ func TestBusinessRuleEnforcement(t *testing.T) {
 	t.Log("=== Business rule enforcement test completed ===")
 }
 
+func TestIdempotentInvestment(t *testing.T) {
+	setup := testutils.SetupTestServer()
+	defer setup.Server.Close()
+
+	baseURL := setup.Server.URL
+
+	// createApprovedLoan creates and approves a loan, returning its ID
+	createApprovedLoan := func(borrowerID string) string {
+		createResp, err := testutils.MakeRequest("POST", baseURL+"/api/v1/loans/", dto.CreateLoanRequest{
+			BorrowerID:      borrowerID,
+			PrincipalAmount: domain.NewMoney(10000.00),
+			Rate:            6.0,
+			ROI:             4.5,
+		})
+		require.NoError(t, err)
+		defer createResp.Body.Close()
+
+		var createResponse dto.SuccessResponse
+		require.NoError(t, json.NewDecoder(createResp.Body).Decode(&createResponse))
+		loanID := createResponse.Data.(map[string]interface{})["id"].(string)
+
+		approveResp, err := testutils.MakeRequest("PUT", baseURL+"/api/v1/loans/"+loanID+"/approve", dto.ApproveLoanRequest{
+			FieldValidatorProof: domain.ProofLinks{"https://example.com/proof.jpg"},
+			FieldValidatorID:    "validator_001",
+		})
+		require.NoError(t, err)
+		defer approveResp.Body.Close()
+		require.Equal(t, http.StatusOK, approveResp.StatusCode)
+
+		return loanID
+	}
+
+	// invest sends an investment with the given idempotency key and returns the response and its body
+	invest := func(loanID, key string, amount float64) (*http.Response, []byte) {
+		resp, err := testutils.MakeRequestWithHeaders("PUT", baseURL+"/api/v1/loans/"+loanID+"/invest",
+			map[string]string{middleware.IdempotencyKeyHeader: key},
+			dto.InvestLoanRequest{InvestorID: "investor_001", Amount: domain.NewMoney(amount)})
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, body
+	}
+
+	// investmentCount returns the number of investments recorded on a loan
+	investmentCount := func(loanID string) int64 {
+		var count int64
+		require.NoError(t, setup.DB.Model(&domain.Investment{}).Where("loan_id = ?", loanID).Count(&count).Error)
+		return count
+	}
+
+	loanID := createApprovedLoan("borrower_020")
+
+	first, firstBody := invest(loanID, "invest-1", 4000.00)
+	assert.Equal(t, http.StatusOK, first.StatusCode)
+	assert.Empty(t, first.Header.Get(middleware.IdempotentReplayedHeader))
+
+	// A retry with the same key replays the first response without investing again
+	retry, retryBody := invest(loanID, "invest-1", 4000.00)
+	assert.Equal(t, http.StatusOK, retry.StatusCode)
+	assert.Equal(t, "true", retry.Header.Get(middleware.IdempotentReplayedHeader))
+	assert.JSONEq(t, string(firstBody), string(retryBody))
+	assert.Equal(t, int64(1), investmentCount(loanID))
+
+	// A new key is a new investment
+	second, _ := invest(loanID, "invest-2", 1000.00)
+	assert.Equal(t, http.StatusOK, second.StatusCode)
+	assert.Equal(t, int64(2), investmentCount(loanID))
+
+	// Keys are scoped to the loan
+	otherLoanID := createApprovedLoan("borrower_021")
+	other, _ := invest(otherLoanID, "invest-1", 4000.00)
+	assert.Equal(t, http.StatusOK, other.StatusCode)
+	assert.Empty(t, other.Header.Get(middleware.IdempotentReplayedHeader))
+	assert.Equal(t, int64(1), investmentCount(otherLoanID))
+
+	var loan domain.Loan
+	require.NoError(t, setup.DB.First(&loan, "id = ?", loanID).Error)
+	assert.Equal(t, domain.NewMoney(5000.00), loan.TotalInvested)
+}
+
 // ========== ERROR HANDLING TESTS ==========
 
 func TestErrorHandlingScenarios(t *testing.T) {
@@ -781,7 +907,7 @@ func TestErrorHandlingScenarios(t *testing.T) {
 
 		t.Run("Approve non-existent loan", func(t *testing.T) {
 			approveReq := dto.ApproveLoanRequest{
-				FieldValidatorProof: "https://example.com/field-validation/proof_444.jpg",
+				FieldValidatorProof: domain.ProofLinks{"https://example.com/field-validation/proof_444.jpg"},
 				FieldValidatorID:    "validator_009",
 			}
 
@@ -789,21 +915,21 @@ func TestErrorHandlingScenarios(t *testing.T) {
 			require.NoError(t, err)
 			defer resp.Body.Close()
 
-			assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+			assert.Equal(t, http.StatusNotFound, resp.StatusCode)
 			t.Log("✓ Correctly handled attempt to approve non-existent loan")
 		})
 
 		t.Run("Invest in non-existent loan", func(t *testing.T) {
 			investReq := dto.InvestLoanRequest{
 				InvestorID: "investor_001",
-				Amount:     10000.00,
+				Amount:     domain.NewMoney(10000.00),
 			}
 
 			resp, err := testutils.MakeRequest("PUT", baseURL+"/api/v1/loans/non-existent-id/invest", investReq)
 			require.NoError(t, err)
 			defer resp.Body.Close()
 
-			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			assert.Equal(t, http.StatusNotFound, resp.StatusCode)
 			t.Log("✓ Correctly handled attempt to invest in non-existent loan")
 		})
 
@@ -817,7 +943,7 @@ func TestErrorHandlingScenarios(t *testing.T) {
 			require.NoError(t, err)
 			defer resp.Body.Close()
 
-			assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+			assert.Equal(t, http.StatusNotFound, resp.StatusCode)
 			t.Log("✓ Correctly handled attempt to disburse non-existent loan")
 		})
 	})
@@ -828,9 +954,9 @@ func TestErrorHandlingScenarios(t *testing.T) {
 		// Create, approve, invest, and disburse a loan
 		createReq := dto.CreateLoanRequest{
 			BorrowerID:      "borrower_008",
-			PrincipalAmount: 20000.00,
-			Rate:            4.5,
-			ROI:             6.0,
+			PrincipalAmount: domain.NewMoney(20000.00),
+			Rate:            6.0,
+			ROI:             4.5,
 		}
 
 		createResp, err := testutils.MakeRequest("POST", baseURL+"/api/v1/loans/", createReq)
@@ -846,7 +972,7 @@ func TestErrorHandlingScenarios(t *testing.T) {
 
 		// Approve
 		approveReq := dto.ApproveLoanRequest{
-			FieldValidatorProof: "https://example.com/field-validation/proof_333.jpg",
+			FieldValidatorProof: domain.ProofLinks{"https://example.com/field-validation/proof_333.jpg"},
 			FieldValidatorID:    "validator_010",
 		}
 
@@ -857,7 +983,7 @@ func TestErrorHandlingScenarios(t *testing.T) {
 		// Invest
 		investReq := dto.InvestLoanRequest{
 			InvestorID: "investor_001",
-			Amount:     20000.00,
+			Amount:     domain.NewMoney(20000.00),
 		}
 
 		investResp, err := testutils.MakeRequest("PUT", baseURL+"/api/v1/loans/"+loanID+"/invest", investReq)
@@ -887,3 +1013,290 @@ func TestErrorHandlingScenarios(t *testing.T) {
 
 	t.Log("=== Error handling scenarios test completed ===")
 }
+
+// ========== AUTHENTICATION TESTS ==========
+
+func TestRoleBasedAccess(t *testing.T) {
+	cfg := testutils.TestConfig()
+	cfg.Auth.JWTSecret = "integration-secret"
+	setup := testutils.SetupTestServerWithConfig(cfg)
+	defer setup.Server.Close()
+	defer middleware.SetJWTSecret("")
+
+	baseURL := setup.Server.URL
+
+	roles := []string{middleware.RoleBorrower, middleware.RoleValidator, middleware.RoleInvestor, middleware.RoleOfficer, middleware.RoleAdmin}
+	tokens := map[string]string{}
+	for _, role := range roles {
+		token, err := middleware.SignToken(middleware.Claims{Subject: role + "_001", Role: role}, []byte(cfg.Auth.JWTSecret))
+		require.NoError(t, err)
+		tokens[role] = token
+	}
+
+	// request sends an authenticated request and returns the status code and decoded body
+	request := func(method, path, role string, body interface{}) (int, map[string]interface{}) {
+		resp, err := testutils.MakeRequestWithToken(method, baseURL+path, tokens[role], body)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var decoded map[string]interface{}
+		_ = json.NewDecoder(resp.Body).Decode(&decoded)
+		return resp.StatusCode, decoded
+	}
+
+	// assertOnlyRole checks every role other than the allowed one is forbidden, then performs the request as the allowed role
+	assertOnlyRole := func(method, path, allowed string, body interface{}, want int) map[string]interface{} {
+		for _, role := range roles {
+			if role == allowed {
+				continue
+			}
+			status, decoded := request(method, path, role, body)
+			assert.Equal(t, http.StatusForbidden, status, "%s %s as %s", method, path, role)
+			assert.Equal(t, dto.CodeForbidden, decoded["code"])
+		}
+
+		status, decoded := request(method, path, allowed, body)
+		require.Equal(t, want, status, "%s %s as %s: %v", method, path, allowed, decoded)
+		return decoded
+	}
+
+	t.Run("Missing or invalid token", func(t *testing.T) {
+		resp, err := testutils.MakeRequest("GET", baseURL+"/api/v1/loans/", nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		forged, err := middleware.SignToken(middleware.Claims{Subject: "intruder", Role: middleware.RoleOfficer}, []byte("wrong-secret"))
+		require.NoError(t, err)
+		resp, err = testutils.MakeRequestWithToken("GET", baseURL+"/api/v1/loans/", forged, nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		// The health check stays public
+		resp, err = testutils.MakeRequest("GET", baseURL+"/health", nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("Lifecycle actions require their role", func(t *testing.T) {
+		createReq := dto.CreateLoanRequest{
+			BorrowerID:      "borrower_001",
+			PrincipalAmount: domain.NewMoney(10000),
+			Rate:            7.2,
+			ROI:             5.5,
+		}
+		created := assertOnlyRole("POST", "/api/v1/loans/", middleware.RoleBorrower, createReq, http.StatusCreated)
+		loanID := created["data"].(map[string]interface{})["id"].(string)
+
+		assertOnlyRole("PUT", "/api/v1/loans/"+loanID+"/approve", middleware.RoleValidator, dto.ApproveLoanRequest{
+			FieldValidatorProof: domain.ProofLinks{"https://example.com/proof.jpg"},
+			FieldValidatorID:    "validator_001",
+		}, http.StatusOK)
+
+		assertOnlyRole("PUT", "/api/v1/loans/"+loanID+"/invest", middleware.RoleInvestor, dto.InvestLoanRequest{
+			InvestorID: "investor_001",
+			Amount:     domain.NewMoney(10000),
+		}, http.StatusOK)
+
+		assertOnlyRole("PUT", "/api/v1/loans/"+loanID+"/disburse", middleware.RoleOfficer, dto.DisburseLoanRequest{
+			SignedAgreementLink: "https://example.com/signed.pdf",
+			FieldOfficerID:      "officer_001",
+		}, http.StatusOK)
+
+		assertOnlyRole("POST", "/api/v1/loans/"+loanID+"/repayments", middleware.RoleOfficer, dto.RecordRepaymentRequest{
+			Amount: domain.NewMoney(1000),
+		}, http.StatusCreated)
+
+		// Retried repayments with the same idempotency key are only recorded once
+		repay := func() *http.Response {
+			resp, err := testutils.MakeRequestWithHeaders("POST", baseURL+"/api/v1/loans/"+loanID+"/repayments", map[string]string{
+				"Authorization":   "Bearer " + tokens[middleware.RoleOfficer],
+				"Idempotency-Key": "repayment-001",
+			}, dto.RecordRepaymentRequest{Amount: domain.NewMoney(500)})
+			require.NoError(t, err)
+			return resp
+		}
+		first := repay()
+		first.Body.Close()
+		require.Equal(t, http.StatusCreated, first.StatusCode)
+		replayed := repay()
+		replayed.Body.Close()
+		assert.Equal(t, http.StatusCreated, replayed.StatusCode)
+		assert.Equal(t, "true", replayed.Header.Get("Idempotent-Replayed"))
+
+		status, full := request("GET", "/api/v1/loans/"+loanID+"/full", middleware.RoleOfficer, nil)
+		require.Equal(t, http.StatusOK, status)
+		summary := full["data"].(map[string]interface{})["repayment_summary"].(map[string]interface{})
+		assert.Equal(t, 1500.0, summary["total_repaid"])
+
+		// Reads are open to every authenticated role
+		for _, role := range roles {
+			status, _ := request("GET", "/api/v1/loans/"+loanID, role, nil)
+			assert.Equal(t, http.StatusOK, status, role)
+		}
+	})
+
+	t.Run("Rejection requires validator", func(t *testing.T) {
+		created := assertOnlyRole("POST", "/api/v1/loans/", middleware.RoleBorrower, dto.CreateLoanRequest{
+			BorrowerID:      "borrower_002",
+			PrincipalAmount: domain.NewMoney(10000),
+			Rate:            7.2,
+			ROI:             5.5,
+		}, http.StatusCreated)
+		loanID := created["data"].(map[string]interface{})["id"].(string)
+
+		assertOnlyRole("PUT", "/api/v1/loans/"+loanID+"/reject", middleware.RoleValidator, dto.RejectLoanRequest{
+			Reason:           "Insufficient documentation",
+			FieldValidatorID: "validator_001",
+		}, http.StatusOK)
+	})
+	t.Run("Forced transitions require admin", func(t *testing.T) {
+		created := assertOnlyRole("POST", "/api/v1/loans/", middleware.RoleBorrower, dto.CreateLoanRequest{
+			BorrowerID:      "borrower_003",
+			PrincipalAmount: domain.NewMoney(10000),
+			Rate:            7.2,
+			ROI:             5.5,
+		}, http.StatusCreated)
+		loanID := created["data"].(map[string]interface{})["id"].(string)
+
+		forced := assertOnlyRole("POST", "/api/v1/admin/loans/"+loanID+"/transition", middleware.RoleAdmin, dto.ForceTransitionRequest{
+			Status:        domain.StatusUnderReview,
+			Justification: "flagged by compliance",
+		}, http.StatusOK)
+		assert.Equal(t, "under_review", forced["data"].(map[string]interface{})["status"])
+	})
+
+	t.Run("Revoking an approval requires admin", func(t *testing.T) {
+		created := assertOnlyRole("POST", "/api/v1/loans/", middleware.RoleBorrower, dto.CreateLoanRequest{
+			BorrowerID:      "borrower_004",
+			PrincipalAmount: domain.NewMoney(10000),
+			Rate:            7.2,
+			ROI:             5.5,
+		}, http.StatusCreated)
+		loanID := created["data"].(map[string]interface{})["id"].(string)
+
+		assertOnlyRole("PUT", "/api/v1/loans/"+loanID+"/approve", middleware.RoleValidator, dto.ApproveLoanRequest{
+			FieldValidatorProof: domain.ProofLinks{"https://example.com/proof-revoked.jpg"},
+			FieldValidatorID:    "validator_001",
+		}, http.StatusOK)
+
+		revoked := assertOnlyRole("POST", "/api/v1/loans/"+loanID+"/revoke-approval", middleware.RoleAdmin, dto.RevokeApprovalRequest{
+			Reason: "proof photos belong to another borrower",
+		}, http.StatusOK)
+		assert.Equal(t, "proposed", revoked["data"].(map[string]interface{})["status"])
+	})
+
+	t.Run("Investor compliance status requires admin", func(t *testing.T) {
+		deactivated := assertOnlyRole("PUT", "/api/v1/investors/investor_009/deactivate", middleware.RoleAdmin, dto.DeactivateInvestorRequest{
+			Reason: "KYC documents expired",
+		}, http.StatusOK)
+		assert.Equal(t, "inactive", deactivated["data"].(map[string]interface{})["status"])
+
+		activated := assertOnlyRole("PUT", "/api/v1/investors/investor_009/activate", middleware.RoleAdmin, nil, http.StatusOK)
+		assert.Equal(t, "active", activated["data"].(map[string]interface{})["status"])
+	})
+
+	t.Run("Reconciling a loan requires admin", func(t *testing.T) {
+		created := assertOnlyRole("POST", "/api/v1/loans/", middleware.RoleBorrower, dto.CreateLoanRequest{
+			BorrowerID:      "borrower_006",
+			PrincipalAmount: domain.NewMoney(10000),
+			Rate:            7.2,
+			ROI:             5.5,
+		}, http.StatusCreated)
+		loanID := created["data"].(map[string]interface{})["id"].(string)
+
+		assertOnlyRole("POST", "/api/v1/loans/"+loanID+"/reconcile", middleware.RoleAdmin, nil, http.StatusOK)
+	})
+
+	t.Run("Webhook subscriptions require admin", func(t *testing.T) {
+		created := assertOnlyRole("POST", "/api/v1/webhooks", middleware.RoleAdmin, dto.CreateWebhookRequest{
+			URL: "https://example.com/hooks/loans",
+		}, http.StatusCreated)
+		subscriptionID := created["data"].(map[string]interface{})["id"].(string)
+
+		listed := assertOnlyRole("GET", "/api/v1/webhooks", middleware.RoleAdmin, nil, http.StatusOK)
+		assert.Len(t, listed["data"], 1)
+
+		assertOnlyRole("DELETE", "/api/v1/webhooks/"+subscriptionID, middleware.RoleAdmin, nil, http.StatusOK)
+	})
+
+	t.Run("Investors only withdraw their own investments", func(t *testing.T) {
+		created := assertOnlyRole("POST", "/api/v1/loans/", middleware.RoleBorrower, dto.CreateLoanRequest{
+			BorrowerID:      "borrower_005",
+			PrincipalAmount: domain.NewMoney(10000),
+			Rate:            7.2,
+			ROI:             5.5,
+		}, http.StatusCreated)
+		loanID := created["data"].(map[string]interface{})["id"].(string)
+
+		status, _ := request("PUT", "/api/v1/loans/"+loanID+"/approve", middleware.RoleValidator, dto.ApproveLoanRequest{
+			FieldValidatorProof: domain.ProofLinks{"https://example.com/proof-withdraw.jpg"},
+			FieldValidatorID:    "validator_001",
+		})
+		require.Equal(t, http.StatusOK, status)
+		status, invested := request("PUT", "/api/v1/loans/"+loanID+"/invest", middleware.RoleInvestor, dto.InvestLoanRequest{
+			InvestorID: "investor_001",
+			Amount:     domain.NewMoney(4000),
+		})
+		require.Equal(t, http.StatusOK, status)
+		investments := invested["data"].(map[string]interface{})["investments"].([]interface{})
+		path := "/api/v1/loans/" + loanID + "/investments/" + investments[0].(map[string]interface{})["id"].(string)
+
+		other, err := middleware.SignToken(middleware.Claims{Subject: "investor_002", Role: middleware.RoleInvestor}, []byte(cfg.Auth.JWTSecret))
+		require.NoError(t, err)
+		resp, err := testutils.MakeRequestWithToken("DELETE", baseURL+path, other, nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		status, _ = request("DELETE", path, middleware.RoleOfficer, nil)
+		assert.Equal(t, http.StatusForbidden, status)
+
+		status, withdrawn := request("DELETE", path, middleware.RoleInvestor, nil)
+		require.Equal(t, http.StatusOK, status)
+		assert.Len(t, withdrawn["data"].(map[string]interface{})["refunds"], 1)
+	})
+}
+
+func TestOpenAPISpec(t *testing.T) {
+	cfg := testutils.TestConfig()
+	cfg.Auth.JWTSecret = "integration-secret"
+	setup := testutils.SetupTestServerWithConfig(cfg)
+	defer setup.Server.Close()
+	defer middleware.SetJWTSecret("")
+
+	// The spec is public, so it is served without a token even when authentication is enabled
+	resp, err := testutils.MakeRequest("GET", setup.Server.URL+"/api/v1/openapi.json", nil)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("Content-Type"), "application/json")
+
+	var spec struct {
+		OpenAPI string                                `json:"openapi"`
+		Servers []struct{ URL string }                `json:"servers"`
+		Paths   map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&spec))
+	assert.True(t, strings.HasPrefix(spec.OpenAPI, "3."), "unexpected OpenAPI version %q", spec.OpenAPI)
+	require.Len(t, spec.Servers, 1)
+	assert.Equal(t, "/api/v1", spec.Servers[0].URL)
+
+	for _, path := range []string{"/loans", "/loans/{id}", "/loans/{id}/approve", "/loans/{id}/invest", "/loans/{id}/disburse", "/admin/loans/{id}/transition"} {
+		assert.Contains(t, spec.Paths, path)
+	}
+
+	// Every loan, borrower and admin route the router serves is documented under its OpenAPI path
+	pathParam := regexp.MustCompile(`:(\w+)`)
+	for _, route := range setup.Router.Routes() {
+		path := strings.TrimPrefix(route.Path, "/api/v1")
+		if path == route.Path || !(strings.HasPrefix(path, "/loans") || strings.HasPrefix(path, "/borrowers") || strings.HasPrefix(path, "/admin")) {
+			continue
+		}
+		path = pathParam.ReplaceAllString(strings.TrimSuffix(path, "/"), "{$1}")
+		assert.Contains(t, spec.Paths[path], strings.ToLower(route.Method), "%s %s is not documented", route.Method, route.Path)
+	}
+}